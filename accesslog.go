@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessLogFormat selects the layout of classic access log lines.
+type AccessLogFormat string
+
+const (
+	// AccessLogCommon is the Apache Common Log Format:
+	// host ident user [time] "request" status bytes
+	AccessLogCommon AccessLogFormat = "common"
+	// AccessLogCombined is the Apache Combined Log Format, which appends
+	// the quoted referer and user agent to the common format.
+	AccessLogCombined AccessLogFormat = "combined"
+)
+
+// accessLogTimeFormat is the timestamp layout of the Apache log formats.
+const accessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// accessLogWriter serializes access log lines to a writer, one write per line.
+type accessLogWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format AccessLogFormat
+}
+
+// WithAccessLog makes the HTTP middleware additionally write classic access log lines.
+// Lines are written to w in addition to the structured entries, for tools that
+// still parse the Apache common or combined formats.
+//
+// Parameters:
+//   - w: The dedicated destination of the access log lines
+//   - format: The line format (AccessLogCommon or AccessLogCombined)
+//
+// Example:
+//
+//	accessFile, _ := os.OpenFile("/var/log/app/access.log", os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+//	middleware := HTTPMiddleware(log, WithAccessLog(accessFile, AccessLogCombined))
+func WithAccessLog(w io.Writer, format AccessLogFormat) HTTPOption {
+	return func(c *httpConfig) {
+		c.AccessLog = &accessLogWriter{w: w, format: format}
+	}
+}
+
+// write formats and writes the access log line of a request.
+func (a *accessLogWriter) write(r *http.Request, start time.Time, status int, size int64) {
+	var b strings.Builder
+	b.Grow(256)
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && u != "" {
+		user = u
+	} else if r.URL.User != nil && r.URL.User.Username() != "" {
+		user = r.URL.User.Username()
+	}
+
+	b.WriteString(accessLogField(accessLogValue(host)))
+	b.WriteString(" - ")
+	b.WriteString(accessLogField(accessLogValue(user)))
+	b.WriteString(" [")
+	b.WriteString(start.Format(accessLogTimeFormat))
+	b.WriteString(`] "`)
+	b.WriteString(accessLogEscape(r.Method + " " + r.RequestURI + " " + r.Proto))
+	b.WriteString(`" `)
+	b.WriteString(strconv.Itoa(status))
+	b.WriteByte(' ')
+	if size > 0 {
+		b.WriteString(strconv.FormatInt(size, 10))
+	} else {
+		b.WriteByte('-')
+	}
+
+	if a.format == AccessLogCombined {
+		b.WriteString(` "`)
+		b.WriteString(accessLogEscape(accessLogValue(r.Referer())))
+		b.WriteString(`" "`)
+		b.WriteString(accessLogEscape(accessLogValue(r.UserAgent())))
+		b.WriteByte('"')
+	}
+	b.WriteByte('\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, _ = io.WriteString(a.w, b.String())
+}
+
+// accessLogValue replaces empty values with the "-" placeholder.
+func accessLogValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// accessLogField escapes an unquoted field, such as the host or the user, which
+// additionally escapes spaces so a value cannot shift the fields after it.
+func accessLogField(s string) string {
+	return accessLogEscapeBytes(s, true)
+}
+
+// accessLogEscape escapes quotes, backslashes and control characters inside quoted fields.
+func accessLogEscape(s string) string {
+	return accessLogEscapeBytes(s, false)
+}
+
+// accessLogEscapeBytes escapes quotes and backslashes with a backslash, newlines, carriage
+// returns and tabs as \n, \r and \t, and the other control characters (C0 and DEL),
+// such as ESC, as \xhh like Apache does, so a value cannot forge lines or terminal
+// sequences. With spaces set, spaces are escaped as \x20.
+func accessLogEscapeBytes(s string, spaces bool) string {
+	i := 0
+	for ; i < len(s); i++ {
+		if accessLogNeedsEscape(s[i], spaces) {
+			break
+		}
+	}
+	if i == len(s) {
+		return s
+	}
+
+	const hex = "0123456789abcdef"
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	b.WriteString(s[:i])
+	for ; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case !accessLogNeedsEscape(c, spaces):
+			b.WriteByte(c)
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteString(`\x`)
+			b.WriteByte(hex[c>>4])
+			b.WriteByte(hex[c&0xf])
+		}
+	}
+	return b.String()
+}
+
+// accessLogNeedsEscape reports whether the byte is escaped in access log fields.
+func accessLogNeedsEscape(c byte, spaces bool) bool {
+	return c < 0x20 || c == 0x7f || c == '"' || c == '\\' || (spaces && c == ' ')
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// FieldKeyComponent is the field naming the library an adapted log line comes from.
+const FieldKeyComponent = "component"
+
+// StdLogger adapts a Logger to the printf-style logger interfaces used by third-party libraries.
+// It implements sarama.StdLogger (Print, Printf, Println), kafka-go's kafka.Logger and
+// amqp091-go's amqp.Logging (Printf). Every line is logged at a fixed level with a
+// component field, and trailing newlines are trimmed.
+type StdLogger struct {
+	log       Logger
+	level     Level
+	component string
+}
+
+// NewStdLogger creates a StdLogger writing every line at the given level.
+//
+// Parameters:
+//   - log: The logger receiving the lines
+//   - level: The level of the entries
+//   - component: The value of the component field, omitted when empty
+//
+// Returns:
+//   - *StdLogger: The adapter
+//
+// Example:
+//
+//	stdlog := NewStdLogger(log, LevelWarning, "legacy-client")
+//	stdlog.Printf("retrying %s", host)
+func NewStdLogger(log Logger, level Level, component string) *StdLogger {
+	return &StdLogger{log: AddCallerSkip(log, stdLoggerCallerSkip), level: level, component: component}
+}
+
+// NewSaramaLogger creates an adapter for sarama's package-level logger.
+// Sarama only logs client internals such as rebalances and broker connections, which
+// are written at InfoLevel.
+//
+// Example:
+//
+//	sarama.Logger = NewSaramaLogger(log)
+func NewSaramaLogger(log Logger) *StdLogger {
+	return NewStdLogger(log, LevelInfo, "sarama")
+}
+
+// NewKafkaLogger creates an adapter for the Logger of kafka-go readers and writers.
+//
+// Example:
+//
+//	reader := kafka.NewReader(kafka.ReaderConfig{
+//	    Brokers:     brokers,
+//	    Logger:      NewKafkaLogger(log),
+//	    ErrorLogger: NewKafkaErrorLogger(log),
+//	})
+func NewKafkaLogger(log Logger) *StdLogger {
+	return NewStdLogger(log, LevelDebug, "kafka-go")
+}
+
+// NewKafkaErrorLogger creates an adapter for the ErrorLogger of kafka-go readers and writers.
+func NewKafkaErrorLogger(log Logger) *StdLogger {
+	return NewStdLogger(log, LevelError, "kafka-go")
+}
+
+// NewAMQPLogger creates an adapter for amqp091-go's package-level logger.
+// The library only logs unexpected conditions, which are written at WarnLevel.
+//
+// Example:
+//
+//	amqp.SetLogger(NewAMQPLogger(log))
+func NewAMQPLogger(log Logger) *StdLogger {
+	return NewStdLogger(log, LevelWarning, "amqp091")
+}
+
+// Print logs the operands formatted like fmt.Sprint.
+func (s *StdLogger) Print(v ...any) {
+	s.write(fmt.Sprint(v...))
+}
+
+// Printf logs the operands formatted like fmt.Sprintf.
+func (s *StdLogger) Printf(format string, v ...any) {
+	s.write(fmt.Sprintf(format, v...))
+}
+
+// Println logs the operands formatted like fmt.Sprintln.
+func (s *StdLogger) Println(v ...any) {
+	s.write(fmt.Sprintln(v...))
+}
+
+// stdLoggerCallerSkip is the number of frames of StdLogger between the application
+// and the Logger call: the Print method, write and logAt.
+const stdLoggerCallerSkip = 3
+
+// write logs a line at the level of the adapter.
+func (s *StdLogger) write(msg string) {
+	var fields []Field
+	if s.component != "" {
+		fields = append(fields, zap.String(FieldKeyComponent, s.component))
+	}
+	logAt(context.Background(), s.log, s.level, strings.TrimRight(msg, "\r\n"), fields...)
+}
+
+// logAt logs a message at the given level. Unknown levels are logged at InfoLevel;
+// PanicLevel and FatalLevel are logged at ErrorLevel so adapters never stop the process.
+func logAt(ctx context.Context, log Logger, level Level, msg string, fields ...Field) {
+	switch level {
+	case LevelDebug:
+		log.Debug(ctx, msg, fields...)
+	case LevelWarning:
+		log.Warn(ctx, msg, fields...)
+	case LevelError, LevelPanic, LevelFatal:
+		log.Error(ctx, msg, fields...)
+	default:
+		log.Info(ctx, msg, fields...)
+	}
+}
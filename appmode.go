@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+var (
+	appModesMu sync.RWMutex
+	appModes   = map[AppMode]func() zap.Config{}
+)
+
+// RegisterAppMode registers an application mode preset selectable with WithAppMode, so
+// platform teams can define modes such as "canary" or "cli-tool" with their own
+// defaults. The zap configuration returned by preset is the base of loggers in the mode:
+// its level, encoding, caller and stack trace settings, output paths and encoder keys
+// replace the defaults of WithDefaultConfig, while options passed to NewLogger still
+// override them. Its encoders, sampling and development flag are used as they are.
+// Registering a name again replaces its preset. Register modes at startup, before
+// creating loggers.
+//
+// Parameters:
+//   - name: The name of the mode, selected with WithAppMode
+//   - preset: Returns the zap configuration of the mode
+//
+// Returns:
+//   - error: An error if the name is empty or a built-in mode, or preset is nil
+//
+// Example:
+//
+//	_ = logger.RegisterAppMode("canary", func() zap.Config {
+//	    cfg := zap.NewProductionConfig()
+//	    cfg.Level = zap.NewAtomicLevelAt(zap.DebugLevel)
+//	    cfg.Sampling = nil
+//	    return cfg
+//	})
+//	log, err := logger.NewLogger(logger.WithAppMode("canary"))
+func RegisterAppMode(name AppMode, preset func() zap.Config) error {
+	if name == "" || name == AppModeEmpty || validAppMode[name] {
+		return fmt.Errorf("app mode %q cannot be registered", name)
+	}
+	if preset == nil {
+		return fmt.Errorf("app mode %q needs a preset", name)
+	}
+
+	appModesMu.Lock()
+	defer appModesMu.Unlock()
+	appModes[name] = preset
+	return nil
+}
+
+// appModePreset returns the preset of a registered application mode.
+func appModePreset(mode AppMode) (func() zap.Config, bool) {
+	if mode == AppModeCLI {
+		return cliConfig, true
+	}
+	appModesMu.RLock()
+	defer appModesMu.RUnlock()
+	preset, ok := appModes[mode]
+	return preset, ok
+}
+
+// applyPreset replaces the defaults of c with the settings of a preset configuration.
+func (c *config) applyPreset(preset zap.Config) {
+	if preset.Level != (zap.AtomicLevel{}) {
+		c.Level = levelName(preset.Level.Level())
+	}
+	if preset.Encoding != "" {
+		c.Encoding = Encoding(preset.Encoding)
+	}
+	if len(preset.OutputPaths) > 0 {
+		c.OutputPaths = preset.OutputPaths
+	}
+	if len(preset.ErrorOutputPaths) > 0 {
+		c.ErrorOutputPaths = preset.ErrorOutputPaths
+	}
+	c.DisableCaller = preset.DisableCaller
+	c.DisableStacktrace = preset.DisableStacktrace
+	c.TimeKey = preset.EncoderConfig.TimeKey
+	c.LevelKey = preset.EncoderConfig.LevelKey
+	c.NameKey = preset.EncoderConfig.NameKey
+	c.CallerKey = preset.EncoderConfig.CallerKey
+	c.MessageKey = preset.EncoderConfig.MessageKey
+	c.StacktraceKey = preset.EncoderConfig.StacktraceKey
+}
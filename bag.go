@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	// fieldBagKey is the context key of the field bag of a request.
+	fieldBagKey struct{}
+
+	// fieldBag holds the fields added to a request with AddField.
+	// Unlike context values, the bag is shared by the request context and every
+	// context derived from it, so fields added deep in the call stack show up in
+	// entries logged by callers, including the access log entry.
+	fieldBag struct {
+		mu     sync.Mutex
+		fields []Field
+	}
+)
+
+// ContextWithFieldBag returns a copy of the context carrying an empty field bag for
+// AddField. HTTPMiddleware, the gRPC interceptors and the Connect interceptor add one
+// to every request, so call it only for other units of work such as queue messages.
+// A context already carrying a bag is returned unchanged.
+//
+// Parameters:
+//   - ctx: The context of the unit of work
+//
+// Returns:
+//   - context.Context: The context carrying a field bag
+//
+// Example:
+//
+//	ctx = ContextWithFieldBag(ctx)
+//	handleMessage(ctx, msg)
+//	log.Info(ctx, "message handled") // includes fields added by handleMessage
+func ContextWithFieldBag(ctx context.Context) context.Context {
+	if fieldBagFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, fieldBagKey{}, &fieldBag{})
+}
+
+// AddField adds fields to the field bag of the context. Every entry logged afterwards
+// with the context or a context derived from it includes them, so deep code can enrich
+// request logs without passing loggers around. Adding a key again replaces its value.
+// Without a field bag in the context AddField does nothing and reports false.
+//
+// Parameters:
+//   - ctx: The request context carrying a field bag
+//   - fields: The fields to add
+//
+// Returns:
+//   - bool: Whether the context carries a field bag
+//
+// Example:
+//
+//	func chargeCard(ctx context.Context, order Order) error {
+//	    logger.AddField(ctx, zap.String("payment_provider", "stripe"))
+//	    ...
+//	}
+//	// the access log entry of the request now carries payment_provider
+func AddField(ctx context.Context, fields ...Field) bool {
+	bag := fieldBagFromContext(ctx)
+	if bag == nil {
+		return false
+	}
+
+	bag.mu.Lock()
+	defer bag.mu.Unlock()
+	for _, f := range fields {
+		replaced := false
+		for i := range bag.fields {
+			if bag.fields[i].Key == f.Key {
+				bag.fields[i], replaced = f, true
+				break
+			}
+		}
+		if !replaced {
+			bag.fields = append(bag.fields, f)
+		}
+	}
+	return true
+}
+
+// fieldBagFromContext returns the field bag of the context or nil.
+func fieldBagFromContext(ctx context.Context) *fieldBag {
+	if ctx == nil {
+		return nil
+	}
+	bag, _ := ctx.Value(fieldBagKey{}).(*fieldBag)
+	return bag
+}
+
+// bagFields returns a copy of the fields in the field bag of the context.
+func bagFields(ctx context.Context) []Field {
+	return appendBagFields(nil, ctx)
+}
+
+// appendBagFields appends the fields in the field bag of the context to fields.
+func appendBagFields(fields []Field, ctx context.Context) []Field {
+	bag := fieldBagFromContext(ctx)
+	if bag == nil {
+		return fields
+	}
+
+	bag.mu.Lock()
+	defer bag.mu.Unlock()
+	return append(fields, bag.fields...)
+}
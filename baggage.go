@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/baggage"
+	"go.uber.org/zap"
+)
+
+// appendBaggageFields appends the members of the OpenTelemetry baggage of the context
+// whose keys are allowed to fields, in the order of the allowlist.
+func appendBaggageFields(fields []Field, ctx context.Context, allowed []string) []Field {
+	if ctx == nil || len(allowed) == 0 {
+		return fields
+	}
+	bag := baggage.FromContext(ctx)
+	if bag.Len() == 0 {
+		return fields
+	}
+
+	for _, key := range allowed {
+		if member := bag.Member(key); member.Key() != "" {
+			fields = append(fields, zap.String(key, member.Value()))
+		}
+	}
+	return fields
+}
@@ -0,0 +1,290 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys stamped on the entries of batched sinks when a batch is sent.
+const (
+	FieldKeySentAt    = "sent_at"
+	FieldKeyClockSkew = "clock_skew_ms"
+)
+
+// Defaults of BatchConfig.
+const (
+	defaultBatchEntries  = 100
+	defaultBatchInterval = time.Second
+)
+
+const (
+	// clockSkewRefreshInterval is how often the clock skew of a batched sink is measured.
+	clockSkewRefreshInterval = time.Minute
+	// clockSkewTimeout bounds a clock skew measurement.
+	clockSkewTimeout = 5 * time.Second
+)
+
+type (
+	// ClockSkewFunc measures the offset of the clock of a collector from the local
+	// clock; positive when the collector is ahead.
+	ClockSkewFunc func(ctx context.Context) (time.Duration, error)
+
+	// BatchConfig configures the batching of an output path.
+	BatchConfig struct {
+		// MaxEntries is the number of entries sent per batch; zero uses 100.
+		MaxEntries int
+		// FlushInterval is the longest an entry waits for its batch; zero uses 1s.
+		FlushInterval time.Duration
+		// ClockSkew measures the clock skew of the collector, refreshed every minute and
+		// stamped on every entry as clock_skew_ms. Nil leaves the skew out.
+		ClockSkew ClockSkewFunc
+	}
+
+	// sinkBatch pairs an output path with its batch configuration.
+	sinkBatch struct {
+		sink  string
+		batch BatchConfig
+	}
+
+	// batchWriter buffers the entries written to a sink and writes them in batches,
+	// stamping every entry with the time the batch is sent and the clock skew of the
+	// collector.
+	batchWriter struct {
+		out     zapcore.WriteSyncer
+		config  BatchConfig
+		diag    *diagnostics
+		skew    atomic.Pointer[time.Duration]
+		skewAt  time.Time
+		stop    chan struct{}
+		done    chan struct{}
+		mu      sync.Mutex
+		entries [][]byte
+	}
+)
+
+// newSinkBatches replaces the writers of batched output paths by batch writers and
+// starts flushing them. Batch writers are shared through the sink pool, so a logger
+// derived with the same batching appends to the batches of its parent. It returns a
+// function flushing and stopping every batch writer, which must run before the sinks
+// are closed.
+func newSinkBatches(paths []string, writers []zapcore.WriteSyncer, batches []sinkBatch, cfg *config, diag *diagnostics, supervisor *supervisor) (func(), error) {
+	closers := make([]func() error, 0, len(batches))
+	closeAll := func() {
+		for _, c := range closers {
+			_ = c()
+		}
+	}
+
+	for _, b := range batches {
+		i := slices.Index(paths, b.sink)
+		if i < 0 {
+			closeAll()
+			return nil, fmt.Errorf("batching for unknown sink %q: it must be one of the output paths", b.sink)
+		}
+		key := newSinkKey(b.sink, cfg)
+		key.batch = true
+		name := "batch:" + sanitizePath(b.sink)
+		w, closeFn, _ := cfg.SinkPool.open(key, func() (zapcore.WriteSyncer, func() error, error) {
+			w := newBatchWriter(writers[i], b.batch, diag, supervisor, name)
+			return w, func() error { w.close(); return nil }, nil
+		})
+		writers[i] = w
+		closers = append(closers, closeFn)
+	}
+	return closeAll, nil
+}
+
+// newBatchWriter creates a batch writer and starts flushing it in a goroutine of the
+// supervisor, listed by GetStats under name.
+func newBatchWriter(out zapcore.WriteSyncer, config BatchConfig, diag *diagnostics, supervisor *supervisor, name string) *batchWriter {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = defaultBatchEntries
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultBatchInterval
+	}
+	w := &batchWriter{
+		out:    out,
+		config: config,
+		diag:   diag,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		defer close(w.done)
+		supervisor.supervise(name, w.stop, w.run)
+	}()
+	return w
+}
+
+// run flushes the batch every flush interval and refreshes the clock skew until the
+// writer is closed.
+func (w *batchWriter) run() {
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		w.refreshSkew()
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.flush()
+		}
+	}
+}
+
+// flush sends the batch. The lock is released even if sending panics, so the writer
+// keeps working once the supervisor restarts run.
+func (w *batchWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushLocked()
+}
+
+// refreshSkew measures the clock skew when it is due. Failed measurements keep the
+// last skew and are retried at the next refresh.
+func (w *batchWriter) refreshSkew() {
+	if w.config.ClockSkew == nil || time.Since(w.skewAt) < clockSkewRefreshInterval {
+		return
+	}
+	w.skewAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), clockSkewTimeout)
+	defer cancel()
+	skew, err := w.config.ClockSkew(ctx)
+	if err != nil {
+		return
+	}
+	w.skew.Store(&skew)
+}
+
+// Write adds the entry to the batch, sending the batch once it is full.
+func (w *batchWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.entries = append(w.entries, bytes.Clone(p))
+	if len(w.entries) >= w.config.MaxEntries {
+		w.flushLocked()
+	}
+	return len(p), nil
+}
+
+// Sync sends the batch and flushes the sink. A failed batch is reported as dropped
+// rather than returned, so it does not count as a sync error as well.
+func (w *batchWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushLocked()
+	return w.out.Sync()
+}
+
+// close stops flushing and sends the last batch.
+func (w *batchWriter) close() {
+	close(w.stop)
+	<-w.done
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushLocked()
+}
+
+// flushLocked stamps and sends the batch in a single write. A failed batch is dropped
+// and reported. It must be called with the lock held.
+func (w *batchWriter) flushLocked() {
+	if len(w.entries) == 0 {
+		return
+	}
+
+	sentAt := time.Now()
+	skew := w.skew.Load()
+	var buf bytes.Buffer
+	for _, entry := range w.entries {
+		buf.Write(stampEntry(entry, sentAt, skew))
+	}
+	count := len(w.entries)
+	w.entries = w.entries[:0]
+
+	if _, err := w.out.Write(buf.Bytes()); err != nil {
+		w.diag.report(DiagnosticDroppedBatch, err, zap.Int("batch_entries", count))
+	}
+}
+
+// stampEntry adds the send time and the clock skew, if known, to a JSON entry. Other
+// entries, such as console lines, are returned unchanged.
+func stampEntry(entry []byte, sentAt time.Time, skew *time.Duration) []byte {
+	end := bytes.LastIndexByte(entry, '}')
+	if end < 0 || !bytes.HasPrefix(bytes.TrimSpace(entry), []byte("{")) {
+		return entry
+	}
+
+	out := make([]byte, 0, len(entry)+80)
+	out = append(out, entry[:end]...)
+	if !bytes.HasSuffix(bytes.TrimSpace(out), []byte("{")) {
+		out = append(out, ',')
+	}
+	out = append(out, `"`+FieldKeySentAt+`":"`...)
+	out = sentAt.UTC().AppendFormat(out, time.RFC3339Nano)
+	out = append(out, '"')
+	if skew != nil {
+		out = append(out, `,"`+FieldKeyClockSkew+`":`...)
+		out = strconv.AppendFloat(out, float64(*skew)/float64(time.Millisecond), 'f', -1, 64)
+	}
+	return append(out, entry[end:]...)
+}
+
+// NewHTTPClockSkew returns a ClockSkewFunc measuring the clock skew of a collector
+// from the Date header of its responses to HEAD requests, relative to the midpoint
+// of each request. The Date header has a resolution of one second, so the skew is
+// accurate to about half a second plus network jitter: enough to tell hosts whose
+// clocks drifted apart from batches merely sent late.
+//
+// Parameters:
+//   - url: The collector endpoint
+//   - client: The HTTP client; nil uses a client with a 5 second timeout
+//
+// Returns:
+//   - ClockSkewFunc: The clock skew measurement
+//
+// Example:
+//
+//	logger := NewLogger(WithBatching("https://collector.example.com/logs", BatchConfig{
+//	    ClockSkew: NewHTTPClockSkew("https://collector.example.com/health", nil),
+//	}))
+func NewHTTPClockSkew(url string, client *http.Client) ClockSkewFunc {
+	if client == nil {
+		client = &http.Client{Timeout: clockSkewTimeout}
+	}
+	return func(ctx context.Context) (time.Duration, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return 0, err
+		}
+		start := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("failed to measure clock skew: %w", err)
+		}
+		end := time.Now()
+		resp.Body.Close()
+
+		date, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			return 0, fmt.Errorf("failed to measure clock skew: invalid Date header: %w", err)
+		}
+		// The Date header is truncated to the second; its midpoint is the best estimate.
+		remote := date.Add(500 * time.Millisecond)
+		local := start.Add(end.Sub(start) / 2)
+		return remote.Sub(local), nil
+	}
+}
@@ -0,0 +1,309 @@
+package logger
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys of the entries written about ingest budgets.
+const (
+	FieldKeyBudgetSink        = "budget_sink"
+	FieldKeyBudgetBytes       = "budget_bytes"
+	FieldKeyBudgetPeriod      = "budget_period"
+	FieldKeyBudgetPeriodStart = "budget_period_start"
+	FieldKeyBudgetDropped     = "budget_dropped_count"
+)
+
+// Messages of the entries written about ingest budgets.
+const (
+	budgetExceededMessage = "log ingest budget exceeded"
+	budgetSummaryMessage  = "log entries dropped by ingest budget"
+)
+
+// Defaults of IngestBudget.
+const (
+	defaultBudgetPeriod      = 24 * time.Hour
+	defaultBudgetSampleEvery = 10
+)
+
+// BudgetAction is what a sink does with entries once its ingest budget is exhausted.
+type BudgetAction int
+
+const (
+	// BudgetDegrade writes only warning and higher entries.
+	BudgetDegrade BudgetAction = iota
+	// BudgetSample writes one of every IngestBudget.SampleEvery entries, and every error.
+	BudgetSample
+	// BudgetDrop drops every entry.
+	BudgetDrop
+)
+
+type (
+	// IngestBudget limits the bytes written to a sink per period.
+	// Periods are aligned to the clock in UTC, e.g. to full hours or midnight.
+	IngestBudget struct {
+		// Bytes is the number of encoded bytes written per period before Action applies.
+		Bytes int64
+		// Period is the budget period, e.g. time.Hour; zero uses 24 hours.
+		Period time.Duration
+		// Action is applied to entries once the budget is exhausted.
+		Action BudgetAction
+		// SampleEvery is the sampling ratio of BudgetSample; zero uses 10.
+		SampleEvery int
+	}
+
+	// IngestBudgetStats is a snapshot of the ingest budget of a sink.
+	IngestBudgetStats struct {
+		// Bytes is the budget per period.
+		Bytes int64
+		// Used is the number of bytes written in the current period.
+		Used int64
+		// PeriodStart is the start of the current period.
+		PeriodStart time.Time
+		// Exceeded reports whether the budget of the current period is exhausted.
+		Exceeded bool
+		// Dropped counts the entries dropped by the budget since the logger was created.
+		Dropped uint64
+	}
+
+	// sinkBudget pairs an IngestBudget with the output path it applies to.
+	sinkBudget struct {
+		sink   string
+		budget IngestBudget
+	}
+
+	// budgetTracker counts the bytes written to a sink and decides which entries are
+	// written once the budget of the period is exhausted.
+	budgetTracker struct {
+		sink   string
+		budget IngestBudget
+		// base writes the notices and summaries; it bypasses the budget.
+		base zapcore.Core
+
+		used    atomic.Int64
+		dropped atomic.Uint64
+		// open is the start of the current period in Unix nanoseconds while the budget is
+		// not exhausted, zero otherwise. Entries of an open period are allowed without
+		// taking mu.
+		open atomic.Int64
+
+		mu          sync.Mutex
+		periodStart time.Time
+		exceeded    bool
+		pending     uint64
+		sampled     uint64
+	}
+
+	// budgetWriter counts the bytes written to a sink.
+	budgetWriter struct {
+		zapcore.WriteSyncer
+		tracker *budgetTracker
+	}
+
+	// budgetCore wraps the core of a sink and applies its ingest budget.
+	budgetCore struct {
+		zapcore.Core
+		tracker *budgetTracker
+	}
+)
+
+// newOutputCore creates the core writing to the output sinks. Sinks with an ingest
+// budget, a field class policy or a field profile get a core of their own, teed with a
+// core writing to the remaining sinks. The trackers are returned for GetStats.
+func newOutputCore(encoder zapcore.Encoder, paths []string, writers []zapcore.WriteSyncer, level zapcore.LevelEnabler, budgets []sinkBudget, policies []sinkPolicy, profiled map[string]*profiledSink) (zapcore.Core, []*budgetTracker, error) {
+	for _, b := range budgets {
+		if !slices.Contains(paths, b.sink) {
+			return nil, nil, fmt.Errorf("ingest budget for unknown sink %q: it must be one of the output paths", b.sink)
+		}
+		if b.budget.Bytes <= 0 {
+			return nil, nil, fmt.Errorf("ingest budget for sink %q must be positive", b.sink)
+		}
+	}
+
+	var (
+		cores    []zapcore.Core
+		trackers []*budgetTracker
+		plain    []zapcore.WriteSyncer
+	)
+	for i, path := range paths {
+		idx := slices.IndexFunc(budgets, func(b sinkBudget) bool { return b.sink == path })
+		policy, ok := findSinkPolicy(policies, path)
+		profile, isProfiled := profiled[path]
+		if idx < 0 && !ok && !isProfiled {
+			plain = append(plain, writers[i])
+			continue
+		}
+		sinkEncoder, renames := encoder, []fieldRename(nil)
+		if isProfiled {
+			sinkEncoder, renames = profile.encoder, profile.renames
+		}
+		if idx < 0 {
+			cores = append(cores, newClassCore(newRenameCore(zapcore.NewCore(sinkEncoder.Clone(), writers[i], level), renames), policy))
+			continue
+		}
+		tracker := newBudgetTracker(path, budgets[idx].budget)
+		tracker.base = zapcore.NewCore(sinkEncoder.Clone(), &budgetWriter{WriteSyncer: writers[i], tracker: tracker}, level)
+		cores = append(cores, &budgetCore{Core: newClassCore(newRenameCore(tracker.base, renames), policy), tracker: tracker})
+		trackers = append(trackers, tracker)
+	}
+	if len(plain) > 0 {
+		cores = append(cores, newClassCore(zapcore.NewCore(encoder.Clone(), zap.CombineWriteSyncers(plain...), level), defaultSinkPolicy))
+	}
+	if len(cores) == 1 {
+		return cores[0], trackers, nil
+	}
+	return zapcore.NewTee(cores...), trackers, nil
+}
+
+// newBudgetTracker creates the tracker of a sink, applying the budget defaults.
+func newBudgetTracker(sink string, budget IngestBudget) *budgetTracker {
+	if budget.Period <= 0 {
+		budget.Period = defaultBudgetPeriod
+	}
+	if budget.SampleEvery <= 0 {
+		budget.SampleEvery = defaultBudgetSampleEvery
+	}
+	return &budgetTracker{sink: sink, budget: budget}
+}
+
+// Write writes p to the sink and counts the bytes written.
+func (w *budgetWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteSyncer.Write(p)
+	w.tracker.used.Add(int64(n))
+	return n, err
+}
+
+// With returns a child core sharing the tracker.
+func (c *budgetCore) With(fields []Field) zapcore.Core {
+	return &budgetCore{Core: c.Core.With(fields), tracker: c.tracker}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *budgetCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry unless the exhausted budget of the sink drops it.
+func (c *budgetCore) Write(ent zapcore.Entry, fields []Field) error {
+	if !c.tracker.allow(ent) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// Sync writes the summary of entries dropped so far and flushes the sink.
+func (c *budgetCore) Sync() error {
+	c.tracker.flush()
+	return c.Core.Sync()
+}
+
+// allow reports whether the entry is written to the sink. It starts a new period when
+// the entry belongs to one, and writes a notice when the budget is first exhausted.
+func (t *budgetTracker) allow(ent zapcore.Entry) bool {
+	period := ent.Time.UTC().Truncate(t.budget.Period)
+	if open := t.open.Load(); open != 0 && open == period.UnixNano() && t.used.Load() < t.budget.Bytes {
+		return true
+	}
+
+	t.mu.Lock()
+	var (
+		summary  uint64
+		notice   bool
+		previous = t.periodStart
+	)
+	if period.After(t.periodStart) {
+		summary, t.pending = t.pending, 0
+		t.periodStart, t.exceeded, t.sampled = period, false, 0
+		t.used.Store(0)
+	}
+	if !t.exceeded && t.used.Load() >= t.budget.Bytes {
+		t.exceeded, notice = true, true
+	}
+	allowed := !t.exceeded || t.admit(ent.Level)
+	if !allowed {
+		t.pending++
+		t.dropped.Add(1)
+	}
+	current := t.periodStart
+	if t.exceeded {
+		t.open.Store(0)
+	} else {
+		t.open.Store(current.UnixNano())
+	}
+	t.mu.Unlock()
+
+	if summary > 0 {
+		t.writeSummary(summary, previous)
+	}
+	if notice {
+		t.write(budgetExceededMessage, current)
+	}
+	return allowed
+}
+
+// admit reports whether the action of the exhausted budget writes an entry of the
+// level. The caller holds t.mu.
+func (t *budgetTracker) admit(level zapcore.Level) bool {
+	switch t.budget.Action {
+	case BudgetDegrade:
+		return level >= zapcore.WarnLevel
+	case BudgetSample:
+		if level >= zapcore.ErrorLevel {
+			return true
+		}
+		t.sampled++
+		return (t.sampled-1)%uint64(t.budget.SampleEvery) == 0
+	default:
+		return false
+	}
+}
+
+// flush writes the summary of the entries dropped since the last summary.
+func (t *budgetTracker) flush() {
+	t.mu.Lock()
+	pending, periodStart := t.pending, t.periodStart
+	t.pending = 0
+	t.mu.Unlock()
+
+	if pending > 0 {
+		t.writeSummary(pending, periodStart)
+	}
+}
+
+// writeSummary writes the number of entries dropped in the period starting at periodStart.
+func (t *budgetTracker) writeSummary(dropped uint64, periodStart time.Time) {
+	t.write(budgetSummaryMessage, periodStart, zap.Uint64(FieldKeyBudgetDropped, dropped))
+}
+
+// write writes a warning about the budget directly to the sink, bypassing the budget.
+func (t *budgetTracker) write(msg string, periodStart time.Time, fields ...Field) {
+	ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: time.Now(), Message: msg}
+	_ = t.base.Write(ent, append([]Field{
+		zap.String(FieldKeyBudgetSink, t.sink),
+		zap.Int64(FieldKeyBudgetBytes, t.budget.Bytes),
+		zap.Duration(FieldKeyBudgetPeriod, t.budget.Period),
+		zap.Time(FieldKeyBudgetPeriodStart, periodStart),
+	}, fields...))
+}
+
+// stats returns a snapshot of the budget.
+func (t *budgetTracker) stats() IngestBudgetStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return IngestBudgetStats{
+		Bytes:       t.budget.Bytes,
+		Used:        t.used.Load(),
+		PeriodStart: t.periodStart,
+		Exceeded:    t.exceeded,
+		Dropped:     t.dropped.Load(),
+	}
+}
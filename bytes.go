@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// BytesEncoding controls how byte slices logged with Bytes are rendered.
+type BytesEncoding string
+
+const (
+	// BytesBase64 renders bytes as standard base64.
+	BytesBase64 BytesEncoding = "base64"
+	// BytesHex renders bytes as lowercase hex.
+	BytesHex BytesEncoding = "hex"
+	// BytesLength renders only the number of bytes, e.g. "4096 bytes".
+	BytesLength BytesEncoding = "length"
+)
+
+// defaultBytesLimit is the number of bytes rendered by Bytes.
+const defaultBytesLimit = 1024
+
+// bytesValue renders a byte slice as a string when the field is encoded.
+type bytesValue struct {
+	data     []byte
+	encoding BytesEncoding
+	limit    int
+}
+
+// Bytes returns a field rendering the bytes as base64, capped at 1 KiB. Bytes beyond
+// the cap are left out and the total length is appended, so raw payloads cannot blow
+// up entry sizes the way zap.ByteString and zap.Binary do. Use BytesAs for another
+// rendering or cap.
+//
+// Parameters:
+//   - key: The field key
+//   - b: The bytes to log
+//
+// Returns:
+//   - Field: The bytes field
+//
+// Example:
+//
+//	log.Debug(ctx, "message received", logger.Bytes("payload", msg.Value))
+//	// "payload":"eyJvcmRlcl9pZCI6...(4096 bytes)"
+func Bytes(key string, b []byte) Field {
+	return zap.Stringer(key, bytesValue{data: b, encoding: BytesBase64, limit: defaultBytesLimit})
+}
+
+// BytesAs returns a field rendering the bytes with the encoding, capped at limit bytes.
+// Unknown encodings render as base64.
+//
+// Parameters:
+//   - key: The field key
+//   - b: The bytes to log
+//   - encoding: The rendering of the bytes
+//   - limit: The maximum number of bytes rendered; zero or less renders every byte
+//
+// Returns:
+//   - Field: The bytes field
+//
+// Example:
+//
+//	log.Debug(ctx, "frame", logger.BytesAs("header", frame[:16], logger.BytesHex, 16))
+//	// "header":"0a1b2c3d4e5f60718293a4b5c6d7e8f9"
+func BytesAs(key string, b []byte, encoding BytesEncoding, limit int) Field {
+	return zap.Stringer(key, bytesValue{data: b, encoding: encoding, limit: limit})
+}
+
+// String renders the bytes, followed by the total length when they are truncated.
+func (v bytesValue) String() string {
+	if v.encoding == BytesLength {
+		return strconv.Itoa(len(v.data)) + " bytes"
+	}
+	data := v.data
+	if v.limit > 0 && len(data) > v.limit {
+		data = data[:v.limit]
+	}
+
+	var s string
+	if v.encoding == BytesHex {
+		s = hex.EncodeToString(data)
+	} else {
+		s = base64.StdEncoding.EncodeToString(data)
+	}
+	if len(data) < len(v.data) {
+		s += "...(" + strconv.Itoa(len(v.data)) + " bytes)"
+	}
+	return s
+}
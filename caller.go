@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"path"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// callerSkip is the number of frames between the code calling a Logger method
+// and the zap call: the logger wrapper and the zapLogger method.
+const callerSkip = 2
+
+// AddCallerSkip returns a logger reporting the caller skip frames further up the
+// stack than the code calling its methods. Adapters and wrappers calling a Logger from
+// their own methods use it so entries report the call site in the application rather
+// than the adapter. Loggers not created by NewLogger are returned unchanged.
+//
+// Parameters:
+//   - log: The logger
+//   - skip: The number of frames between the application and the Logger call
+//
+// Returns:
+//   - Logger: The logger with the additional caller skip
+//
+// Example:
+//
+//	type Repo struct{ log logger.Logger }
+//
+//	func NewRepo(log logger.Logger) *Repo {
+//	    // entries logged through r.logf report the caller of logf
+//	    return &Repo{log: logger.AddCallerSkip(log, 1)}
+//	}
+//
+//	func (r *Repo) logf(ctx context.Context, format string, args ...any) {
+//	    r.log.Info(ctx, fmt.Sprintf(format, args...))
+//	}
+func AddCallerSkip(log Logger, skip int) Logger {
+	z, ok := unwrapLogger(log)
+	if !ok || skip == 0 {
+		return log
+	}
+	return &logger{logger: &zapLogger{zapLogger: z.zapLogger.WithOptions(zap.AddCallerSkip(skip)), state: z.state, fields: z.fields}}
+}
+
+// callerFormatter renders callers relative to the module root instead of zap's
+// short package/file.go form, which is ambiguous when packages in different
+// directories share a name. Callers inside the module are written as
+// internal/db/repo.go:42, callers in other modules keep their import path,
+// e.g. github.com/lib/pq/conn.go:88.
+type callerFormatter struct {
+	// module is the import path of the module root.
+	module string
+	// mainPath is the import path of the main package, which runtime reports as main.
+	mainPath string
+	// function appends the function name, e.g. internal/db/repo.go:42 (*Repo).Get.
+	function bool
+}
+
+// newCallerFormatter creates a formatter for the module, detecting the main module
+// from the build info when module is empty. It returns nil when the module is unknown,
+// in which case zap's short caller format is used.
+func newCallerFormatter(module string, function bool) *callerFormatter {
+	var mainPath string
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if module == "" && info.Main.Path != "command-line-arguments" {
+			module = info.Main.Path
+		}
+		if info.Path != "command-line-arguments" {
+			mainPath = info.Path
+		}
+	}
+	if module == "" {
+		return nil
+	}
+	return &callerFormatter{module: strings.TrimSuffix(module, "/"), mainPath: mainPath, function: function}
+}
+
+// encode is a zapcore.CallerEncoder writing the formatted caller.
+func (f *callerFormatter) encode(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(f.format(caller))
+}
+
+// format renders the caller. Callers without a function name, such as entries
+// written by hand-built cores, fall back to zap's short format.
+func (f *callerFormatter) format(caller zapcore.EntryCaller) string {
+	if !caller.Defined {
+		return "undefined"
+	}
+	pkg, fn := splitFunction(caller.Function)
+	if pkg == "" {
+		return caller.TrimmedPath()
+	}
+	if pkg == "main" && f.mainPath != "" {
+		pkg = f.mainPath
+	}
+
+	var b strings.Builder
+	switch {
+	case pkg == f.module:
+	case strings.HasPrefix(pkg, f.module+"/"):
+		b.WriteString(strings.TrimPrefix(pkg, f.module+"/"))
+		b.WriteByte('/')
+	default:
+		b.WriteString(pkg)
+		b.WriteByte('/')
+	}
+	b.WriteString(path.Base(caller.File))
+	b.WriteByte(':')
+	b.WriteString(strconv.Itoa(caller.Line))
+	if f.function && fn != "" {
+		b.WriteByte(' ')
+		b.WriteString(fn)
+	}
+	return b.String()
+}
+
+// splitFunction splits a fully qualified function name such as
+// github.com/acme/app/internal/db.(*Repo).Get into its package import path
+// and the function name within the package.
+func splitFunction(function string) (pkg, fn string) {
+	slash := strings.LastIndexByte(function, '/')
+	dot := strings.IndexByte(function[slash+1:], '.')
+	if dot < 0 {
+		return "", ""
+	}
+	dot += slash + 1
+	return function[:dot], function[dot+1:]
+}
@@ -0,0 +1,346 @@
+package logger
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// captureMemoryLimit is the size of captured entries kept in memory before a capture
+	// spills to a temporary file.
+	captureMemoryLimit = 4 << 20
+	// captureMaxBytes is the size of captured entries after which entries are dropped.
+	captureMaxBytes = 256 << 20
+)
+
+// Names of the files in a snapshot bundle.
+const (
+	snapshotEntriesFile  = "entries.jsonl"
+	snapshotMetadataFile = "snapshot.json"
+)
+
+type (
+	// Snapshot holds the entries recorded by CaptureWindow as JSON lines. Entries are
+	// kept in memory and spilled to a temporary file for large captures; call Close to
+	// remove it.
+	Snapshot struct {
+		// Start is when the capture started.
+		Start time.Time `json:"start"`
+		// End is when the capture ended.
+		End time.Time `json:"end"`
+		// Entries is the number of recorded entries.
+		Entries int `json:"entries"`
+		// Bytes is the size of the recorded entries.
+		Bytes int64 `json:"bytes"`
+		// Dropped is the number of entries not recorded because the capture reached its
+		// maximum size of 256 MiB.
+		Dropped int `json:"dropped"`
+
+		mem  []byte
+		file *os.File
+	}
+
+	// captureState holds the captures in progress of a logger and its children.
+	captureState struct {
+		encoder  zapcore.Encoder
+		redacted *redactedFields
+		active   atomic.Int32
+
+		mu        sync.Mutex
+		recorders map[*captureRecorder]struct{}
+	}
+
+	// captureRecorder records the entries of one capture.
+	captureRecorder struct {
+		snapshot *Snapshot
+		mem      bytes.Buffer
+		err      error
+	}
+
+	// captureCore wraps the core of a logger and adds every entry, whatever its level,
+	// to the captures in progress.
+	captureCore struct {
+		zapcore.Core
+		state  *captureState
+		fields []Field
+	}
+
+	// captureSink is the core added to checked entries while a capture is in progress.
+	captureSink struct {
+		core *captureCore
+	}
+)
+
+// CaptureWindow records every entry of the logger and its children, regardless of
+// level and sampling, for the duration of the window, e.g. to attach the full logs of
+// an incident to its ticket. It blocks until the window ends and returns the snapshot
+// of the recorded entries, encoded as JSON lines. Captures are kept in memory up to
+// 4 MiB and spill to a temporary file beyond; entries past 256 MiB are dropped and
+// counted. Debug entries are recorded but still only written to the sinks when the
+// level allows. Concurrent captures are independent.
+//
+// Parameters:
+//   - ctx: The context ending the capture early
+//   - log: The logger created by NewLogger
+//   - window: How long entries are recorded
+//
+// Returns:
+//   - *Snapshot: The recorded entries; close it to remove its temporary file
+//   - error: An error if the logger was not created by NewLogger, and with the partial
+//     snapshot if ctx was done before the window ended or the capture could not be
+//     spilled to disk, after which entries are dropped
+//
+// Example:
+//
+//	snapshot, err := logger.CaptureWindow(ctx, log, 30*time.Second)
+//	if snapshot != nil {
+//	    defer snapshot.Close()
+//	    f, _ := os.Create("incident-1234.tar.gz")
+//	    _ = snapshot.WriteBundle(f)
+//	    f.Close()
+//	}
+func CaptureWindow(ctx context.Context, log Logger, window time.Duration) (*Snapshot, error) {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return nil, errRuntimeUnsupported
+	}
+
+	rec := &captureRecorder{snapshot: &Snapshot{Start: time.Now()}}
+	state := z.state.capture
+	state.add(rec)
+
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	state.remove(rec)
+	snapshot := rec.snapshot
+	snapshot.End = time.Now()
+	snapshot.mem = rec.mem.Bytes()
+	if rec.err != nil {
+		err = errors.Join(err, fmt.Errorf("failed to spill capture to disk: %w", rec.err))
+	}
+	return snapshot, err
+}
+
+// Open returns a reader of the recorded entries, one JSON object per line.
+//
+// Returns:
+//   - io.ReadCloser: The recorded entries
+//   - error: An error if the temporary file cannot be read
+func (s *Snapshot) Open() (io.ReadCloser, error) {
+	if s.file == nil {
+		return io.NopCloser(bytes.NewReader(s.mem)), nil
+	}
+	f, err := os.Open(s.file.Name())
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// WriteBundle writes the snapshot as a gzip compressed tar archive holding the recorded
+// entries in entries.jsonl and the start, end and counts of the capture in snapshot.json.
+//
+// Parameters:
+//   - w: The destination of the bundle
+//
+// Returns:
+//   - error: An error if the entries cannot be read or the bundle cannot be written
+func (s *Snapshot) WriteBundle(w io.Writer) error {
+	metadata, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	entries, err := s.Open()
+	if err != nil {
+		return err
+	}
+	defer entries.Close()
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeTarFile(tw, snapshotMetadataFile, int64(len(metadata)), s.End, bytes.NewReader(metadata)); err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, snapshotEntriesFile, s.Bytes, s.End, entries); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Close removes the temporary file of a spilled snapshot.
+func (s *Snapshot) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	name := s.file.Name()
+	_ = s.file.Close()
+	s.file = nil
+	return os.Remove(name)
+}
+
+// writeTarFile writes a regular file to the tar archive.
+func writeTarFile(tw *tar.Writer, name string, size int64, modTime time.Time, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: size, ModTime: modTime}); err != nil {
+		return err
+	}
+	_, err := io.CopyN(tw, r, size)
+	return err
+}
+
+// newCaptureState creates the capture state of a logger encoding entries with encoder
+// and redacting the fields of redacted.
+func newCaptureState(encoder zapcore.Encoder, redacted *redactedFields) *captureState {
+	return &captureState{encoder: encoder, redacted: redacted, recorders: make(map[*captureRecorder]struct{})}
+}
+
+// add starts recording entries into the recorder.
+func (s *captureState) add(rec *captureRecorder) {
+	s.mu.Lock()
+	s.recorders[rec] = struct{}{}
+	s.mu.Unlock()
+	s.active.Add(1)
+}
+
+// remove stops recording entries into the recorder.
+func (s *captureState) remove(rec *captureRecorder) {
+	s.mu.Lock()
+	delete(s.recorders, rec)
+	s.mu.Unlock()
+	s.active.Add(-1)
+}
+
+// record encodes the entry once and appends it to every capture in progress. Fields are
+// redacted and classified secrets masked as by the sinks without a policy.
+func (s *captureState) record(ent zapcore.Entry, contextFields, fields []Field) {
+	enc := s.encoder.Clone()
+	for _, f := range defaultSinkPolicy.apply(s.redacted.redact(contextFields)) {
+		f.AddTo(enc)
+	}
+	buf, err := enc.EncodeEntry(ent, defaultSinkPolicy.apply(s.redacted.redact(fields)))
+	if err != nil {
+		return
+	}
+	defer buf.Free()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for rec := range s.recorders {
+		rec.write(buf.Bytes())
+	}
+}
+
+// write appends an encoded entry, spilling to a temporary file past the memory limit.
+func (r *captureRecorder) write(line []byte) {
+	snapshot := r.snapshot
+	if r.err != nil || snapshot.Bytes+int64(len(line)) > captureMaxBytes {
+		snapshot.Dropped++
+		return
+	}
+
+	if snapshot.file == nil && r.mem.Len()+len(line) > captureMemoryLimit {
+		f, err := os.CreateTemp("", "logger-capture-*.jsonl")
+		if err == nil {
+			_, err = f.Write(r.mem.Bytes())
+		}
+		if err != nil {
+			if f != nil {
+				_ = f.Close()
+				_ = os.Remove(f.Name())
+			}
+			r.err = err
+			snapshot.Dropped++
+			return
+		}
+		snapshot.file = f
+		r.mem = bytes.Buffer{}
+	}
+
+	if snapshot.file != nil {
+		if _, err := snapshot.file.Write(line); err != nil {
+			r.err = err
+			snapshot.Dropped++
+			return
+		}
+	} else {
+		r.mem.Write(line)
+	}
+	snapshot.Entries++
+	snapshot.Bytes += int64(len(line))
+}
+
+// Enabled reports true for every level while a capture is in progress.
+func (c *captureCore) Enabled(level zapcore.Level) bool {
+	return c.Core.Enabled(level) || c.state.active.Load() > 0
+}
+
+// With returns a child core remembering the fields for captured entries.
+func (c *captureCore) With(fields []Field) zapcore.Core {
+	contextFields := make([]Field, 0, len(c.fields)+len(fields))
+	contextFields = append(contextFields, c.fields...)
+	contextFields = append(contextFields, fields...)
+	return &captureCore{Core: c.Core.With(fields), state: c.state, fields: contextFields}
+}
+
+// lowered returns a copy of the core capturing the entries of the lowered wrapped core.
+func (c *captureCore) lowered() zapcore.Core {
+	return &captureCore{Core: lowerCore(c.Core), state: c.state, fields: c.fields}
+}
+
+// Check lets the wrapped core decide on the entry and adds the capture sink while a
+// capture is in progress.
+func (c *captureCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.Core.Check(ent, ce)
+	if c.state.active.Load() > 0 {
+		return ce.AddCore(ent, captureSink{core: c})
+	}
+	return ce
+}
+
+// Enabled reports true; the sink is only added while a capture is in progress.
+func (s captureSink) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With returns the sink unchanged; fields are tracked by captureCore.
+func (s captureSink) With([]Field) zapcore.Core {
+	return s
+}
+
+// Check adds the sink to the checked entry.
+func (s captureSink) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, s)
+}
+
+// Write records the entry in the captures in progress.
+func (s captureSink) Write(ent zapcore.Entry, fields []Field) error {
+	s.core.state.record(ent, s.core.fields, fields)
+	return nil
+}
+
+// Sync does nothing; captures are kept until CaptureWindow returns.
+func (s captureSink) Sync() error {
+	return nil
+}
@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewRelicSink is the sink name of the New Relic forwarder in WithSinkPolicy.
+const NewRelicSink = "newrelic"
+
+// FieldClass is the data classification of a field key.
+type FieldClass string
+
+const (
+	// ClassPublic marks fields that may be written anywhere. Unregistered keys are public.
+	ClassPublic FieldClass = "public"
+	// ClassInternal marks fields that must stay within the company.
+	ClassInternal FieldClass = "internal"
+	// ClassPII marks personal data such as emails, names and addresses.
+	ClassPII FieldClass = "pii"
+	// ClassSecret marks credentials and keys. Secrets are masked by every sink whose
+	// policy does not allow them.
+	ClassSecret FieldClass = "secret"
+)
+
+// PolicyAction is what a sink does with the fields of a classification.
+type PolicyAction int
+
+const (
+	// PolicyAllow writes the field unchanged.
+	PolicyAllow PolicyAction = iota
+	// PolicyMask writes the field with its value replaced by [REDACTED].
+	PolicyMask
+	// PolicyDrop leaves the field out.
+	PolicyDrop
+)
+
+// SinkPolicy maps field classifications to the action of a sink. Classifications
+// missing from a policy are allowed, except ClassSecret which is masked.
+type SinkPolicy map[FieldClass]PolicyAction
+
+type (
+	// sinkPolicy pairs a SinkPolicy with the sink it applies to.
+	sinkPolicy struct {
+		sink   string
+		policy SinkPolicy
+	}
+
+	// classCore wraps the core of a sink and applies its policy to classified fields.
+	classCore struct {
+		zapcore.Core
+		policy SinkPolicy
+	}
+)
+
+// defaultSinkPolicy is the policy of sinks configured without one.
+var defaultSinkPolicy = SinkPolicy{}
+
+var (
+	fieldClassesMu sync.Mutex
+	// fieldClasses maps registered keys to their classification; it is replaced on
+	// registration so cores read it without locking.
+	fieldClasses atomic.Pointer[map[string]FieldClass]
+)
+
+// RegisterFieldClass classifies field keys for every logger. Each sink applies its
+// policy, set with WithSinkPolicy, to the fields of a classification: PII can be
+// written to an internal sink but masked for a vendor sink, for example. Secrets are
+// masked by every sink unless its policy allows them. Keys are matched against
+// top-level fields as written, after renames, whether they come from With or the log
+// call; fields added with With are classified when the child logger is created.
+// Registering a key again replaces its classification. Register keys at startup,
+// before logging.
+//
+// Parameters:
+//   - class: The classification of the keys
+//   - keys: The field keys
+//
+// Returns:
+//   - error: An error if the classification is unknown
+//
+// Example:
+//
+//	_ = logger.RegisterFieldClass(logger.ClassPII, "email", "phone", "ip_address")
+//	_ = logger.RegisterFieldClass(logger.ClassSecret, "api_key", "session_token")
+func RegisterFieldClass(class FieldClass, keys ...string) error {
+	switch class {
+	case ClassPublic, ClassInternal, ClassPII, ClassSecret:
+	default:
+		return fmt.Errorf("unknown field class %q", class)
+	}
+
+	fieldClassesMu.Lock()
+	defer fieldClassesMu.Unlock()
+	classes := make(map[string]FieldClass)
+	if current := fieldClasses.Load(); current != nil {
+		maps.Copy(classes, *current)
+	}
+	for _, key := range keys {
+		classes[key] = class
+	}
+	fieldClasses.Store(&classes)
+	return nil
+}
+
+// validateSinkPolicies checks that every policy applies to an output path, or to the
+// New Relic forwarder when it is enabled, and uses known actions.
+func validateSinkPolicies(policies []sinkPolicy, paths []string, newRelic bool) error {
+	for _, p := range policies {
+		if !slices.Contains(paths, p.sink) && !(newRelic && p.sink == NewRelicSink) {
+			return fmt.Errorf("sink policy for unknown sink %q: it must be one of the output paths or %q with New Relic enabled", p.sink, NewRelicSink)
+		}
+		for class, action := range p.policy {
+			if action < PolicyAllow || action > PolicyDrop {
+				return fmt.Errorf("sink policy for %q has unknown action %d for class %q", p.sink, action, class)
+			}
+		}
+	}
+	return nil
+}
+
+// findSinkPolicy returns the policy of the sink, or the default policy.
+func findSinkPolicy(policies []sinkPolicy, sink string) (SinkPolicy, bool) {
+	for _, p := range policies {
+		if p.sink == sink {
+			return p.policy, true
+		}
+	}
+	return defaultSinkPolicy, false
+}
+
+// newClassCore wraps the core of a sink with its policy.
+func newClassCore(core zapcore.Core, policy SinkPolicy) zapcore.Core {
+	return &classCore{Core: core, policy: policy}
+}
+
+// action returns what the sink does with fields of the classification.
+func (p SinkPolicy) action(class FieldClass) PolicyAction {
+	if action, ok := p[class]; ok {
+		return action
+	}
+	if class == ClassSecret {
+		return PolicyMask
+	}
+	return PolicyAllow
+}
+
+// apply returns the fields with the policy applied. The input slice is never modified.
+func (p SinkPolicy) apply(fields []Field) []Field {
+	classes := fieldClasses.Load()
+	if classes == nil {
+		return fields
+	}
+
+	var out []Field
+	for i, f := range fields {
+		action := PolicyAllow
+		if class, ok := (*classes)[f.Key]; ok {
+			action = p.action(class)
+		}
+		if action == PolicyAllow {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		if action == PolicyMask {
+			out = append(out, zap.String(f.Key, redactedValue))
+		}
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// With returns a child core with the policy applied to the fields.
+func (c *classCore) With(fields []Field) zapcore.Core {
+	return &classCore{Core: c.Core.With(c.policy.apply(fields)), policy: c.policy}
+}
+
+// lowered returns a copy of the core keeping the policy.
+func (c *classCore) lowered() zapcore.Core {
+	return &classCore{Core: lowerCore(c.Core), policy: c.policy}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *classCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry with the policy applied to the fields.
+func (c *classCore) Write(ent zapcore.Entry, fields []Field) error {
+	return c.Core.Write(ent, c.policy.apply(fields))
+}
@@ -0,0 +1,186 @@
+package logger
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// CLIFlags are the logging flags of a command-line tool, bound to a flag set by
+	// BindCLIFlags or AddFlags and applied to a logger by WithCLIFlags.
+	CLIFlags struct {
+		// Verbosity is the number of -v flags; -vv counts as two.
+		Verbosity Verbosity
+		// Quiet limits the output to errors, whatever the other flags.
+		Quiet bool
+		// LogLevel is the level set by --log-level, which takes precedence over Verbosity.
+		LogLevel Level
+		// Format is the encoding set by --log-format; empty keeps the encoding of the mode.
+		Format Encoding
+	}
+
+	// Verbosity counts the occurrences of a -v flag. It implements flag.Value as a boolean
+	// flag, so each -v adds one, and pflag.Value, so with spf13/pflag it can be registered
+	// as a shorthand parsing -vv:
+	//
+	//	pflags.VarPF(&cli.Verbosity, "verbose", "v", "increase verbosity").NoOptDefVal = "true"
+	Verbosity int
+)
+
+// cliConfig returns the zap configuration of AppModeCLI: human console output to
+// stderr, without timestamps, callers or stack traces.
+func cliConfig() zap.Config {
+	return zap.Config{
+		Level:             zap.NewAtomicLevelAt(zapcore.WarnLevel),
+		Encoding:          EncodingConsole.String(),
+		DisableCaller:     true,
+		DisableStacktrace: true,
+		OutputPaths:       []string{"stderr"},
+		ErrorOutputPaths:  []string{"stderr"},
+		EncoderConfig: zapcore.EncoderConfig{
+			LevelKey:         "level",
+			MessageKey:       "message",
+			LineEnding:       zapcore.DefaultLineEnding,
+			EncodeLevel:      zapcore.CapitalLevelEncoder,
+			EncodeTime:       zapcore.ISO8601TimeEncoder,
+			EncodeDuration:   zapcore.StringDurationEncoder,
+			EncodeCaller:     zapcore.ShortCallerEncoder,
+			EncodeName:       zapcore.FullNameEncoder,
+			ConsoleSeparator: " ",
+		},
+	}
+}
+
+// BindCLIFlags registers the verbosity flags of a command-line tool: -v for info
+// entries, -vv or -v -v for debug entries and -q for errors only. Without flags, only
+// warnings and errors are written. Apply the parsed flags with WithCLIFlags. Tools
+// using spf13/pflag can add the flags with AddGoFlagSet, or register Verbosity as a
+// shorthand flag themselves.
+//
+// Parameters:
+//   - fs: The flag set to register the flags on; nil uses flag.CommandLine
+//
+// Returns:
+//   - *CLIFlags: The flags, set once fs is parsed
+//
+// Example:
+//
+//	cli := logger.BindCLIFlags(nil)
+//	flag.Parse()
+//	log, err := logger.NewLogger(logger.WithAppMode(logger.AppModeCLI), logger.WithCLIFlags(cli))
+func BindCLIFlags(fs *flag.FlagSet) *CLIFlags {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	f := &CLIFlags{}
+	fs.Var(&f.Verbosity, "v", "verbose output, repeat or use -vv for debug output")
+	fs.BoolFunc("vv", "debug output", func(string) error {
+		f.Verbosity += 2
+		return nil
+	})
+	fs.BoolVar(&f.Quiet, "q", false, "only output errors")
+	return f
+}
+
+// AddFlags registers the logging flags every command-line tool built on this package
+// exposes: --log-level and --log-format set the level and encoding, -v raises the
+// verbosity each time it is given, -v -v writing debug entries, and --quiet writes
+// errors only. Without flags, only warnings and errors are written. Invalid levels and
+// formats fail the parsing of fs. Apply the parsed flags with WithCLIFlags.
+//
+// Parameters:
+//   - fs: The flag set to register the flags on; nil uses flag.CommandLine
+//
+// Returns:
+//   - *CLIFlags: The flags, set once fs is parsed
+//
+// Example:
+//
+//	flags := logger.AddFlags(nil)
+//	flag.Parse()
+//	log, err := logger.NewLogger(logger.WithAppMode(logger.AppModeCLI), logger.WithCLIFlags(flags))
+//	// mytool --log-level=debug --log-format=json
+//	// mytool -v -v
+//	// mytool --quiet
+func AddFlags(fs *flag.FlagSet) *CLIFlags {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	f := &CLIFlags{}
+	fs.Func("log-level", "minimum log level: debug, info, warning, error, panic or fatal", func(s string) error {
+		if _, err := parseLevel(Level(s)); err != nil {
+			return err
+		}
+		f.LogLevel = Level(s)
+		return nil
+	})
+	fs.Func("log-format", "log format: json or console", func(s string) error {
+		if !validEncoding[Encoding(s)] {
+			return fmt.Errorf("invalid log format %q, use json or console", s)
+		}
+		f.Format = Encoding(s)
+		return nil
+	})
+	fs.Var(&f.Verbosity, "v", "verbose output, repeat for debug output")
+	fs.BoolVar(&f.Quiet, "quiet", false, "only output errors")
+	return f
+}
+
+// Level returns the minimum level selected by the flags: error with quiet, the level
+// set by --log-level, debug with -vv, info with -v and warning otherwise.
+//
+// Returns:
+//   - Level: The minimum level
+func (f *CLIFlags) Level() Level {
+	switch {
+	case f.Quiet:
+		return LevelError
+	case f.LogLevel != "":
+		return f.LogLevel
+	case f.Verbosity >= 2:
+		return LevelDebug
+	case f.Verbosity == 1:
+		return LevelInfo
+	default:
+		return LevelWarning
+	}
+}
+
+// String returns the number of -v flags.
+func (v *Verbosity) String() string {
+	if v == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*v))
+}
+
+// Set adds one for "true", as passed for each -v flag, or sets the count, e.g. -v=3.
+func (v *Verbosity) Set(s string) error {
+	switch s {
+	case "true":
+		*v++
+		return nil
+	case "false":
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*v = Verbosity(n)
+	return nil
+}
+
+// IsBoolFlag makes -v a flag without value for the flag package.
+func (v *Verbosity) IsBoolFlag() bool {
+	return true
+}
+
+// Type returns the type name shown by spf13/pflag.
+func (v *Verbosity) Type() string {
+	return "count"
+}
@@ -0,0 +1,288 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Field keys stamped by WithCloudMetadata.
+const (
+	FieldKeyCloudProvider = "cloud_provider"
+	FieldKeyCloudRegion   = "cloud_region"
+	FieldKeyCloudZone     = "cloud_zone"
+	FieldKeyInstanceID    = "instance_id"
+	FieldKeyInstanceType  = "instance_type"
+)
+
+// Cloud providers detected by DetectCloudMetadata.
+const (
+	CloudProviderAWS   = "aws"
+	CloudProviderGCP   = "gcp"
+	CloudProviderAzure = "azure"
+)
+
+// DiagnosticCloudMetadata is the kind of diagnostic written when WithCloudMetadata finds
+// no instance metadata service.
+const DiagnosticCloudMetadata = "cloud_metadata_unavailable"
+
+// defaultCloudMetadataTimeout bounds the detection of WithCloudMetadata when no timeout
+// is given.
+const defaultCloudMetadataTimeout = 500 * time.Millisecond
+
+// maxCloudMetadataBytes limits the size of the metadata documents read.
+const maxCloudMetadataBytes = 64 << 10
+
+// Instance metadata endpoints, variables so they can point to a local server.
+var (
+	awsMetadataURL   = "http://169.254.169.254"
+	gcpMetadataURL   = "http://metadata.google.internal"
+	azureMetadataURL = "http://169.254.169.254"
+)
+
+// errNoCloudMetadata is returned when no provider answers.
+var errNoCloudMetadata = errors.New("no cloud instance metadata service answered")
+
+// CloudMetadata describes the cloud instance the process runs on.
+type CloudMetadata struct {
+	// Provider is CloudProviderAWS, CloudProviderGCP or CloudProviderAzure.
+	Provider string
+	// Region is the region of the instance, e.g. "eu-west-1".
+	Region string
+	// Zone is the availability zone of the instance, e.g. "eu-west-1a"; empty on Azure
+	// instances outside availability zones.
+	Zone string
+	// InstanceID identifies the instance.
+	InstanceID string
+	// InstanceType is the instance type or machine size, e.g. "m6i.large".
+	InstanceType string
+}
+
+// cloudMetadataCache holds the result of the first detection of the process, as the
+// instance does not change while it runs.
+var cloudMetadataCache struct {
+	mu       sync.Mutex
+	done     bool
+	metadata CloudMetadata
+	err      error
+}
+
+// DetectCloudMetadata queries the AWS, GCP and Azure instance metadata services in
+// parallel and returns the metadata of the first that answers. Off the cloud, the
+// services do not answer, so bound the call with a context deadline.
+//
+// Parameters:
+//   - ctx: The context bounding the detection
+//
+// Returns:
+//   - CloudMetadata: The metadata of the instance
+//   - error: An error if no provider answered before ctx is done
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Second)
+//	defer cancel()
+//	if md, err := logger.DetectCloudMetadata(ctx); err == nil {
+//	    fmt.Println(md.Provider, md.Region)
+//	}
+func DetectCloudMetadata(ctx context.Context) (CloudMetadata, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	probes := []func(context.Context, *http.Client) (CloudMetadata, error){
+		awsMetadata, gcpMetadata, azureMetadata,
+	}
+	type result struct {
+		metadata CloudMetadata
+		err      error
+	}
+	results := make(chan result, len(probes))
+	client := &http.Client{Transport: &http.Transport{Proxy: nil}}
+	defer client.CloseIdleConnections()
+	for _, probe := range probes {
+		go func() {
+			md, err := probe(ctx, client)
+			results <- result{md, err}
+		}()
+	}
+
+	var errs []error
+	for range probes {
+		r := <-results
+		if r.err == nil {
+			return r.metadata, nil
+		}
+		errs = append(errs, r.err)
+	}
+	return CloudMetadata{}, fmt.Errorf("%w: %w", errNoCloudMetadata, errors.Join(errs...))
+}
+
+// Fields returns the non-empty metadata as the fields stamped by WithCloudMetadata.
+//
+// Returns:
+//   - []Field: The fields of the metadata
+func (m CloudMetadata) Fields() []Field {
+	var fields []Field
+	for _, f := range []struct{ key, value string }{
+		{FieldKeyCloudProvider, m.Provider},
+		{FieldKeyCloudRegion, m.Region},
+		{FieldKeyCloudZone, m.Zone},
+		{FieldKeyInstanceID, m.InstanceID},
+		{FieldKeyInstanceType, m.InstanceType},
+	} {
+		if f.value != "" {
+			fields = append(fields, zap.String(f.key, f.value))
+		}
+	}
+	return fields
+}
+
+// cachedCloudMetadata returns the metadata of the instance, detecting it within timeout
+// the first time it is called. Failures are cached as well, so loggers created off the
+// cloud do not wait for the timeout again.
+func cachedCloudMetadata(timeout time.Duration) (CloudMetadata, error) {
+	c := &cloudMetadataCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.done {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		c.metadata, c.err = DetectCloudMetadata(ctx)
+		cancel()
+		c.done = true
+	}
+	return c.metadata, c.err
+}
+
+// cloudMetadataFields returns the fields of the cached metadata of the instance, nil
+// with a diagnostic when it cannot be detected.
+func cloudMetadataFields(timeout time.Duration, diag *diagnostics) []Field {
+	md, err := cachedCloudMetadata(timeout)
+	if err != nil {
+		diag.notice(DiagnosticCloudMetadata, "cloud instance metadata unavailable", zap.Error(err))
+		return nil
+	}
+	return md.Fields()
+}
+
+// awsMetadata reads the instance identity document of EC2, with an IMDSv2 session token
+// when the service issues one.
+func awsMetadata(ctx context.Context, client *http.Client) (CloudMetadata, error) {
+	headers := map[string]string{}
+	token, err := fetchMetadata(ctx, client, http.MethodPut, awsMetadataURL+"/latest/api/token",
+		map[string]string{"X-aws-ec2-metadata-token-ttl-seconds": "60"})
+	if err == nil {
+		headers["X-aws-ec2-metadata-token"] = string(token)
+	} else if ctx.Err() != nil {
+		return CloudMetadata{}, fmt.Errorf("aws: %w", err)
+	}
+
+	body, err := fetchMetadata(ctx, client, http.MethodGet, awsMetadataURL+"/latest/dynamic/instance-identity/document", headers)
+	if err != nil {
+		return CloudMetadata{}, fmt.Errorf("aws: %w", err)
+	}
+	var doc struct {
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil || doc.InstanceID == "" {
+		return CloudMetadata{}, errors.New("aws: invalid instance identity document")
+	}
+	return CloudMetadata{
+		Provider:     CloudProviderAWS,
+		Region:       doc.Region,
+		Zone:         doc.AvailabilityZone,
+		InstanceID:   doc.InstanceID,
+		InstanceType: doc.InstanceType,
+	}, nil
+}
+
+// gcpMetadata reads the instance metadata of Compute Engine, whose zone and machine type
+// are resource paths such as projects/123/zones/us-central1-a.
+func gcpMetadata(ctx context.Context, client *http.Client) (CloudMetadata, error) {
+	body, err := fetchMetadata(ctx, client, http.MethodGet, gcpMetadataURL+"/computeMetadata/v1/instance/?recursive=true",
+		map[string]string{"Metadata-Flavor": "Google"})
+	if err != nil {
+		return CloudMetadata{}, fmt.Errorf("gcp: %w", err)
+	}
+	var doc struct {
+		ID          json.Number `json:"id"`
+		Zone        string      `json:"zone"`
+		MachineType string      `json:"machineType"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil || doc.ID == "" {
+		return CloudMetadata{}, errors.New("gcp: invalid instance metadata")
+	}
+	zone := lastPathSegment(doc.Zone)
+	region := zone
+	if i := strings.LastIndexByte(zone, '-'); i > 0 {
+		region = zone[:i]
+	}
+	return CloudMetadata{
+		Provider:     CloudProviderGCP,
+		Region:       region,
+		Zone:         zone,
+		InstanceID:   doc.ID.String(),
+		InstanceType: lastPathSegment(doc.MachineType),
+	}, nil
+}
+
+// azureMetadata reads the compute metadata of the Azure Instance Metadata Service.
+func azureMetadata(ctx context.Context, client *http.Client) (CloudMetadata, error) {
+	body, err := fetchMetadata(ctx, client, http.MethodGet, azureMetadataURL+"/metadata/instance/compute?api-version=2021-02-01",
+		map[string]string{"Metadata": "true"})
+	if err != nil {
+		return CloudMetadata{}, fmt.Errorf("azure: %w", err)
+	}
+	var doc struct {
+		Location string `json:"location"`
+		Zone     string `json:"zone"`
+		VMID     string `json:"vmId"`
+		VMSize   string `json:"vmSize"`
+	}
+	if err := json.Unmarshal(body, &doc); err != nil || doc.VMID == "" {
+		return CloudMetadata{}, errors.New("azure: invalid compute metadata")
+	}
+	return CloudMetadata{
+		Provider:     CloudProviderAzure,
+		Region:       doc.Location,
+		Zone:         doc.Zone,
+		InstanceID:   doc.VMID,
+		InstanceType: doc.VMSize,
+	}, nil
+}
+
+// fetchMetadata sends a request to an instance metadata service and returns the body of
+// a 200 response.
+func fetchMetadata(ctx context.Context, client *http.Client, method, url string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxCloudMetadataBytes))
+}
+
+// lastPathSegment returns the part of a resource path after the last slash.
+func lastPathSegment(path string) string {
+	return path[strings.LastIndexByte(path, '/')+1:]
+}
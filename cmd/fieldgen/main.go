@@ -0,0 +1,270 @@
+// Command fieldgen generates typed field constructors from a YAML field dictionary, so
+// every package of a codebase logs a field under the same key with the same type.
+//
+// Usage:
+//
+//	fieldgen [-in fields.yaml] [-out fields_gen.go] [-package fields]
+//
+// Run it with go generate from the package holding the dictionary:
+//
+//	//go:generate go run github.com/andryhardiyanto/go-logger/cmd/fieldgen -in fields.yaml
+//
+// The dictionary lists the fields with their key, type and an optional name and doc;
+// the name defaults to the key in camel case, with common initialisms such as ID and
+// URL upper case:
+//
+//	fields:
+//	  - key: order_id
+//	    type: string
+//	    doc: identifies the order across services
+//	  - key: amount_cents
+//	    type: int64
+//	  - name: Retry
+//	    key: retry_attempt
+//	    type: int
+//
+// produces the constant KeyOrderID = "order_id" and the constructor
+// OrderID(value string) logger.Field, and so on. Supported types are string, bool,
+// int, int32, int64, uint, uint32, uint64, float32, float64, duration, time, error,
+// strings, ints and any.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// dictionary is the YAML field dictionary.
+	dictionary struct {
+		// Package is the package of the generated file; the -package flag and $GOPACKAGE
+		// take precedence.
+		Package string `yaml:"package"`
+		// Fields are the fields to generate constructors for.
+		Fields []field `yaml:"fields"`
+	}
+
+	// field is an entry of the dictionary.
+	field struct {
+		Name string `yaml:"name"`
+		Key  string `yaml:"key"`
+		Type string `yaml:"type"`
+		Doc  string `yaml:"doc"`
+	}
+
+	// fieldType is how a dictionary type is passed to a constructor and encoded.
+	fieldType struct {
+		goType      string
+		constructor string
+		imports     []string
+	}
+
+	// templateField is a validated field ready to render.
+	templateField struct {
+		Name        string
+		Key         string
+		Doc         string
+		GoType      string
+		Constructor string
+	}
+)
+
+// fieldTypes maps the dictionary types to their Go type and zap constructor.
+var fieldTypes = map[string]fieldType{
+	"string":   {goType: "string", constructor: "zap.String"},
+	"bool":     {goType: "bool", constructor: "zap.Bool"},
+	"int":      {goType: "int", constructor: "zap.Int"},
+	"int32":    {goType: "int32", constructor: "zap.Int32"},
+	"int64":    {goType: "int64", constructor: "zap.Int64"},
+	"uint":     {goType: "uint", constructor: "zap.Uint"},
+	"uint32":   {goType: "uint32", constructor: "zap.Uint32"},
+	"uint64":   {goType: "uint64", constructor: "zap.Uint64"},
+	"float32":  {goType: "float32", constructor: "zap.Float32"},
+	"float64":  {goType: "float64", constructor: "zap.Float64"},
+	"duration": {goType: "time.Duration", constructor: "zap.Duration", imports: []string{"time"}},
+	"time":     {goType: "time.Time", constructor: "zap.Time", imports: []string{"time"}},
+	"error":    {goType: "error", constructor: "zap.NamedError"},
+	"strings":  {goType: "[]string", constructor: "zap.Strings"},
+	"ints":     {goType: "[]int", constructor: "zap.Ints"},
+	"any":      {goType: "any", constructor: "zap.Any"},
+}
+
+// initialisms are the words written upper case in generated names.
+var initialisms = map[string]bool{
+	"api": true, "id": true, "ip": true, "url": true, "uri": true, "http": true, "https": true,
+	"json": true, "sql": true, "tcp": true, "udp": true, "uuid": true, "ttl": true, "cpu": true,
+	"db": true, "dns": true, "grpc": true, "rpc": true, "sku": true, "tls": true, "ui": true,
+}
+
+// validKey matches the keys accepted in the dictionary: lower case words separated by
+// underscores or dots, e.g. order_id or http.status_code.
+var validKey = regexp.MustCompile(`^[a-z][a-z0-9]*([_.][a-z0-9]+)*$`)
+
+// source is the template of the generated file.
+var source = template.Must(template.New("fields").Parse(`// Code generated by fieldgen from {{.Source}}; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"go.uber.org/zap"
+)
+
+// Keys of the fields of the dictionary.
+const (
+{{- range .Fields}}
+	Key{{.Name}} = "{{.Key}}"
+{{- end}}
+)
+{{range .Fields}}
+// {{.Name}} returns the {{.Key}} field{{if .Doc}}, which {{.Doc}}{{end}}.
+func {{.Name}}(value {{.GoType}}) logger.Field {
+	return {{.Constructor}}(Key{{.Name}}, value)
+}
+{{end}}`))
+
+func main() {
+	in := flag.String("in", "fields.yaml", "field dictionary")
+	out := flag.String("out", "", "generated file; defaults to the dictionary name with a _gen.go suffix")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated file; defaults to $GOPACKAGE, set by go generate")
+	flag.Parse()
+
+	if err := run(*in, *out, *pkg); err != nil {
+		fmt.Fprintln(os.Stderr, "fieldgen:", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the constructors of the dictionary in to the file out.
+func run(in, out, pkg string) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	var dict dictionary
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&dict); err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+
+	if pkg == "" {
+		pkg = dict.Package
+	}
+	if pkg == "" {
+		return errors.New("the package is not set: use -package, go generate or the package key of the dictionary")
+	}
+	if out == "" {
+		out = strings.TrimSuffix(in, filepath.Ext(in)) + "_gen.go"
+	}
+
+	code, err := generate(filepath.Base(in), pkg, dict.Fields)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+	return os.WriteFile(out, code, 0o644)
+}
+
+// generate validates the fields and renders the formatted source of their constructors.
+func generate(sourceName, pkg string, fields []field) ([]byte, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("the dictionary has no fields")
+	}
+
+	var (
+		rendered = make([]templateField, 0, len(fields))
+		imports  = map[string]bool{}
+		keys     = map[string]int{}
+		names    = map[string]int{}
+		errs     []error
+	)
+	for i, f := range fields {
+		entry := i + 1
+		if !validKey.MatchString(f.Key) {
+			errs = append(errs, fmt.Errorf("field %d: key %q must be lower case words separated by _ or .", entry, f.Key))
+			continue
+		}
+		typ, ok := fieldTypes[f.Type]
+		if !ok {
+			errs = append(errs, fmt.Errorf("field %s: unknown type %q", f.Key, f.Type))
+			continue
+		}
+		name := f.Name
+		if name == "" {
+			name = goName(f.Key)
+		}
+		if !token.IsIdentifier(name) || !token.IsExported(name) {
+			errs = append(errs, fmt.Errorf("field %s: name %q is not an exported Go identifier", f.Key, name))
+			continue
+		}
+		if prev, dup := keys[f.Key]; dup {
+			errs = append(errs, fmt.Errorf("field %d: key %q is already defined by field %d", entry, f.Key, prev))
+			continue
+		}
+		if prev, dup := names[name]; dup {
+			errs = append(errs, fmt.Errorf("field %s: name %s is already used by field %d", f.Key, name, prev))
+			continue
+		}
+		keys[f.Key], names[name] = entry, entry
+
+		for _, imp := range typ.imports {
+			imports[imp] = true
+		}
+		rendered = append(rendered, templateField{
+			Name:        name,
+			Key:         f.Key,
+			Doc:         strings.TrimSuffix(strings.TrimSpace(f.Doc), "."),
+			GoType:      typ.goType,
+			Constructor: typ.constructor,
+		})
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var importList []string
+	for imp := range imports {
+		importList = append(importList, imp)
+	}
+
+	var buf bytes.Buffer
+	err := source.Execute(&buf, map[string]any{
+		"Source":  sourceName,
+		"Package": pkg,
+		"Imports": importList,
+		"Fields":  rendered,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// goName converts a key such as order_id or http.status_code to an exported Go name
+// such as OrderID or HTTPStatusCode.
+func goName(key string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(key, func(r rune) bool { return r == '_' || r == '.' }) {
+		if initialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+// Command go-logger provides maintenance utilities for logs written by the logger package.
+//
+// Usage:
+//
+//	go-logger decrypt -key-file /etc/app/log.key [-key-encoding hex] [-in app.log] [-out app.decrypted.log]
+//
+// The key file holds the key encryption key passed to logger.NewAESKeyWrapper,
+// hex encoded by default; use -key-encoding to read base64 or raw key files.
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	logger "github.com/andryhardiyanto/go-logger"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "decrypt":
+		err = runDecrypt(os.Args[2:])
+	case "help", "-h", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "go-logger:", err)
+		os.Exit(1)
+	}
+}
+
+// usage prints the available commands.
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: go-logger <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	fmt.Fprintln(os.Stderr, "  decrypt   decrypt a log file written with logger.WithFileEncryption")
+}
+
+// runDecrypt implements the decrypt command.
+func runDecrypt(args []string) error {
+	fs := flag.NewFlagSet("decrypt", flag.ContinueOnError)
+	keyFile := fs.String("key-file", "", "path to the key encryption key")
+	keyEncoding := fs.String("key-encoding", "hex", "encoding of the key file: hex, base64 or raw")
+	in := fs.String("in", "-", "encrypted log file, - for stdin")
+	out := fs.String("out", "-", "destination of the decrypted entries, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *keyFile == "" {
+		return errors.New("decrypt: -key-file is required")
+	}
+
+	key, err := readKey(*keyFile, *keyEncoding)
+	if err != nil {
+		return err
+	}
+
+	wrapper, err := logger.NewAESKeyWrapper(key)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return logger.DecryptLog(r, w, wrapper)
+}
+
+// readKey loads a key file in the given encoding.
+func readKey(path, encoding string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key []byte
+	switch encoding {
+	case "hex":
+		key, err = hex.DecodeString(string(bytes.TrimSpace(raw)))
+	case "base64":
+		key, err = base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw)))
+	case "raw":
+		key = raw
+	default:
+		return nil, fmt.Errorf("unsupported key encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key file %s is not valid %s: %w", path, encoding, err)
+	}
+
+	return key, nil
+}
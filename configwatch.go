@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// configFile is the logging configuration read by WatchConfigFile. Settings left out
+	// are reset to the value the logger had when the watch started.
+	configFile struct {
+		Level          Level               `yaml:"level"`
+		Sampling       *configFileSampling `yaml:"sampling"`
+		RedactedFields []string            `yaml:"redacted_fields"`
+	}
+
+	// configFileSampling is the sampling of a configFile; zero for both disables sampling.
+	configFileSampling struct {
+		Initial    int `yaml:"initial"`
+		Thereafter int `yaml:"thereafter"`
+	}
+
+	// configWatcher applies the changes of a configuration file to a logger.
+	configWatcher struct {
+		z    *zapLogger
+		path string
+		// base holds the settings of the logger when the watch started.
+		base configSettings
+		// last is the content of the file last read, nil before the first read.
+		last []byte
+		// lastErr is the last read error, logged once until it changes.
+		lastErr string
+	}
+
+	// configSettings are the settings controlled by a configuration file.
+	configSettings struct {
+		level    zapcore.Level
+		sampling configFileSampling
+		redacted []string
+	}
+)
+
+// WatchConfigFile applies the level, sampling and field redaction of a YAML or JSON
+// configuration file to the logger and all its children, re-reading the file every
+// interval until the context is done, and returns the context error. It is meant for a
+// Kubernetes ConfigMap mounted as a volume, so a platform team can change the verbosity
+// of a whole cluster without redeploying; the kubelet updates mounted files in place.
+// Every change is recorded by an audit entry like in SetLevel, with the file as
+// changed_by unless the context carries a user ID. Settings left out of the file, or a
+// missing file, reset the logger to its settings when the watch started. An invalid
+// file is logged as an error and leaves the logger unchanged; a file is only applied
+// when its content changes, so runtime changes made in between are kept. A panic while
+// reading or applying the file is reported as a goroutine_panic diagnostic and the watch
+// restarts after a backoff.
+//
+// The file has the following keys, all optional:
+//
+//	level: debug              # debug, info, warning, error, panic or fatal
+//	sampling:                 # zero for both disables sampling
+//	  initial: 100
+//	  thereafter: 100
+//	redacted_fields: [email, card_number]
+//
+// Parameters:
+//   - ctx: The context stopping the watch
+//   - log: The logger created by NewLogger
+//   - path: The path of the configuration file
+//   - interval: The time between reads of the file
+//
+// Returns:
+//   - error: An error if the logger was not created by NewLogger, or the context error
+//     once the watch stops
+//
+// Example:
+//
+//	go func() {
+//	    _ = logger.WatchConfigFile(ctx, log, "/etc/logging/config.yaml", 10*time.Second)
+//	}()
+func WatchConfigFile(ctx context.Context, log Logger, path string, interval time.Duration) error {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return errRuntimeUnsupported
+	}
+	if _, ok := UserIDFromContext(ctx); !ok {
+		ctx = WithUserID(ctx, "config_file:"+path)
+	}
+
+	w := &configWatcher{z: z, path: path, base: currentSettings(z)}
+	z.state.supervisor.supervise("config_file:"+path, ctx.Done(), func() {
+		w.watch(ctx, interval)
+	})
+	return ctx.Err()
+}
+
+// watch polls the file every interval until the context is done.
+func (w *configWatcher) watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll reads the file and applies it when its content changed.
+func (w *configWatcher) poll(ctx context.Context) {
+	data, err := os.ReadFile(w.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = []byte{}, nil
+	}
+	if err != nil {
+		if err.Error() != w.lastErr {
+			w.lastErr = err.Error()
+			w.z.Error(ctx, "failed to read logger config file", zap.String("path", w.path), zap.Error(err))
+		}
+		return
+	}
+	w.lastErr = ""
+	if w.last != nil && bytes.Equal(data, w.last) {
+		return
+	}
+	w.last = data
+
+	settings, err := w.parse(data)
+	if err != nil {
+		w.z.Error(ctx, "invalid logger config file", zap.String("path", w.path), zap.Error(err))
+		return
+	}
+	w.apply(ctx, settings)
+}
+
+// parse decodes the file and returns the settings it asks for, falling back to the
+// settings the watch started with.
+func (w *configWatcher) parse(data []byte) (configSettings, error) {
+	var file configFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return configSettings{}, err
+	}
+
+	settings := w.base
+	if file.Level != "" {
+		level, err := parseLevel(file.Level)
+		if err != nil {
+			return configSettings{}, err
+		}
+		settings.level = level.Level()
+	}
+	if file.Sampling != nil {
+		if file.Sampling.Initial < 0 || file.Sampling.Thereafter < 0 {
+			return configSettings{}, errors.New("sampling values cannot be negative")
+		}
+		settings.sampling = *file.Sampling
+	}
+	if file.RedactedFields != nil {
+		settings.redacted = slices.Clone(file.RedactedFields)
+		sort.Strings(settings.redacted)
+		settings.redacted = slices.Compact(settings.redacted)
+	}
+	return settings, nil
+}
+
+// apply changes the settings of the logger that differ from the wanted ones.
+func (w *configWatcher) apply(ctx context.Context, settings configSettings) {
+	current := currentSettings(w.z)
+	if settings.level != current.level {
+		setLevel(ctx, w.z, settings.level)
+	}
+	if settings.sampling != current.sampling {
+		_ = SetSampling(ctx, w.z, settings.sampling.Initial, settings.sampling.Thereafter)
+	}
+	if !slices.Equal(settings.redacted, current.redacted) {
+		_ = SetRedactedFields(ctx, w.z, settings.redacted...)
+	}
+}
+
+// currentSettings returns the settings of the logger controlled by a configuration file.
+func currentSettings(z *zapLogger) configSettings {
+	settings := configSettings{
+		level:    z.state.zapConfig.Level.Level(),
+		redacted: z.state.redacted.list(),
+	}
+	if s := z.state.sampler.current(); s != nil {
+		settings.sampling = configFileSampling{Initial: s.Initial, Thereafter: s.Thereafter}
+	}
+	return settings
+}
@@ -0,0 +1,103 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc/codes"
+)
+
+// connectInterceptor implements connect.Interceptor with access logging and trace propagation.
+type connectInterceptor struct {
+	log   Logger
+	newID func() string
+}
+
+// ConnectInterceptor returns a connect-go interceptor.
+// On handlers it writes one access log entry per call using the same fields as the
+// gRPC interceptors, after reading the request ID and trace context from the request
+// headers, and echoes the request ID in the X-Request-ID response header. On clients it
+// propagates the correlation IDs of the context as headers.
+//
+// Parameters:
+//   - log: The logger writing the access log entries
+//
+// Returns:
+//   - connect.Interceptor: The interceptor
+//
+// Example:
+//
+//	path, handler := greetv1connect.NewGreetServiceHandler(svc, connect.WithInterceptors(ConnectInterceptor(log)))
+func ConnectInterceptor(log Logger) connect.Interceptor {
+	return &connectInterceptor{log: log, newID: idGenerator(log)}
+}
+
+// WrapUnary logs unary handler calls and propagates correlation IDs on unary client calls.
+func (i *connectInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			headersFromContext(ctx, req.Header().Set)
+			return next(ctx, req)
+		}
+
+		start := time.Now()
+		ctx, requestID := contextFromHeaders(ctx, req.Header().Get, i.newID)
+
+		resp, err := next(ctx, req)
+		echoRequestID(resp, err, requestID)
+
+		logRPC(ctx, i.log, req.Peer().Protocol, req.Spec().Procedure, connectCode(err), start, req.Peer().Addr, err)
+		return resp, err
+	}
+}
+
+// WrapStreamingClient propagates correlation IDs on streaming client calls.
+func (i *connectInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		headersFromContext(ctx, conn.RequestHeader().Set)
+		return conn
+	}
+}
+
+// WrapStreamingHandler logs streaming handler calls.
+func (i *connectInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		start := time.Now()
+		ctx, requestID := contextFromHeaders(ctx, conn.RequestHeader().Get, i.newID)
+		conn.ResponseHeader().Set(HeaderRequestID, requestID)
+
+		err := next(ctx, conn)
+
+		logRPC(ctx, i.log, conn.Peer().Protocol, conn.Spec().Procedure, connectCode(err), start, conn.Peer().Addr, err)
+		return err
+	}
+}
+
+// echoRequestID sets the X-Request-ID header of the response, or of the error metadata
+// when the call failed.
+func echoRequestID(resp connect.AnyResponse, err error, requestID string) {
+	if resp != nil {
+		resp.Header().Set(HeaderRequestID, requestID)
+		return
+	}
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		connectErr.Meta().Set(HeaderRequestID, requestID)
+	}
+}
+
+// connectCode converts the error of a Connect call to the equivalent gRPC status code.
+// Connect codes share their numeric values with gRPC codes.
+func connectCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	var connectErr *connect.Error
+	if errors.As(err, &connectErr) {
+		return codes.Code(connectErr.Code())
+	}
+	return codes.Unknown
+}
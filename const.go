@@ -1,7 +1,10 @@
 package logger
 
 import (
+	"time"
+
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type (
@@ -15,12 +18,50 @@ type (
 		Key   ContextKey
 		Value string
 	}
+
+	// DurationField is a context key carrying a time.Duration, such as the latency of an
+	// upstream call, logged as a number of milliseconds rather than a string.
+	DurationField struct {
+		Key   ContextKey
+		Value time.Duration
+	}
+
+	// TimeField is a context key carrying a time.Time, such as when a request was
+	// received, logged as a time rather than a string.
+	TimeField struct {
+		Key   ContextKey
+		Value time.Time
+	}
+
+	// IntField is a context key carrying an int, int32 or int64, such as a retry count,
+	// logged as a number rather than a string.
+	IntField struct {
+		Key   ContextKey
+		Value int64
+	}
+
+	// ErrorField is a context key carrying an error, such as the cause of a retry,
+	// logged as the error message.
+	ErrorField struct {
+		Key   ContextKey
+		Value error
+	}
+
+	// Entry describes a log entry handed to filters and processors before it is encoded.
+	// It embeds the zap entry (level, time, logger name, message, caller and stack)
+	// and carries every structured field, including those added with With and
+	// the fields extracted from the context.
+	Entry struct {
+		zapcore.Entry
+		Fields []Field
+	}
 )
 
 const (
 	AppModeDevelopment AppMode = "development"
 	AppModeStaging     AppMode = "staging"
 	AppModeProduction  AppMode = "production"
+	AppModeCLI         AppMode = "cli"
 	AppModeEmpty       AppMode = "empty"
 
 	EncodingJson    Encoding = "json"
@@ -44,12 +85,12 @@ const (
 	ContextKeyAcceptLanguage         ContextKey = "accept_language"
 	ContextKeyUserContext            ContextKey = "user_context"
 	ContextKeyIpAddress              ContextKey = "ip_address"
+	ContextKeyDataResidency          ContextKey = "data_residency"
 )
 
-// ContextKeys contains all predefined context keys for automatic field extraction.
-// This slice is used by GetLoggingFields to iterate through all possible context values.
-// Add new context keys to this slice to enable automatic extraction.
-var contextKeys = []ContextKey{
+// defaultContextKeys contains all predefined context keys for automatic field extraction.
+// contextKeys returns them along with the keys added by AppendContextKeys.
+var defaultContextKeys = []ContextKey{
 	ContextKeyUserID,
 	ContextKeyTraceID,
 	ContextKeySpanID,
@@ -61,6 +102,7 @@ var contextKeys = []ContextKey{
 	ContextKeyAcceptLanguage,
 	ContextKeyUserContext,
 	ContextKeyIpAddress,
+	ContextKeyDataResidency,
 }
 
 var (
@@ -68,6 +110,7 @@ var (
 		AppModeDevelopment: true,
 		AppModeProduction:  true,
 		AppModeStaging:     true,
+		AppModeCLI:         true,
 	}
 	validEncoding = map[Encoding]bool{
 		EncodingConsole: true,
@@ -76,12 +119,15 @@ var (
 )
 
 // String returns the string representation of AppMode.
-// Returns AppModeEmpty if the mode is not valid.
+// Returns AppModeEmpty if the mode is neither built in nor registered with RegisterAppMode.
 // Implements the Stringer interface for better debugging and logging.
 func (am AppMode) String() string {
 	if validAppMode[am] {
 		return string(am)
 	}
+	if _, ok := appModePreset(am); ok {
+		return string(am)
+	}
 	return string(AppModeEmpty)
 }
 
@@ -100,3 +146,20 @@ func (e Encoding) String() string {
 func (ck ContextKey) String() string {
 	return string(ck)
 }
+
+// Field returns the last field with the given key.
+// Fields added later override earlier ones, mirroring how the entry is encoded.
+//
+// Example:
+//
+//	if f, ok := entry.Field("path"); ok && f.String == "/healthz" {
+//	    return true
+//	}
+func (e Entry) Field(key string) (Field, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i], true
+		}
+	}
+	return Field{}, false
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Field keys written by the message consumer middleware.
+const (
+	FieldKeyMessagingSystem      = "messaging_system"
+	FieldKeyMessagingDestination = "messaging_destination"
+	FieldKeyMessageID            = "message_id"
+	FieldKeyMessageOutcome       = "message_outcome"
+)
+
+// Outcomes of consumed messages, written as message_outcome.
+const (
+	MessageOutcomeSuccess = "success"
+	MessageOutcomeFailure = "failure"
+)
+
+// messageProcessedMessage is the message of the entries written by ConsumerMiddleware.
+const messageProcessedMessage = "message processed"
+
+type (
+	// ConsumedMessage is the broker-neutral description of a consumed message, such as
+	// a Kafka record, an SQS message or a Pub/Sub message.
+	ConsumedMessage struct {
+		// System names the broker, e.g. "kafka", "sqs" or "pubsub".
+		System string
+		// Destination is the topic, queue or subscription the message was consumed from.
+		Destination string
+		// ID identifies the message, e.g. the SQS message ID or "partition/offset".
+		ID string
+		// Attributes are the headers or attributes of the message. X-Request-ID,
+		// traceparent and X-Log-Dimensions are read from them, case-insensitively.
+		Attributes map[string]string
+		// Fields are additional fields of the entry, e.g. the Kafka partition.
+		Fields []Field
+	}
+
+	// MessageHandler processes a consumed message of type M.
+	MessageHandler[M any] func(ctx context.Context, msg M) error
+)
+
+// ConsumerMiddleware returns middleware for message handlers writing one entry per
+// message, as HTTPMiddleware does per request. Each message gets its own logging
+// context: the request ID, trace context and dimensions are taken from the message
+// attributes, the request ID is generated when missing, and a field bag is added so
+// AddField calls of the handler end up on the entry. The entry carries the broker,
+// destination, message ID, outcome and duration, at ErrorLevel when the handler
+// fails and InfoLevel otherwise. The describe function maps the message type of a
+// client library to a ConsumedMessage.
+//
+// Parameters:
+//   - log: The logger writing the entries
+//   - describe: Describes a message of the client library
+//
+// Returns:
+//   - func(MessageHandler[M]) MessageHandler[M]: The middleware
+//
+// Example:
+//
+//	consume := ConsumerMiddleware(log, func(m *sqs.Message) ConsumedMessage {
+//	    attrs := map[string]string{}
+//	    for k, v := range m.MessageAttributes {
+//	        attrs[k] = aws.StringValue(v.StringValue)
+//	    }
+//	    return ConsumedMessage{System: "sqs", Destination: queueName, ID: *m.MessageId, Attributes: attrs}
+//	})(handleOrder)
+//	err := consume(ctx, msg)
+func ConsumerMiddleware[M any](log Logger, describe func(msg M) ConsumedMessage) func(MessageHandler[M]) MessageHandler[M] {
+	newID := idGenerator(log)
+	return func(next MessageHandler[M]) MessageHandler[M] {
+		return func(ctx context.Context, msg M) error {
+			start := time.Now()
+			desc := describe(msg)
+			ctx, _ = contextFromHeaders(ctx, desc.attribute, newID)
+
+			err := next(ctx, msg)
+
+			fields := make([]Field, 0, len(desc.Fields)+6)
+			fields = append(fields,
+				zap.String(FieldKeyMessagingSystem, desc.System),
+				zap.String(FieldKeyMessagingDestination, desc.Destination),
+				zap.String(FieldKeyMessageID, desc.ID),
+				zap.Duration(FieldKeyDuration, time.Since(start)),
+			)
+			fields = append(fields, desc.Fields...)
+			if err != nil {
+				fields = append(fields, zap.String(FieldKeyMessageOutcome, MessageOutcomeFailure), zap.Error(err))
+				log.Error(ctx, messageProcessedMessage, fields...)
+				return err
+			}
+			fields = append(fields, zap.String(FieldKeyMessageOutcome, MessageOutcomeSuccess))
+			log.Info(ctx, messageProcessedMessage, fields...)
+			return nil
+		}
+	}
+}
+
+// attribute returns the value of the attribute with the key, matched case-insensitively.
+func (m ConsumedMessage) attribute(key string) string {
+	if v, ok := m.Attributes[key]; ok {
+		return v
+	}
+	for k, v := range m.Attributes {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
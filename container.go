@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys of the parts of an entry split by WithContainerOutput.
+const (
+	FieldKeyPartialID      = "partial_id"
+	FieldKeyPartialIndex   = "partial_index"
+	FieldKeyPartialLast    = "partial_last"
+	FieldKeyPartialMessage = "partial_message"
+)
+
+const (
+	// defaultContainerLineBytes is the line limit of the Docker and CRI log formats,
+	// beyond which container runtimes split lines themselves.
+	defaultContainerLineBytes = 16 * 1024
+	// minContainerLineBytes is the smallest line limit of WithContainerOutput, leaving
+	// room for the partial fields and some content in every part.
+	minContainerLineBytes = 512
+)
+
+// containerBufferPool provides the buffers of the parts returned by containerEncoder.
+var containerBufferPool = buffer.NewPool()
+
+// containerEncoder wraps the JSON encoder of WithContainerOutput and splits entries
+// longer than the line limit into parts. Each part is a JSON object of its own with
+// the level and time of the entry, the partial fields and a piece of the encoded
+// entry in partial_message; concatenating the pieces in partial_index order restores
+// the entry.
+type containerEncoder struct {
+	zapcore.Encoder
+	// parts encodes the parts; it never carries fields added with With.
+	parts zapcore.Encoder
+	max   int
+}
+
+// utcRFC3339NanoTimeEncoder encodes times as UTC RFC 3339 with nanoseconds, the time
+// format of container log drivers.
+func utcRFC3339NanoTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(t.UTC().Format(time.RFC3339Nano))
+}
+
+// validateContainerLineBytes returns an error if the line limit of WithContainerOutput
+// leaves no room for content.
+func validateContainerLineBytes(maxBytes int) error {
+	if maxBytes != 0 && maxBytes < minContainerLineBytes {
+		return fmt.Errorf("container line limit must be at least %d bytes, got %d", minContainerLineBytes, maxBytes)
+	}
+	return nil
+}
+
+// newContainerEncoder wraps the JSON encoder to split entries longer than maxBytes,
+// including the newline; zero uses 16 KiB.
+func newContainerEncoder(encoder zapcore.Encoder, encoderConfig zapcore.EncoderConfig, maxBytes int) zapcore.Encoder {
+	if maxBytes <= 0 {
+		maxBytes = defaultContainerLineBytes
+	}
+	return &containerEncoder{Encoder: encoder, parts: zapcore.NewJSONEncoder(encoderConfig), max: maxBytes}
+}
+
+// Clone returns a copy of the encoder keeping the line limit.
+func (e *containerEncoder) Clone() zapcore.Encoder {
+	return &containerEncoder{Encoder: e.Encoder.Clone(), parts: e.parts, max: e.max}
+}
+
+// EncodeEntry encodes the entry, split into parts of at most the line limit when it is
+// longer.
+func (e *containerEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil || buf.Len() <= e.max {
+		return buf, err
+	}
+	defer buf.Free()
+
+	line := buf.String()
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		line = line[:len(line)-1]
+	}
+	part := zapcore.Entry{Level: ent.Level, Time: ent.Time, LoggerName: ent.LoggerName}
+	id := strconv.FormatUint(rand.Uint64(), 16)
+
+	out := containerBufferPool.Get()
+	for index := 0; len(line) > 0; index++ {
+		// The overhead is measured with an empty piece and partial_last false, its
+		// longest value.
+		overhead, err := e.parts.EncodeEntry(part, partFields(id, index, false, ""))
+		if err != nil {
+			out.Free()
+			return nil, err
+		}
+		budget := e.max - overhead.Len()
+		overhead.Free()
+
+		n := jsonPrefixLen(line, max(budget, 1))
+		piece := line[:n]
+		line = line[n:]
+		encoded, err := e.parts.EncodeEntry(part, partFields(id, index, len(line) == 0, piece))
+		if err != nil {
+			out.Free()
+			return nil, err
+		}
+		_, _ = out.Write(encoded.Bytes())
+		encoded.Free()
+	}
+	return out, nil
+}
+
+// partFields returns the partial fields of a part.
+func partFields(id string, index int, last bool, piece string) []Field {
+	return []Field{
+		zap.String(FieldKeyPartialID, id),
+		zap.Int(FieldKeyPartialIndex, index),
+		zap.Bool(FieldKeyPartialLast, last),
+		zap.String(FieldKeyPartialMessage, piece),
+	}
+}
+
+// jsonPrefixLen returns the length of the longest prefix of s, cut at a rune boundary,
+// whose JSON string escaping fits in budget bytes; at least one rune.
+func jsonPrefixLen(s string, budget int) int {
+	size := 0
+	for i, r := range s {
+		width := utf8.RuneLen(r)
+		switch {
+		case r == '"' || r == '\\':
+			width = 2
+		case r < 0x20:
+			width = 6
+		case r == utf8.RuneError:
+			width = 6
+		}
+		if size+width > budget && i > 0 {
+			return i
+		}
+		size += width
+	}
+	return len(s)
+}
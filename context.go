@@ -0,0 +1,196 @@
+package logger
+
+import "context"
+
+// loggerContextKey is the context key of the context-carried logger.
+type loggerContextKey struct{}
+
+// ContextWithLogger returns a copy of the context carrying the logger.
+// Use it to hand a request-scoped child logger to code that only receives a context.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - log: The logger to carry
+//
+// Returns:
+//   - context.Context: The context carrying the logger
+//
+// Example:
+//
+//	ctx = ContextWithLogger(ctx, log.With(zap.String("order_id", id)))
+//	FromContext(ctx, log).Info(ctx, "order created")
+func ContextWithLogger(ctx context.Context, log Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, log)
+}
+
+// FromContext returns the logger carried by the context, or fallback when there is none.
+//
+// Parameters:
+//   - ctx: The context to read the logger from
+//   - fallback: The logger returned when the context carries none
+//
+// Returns:
+//   - Logger: The context-carried logger or fallback
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if ctx == nil {
+		return fallback
+	}
+	if log, ok := ctx.Value(loggerContextKey{}).(Logger); ok {
+		return log
+	}
+	return fallback
+}
+
+// DetachContext returns a fresh context holding the logging values of ctx.
+// The values of all registered context keys, the dimensions and the context-carried
+// logger are copied; cancellation, deadlines and any other values of ctx are not.
+// Fields added with AddField are copied into a new field bag, so fields added by the
+// background work do not show up in the request's entries.
+// Use it when starting background work from a request so its logs keep the trace
+// and request IDs after the request has finished.
+//
+// Parameters:
+//   - ctx: The request context
+//
+// Returns:
+//   - context.Context: A context that is never canceled
+//
+// Example:
+//
+//	go func(ctx context.Context) {
+//	    sendReceipt(ctx, order)
+//	    log.Info(ctx, "receipt sent") // still carries trace_id and request_id
+//	}(DetachContext(r.Context()))
+func DetachContext(ctx context.Context) context.Context {
+	detached := context.Background()
+	if ctx == nil {
+		return detached
+	}
+
+	for _, key := range contextKeys() {
+		if value := ctx.Value(key); value != nil {
+			detached = context.WithValue(detached, key, value)
+		}
+	}
+	if log := ctx.Value(loggerContextKey{}); log != nil {
+		detached = context.WithValue(detached, loggerContextKey{}, log)
+	}
+	if dims := dimensionsFromContext(ctx); dims != nil {
+		detached = context.WithValue(detached, dimensionsKey{}, dims)
+	}
+	if sampled, ok := ctx.Value(traceSampledKey{}).(bool); ok {
+		detached = context.WithValue(detached, traceSampledKey{}, sampled)
+	}
+	if fieldBagFromContext(ctx) != nil {
+		detached = context.WithValue(detached, fieldBagKey{}, &fieldBag{fields: bagFields(ctx)})
+	}
+
+	return detached
+}
+
+// WithRequestID returns a copy of the context carrying the request ID, logged as
+// request_id and propagated by the outgoing header helpers.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - id: The request ID
+//
+// Returns:
+//   - context.Context: The context carrying the request ID
+//
+// Example:
+//
+//	ctx = logger.WithRequestID(ctx, msg.Headers["X-Request-ID"])
+//	log.Info(ctx, "message received") // {"message":"message received","request_id":"..."}
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ContextKeyRequestID, id)
+}
+
+// RequestIDFromContext returns the request ID carried by the context.
+//
+// Parameters:
+//   - ctx: The context to read the request ID from
+//
+// Returns:
+//   - string: The request ID, empty when there is none
+//   - bool: Whether the context carries a request ID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	return getStringFromContext(ctx, ContextKeyRequestID)
+}
+
+// WithTraceID returns a copy of the context carrying the trace ID, logged as trace_id.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - id: The trace ID, e.g. 32 lowercase hex characters
+//
+// Returns:
+//   - context.Context: The context carrying the trace ID
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ContextKeyTraceID, id)
+}
+
+// TraceIDFromContext returns the trace ID carried by the context.
+//
+// Parameters:
+//   - ctx: The context to read the trace ID from
+//
+// Returns:
+//   - string: The trace ID, empty when there is none
+//   - bool: Whether the context carries a trace ID
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	return getStringFromContext(ctx, ContextKeyTraceID)
+}
+
+// WithSpanID returns a copy of the context carrying the span ID, logged as span_id.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - id: The span ID, e.g. 16 lowercase hex characters
+//
+// Returns:
+//   - context.Context: The context carrying the span ID
+func WithSpanID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ContextKeySpanID, id)
+}
+
+// SpanIDFromContext returns the span ID carried by the context.
+//
+// Parameters:
+//   - ctx: The context to read the span ID from
+//
+// Returns:
+//   - string: The span ID, empty when there is none
+//   - bool: Whether the context carries a span ID
+func SpanIDFromContext(ctx context.Context) (string, bool) {
+	return getStringFromContext(ctx, ContextKeySpanID)
+}
+
+// WithUserID returns a copy of the context carrying the user ID, logged as user_id.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - id: The ID of the authenticated user
+//
+// Returns:
+//   - context.Context: The context carrying the user ID
+//
+// Example:
+//
+//	ctx = logger.WithUserID(r.Context(), claims.Subject)
+//	next.ServeHTTP(w, r.WithContext(ctx))
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ContextKeyUserID, id)
+}
+
+// UserIDFromContext returns the user ID carried by the context.
+//
+// Parameters:
+//   - ctx: The context to read the user ID from
+//
+// Returns:
+//   - string: The user ID, empty when there is none
+//   - bool: Whether the context carries a user ID
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	return getStringFromContext(ctx, ContextKeyUserID)
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// contextStructTag is the struct tag naming the log field of a struct field.
+const contextStructTag = "log"
+
+type (
+	// contextStruct describes a struct type carried under a context key whose tagged
+	// fields are logged.
+	contextStruct struct {
+		key    any
+		typ    reflect.Type
+		fields []contextStructField
+	}
+
+	// contextStructField is a tagged field of a registered struct.
+	contextStructField struct {
+		index     []int
+		name      string
+		omitEmpty bool
+	}
+)
+
+var (
+	contextStructsMu sync.RWMutex
+	contextStructs   []contextStruct
+)
+
+// RegisterContextStruct registers a struct type carried in contexts under key. Every
+// entry logged with a context carrying a T or *T under key includes the struct fields
+// tagged with `log:"field_name"`, so applications can keep one request-info struct in
+// the context instead of a dozen separate string values. The ",omitempty" tag option
+// skips zero values, "-" and untagged fields are never logged, and fields of embedded
+// structs are included. Registering a key again replaces its struct type. Register
+// structs at startup, before logging.
+//
+// Parameters:
+//   - key: The context key the struct is stored under
+//
+// Returns:
+//   - error: An error if T is not a struct or has no tagged fields
+//
+// Example:
+//
+//	type RequestInfo struct {
+//	    TenantID string `log:"tenant_id"`
+//	    Plan     string `log:"plan,omitempty"`
+//	    Token    string // not logged
+//	}
+//	type requestInfoKey struct{}
+//
+//	_ = logger.RegisterContextStruct[RequestInfo](requestInfoKey{})
+//	ctx = context.WithValue(ctx, requestInfoKey{}, &RequestInfo{TenantID: "acme"})
+//	log.Info(ctx, "invoice sent") // {"message":"invoice sent","tenant_id":"acme"}
+func RegisterContextStruct[T any](key any) error {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("context struct %s must be a struct type", typ)
+	}
+	if key == nil || !reflect.TypeOf(key).Comparable() {
+		return fmt.Errorf("context struct %s needs a non-nil, comparable key", typ)
+	}
+
+	fields := taggedFields(typ, nil)
+	if len(fields) == 0 {
+		return fmt.Errorf("context struct %s has no fields tagged with %q", typ, contextStructTag)
+	}
+
+	contextStructsMu.Lock()
+	defer contextStructsMu.Unlock()
+	registered := contextStruct{key: key, typ: typ, fields: fields}
+	for i := range contextStructs {
+		if contextStructs[i].key == key {
+			contextStructs[i] = registered
+			return nil
+		}
+	}
+	contextStructs = append(contextStructs, registered)
+	return nil
+}
+
+// taggedFields returns the tagged exported fields of the struct type, descending into
+// embedded structs. index is the index path of typ within the registered struct.
+func taggedFields(typ reflect.Type, index []int) []contextStructField {
+	var fields []contextStructField
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		path := append(append([]int(nil), index...), i)
+
+		tag, tagged := f.Tag.Lookup(contextStructTag)
+		if f.Anonymous && !tagged && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, taggedFields(f.Type, path)...)
+			continue
+		}
+		if !f.IsExported() || !tagged || tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, contextStructField{index: path, name: name, omitEmpty: opts == "omitempty"})
+	}
+	return fields
+}
+
+// appendContextStructFields appends the log fields of the registered structs carried by
+// the context to fields.
+func appendContextStructFields(fields []Field, ctx context.Context) []Field {
+	if ctx == nil {
+		return fields
+	}
+
+	contextStructsMu.RLock()
+	defer contextStructsMu.RUnlock()
+
+	for _, cs := range contextStructs {
+		v := reflect.ValueOf(ctx.Value(cs.key))
+		if v.Kind() == reflect.Pointer && !v.IsNil() {
+			v = v.Elem()
+		}
+		if !v.IsValid() || v.Type() != cs.typ {
+			continue
+		}
+		for _, f := range cs.fields {
+			fv := v.FieldByIndex(f.index)
+			if f.omitEmpty && fv.IsZero() {
+				continue
+			}
+			fields = append(fields, zap.Any(f.name, fv.Interface()))
+		}
+	}
+	return fields
+}
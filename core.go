@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// levelCore wraps a zapcore.Core and replaces its level with its own. Cores created
+	// with newLevelCore wrap the lowered core, so entries below the level of the wrapped
+	// core still go through its sampling, filters, storm suppression and other checks.
+	levelCore struct {
+		zapcore.Core
+		level zapcore.LevelEnabler
+		// fixed keeps the level when an outer levelCore lowers the core, as for the
+		// separate level of New Relic forwarding.
+		fixed bool
+	}
+
+	// rateLimitCore wraps a zapcore.Core and drops entries exceeding a token bucket budget.
+	rateLimitCore struct {
+		zapcore.Core
+		bucket  *tokenBucket
+		dropped *atomic.Uint64
+	}
+
+	// teeCore duplicates entries into several cores like zapcore.NewTee, but writes an
+	// entry only to the cores enabling its level. The cores wrapping it add themselves to
+	// checked entries and write through it, so a plain tee would hand every entry to all
+	// of its cores regardless of their levels.
+	teeCore []zapcore.Core
+
+	// lowerableCore is implemented by the cores of this package wrapping other cores.
+	lowerableCore interface {
+		// lowered returns a copy of the core enabling every level, whose wrapped cores
+		// are lowered too.
+		lowered() zapcore.Core
+	}
+)
+
+// newLevelCore wraps core with level. Entries the level enables are checked by the
+// lowered core, so the level can both raise and lower the level of core.
+func newLevelCore(core zapcore.Core, level zapcore.LevelEnabler) *levelCore {
+	return &levelCore{Core: lowerCore(core), level: level}
+}
+
+// lowerCore returns core enabling every level while keeping its other checks. Cores not
+// built by this package are wrapped with a levelCore at DebugLevel, which writes the
+// entries they do not enable to them directly.
+func lowerCore(core zapcore.Core) zapcore.Core {
+	if l, ok := core.(lowerableCore); ok {
+		return l.lowered()
+	}
+	return &levelCore{Core: core, level: zapcore.DebugLevel}
+}
+
+// Enabled reports whether the level is enabled by the overriding level.
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+// With returns a child core keeping the overriding level.
+func (c *levelCore) With(fields []Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level, fixed: c.fixed}
+}
+
+// lowered returns the lowered wrapped core, or the core itself when its level is fixed.
+func (c *levelCore) lowered() zapcore.Core {
+	if c.fixed {
+		return c
+	}
+	return lowerCore(c.Core)
+}
+
+// Check lets the wrapped core check the entries the overriding level enables. Entries
+// a wrapped core not built by this package does not enable are written to it directly.
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if c.Core.Enabled(ent.Level) {
+		return c.Core.Check(ent, ce)
+	}
+	return ce.AddCore(ent, c)
+}
+
+// With returns a child core sharing the token bucket.
+func (c *rateLimitCore) With(fields []Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), bucket: c.bucket, dropped: c.dropped}
+}
+
+// lowered returns a copy of the core sharing the token bucket.
+func (c *rateLimitCore) lowered() zapcore.Core {
+	return &rateLimitCore{Core: lowerCore(c.Core), bucket: c.bucket, dropped: c.dropped}
+}
+
+// Check lets the wrapped core check the entry when the budget allows it and counts it
+// as dropped otherwise.
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if !c.bucket.Allow() {
+		c.dropped.Add(1)
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// Enabled reports whether any of the cores enables the level.
+func (t teeCore) Enabled(level zapcore.Level) bool {
+	for _, c := range t {
+		if c.Enabled(level) {
+			return true
+		}
+	}
+	return false
+}
+
+// With returns a tee of the child cores.
+func (t teeCore) With(fields []Field) zapcore.Core {
+	cores := make(teeCore, len(t))
+	for i, c := range t {
+		cores[i] = c.With(fields)
+	}
+	return cores
+}
+
+// lowered returns a tee of the lowered cores.
+func (t teeCore) lowered() zapcore.Core {
+	cores := make(teeCore, len(t))
+	for i, c := range t {
+		cores[i] = lowerCore(c)
+	}
+	return cores
+}
+
+// Check lets every core check the entry.
+func (t teeCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	for _, c := range t {
+		ce = c.Check(ent, ce)
+	}
+	return ce
+}
+
+// Write writes the entry to the cores enabling its level.
+func (t teeCore) Write(ent zapcore.Entry, fields []Field) error {
+	var errs []error
+	for _, c := range t {
+		if c.Enabled(ent.Level) {
+			errs = append(errs, c.Write(ent, fields))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync flushes every core.
+func (t teeCore) Sync() error {
+	errs := make([]error, len(t))
+	for i, c := range t {
+		errs[i] = c.Sync()
+	}
+	return errors.Join(errs...)
+}
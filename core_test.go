@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLoweredEntriesAreChecked logs below the level of the logger through a tenant
+// logger with a lower level and checks that the entries are sampled, reach the sink
+// next to the New Relic forwarder and keep the separate New Relic level.
+func TestLoweredEntriesAreChecked(t *testing.T) {
+	app, observed := observeNewRelic(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := NewLogger(
+		WithEncoding(EncodingJson),
+		WithOutputPaths([]string{path}),
+		WithLevel(LevelInfo),
+		WithNewRelicApp(app),
+		WithNewRelicLevel(LevelWarning),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := SetSampling(ctx, log, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	tenants, err := NewTenantLoggers(log, TenantConfig{Level: LevelDebug}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		tenants.Get("acme").Debug(ctx, "lowered")
+	}
+	if err := Close(ctx, log); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(b), "lowered"); n != 1 {
+		t.Errorf("sink received %d lowered entries, want 1 after sampling:\n%s", n, b)
+	}
+	if n := observed.FilterMessage("lowered").Len(); n != 0 {
+		t.Errorf("New Relic received %d entries below its level", n)
+	}
+}
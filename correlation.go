@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DiagnosticMissingCorrelation is the kind of diagnostic reported by WithCorrelationCheck
+// for a call site logging without correlation fields.
+const DiagnosticMissingCorrelation = "missing_correlation"
+
+// Fields of a missing_correlation diagnostic.
+const (
+	FieldKeyCallSite        = "call_site"
+	FieldKeyEntryMessage    = "entry_message"
+	FieldKeyCorrelationKeys = "correlation_keys"
+)
+
+// errMissingCorrelation is the error of the missing_correlation diagnostics of strict checks.
+var errMissingCorrelation = errors.New("log entry without correlation fields")
+
+type (
+	// correlationChecker reports the call sites of entries without any of the correlation
+	// keys, once per call site.
+	correlationChecker struct {
+		keys []string
+		diag *diagnostics
+		// strict reports call sites as internal failures rather than warnings.
+		strict bool
+
+		uncorrelated atomic.Uint64
+		mu           sync.Mutex
+		reported     map[string]struct{}
+	}
+
+	// correlationCore wraps a zapcore.Core and checks that entries carry a correlation
+	// field, from the context, With or the log call.
+	correlationCore struct {
+		zapcore.Core
+		checker *correlationChecker
+		// correlated is set once With added one of the keys.
+		correlated bool
+	}
+)
+
+// newCorrelationCore wraps core with the correlation check. It returns core unchanged
+// and a nil checker when keys is nil.
+func newCorrelationCore(core zapcore.Core, keys []ContextKey, diag *diagnostics, strict bool) (zapcore.Core, *correlationChecker) {
+	if keys == nil {
+		return core, nil
+	}
+	checker := &correlationChecker{diag: diag, strict: strict, reported: make(map[string]struct{})}
+	for _, key := range keys {
+		checker.keys = append(checker.keys, string(key))
+	}
+	return &correlationCore{Core: core, checker: checker}, checker
+}
+
+// With returns a child core that is correlated when the fields hold a correlation key.
+func (c *correlationCore) With(fields []Field) zapcore.Core {
+	return &correlationCore{
+		Core:       c.Core.With(fields),
+		checker:    c.checker,
+		correlated: c.correlated || c.checker.has(fields),
+	}
+}
+
+// lowered returns a copy of the core sharing the checker.
+func (c *correlationCore) lowered() zapcore.Core {
+	return &correlationCore{Core: lowerCore(c.Core), checker: c.checker, correlated: c.correlated}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *correlationCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write reports the call site of an entry without correlation fields, then writes it.
+func (c *correlationCore) Write(ent zapcore.Entry, fields []Field) error {
+	if !c.correlated && !c.checker.has(fields) {
+		c.checker.report(ent)
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// has reports whether the fields hold one of the correlation keys with a value.
+func (c *correlationChecker) has(fields []Field) bool {
+	for _, f := range fields {
+		if slices.Contains(c.keys, f.Key) && (f.Type != zapcore.StringType || f.String != "") {
+			return true
+		}
+	}
+	return false
+}
+
+// report counts an uncorrelated entry and reports its call site the first time, keyed
+// by the caller, or by the message for entries without one. Call sites beyond
+// maxCallSites are counted but not reported.
+func (c *correlationChecker) report(ent zapcore.Entry) {
+	c.uncorrelated.Add(1)
+	site := ent.Message
+	if ent.Caller.Defined {
+		site = ent.Caller.TrimmedPath()
+	}
+
+	c.mu.Lock()
+	_, seen := c.reported[site]
+	full := len(c.reported) >= maxCallSites
+	if !seen && !full {
+		c.reported[site] = struct{}{}
+	}
+	c.mu.Unlock()
+	if seen || full {
+		return
+	}
+
+	fields := []Field{
+		zap.String(FieldKeyCallSite, site),
+		zap.String(FieldKeyEntryMessage, ent.Message),
+		zap.Strings(FieldKeyCorrelationKeys, c.keys),
+	}
+	if c.strict {
+		c.diag.report(DiagnosticMissingCorrelation, errMissingCorrelation, fields...)
+		return
+	}
+	c.diag.write(zapcore.WarnLevel, time.Now(), "log entry without correlation fields",
+		append([]Field{zap.String(FieldKeyDiagnostic, DiagnosticMissingCorrelation)}, fields...))
+}
+
+// uncorrelatedCount returns the number of entries without correlation fields, zero when
+// the check is disabled.
+func (c *correlationChecker) uncorrelatedCount() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.uncorrelated.Load()
+}
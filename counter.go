@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxCounterShards bounds the number of shards of a counter.
+const maxCounterShards = 64
+
+type (
+	// counter is a sharded counter for statistics updated on the logging path. Adds go to
+	// a random shard, each on its own cache line, so goroutines logging on different CPUs
+	// neither wait for a lock nor contend for a cache line; loads sum the shards.
+	counter struct {
+		shards []counterShard
+		mask   uint32
+	}
+
+	// counterShard is a shard of a counter, padded to a cache line.
+	counterShard struct {
+		n atomic.Uint64
+		_ [56]byte
+	}
+
+	// levelCounters counts the entries written per level.
+	levelCounters [zapcore.FatalLevel - zapcore.DebugLevel + 1]counter
+
+	// maxSize is the size and call site of the largest entry seen by a maxTracker.
+	maxSize struct {
+		size   int
+		caller string
+	}
+
+	// maxTracker tracks the largest entry without locks: a larger entry replaces the
+	// current maximum with a compare-and-swap, smaller ones only load it.
+	maxTracker struct {
+		current atomic.Pointer[maxSize]
+	}
+)
+
+// newCounter creates a counter with a shard per CPU, rounded up to a power of two.
+func newCounter() counter {
+	n := 1 << bits.Len(uint(min(runtime.GOMAXPROCS(0), maxCounterShards)-1))
+	return counter{shards: make([]counterShard, n), mask: uint32(n - 1)}
+}
+
+// add adds delta to the counter.
+func (c *counter) add(delta uint64) {
+	c.shards[rand.Uint32()&c.mask].n.Add(delta)
+}
+
+// load returns the sum of the shards. Adds running concurrently may or may not be
+// included.
+func (c *counter) load() uint64 {
+	var sum uint64
+	for i := range c.shards {
+		sum += c.shards[i].n.Load()
+	}
+	return sum
+}
+
+// newLevelCounters creates the counters of every level.
+func newLevelCounters() *levelCounters {
+	var l levelCounters
+	for i := range l {
+		l[i] = newCounter()
+	}
+	return &l
+}
+
+// add counts an entry of the level.
+func (l *levelCounters) add(level zapcore.Level) {
+	if level >= zapcore.DebugLevel && level <= zapcore.FatalLevel {
+		l[level-zapcore.DebugLevel].add(1)
+	}
+}
+
+// snapshot returns the counts of the levels with entries, nil when none were counted.
+func (l *levelCounters) snapshot() map[Level]uint64 {
+	var counts map[Level]uint64
+	for i := range l {
+		if n := l[i].load(); n > 0 {
+			if counts == nil {
+				counts = make(map[Level]uint64)
+			}
+			counts[levelName(zapcore.DebugLevel+zapcore.Level(i))] += n
+		}
+	}
+	return counts
+}
+
+// observe records an entry of the size, replacing the maximum when it is larger.
+func (m *maxTracker) observe(size int, caller zapcore.EntryCaller) {
+	for {
+		current := m.current.Load()
+		if current != nil && size <= current.size {
+			return
+		}
+		if m.current.CompareAndSwap(current, &maxSize{size: size, caller: caller.TrimmedPath()}) {
+			return
+		}
+	}
+}
+
+// load returns the size and call site of the largest entry recorded so far.
+func (m *maxTracker) load() (int, string) {
+	if current := m.current.Load(); current != nil {
+		return current.size, current.caller
+	}
+	return 0, ""
+}
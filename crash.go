@@ -0,0 +1,300 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DiagnosticCrashReport is the kind of diagnostic reported when a crash report cannot be
+// written.
+const DiagnosticCrashReport = "crash_report_error"
+
+// defaultCrashReportEntries is the number of entries kept for crash reports when no
+// number is given.
+const defaultCrashReportEntries = 100
+
+// Reasons of a crash report.
+const (
+	CrashReasonFatal          = "fatal"
+	CrashReasonPanic          = "panic"
+	CrashReasonUnhandledPanic = "unhandled_panic"
+)
+
+type (
+	// CrashReport is the JSON document written by WithCrashReport when the process crashes.
+	CrashReport struct {
+		// Time is when the crash happened.
+		Time time.Time `json:"time"`
+		// Reason is CrashReasonFatal, CrashReasonPanic or CrashReasonUnhandledPanic.
+		Reason string `json:"reason"`
+		// Message is the message of the fatal or panic entry, or the unhandled panic value.
+		Message string `json:"message"`
+		// PID is the process ID.
+		PID int `json:"pid"`
+		// Stack is the stack trace of the crashing goroutine.
+		Stack string `json:"stack"`
+		// Entries are the last entries logged before the crash, oldest first, encoded as
+		// JSON objects.
+		Entries []json.RawMessage `json:"entries"`
+		// Config is the configuration summary written by LogConfig.
+		Config map[string]any `json:"config"`
+	}
+
+	// crashReport keeps the last entries of a logger in a ring buffer and writes them with
+	// the configuration and stack to the report file on a crash.
+	crashReport struct {
+		path     string
+		encoder  zapcore.Encoder
+		redacted *redactedFields
+		diag     *diagnostics
+		// logger describes the configuration, set once the logger is created.
+		logger *zapLogger
+
+		mu      sync.Mutex
+		entries [][]byte
+		next    int
+		full    bool
+	}
+
+	// crashCore wraps the core of a logger and records the entries it writes in the ring
+	// buffer of the crash report.
+	crashCore struct {
+		zapcore.Core
+		report *crashReport
+		fields []Field
+	}
+
+	// crashSink is the core added to checked entries to record them.
+	crashSink struct {
+		core *crashCore
+	}
+
+	// crashPanicHook writes the crash report of a panic entry, then panics.
+	crashPanicHook struct {
+		report *crashReport
+	}
+)
+
+// newCrashReport creates the crash report of a logger, nil when path is empty.
+func newCrashReport(path string, entries int, encoderConfig zapcore.EncoderConfig, redacted *redactedFields, diag *diagnostics) *crashReport {
+	if path == "" {
+		return nil
+	}
+	if entries <= 0 {
+		entries = defaultCrashReportEntries
+	}
+	encoderConfig.LineEnding = ""
+	return &crashReport{
+		path:     path,
+		encoder:  zapcore.NewJSONEncoder(encoderConfig),
+		redacted: redacted,
+		diag:     diag,
+		entries:  make([][]byte, entries),
+	}
+}
+
+// ReportCrash writes the crash report of an unhandled panic of the calling goroutine,
+// then panics again with the same value, so the process still crashes with the usual
+// exit code and goroutine dump. The panic is logged at ErrorLevel before the report is
+// written and the logger is synced. It must be deferred directly, at the top of main
+// and of goroutines whose panics would crash the process. Loggers without
+// WithCrashReport only log the panic.
+//
+// Parameters:
+//   - ctx: The context whose fields are added to the panic entry
+//   - log: The logger created by NewLogger with WithCrashReport
+//
+// Example:
+//
+//	func main() {
+//	    log, err := logger.NewLogger(logger.WithCrashReport("/var/log/app/crash.json", 200))
+//	    if err != nil {
+//	        panic(err)
+//	    }
+//	    defer logger.ReportCrash(ctx, log)
+//	    run(log)
+//	}
+func ReportCrash(ctx context.Context, log Logger) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := debug.Stack()
+	log.Error(ctx, "unhandled panic", PanicValue(r))
+	if z, ok := unwrapLogger(log); ok {
+		if z.state.crash != nil {
+			z.state.crash.write(CrashReasonUnhandledPanic, time.Now(), fmt.Sprint(r), string(stack))
+		}
+		_ = z.zapLogger.Sync()
+	}
+	panic(r)
+}
+
+// wrap wraps core to record its entries.
+func (r *crashReport) wrap(core zapcore.Core) zapcore.Core {
+	return &crashCore{Core: core, report: r}
+}
+
+// record encodes an entry and stores it in the ring buffer, replacing the oldest entry
+// once the buffer is full. Fields are redacted and classified secrets masked as by the
+// sinks.
+func (r *crashReport) record(ent zapcore.Entry, contextFields, fields []Field) {
+	enc := r.encoder.Clone()
+	for _, f := range defaultSinkPolicy.apply(r.redacted.redact(contextFields)) {
+		f.AddTo(enc)
+	}
+	buf, err := enc.EncodeEntry(ent, defaultSinkPolicy.apply(r.redacted.redact(fields)))
+	if err != nil {
+		return
+	}
+	line := append([]byte(nil), buf.Bytes()...)
+	buf.Free()
+
+	r.mu.Lock()
+	r.entries[r.next] = line
+	r.next = (r.next + 1) % len(r.entries)
+	r.full = r.full || r.next == 0
+	r.mu.Unlock()
+}
+
+// recorded returns the entries of the ring buffer, oldest first.
+func (r *crashReport) recorded() []json.RawMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ordered [][]byte
+	if r.full {
+		ordered = append(ordered, r.entries[r.next:]...)
+	}
+	ordered = append(ordered, r.entries[:r.next]...)
+	entries := make([]json.RawMessage, len(ordered))
+	for i, line := range ordered {
+		entries[i] = line
+	}
+	return entries
+}
+
+// write writes the report atomically to the report file, replacing the report of an
+// earlier crash. Failures are reported to the diagnostics sink.
+func (r *crashReport) write(reason string, now time.Time, message, stack string) {
+	report := CrashReport{
+		Time:    now,
+		Reason:  reason,
+		Message: message,
+		PID:     os.Getpid(),
+		Stack:   stack,
+		Entries: r.recorded(),
+	}
+	if r.logger != nil {
+		enc := zapcore.NewMapObjectEncoder()
+		for _, f := range configFields(r.logger) {
+			f.AddTo(enc)
+		}
+		report.Config = enc.Fields
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(r.path, append(data, '\n'))
+	}
+	if err != nil {
+		r.diag.report(DiagnosticCrashReport, fmt.Errorf("failed to write crash report %s: %w", r.path, err))
+	}
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it to path,
+// so readers never see a partial report.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// crashStack returns the stack trace of an entry, or of the calling goroutine when the
+// entry has none.
+func crashStack(ent zapcore.Entry) string {
+	if ent.Stack != "" {
+		return ent.Stack
+	}
+	return string(debug.Stack())
+}
+
+// With returns a child core remembering the fields for recorded entries.
+func (c *crashCore) With(fields []Field) zapcore.Core {
+	contextFields := make([]Field, 0, len(c.fields)+len(fields))
+	contextFields = append(contextFields, c.fields...)
+	contextFields = append(contextFields, fields...)
+	return &crashCore{Core: c.Core.With(fields), report: c.report, fields: contextFields}
+}
+
+// lowered returns a copy of the core recording the entries of the lowered wrapped core.
+func (c *crashCore) lowered() zapcore.Core {
+	return &crashCore{Core: lowerCore(c.Core), report: c.report, fields: c.fields}
+}
+
+// Check lets the wrapped core decide on the entry and records the entries it writes.
+func (c *crashCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ce = c.Core.Check(ent, ce); ce != nil {
+		return ce.AddCore(ent, crashSink{core: c})
+	}
+	return ce
+}
+
+// Enabled reports true; the sink is only added to entries the logger writes.
+func (s crashSink) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With returns the sink unchanged; fields are tracked by crashCore.
+func (s crashSink) With([]Field) zapcore.Core {
+	return s
+}
+
+// Check adds the sink to the checked entry.
+func (s crashSink) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, s)
+}
+
+// Write records the entry in the ring buffer.
+func (s crashSink) Write(ent zapcore.Entry, fields []Field) error {
+	s.core.report.record(ent, s.core.fields, fields)
+	return nil
+}
+
+// Sync does nothing; the ring buffer is only written on a crash.
+func (s crashSink) Sync() error {
+	return nil
+}
+
+// OnWrite writes the crash report of the panic entry, then panics like zap.
+func (h crashPanicHook) OnWrite(ce *zapcore.CheckedEntry, fields []Field) {
+	h.report.write(CrashReasonPanic, ce.Time, ce.Message, crashStack(ce.Entry))
+	zapcore.WriteThenPanic.OnWrite(ce, fields)
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Field keys describing the deadline and cancellation of the context of an entry.
+const (
+	FieldKeyDeadlineRemaining = "deadline_remaining"
+	FieldKeyContextCanceled   = "context_canceled"
+	FieldKeyContextCause      = "context_cause"
+)
+
+// appendDeadlineFields appends the remaining time until the deadline of the context and,
+// once the context is done, that it was canceled and the cause, e.g. "context deadline
+// exceeded" or the cause passed to context.WithCancelCause. The remaining time is
+// negative when the deadline has passed. Active contexts without a deadline yield
+// no fields.
+func appendDeadlineFields(fields []Field, ctx context.Context) []Field {
+	if ctx == nil {
+		return fields
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		fields = append(fields, zap.Duration(FieldKeyDeadlineRemaining, time.Until(deadline)))
+	}
+	if ctx.Err() != nil {
+		fields = append(fields,
+			zap.Bool(FieldKeyContextCanceled, true),
+			zap.String(FieldKeyContextCause, context.Cause(ctx).Error()),
+		)
+	}
+	return fields
+}
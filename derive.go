@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"slices"
+
+	"go.uber.org/zap"
+)
+
+// InheritedPart is a part of the configuration a logger derived by Logger.WithOptions
+// takes from its parent, see WithoutInheriting.
+type InheritedPart string
+
+// Parts of the configuration inherited by derived loggers.
+const (
+	// InheritLevel is the minimum level. An inherited level is shared with the parent
+	// unless overridden with WithLevel, so SetLevel on the parent changes both.
+	InheritLevel InheritedPart = "level"
+	// InheritSinks are the output and error output paths along with their ingest
+	// budgets, policies, profiles, fallbacks, batching and residency routing. Inherited
+	// sinks are shared with the parent: a path the parent opened is written through the
+	// parent's file and batches instead of being opened again, and it is closed once
+	// the parent and every derived logger writing to it are closed. Files the derived
+	// logger opens with other file settings, such as another mode or encryption, are
+	// opened again.
+	InheritSinks InheritedPart = "sinks"
+	// InheritRedaction are the redacted fields. Inherited redaction is shared with the
+	// parent unless WithRedactedFields adds keys, so SetRedactedFields on the parent
+	// changes both.
+	InheritRedaction InheritedPart = "redaction"
+	// InheritSampling is the sampling. Inherited sampling is shared with the parent, so
+	// SetSampling on the parent changes both.
+	InheritSampling InheritedPart = "sampling"
+)
+
+// inheritedState holds the runtime state a derived logger shares with its parent.
+// Nil fields are created by the derived logger.
+type inheritedState struct {
+	level    *zap.AtomicLevel
+	redacted *redactedFields
+	sampler  *sampler
+	sinks    *sinkPool
+}
+
+// derive returns the configuration of a logger derived from s by the options and the
+// runtime state it shares with s.
+func (s *loggerState) derive(opts []Option) (*config, inheritedState) {
+	// The parts to reset must be known before the options override the inherited ones.
+	probe := &config{}
+	for _, opt := range opts {
+		opt(probe)
+	}
+	inherits := func(part InheritedPart) bool {
+		return !slices.Contains(probe.Uninherited, part)
+	}
+
+	defaults := &config{}
+	WithDefaultConfig()(defaults)
+
+	cfg := s.cfg.clone()
+	cfg.Uninherited = nil
+	// An empty level and no redacted fields tell whether the options override them.
+	cfg.Level = ""
+	cfg.RedactedFields = nil
+	cfg.SinkPool = nil
+	if !inherits(InheritSinks) {
+		cfg.OutputPaths = defaults.OutputPaths
+		cfg.ErrorOutputPaths = defaults.ErrorOutputPaths
+		cfg.IngestBudgets = nil
+		cfg.SinkPolicies = nil
+		cfg.SinkProfiles = nil
+		cfg.SinkFallbacks = nil
+		cfg.SinkBatches = nil
+		cfg.Residency = nil
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var inherited inheritedState
+	if cfg.Level == "" {
+		cfg.Level = defaults.Level
+		if inherits(InheritLevel) {
+			cfg.Level = s.cfg.Level
+			inherited.level = &s.zapConfig.Level
+		}
+	}
+	if inherits(InheritRedaction) {
+		if len(cfg.RedactedFields) == 0 {
+			inherited.redacted = s.redacted
+		}
+		cfg.RedactedFields = append(s.redacted.list(), cfg.RedactedFields...)
+	}
+	if inherits(InheritSinks) {
+		inherited.sinks = s.cfg.SinkPool
+	}
+	if inherits(InheritSampling) {
+		inherited.sampler = s.sampler
+	}
+	return cfg, inherited
+}
+
+// clone returns a copy of the configuration whose slices can be appended to without
+// changing c.
+func (c *config) clone() *config {
+	cfg := *c
+	cfg.OutputPaths = slices.Clone(c.OutputPaths)
+	cfg.ErrorOutputPaths = slices.Clone(c.ErrorOutputPaths)
+	cfg.Filters = slices.Clone(c.Filters)
+	cfg.FieldRenames = slices.Clone(c.FieldRenames)
+	cfg.RedactedFields = slices.Clone(c.RedactedFields)
+	cfg.BaggageKeys = slices.Clone(c.BaggageKeys)
+	cfg.Enrichers = slices.Clone(c.Enrichers)
+	cfg.LatencyBuckets = slices.Clone(c.LatencyBuckets)
+	cfg.MetricRules = slices.Clone(c.MetricRules)
+	cfg.IngestBudgets = slices.Clone(c.IngestBudgets)
+	cfg.SinkPolicies = slices.Clone(c.SinkPolicies)
+	cfg.SinkProfiles = slices.Clone(c.SinkProfiles)
+	cfg.SinkFallbacks = slices.Clone(c.SinkFallbacks)
+	cfg.SinkBatches = slices.Clone(c.SinkBatches)
+	cfg.Uninherited = slices.Clone(c.Uninherited)
+	return &cfg
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// DeterministicTime is the time of every entry written with WithDeterministicOutput.
+var DeterministicTime = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type (
+	// fixedClock is a zapcore.Clock always telling DeterministicTime.
+	fixedClock struct{}
+
+	// sortedCore wraps a zapcore.Core and writes the fields of entries, including the
+	// fields added with With, in the order of their keys.
+	sortedCore struct {
+		zapcore.Core
+		fields []Field
+	}
+)
+
+// Now returns DeterministicTime.
+func (fixedClock) Now() time.Time {
+	return DeterministicTime
+}
+
+// NewTicker returns a ticker of the real clock, as tickers only pace background work.
+func (fixedClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
+
+// With returns a child core keeping the fields until entries are written, so they can
+// be sorted along with the fields of the entries.
+func (c *sortedCore) With(fields []Field) zapcore.Core {
+	return &sortedCore{Core: c.Core, fields: slices.Concat(c.fields, fields)}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *sortedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry with the fields of the core and the entry sorted by key.
+// Fields with the same key keep their order.
+func (c *sortedCore) Write(ent zapcore.Entry, fields []Field) error {
+	all := slices.Concat(c.fields, fields)
+	slices.SortStableFunc(all, func(a, b Field) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return c.Core.Write(ent, all)
+}
@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DiagnosticsLoggerName is the logger name of the entries reporting internal failures.
+const DiagnosticsLoggerName = "logger.diagnostics"
+
+// FieldKeyDiagnostic is the field naming the kind of internal failure.
+const FieldKeyDiagnostic = "diagnostic"
+
+// Kinds of internal failures reported by the diagnostics channel.
+const (
+	DiagnosticSinkWrite     = "sink_write_error"
+	DiagnosticSinkSync      = "sink_sync_error"
+	DiagnosticDroppedBatch  = "dropped_batch"
+	DiagnosticRedactorPanic = "redactor_panic"
+	DiagnosticFieldPanic    = "field_panic"
+	DiagnosticSinkCheck     = "sink_check_error"
+	DiagnosticSinkDegraded  = "sink_degraded"
+	DiagnosticSinkRecovered = "sink_recovered"
+)
+
+const (
+	// diagnosticsRate is the number of diagnostic entries written per second.
+	diagnosticsRate = 10
+	// diagnosticsBurst is the number of diagnostic entries written in a burst.
+	diagnosticsBurst = 20
+)
+
+type (
+	// diagnostics reports internal failures of a logger as structured entries on the
+	// fallback sink and counts them for GetStats. Entries are written directly to the
+	// fallback sink, never through the logger, and are rate limited, so a failing
+	// sink cannot trigger a feedback loop.
+	diagnostics struct {
+		core    zapcore.Core
+		limiter *tokenBucket
+
+		sinkWriteErrors atomic.Uint64
+		sinkSyncErrors  atomic.Uint64
+		droppedBatches  atomic.Uint64
+		redactorPanics  atomic.Uint64
+		fieldPanics     atomic.Uint64
+		suppressed      atomic.Uint64
+		// entries counts the entries handed to the output sinks per level.
+		entries *levelCounters
+
+		mu          sync.Mutex
+		lastError   string
+		lastErrorAt time.Time
+	}
+
+	// diagnosticsCore wraps a zapcore.Core and reports its write and sync errors and the
+	// panics of fields that cannot be encoded.
+	diagnosticsCore struct {
+		zapcore.Core
+		diag *diagnostics
+		// counted counts the entries per level; set for the output sinks only.
+		counted bool
+	}
+)
+
+// newDiagnostics creates the diagnostics channel writing to the fallback sink.
+func newDiagnostics(encoder zapcore.Encoder, fallback zapcore.WriteSyncer) *diagnostics {
+	return &diagnostics{
+		core:    zapcore.NewCore(encoder, fallback, zapcore.DebugLevel),
+		limiter: newTokenBucket(diagnosticsRate, diagnosticsBurst),
+		entries: newLevelCounters(),
+	}
+}
+
+// report counts an internal failure and writes a diagnostic entry unless rate limited.
+func (d *diagnostics) report(kind string, err error, fields ...Field) {
+	switch kind {
+	case DiagnosticSinkWrite:
+		d.sinkWriteErrors.Add(1)
+	case DiagnosticSinkSync:
+		d.sinkSyncErrors.Add(1)
+	case DiagnosticDroppedBatch:
+		d.droppedBatches.Add(1)
+	case DiagnosticRedactorPanic:
+		d.redactorPanics.Add(1)
+	case DiagnosticFieldPanic:
+		d.fieldPanics.Add(1)
+	}
+
+	now := time.Now()
+	d.mu.Lock()
+	d.lastError = kind + ": " + err.Error()
+	d.lastErrorAt = now
+	d.mu.Unlock()
+
+	d.write(zapcore.ErrorLevel, now, "logger internal failure",
+		append([]Field{zap.String(FieldKeyDiagnostic, kind), zap.Error(err)}, fields...))
+}
+
+// notice writes a diagnostic entry about a change that is not a failure, such as a
+// sink recovering, unless rate limited. It leaves the last error untouched.
+func (d *diagnostics) notice(kind, message string, fields ...Field) {
+	d.write(zapcore.InfoLevel, time.Now(), message, append([]Field{zap.String(FieldKeyDiagnostic, kind)}, fields...))
+}
+
+// write writes a diagnostic entry to the fallback sink unless rate limited.
+func (d *diagnostics) write(level zapcore.Level, now time.Time, message string, fields []Field) {
+	if !d.limiter.Allow() {
+		d.suppressed.Add(1)
+		return
+	}
+
+	entry := zapcore.Entry{
+		Level:      level,
+		Time:       now,
+		LoggerName: DiagnosticsLoggerName,
+		Message:    message,
+	}
+	if writeErr := d.core.Write(entry, fields); writeErr != nil {
+		d.suppressed.Add(1)
+	}
+}
+
+// With returns a child core reporting to the same diagnostics channel.
+func (c *diagnosticsCore) With(fields []Field) zapcore.Core {
+	return &diagnosticsCore{Core: withRecovered(c.Core, c.diag, fields), diag: c.diag, counted: c.counted}
+}
+
+// lowered returns a copy of the core reporting the failures of the lowered wrapped core.
+func (c *diagnosticsCore) lowered() zapcore.Core {
+	return &diagnosticsCore{Core: lowerCore(c.Core), diag: c.diag, counted: c.counted}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *diagnosticsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry and reports a failure instead of returning it to zap.
+func (c *diagnosticsCore) Write(ent zapcore.Entry, fields []Field) error {
+	if err := writeRecovered(c.Core, c.diag, ent, fields); err != nil {
+		c.diag.report(DiagnosticSinkWrite, err, zap.String("entry_message", ent.Message))
+	} else if c.counted {
+		c.diag.entries.add(ent.Level)
+	}
+	return nil
+}
+
+// Sync flushes the sinks and reports a failure.
+func (c *diagnosticsCore) Sync() error {
+	err := c.Core.Sync()
+	if err != nil {
+		c.diag.report(DiagnosticSinkSync, err)
+	}
+	return err
+}
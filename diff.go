@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxDiffChanges is the number of changed paths written by Diff.
+const maxDiffChanges = 100
+
+// diffTruncatedKey is the member of a diff counting the changed paths left out.
+const diffTruncatedKey = "_truncated"
+
+type (
+	// diffValue logs the structural diff of two values as an object.
+	diffValue struct {
+		before, after any
+	}
+
+	// diffChange is a changed path with its old and new value; a missing value means
+	// the path was added or removed.
+	diffChange struct {
+		path           string
+		old, new       any
+		hasOld, hasNew bool
+	}
+)
+
+// Diff returns a field with the structural diff of two values: an object holding only
+// the changed paths, each with its old and new value. Paths are dotted for object
+// members and indexed for array elements, e.g. spec.containers[0].image; added paths
+// have no old value and removed paths no new value. Values are compared by their JSON
+// encoding, so json tags apply and unexported fields are ignored; cycles, values nested
+// deeper than 32 levels and non-finite floats are compared as their markers and
+// strings, like reflected fields. At most 100 paths
+// are written; the number of paths left out is written as _truncated. The diff is
+// computed when the entry is encoded, so disabled levels cost nothing.
+//
+// Parameters:
+//   - key: The field key
+//   - before: The value before the change
+//   - after: The value after the change
+//
+// Returns:
+//   - Field: The diff field
+//
+// Example:
+//
+//	before := deployment.DeepCopy()
+//	reconcile(deployment)
+//	log.Info(ctx, "deployment reconciled", logger.Diff("changes", before, deployment))
+//	// "changes":{"spec.replicas":{"old":2,"new":3},"metadata.labels.tier":{"new":"web"}}
+func Diff(key string, before, after any) Field {
+	return zap.Object(key, diffValue{before: before, after: after})
+}
+
+// MarshalLogObject writes the changed paths in order.
+func (d diffValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	before, err := jsonTree(d.before)
+	if err != nil {
+		return fmt.Errorf("failed to encode value before: %w", err)
+	}
+	after, err := jsonTree(d.after)
+	if err != nil {
+		return fmt.Errorf("failed to encode value after: %w", err)
+	}
+
+	var changes []diffChange
+	diffTrees("", before, after, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].path < changes[j].path })
+
+	for i, c := range changes {
+		if i == maxDiffChanges {
+			enc.AddInt(diffTruncatedKey, len(changes)-maxDiffChanges)
+			break
+		}
+		if err := enc.AddObject(c.path, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarshalLogObject writes the old and new value of the change.
+func (c diffChange) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if c.hasOld {
+		if err := enc.AddReflected("old", c.old); err != nil {
+			return err
+		}
+	}
+	if c.hasNew {
+		if err := enc.AddReflected("new", c.new); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonTree returns the JSON encoding of v decoded into maps, slices and scalars. The
+// value is converted like a reflected field first, so cycles cannot fail the encoding.
+func jsonTree(v any) (any, error) {
+	w := &reflectedWalker{maxDepth: defaultMaxFieldDepth, nonFinite: NonFiniteString, parents: make(map[reflectedParent]bool)}
+	data, err := json.Marshal(w.convert(reflect.ValueOf(v), 0))
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// diffTrees appends the changes between two JSON trees below the path.
+func diffTrees(path string, before, after any, changes *[]diffChange) {
+	switch b := before.(type) {
+	case map[string]any:
+		if a, ok := after.(map[string]any); ok {
+			for k, bv := range b {
+				child := joinPath(path, k)
+				if av, ok := a[k]; ok {
+					diffTrees(child, bv, av, changes)
+				} else {
+					*changes = append(*changes, diffChange{path: child, old: bv, hasOld: true})
+				}
+			}
+			for k, av := range a {
+				if _, ok := b[k]; !ok {
+					*changes = append(*changes, diffChange{path: joinPath(path, k), new: av, hasNew: true})
+				}
+			}
+			return
+		}
+
+	case []any:
+		if a, ok := after.([]any); ok {
+			for i := 0; i < len(b) || i < len(a); i++ {
+				child := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(a):
+					*changes = append(*changes, diffChange{path: child, old: b[i], hasOld: true})
+				case i >= len(b):
+					*changes = append(*changes, diffChange{path: child, new: a[i], hasNew: true})
+				default:
+					diffTrees(child, b[i], a[i], changes)
+				}
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		if path == "" {
+			path = "."
+		}
+		*changes = append(*changes, diffChange{path: path, old: before, new: after, hasOld: true, hasNew: true})
+	}
+}
+
+// joinPath appends an object member to a path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
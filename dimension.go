@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// HeaderDimensions is the header carrying the dimensions of a context between services.
+// Its value uses the W3C baggage syntax: comma separated key=value pairs with
+// percent-encoded values.
+const HeaderDimensions = "X-Log-Dimensions"
+
+// DimensionKeyPrefix is the prefix of dimension keys accepted from an incoming
+// X-Log-Dimensions header without registration; see RegisterDimensionKeys.
+const DimensionKeyPrefix = "dim_"
+
+// maxDimensions bounds the number of dimensions accepted from an incoming header.
+const maxDimensions = 64
+
+// reservedDimensionKeys are the keys of fields written by the logger itself, which a
+// dimension from an incoming header must not set. The entry keys of the encoders of
+// the loggers created, reserved by reserveDimensionKeys, and the context keys,
+// including the ones added by AppendContextKeys, are reserved as well.
+var reservedDimensionKeys = map[string]bool{
+	"error":       true,
+	tenantIDKey:   true,
+	FieldKeyEvent: true,
+}
+
+var (
+	dimensionKeysMu sync.Mutex
+	// dimensionKeys holds the keys registered with RegisterDimensionKeys; it is
+	// replaced on registration so incoming headers are read without locking.
+	dimensionKeys atomic.Pointer[map[string]bool]
+	// encoderKeys holds the entry keys reserved by reserveDimensionKeys; it is replaced
+	// when a logger uses a new key.
+	encoderKeys atomic.Pointer[map[string]bool]
+)
+
+type (
+	// dimensionsKey is the context key of the dimensions of a context.
+	dimensionsKey struct{}
+
+	// dimension is a propagated key-value pair added to every entry of a context.
+	dimension struct {
+		key   string
+		value string
+	}
+)
+
+// WithValue returns a copy of the context carrying an additional dimension.
+// Dimensions are added as fields to every entry logged with the context and its
+// children, are kept by DetachContext, and are propagated to other services through
+// the X-Log-Dimensions header by HTTPMiddleware, the gRPC interceptors and the Connect
+// interceptor. The receiving service only reads keys registered with
+// RegisterDimensionKeys or starting with DimensionKeyPrefix. Setting an existing key
+// replaces its value.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - key: The field key of the dimension
+//   - value: The value of the dimension
+//
+// Returns:
+//   - context.Context: The context carrying the dimension
+//
+// Example:
+//
+//	ctx = WithValue(ctx, "checkout_flow", "v2")
+//	log.Info(ctx, "cart loaded") // {"message":"cart loaded","checkout_flow":"v2"}
+func WithValue(ctx context.Context, key, value string) context.Context {
+	current := dimensionsFromContext(ctx)
+	dims := make([]dimension, 0, len(current)+1)
+	for _, d := range current {
+		if d.key != key {
+			dims = append(dims, d)
+		}
+	}
+	dims = append(dims, dimension{key: key, value: value})
+	return context.WithValue(ctx, dimensionsKey{}, dims)
+}
+
+// RegisterDimensionKeys allows dimension keys to be read from the X-Log-Dimensions
+// header of incoming requests and messages. A header is sent by the caller, so only
+// registered keys and keys starting with DimensionKeyPrefix become fields; other keys
+// are dropped. Keys of fields written by the logger, such as the level and message
+// keys configured for the loggers created, user_id, tenant_id, trace_id or
+// data_residency, can never be registered. Register keys at startup, after creating
+// the loggers and before serving requests.
+//
+// Parameters:
+//   - keys: The dimension keys
+//
+// Returns:
+//   - error: An error if a key is empty or reserved
+//
+// Example:
+//
+//	_ = logger.RegisterDimensionKeys("checkout_flow", "experiment")
+func RegisterDimensionKeys(keys ...string) error {
+	for _, key := range keys {
+		if key == "" || isReservedDimensionKey(key) {
+			return fmt.Errorf("dimension key %q is empty or reserved", key)
+		}
+	}
+
+	dimensionKeysMu.Lock()
+	defer dimensionKeysMu.Unlock()
+	registered := make(map[string]bool)
+	if current := dimensionKeys.Load(); current != nil {
+		maps.Copy(registered, *current)
+	}
+	for _, key := range keys {
+		registered[key] = true
+	}
+	dimensionKeys.Store(&registered)
+	return nil
+}
+
+// Dimensions returns the dimensions carried by the context.
+//
+// Parameters:
+//   - ctx: The context to read the dimensions from
+//
+// Returns:
+//   - map[string]string: The dimensions, empty when there are none
+func Dimensions(ctx context.Context) map[string]string {
+	dims := dimensionsFromContext(ctx)
+	out := make(map[string]string, len(dims))
+	for _, d := range dims {
+		out[d.key] = d.value
+	}
+	return out
+}
+
+// DimensionsHeader serializes the dimensions of the context into an X-Log-Dimensions header value.
+// It returns an empty string when the context carries no dimensions.
+//
+// Example:
+//
+//	ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(HeaderDimensions), DimensionsHeader(ctx))
+func DimensionsHeader(ctx context.Context) string {
+	dims := dimensionsFromContext(ctx)
+	if len(dims) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, d := range dims {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(url.PathEscape(d.key))
+		b.WriteByte('=')
+		b.WriteString(url.PathEscape(d.value))
+	}
+	return b.String()
+}
+
+// contextWithDimensionsHeader adds the dimensions of an X-Log-Dimensions header value to the context.
+// Malformed members and keys not accepted by isAllowedDimensionKey are skipped, and at
+// most maxDimensions members are read.
+func contextWithDimensionsHeader(ctx context.Context, header string) context.Context {
+	if header == "" {
+		return ctx
+	}
+
+	for i, member := range strings.Split(header, ",") {
+		if i == maxDimensions {
+			break
+		}
+		// Baggage members may carry ;-separated properties, which are ignored.
+		member, _, _ = strings.Cut(member, ";")
+		rawKey, rawValue, ok := strings.Cut(strings.TrimSpace(member), "=")
+		if !ok {
+			continue
+		}
+		key, errKey := url.PathUnescape(strings.TrimSpace(rawKey))
+		value, errValue := url.PathUnescape(strings.TrimSpace(rawValue))
+		if errKey != nil || errValue != nil || !isAllowedDimensionKey(key) {
+			continue
+		}
+		ctx = WithValue(ctx, key, value)
+	}
+	return ctx
+}
+
+// isAllowedDimensionKey reports whether a key of an incoming header is registered or
+// starts with DimensionKeyPrefix, and is not reserved.
+func isAllowedDimensionKey(key string) bool {
+	if key == "" || isReservedDimensionKey(key) {
+		return false
+	}
+	if strings.HasPrefix(key, DimensionKeyPrefix) && len(key) > len(DimensionKeyPrefix) {
+		return true
+	}
+	registered := dimensionKeys.Load()
+	return registered != nil && (*registered)[key]
+}
+
+// reserveDimensionKeys reserves the keys of the time, level, logger name, caller,
+// function, message and stack trace of the entries written with the encoder config,
+// so dimensions from incoming headers cannot set them.
+func reserveDimensionKeys(cfg zapcore.EncoderConfig) {
+	keys := []string{cfg.TimeKey, cfg.LevelKey, cfg.NameKey, cfg.CallerKey, cfg.FunctionKey, cfg.MessageKey, cfg.StacktraceKey}
+
+	dimensionKeysMu.Lock()
+	defer dimensionKeysMu.Unlock()
+	reserved := make(map[string]bool)
+	if current := encoderKeys.Load(); current != nil {
+		maps.Copy(reserved, *current)
+	}
+	added := false
+	for _, key := range keys {
+		if key != "" && key != zapcore.OmitKey && !reserved[key] {
+			reserved[key] = true
+			added = true
+		}
+	}
+	if added {
+		encoderKeys.Store(&reserved)
+	}
+}
+
+// isReservedDimensionKey reports whether a key is the key of a field written by the logger.
+func isReservedDimensionKey(key string) bool {
+	if reservedDimensionKeys[key] {
+		return true
+	}
+	if reserved := encoderKeys.Load(); reserved != nil && (*reserved)[key] {
+		return true
+	}
+	for _, contextKey := range contextKeys() {
+		if string(contextKey) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// dimensionsFromContext returns the dimensions carried by the context.
+func dimensionsFromContext(ctx context.Context) []dimension {
+	if ctx == nil {
+		return nil
+	}
+	dims, _ := ctx.Value(dimensionsKey{}).([]dimension)
+	return dims
+}
+
+// appendDimensionFields appends the dimensions of the context to fields.
+func appendDimensionFields(fields []Field, ctx context.Context) []Field {
+	for _, d := range dimensionsFromContext(ctx) {
+		fields = append(fields, zap.String(d.key, d.value))
+	}
+	return fields
+}
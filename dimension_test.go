@@ -0,0 +1,23 @@
+package logger
+
+import (
+	"context"
+	"maps"
+	"testing"
+)
+
+// TestDimensionKeysReservedFromEncoderConfig creates a logger with custom entry keys and
+// checks that dimensions from an incoming header cannot set or register them.
+func TestDimensionKeysReservedFromEncoderConfig(t *testing.T) {
+	if _, err := NewLogger(WithMessageKey("dim_message"), WithLevelKey("severity")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RegisterDimensionKeys("severity"); err == nil {
+		t.Error("registered the level key of a logger as a dimension key")
+	}
+	ctx := contextWithDimensionsHeader(context.Background(), "dim_message=spoofed,severity=debug,dim_cohort=b")
+	if got, want := Dimensions(ctx), map[string]string{"dim_cohort": "b"}; !maps.Equal(got, want) {
+		t.Errorf("dimensions = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// DualWrite configures the copying of entries to a secondary logger during a migration
+	// between log schemas or vendors, see WithDualWrite.
+	DualWrite struct {
+		// Secondary receives the copies. It keeps its own level, encoding, sinks, field
+		// renames, redaction and schema version, so it can write the new schema to the new
+		// vendor. It must be created by NewLogger.
+		Secondary Logger
+		// Start is when copying begins; zero begins when the option is applied.
+		Start time.Time
+		// Ramp is the time over which the share of copied entries grows linearly from zero to
+		// Percent; zero copies Percent from Start.
+		Ramp time.Duration
+		// Percent is the share of entries copied once the ramp is over, 1 to 100; zero copies
+		// every entry.
+		Percent int
+		// Duration is how long entries are copied from Start; zero copies for the lifetime of
+		// the logger.
+		Duration time.Duration
+	}
+
+	// DualWriteStats is the progress of a dual-write migration.
+	DualWriteStats struct {
+		// Percent is the share of entries currently copied, zero before Start and after
+		// Duration.
+		Percent float64
+		// Copied counts the entries copied to the secondary logger.
+		Copied uint64
+		// Skipped counts the entries enabled on the secondary logger but not copied because of
+		// the ramp or the period.
+		Skipped uint64
+	}
+
+	// dualWrite decides which entries are copied and counts them.
+	dualWrite struct {
+		settings DualWrite
+		copied   counter
+		skipped  counter
+	}
+
+	// dualWriteCore tees entries to the core of the secondary logger while the migration
+	// copies them.
+	dualWriteCore struct {
+		zapcore.Core
+		state *dualWrite
+	}
+)
+
+// validateDualWrite returns an error if the migration cannot run.
+func validateDualWrite(dw *DualWrite) error {
+	if dw.Secondary == nil {
+		return errors.New("dual-write secondary logger is required")
+	}
+	if _, ok := unwrapLogger(dw.Secondary); !ok {
+		return errors.New("dual-write secondary logger must be created by NewLogger")
+	}
+	if dw.Percent < 0 || dw.Percent > 100 {
+		return errors.New("dual-write percent must be between 0 and 100")
+	}
+	if dw.Ramp < 0 || dw.Duration < 0 {
+		return errors.New("dual-write ramp and duration cannot be negative")
+	}
+	return nil
+}
+
+// newDualWrite creates the state of a migration, nil when dw is nil.
+func newDualWrite(dw *DualWrite) *dualWrite {
+	if dw == nil {
+		return nil
+	}
+	return &dualWrite{settings: *dw, copied: newCounter(), skipped: newCounter()}
+}
+
+// wrap tees core with the core of the secondary logger.
+func (d *dualWrite) wrap(core zapcore.Core) zapcore.Core {
+	secondary, _ := unwrapLogger(d.settings.Secondary)
+	return teeCore{core, &dualWriteCore{Core: secondary.zapLogger.Core(), state: d}}
+}
+
+// percent returns the share of entries copied at now.
+func (d *dualWrite) percent(now time.Time) float64 {
+	s := d.settings
+	target := float64(s.Percent)
+	if s.Percent == 0 {
+		target = 100
+	}
+	elapsed := now.Sub(s.Start)
+	switch {
+	case elapsed < 0:
+		return 0
+	case s.Duration > 0 && elapsed >= s.Duration:
+		return 0
+	case elapsed < s.Ramp:
+		return target * float64(elapsed) / float64(s.Ramp)
+	default:
+		return target
+	}
+}
+
+// copies reports whether an entry logged at now is copied, counting the decision.
+func (d *dualWrite) copies(now time.Time) bool {
+	if rand.Float64()*100 < d.percent(now) {
+		d.copied.add(1)
+		return true
+	}
+	d.skipped.add(1)
+	return false
+}
+
+// stats returns the progress of the migration, the zero value when d is nil.
+func (d *dualWrite) stats() DualWriteStats {
+	if d == nil {
+		return DualWriteStats{}
+	}
+	return DualWriteStats{Percent: d.percent(time.Now()), Copied: d.copied.load(), Skipped: d.skipped.load()}
+}
+
+// With returns a child core copying entries with the fields.
+func (c *dualWriteCore) With(fields []Field) zapcore.Core {
+	return &dualWriteCore{Core: c.Core.With(fields), state: c.state}
+}
+
+// lowered returns a copy of the core copying entries to the lowered secondary core.
+func (c *dualWriteCore) lowered() zapcore.Core {
+	return &dualWriteCore{Core: lowerCore(c.Core), state: c.state}
+}
+
+// Check lets the secondary core check entries chosen for copying.
+func (c *dualWriteCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Core.Enabled(ent.Level) || !c.state.copies(time.Now()) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
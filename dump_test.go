@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// TestLogConfigListsBodyRedactorsPerMiddleware creates middlewares with different body
+// redactors and checks that the configuration entry lists each of them.
+func TestLogConfigListsBodyRedactorsPerMiddleware(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := NewLogger(WithEncoding(EncodingJson), WithOutputPaths([]string{path}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	HTTPMiddleware(log, WithBodyCapture(1024), WithBodyRedactedKeys("ssn"))
+	HTTPMiddleware(log)
+	HTTPMiddleware(log, WithBodyCapture(1024), WithBodyRedactor(nil))
+	ctx := context.Background()
+	LogConfig(ctx, log)
+	if err := Close(ctx, log); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readEntries(t, path)
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want the configuration entry", len(entries))
+	}
+	got, _ := json.Marshal(entries[0]["body_redactors"])
+	if want := `[{"redacted_keys":["ssn"],"redactor":"keys"},{"redactor":"none"}]`; string(got) != want {
+		t.Errorf("body_redactors = %s, want %s", got, want)
+	}
+}
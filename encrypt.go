@@ -0,0 +1,266 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	// encryptedKeyPrefix marks the header line holding the wrapped data key of an encrypted log file.
+	encryptedKeyPrefix = "#key:"
+	// encryptedKeyIDSize is the size in bytes of the random id tagging a data key and its records.
+	encryptedKeyIDSize = 8
+)
+
+type (
+	// KeyWrapper protects the data keys used to encrypt log files.
+	// Every time an encrypted file sink is opened a fresh data key is generated,
+	// wrapped with the KeyWrapper and stored in the file under a random key id, so
+	// only the wrapped key ever touches the disk. Records are tagged with the id of
+	// their key, which they authenticate, so several writers can append to the same
+	// file. Implement this interface to delegate key wrapping to
+	// a KMS; use NewAESKeyWrapper for a locally configured key.
+	KeyWrapper interface {
+		// WrapKey encrypts a data key.
+		WrapKey(dataKey []byte) ([]byte, error)
+		// UnwrapKey decrypts a data key previously returned by WrapKey.
+		UnwrapKey(wrappedKey []byte) ([]byte, error)
+	}
+
+	// aesKeyWrapper wraps data keys with a local AES-GCM key.
+	aesKeyWrapper struct {
+		aead cipher.AEAD
+	}
+
+	// encryptedFile is a WriteSyncer that encrypts every log entry before writing it.
+	// Each entry becomes one line holding the key id and the base64 encoded record,
+	// keeping the file line oriented and preserving the single-write-per-entry
+	// guarantee of file sinks. The key id is the additional data of the record, so a
+	// record cannot be moved under the id of another key.
+	encryptedFile struct {
+		mu   sync.Mutex
+		file *os.File
+		aead cipher.AEAD
+		// keyID tags every record, "<id>:<record>".
+		keyID []byte
+	}
+)
+
+// NewAESKeyWrapper creates a KeyWrapper that wraps data keys with AES-GCM.
+//
+// Parameters:
+//   - key: The key encryption key (16, 24 or 32 bytes for AES-128, AES-192 or AES-256)
+//
+// Returns:
+//   - KeyWrapper: The key wrapper
+//   - error: An error if the key has an invalid length
+//
+// Example:
+//
+//	wrapper, err := NewAESKeyWrapper(key)
+//	logger, err := NewLogger(WithFileEncryption(wrapper))
+func NewAESKeyWrapper(key []byte) (KeyWrapper, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &aesKeyWrapper{aead: aead}, nil
+}
+
+// WrapKey encrypts a data key with the key encryption key.
+func (w *aesKeyWrapper) WrapKey(dataKey []byte) ([]byte, error) {
+	return sealRecord(w.aead, dataKey, nil)
+}
+
+// UnwrapKey decrypts a wrapped data key with the key encryption key.
+func (w *aesKeyWrapper) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	return openRecord(w.aead, wrappedKey, nil)
+}
+
+// newEncryptedFile generates a data key and its id, appends the wrapped key as a
+// header line of the file and returns a writer encrypting entries with it. The header
+// is written in a single write, so it does not interleave with the records of other
+// writers of the file.
+func newEncryptedFile(f *os.File, wrapper KeyWrapper) (*encryptedFile, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	id := make([]byte, encryptedKeyIDSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	keyID := hex.EncodeToString(id)
+
+	wrapped, err := wrapper.WrapKey(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap log encryption key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header := encryptedKeyPrefix + keyID + ":" + base64.StdEncoding.EncodeToString(wrapped) + "\n"
+	if _, err := f.WriteString(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptedFile{file: f, aead: aead, keyID: []byte(keyID)}, nil
+}
+
+// Write encrypts a single encoded entry and appends it to the file.
+func (e *encryptedFile) Write(p []byte) (int, error) {
+	sealed, err := sealRecord(e.aead, bytes.TrimSuffix(p, []byte("\n")), e.keyID)
+	if err != nil {
+		return 0, err
+	}
+
+	line := make([]byte, len(e.keyID)+1+base64.StdEncoding.EncodedLen(len(sealed))+1)
+	n := copy(line, e.keyID)
+	line[n] = ':'
+	base64.StdEncoding.Encode(line[n+1:], sealed)
+	line[len(line)-1] = '\n'
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, err := e.file.Write(line); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync flushes the file to stable storage.
+func (e *encryptedFile) Sync() error {
+	return e.file.Sync()
+}
+
+// Close closes the underlying file.
+func (e *encryptedFile) Close() error {
+	return e.file.Close()
+}
+
+// DecryptLog decrypts a log file written with WithFileEncryption.
+// Files hold one wrapped data key per time the sink was opened, and the records of
+// several writers may interleave; every record is decrypted with the key its id
+// refers to and authenticates the id. Lines without a key id are rejected.
+//
+// Parameters:
+//   - r: The encrypted log content
+//   - w: The destination for the decrypted entries, one per line
+//   - wrapper: The KeyWrapper used when the file was written
+//
+// Returns:
+//   - error: An error if a key cannot be unwrapped, or an entry has no known key id
+//     or fails authentication
+//
+// Example:
+//
+//	wrapper, _ := NewAESKeyWrapper(key)
+//	err := DecryptLog(encryptedFile, os.Stdout, wrapper)
+func DecryptLog(r io.Reader, w io.Writer, wrapper KeyWrapper) error {
+	var (
+		keys    = make(map[string]cipher.AEAD)
+		scanner = bufio.NewScanner(r)
+		lineNo  int
+	)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		// Base64 never contains a colon, so one separates the key id from the data.
+		if rest, ok := bytes.CutPrefix(line, []byte(encryptedKeyPrefix)); ok {
+			id, encoded, tagged := bytes.Cut(rest, []byte(":"))
+			if !tagged || len(id) == 0 {
+				return fmt.Errorf("line %d: key header without key id", lineNo)
+			}
+			wrapped, err := base64.StdEncoding.DecodeString(string(encoded))
+			if err != nil {
+				return fmt.Errorf("line %d: invalid key header: %w", lineNo, err)
+			}
+			dataKey, err := wrapper.UnwrapKey(wrapped)
+			if err != nil {
+				return fmt.Errorf("line %d: failed to unwrap data key: %w", lineNo, err)
+			}
+			aead, err := newAEAD(dataKey)
+			if err != nil {
+				return fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			keys[string(id)] = aead
+			continue
+		}
+
+		id, encoded, tagged := bytes.Cut(line, []byte(":"))
+		if !tagged {
+			return fmt.Errorf("line %d: entry without key id", lineNo)
+		}
+		aead := keys[string(id)]
+		if aead == nil {
+			return fmt.Errorf("line %d: entry references unknown key %q", lineNo, id)
+		}
+
+		sealed, err := base64.StdEncoding.DecodeString(string(encoded))
+		if err != nil {
+			return fmt.Errorf("line %d: invalid entry encoding: %w", lineNo, err)
+		}
+		plain, err := openRecord(aead, sealed, id)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if _, err := w.Write(append(plain, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// newAEAD creates an AES-GCM cipher from the key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealRecord encrypts plaintext, authenticating additionalData with it, and prefixes
+// the random nonce.
+func sealRecord(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// openRecord decrypts a nonce-prefixed ciphertext produced by sealRecord with the same
+// additional data.
+func openRecord(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("encrypted log record is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, errors.New("failed to decrypt log record: authentication failed")
+	}
+	return plain, nil
+}
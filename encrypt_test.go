@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecryptLogTwoWritersOnOneFile(t *testing.T) {
+	wrapper, err := NewAESKeyWrapper(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "app.log")
+	newLogger := func() Logger {
+		log, err := NewLogger(WithOutputPaths([]string{path}), WithFileEncryption(wrapper))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return log
+	}
+
+	ctx := context.Background()
+	first := newLogger()
+	first.Info(ctx, "first 1")
+	second := newLogger()
+	second.Info(ctx, "second 1")
+	first.Info(ctx, "first 2")
+	second.Info(ctx, "second 2")
+	for _, log := range []Logger{first, second} {
+		if err := Close(ctx, log); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var out bytes.Buffer
+	if err := DecryptLog(f, &out, wrapper); err != nil {
+		t.Fatalf("DecryptLog: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	want := []string{"first 1", "second 1", "first 2", "second 2"}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d entries, want %d:\n%s", len(lines), len(want), out.String())
+	}
+	for i, msg := range want {
+		if !strings.Contains(lines[i], `"`+msg+`"`) {
+			t.Errorf("entry %d = %s, want message %q", i, lines[i], msg)
+		}
+	}
+}
+
+// TestDecryptLogRejectsRetaggedAndUntaggedEntries writes an entry with each of two keys
+// and checks that decryption fails once the entry of the second key is tagged with the
+// id of the first, or carries no id at all.
+func TestDecryptLogRejectsRetaggedAndUntaggedEntries(t *testing.T) {
+	wrapper, err := NewAESKeyWrapper(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(t.TempDir(), "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var ids []string
+	for _, msg := range []string{"first", "second"} {
+		enc, err := newEncryptedFile(f, wrapper)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := enc.Write([]byte(msg + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, string(enc.keyID))
+	}
+	b, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(b)
+
+	var out bytes.Buffer
+	if err := DecryptLog(strings.NewReader(content), &out, wrapper); err != nil {
+		t.Fatalf("DecryptLog: %v", err)
+	}
+	if got := out.String(); got != "first\nsecond\n" {
+		t.Fatalf("decrypted %q", got)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	second := lines[len(lines)-1]
+	for name, line := range map[string]string{
+		"retagged": ids[0] + strings.TrimPrefix(second, ids[1]),
+		"untagged": strings.TrimPrefix(second, ids[1]+":"),
+	} {
+		lines[len(lines)-1] = line
+		tampered := strings.Join(lines, "\n") + "\n"
+		if err := DecryptLog(strings.NewReader(tampered), io.Discard, wrapper); err == nil {
+			t.Errorf("%s entry was decrypted", name)
+		}
+	}
+}
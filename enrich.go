@@ -0,0 +1,31 @@
+package logger
+
+import "context"
+
+type (
+	// Enricher adds fields derived from the context to every entry logged with it.
+	// Enrich is called on the logging goroutine for every entry, so implementations
+	// must be safe for concurrent use and fast; cache expensive lookups.
+	Enricher interface {
+		Enrich(ctx context.Context) []Field
+	}
+
+	// EnricherFunc adapts a function to the Enricher interface.
+	EnricherFunc func(ctx context.Context) []Field
+)
+
+// Enrich calls f(ctx).
+func (f EnricherFunc) Enrich(ctx context.Context) []Field {
+	return f(ctx)
+}
+
+// appendEnricherFields appends the fields of every enricher for the context to fields.
+func appendEnricherFields(fields []Field, ctx context.Context, enrichers []Enricher) []Field {
+	if ctx == nil {
+		return fields
+	}
+	for _, e := range enrichers {
+		fields = append(fields, e.Enrich(ctx)...)
+	}
+	return fields
+}
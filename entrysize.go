@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys added to entries larger than the threshold of WithEntrySizeTracking.
+const (
+	FieldKeyEntrySize      = "entry_size_bytes"
+	FieldKeyEntryOversized = "entry_oversized"
+)
+
+type (
+	// entrySizes counts the sizes of encoded entries for GetStats.
+	entrySizes struct {
+		// threshold is the size above which entries are flagged; zero disables flagging.
+		threshold int
+
+		entries   counter
+		bytes     counter
+		oversized counter
+		maximum   maxTracker
+	}
+
+	// sizeEncoder wraps an encoder and records the size of every encoded entry.
+	// Entries larger than the threshold are encoded again with their size and an
+	// oversized flag, so they can be found in the logs along with their caller.
+	sizeEncoder struct {
+		zapcore.Encoder
+		sizes *entrySizes
+	}
+)
+
+// newEntrySizes creates the size counters, or returns nil when tracking is disabled.
+func newEntrySizes(enabled bool, threshold int) *entrySizes {
+	if !enabled {
+		return nil
+	}
+	return &entrySizes{
+		threshold: max(threshold, 0),
+		entries:   newCounter(),
+		bytes:     newCounter(),
+		oversized: newCounter(),
+	}
+}
+
+// wrap returns the encoder recording entry sizes, or the encoder unchanged when s is nil.
+func (s *entrySizes) wrap(encoder zapcore.Encoder) zapcore.Encoder {
+	if s == nil {
+		return encoder
+	}
+	return &sizeEncoder{Encoder: encoder, sizes: s}
+}
+
+// Clone returns a copy of the encoder sharing the size counters.
+func (e *sizeEncoder) Clone() zapcore.Encoder {
+	return &sizeEncoder{Encoder: e.Encoder.Clone(), sizes: e.sizes}
+}
+
+// EncodeEntry encodes the entry, records its size and flags it when it is oversized.
+func (e *sizeEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return buf, err
+	}
+
+	size := buf.Len()
+	e.sizes.record(ent, size)
+	if e.sizes.threshold == 0 || size <= e.sizes.threshold {
+		return buf, nil
+	}
+
+	buf.Free()
+	flagged := make([]Field, 0, len(fields)+2)
+	flagged = append(flagged, fields...)
+	flagged = append(flagged, zap.Int(FieldKeyEntrySize, size), zap.Bool(FieldKeyEntryOversized, true))
+	return e.Encoder.EncodeEntry(ent, flagged)
+}
+
+// record counts an encoded entry of the size.
+func (s *entrySizes) record(ent zapcore.Entry, size int) {
+	s.entries.add(1)
+	s.bytes.add(uint64(size))
+	if s.threshold > 0 && size > s.threshold {
+		s.oversized.add(1)
+	}
+	s.maximum.observe(size, ent.Caller)
+}
@@ -0,0 +1,217 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldKeyEventProperties is the field holding the properties of product analytics
+// events written by Events; their name is written as FieldKeyEvent.
+const FieldKeyEventProperties = "event_properties"
+
+// EventsLoggerName is the logger name of product analytics events.
+const EventsLoggerName = "logger.events"
+
+// snakeCase matches event names and property keys accepted by Events.
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+type (
+	// EventDefinition registers a product analytics event.
+	EventDefinition struct {
+		// Name is the snake_case event name, e.g. "feature_used".
+		Name string
+		// Properties lists the snake_case properties the event may carry. An empty list
+		// allows any snake_case property.
+		Properties []string
+	}
+
+	// EventsConfig configures the product analytics events of an Events facade.
+	EventsConfig struct {
+		// OutputPaths are the dedicated analytics sinks receiving the events. Events are
+		// not written to the sinks of the base logger.
+		OutputPaths []string
+		// Events is the registry of events that may be logged.
+		Events []EventDefinition
+	}
+
+	// Events logs product analytics events through the logging pipeline. Only
+	// registered events with snake_case properties of scalar values are written, so
+	// analytics cannot leak arbitrary payloads, and events go to dedicated sinks
+	// regardless of the level and sampling of the base logger.
+	// Events is safe for concurrent use.
+	Events struct {
+		base       *zapLogger
+		log        *zap.Logger
+		registry   map[string]map[string]bool
+		closeSinks func()
+	}
+
+	// eventProperties logs the properties of an event as one nested object.
+	eventProperties map[string]any
+)
+
+// NewEvents creates a product analytics events facade writing to dedicated sinks.
+//
+// Parameters:
+//   - base: The logger providing the encoder and context fields; must be created by NewLogger
+//   - config: The analytics sinks and the event registry
+//
+// Returns:
+//   - *Events: The events facade
+//   - error: An error if base was not created by NewLogger, no sink is configured, an
+//     event or property name is not snake_case, an event is registered twice or a
+//     sink cannot be opened
+//
+// Example:
+//
+//	events, err := logger.NewEvents(log, logger.EventsConfig{
+//	    OutputPaths: []string{"/var/log/app/analytics.log"},
+//	    Events: []logger.EventDefinition{
+//	        {Name: "feature_used", Properties: []string{"feature", "plan"}},
+//	    },
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer events.Close()
+func NewEvents(base Logger, config EventsConfig) (*Events, error) {
+	zl, ok := unwrapLogger(base)
+	if !ok {
+		return nil, errors.New("events require a logger created by NewLogger")
+	}
+	if len(config.OutputPaths) == 0 {
+		return nil, errors.New("events require at least one output path")
+	}
+
+	registry := make(map[string]map[string]bool, len(config.Events))
+	for _, def := range config.Events {
+		if !snakeCase.MatchString(def.Name) {
+			return nil, fmt.Errorf("event name %q is not snake_case", def.Name)
+		}
+		if _, ok := registry[def.Name]; ok {
+			return nil, fmt.Errorf("event %q registered twice", def.Name)
+		}
+		var allowed map[string]bool
+		if len(def.Properties) > 0 {
+			allowed = make(map[string]bool, len(def.Properties))
+			for _, p := range def.Properties {
+				if !snakeCase.MatchString(p) {
+					return nil, fmt.Errorf("property %q of event %q is not snake_case", p, def.Name)
+				}
+				allowed[p] = true
+			}
+		}
+		registry[def.Name] = allowed
+	}
+
+	state := zl.state
+	sink, closeSinks, err := openSinks(config.OutputPaths, state.cfg)
+	if err != nil {
+		return nil, err
+	}
+	var core zapcore.Core = zapcore.NewCore(state.encoder.Clone(), sink, zapcore.DebugLevel)
+	core = &diagnosticsCore{Core: core, diag: state.diag}
+	log := zap.New(core).Named(EventsLoggerName)
+	if state.cfg.SchemaVersion != "" {
+		log = log.With(zap.String(FieldKeySchemaVersion, state.cfg.SchemaVersion))
+	}
+	if len(state.cfg.ResourceFields) > 0 {
+		log = log.With(state.cfg.ResourceFields...)
+	}
+	if len(state.cfg.CloudFields) > 0 {
+		log = log.With(state.cfg.CloudFields...)
+	}
+
+	return &Events{base: zl, log: log, registry: registry, closeSinks: closeSinks}, nil
+}
+
+// Event logs a registered product analytics event with its properties and the fields
+// of the context, such as user_id and trace_id. The event is rejected when it is not
+// registered, a property key is not snake_case or not registered for the event, or a
+// property value is not a string, bool, number, time or duration.
+//
+// Parameters:
+//   - ctx: The context of the event
+//   - name: The registered event name
+//   - props: The event properties
+//
+// Returns:
+//   - error: An error describing every violation; nothing is logged in that case
+//
+// Example:
+//
+//	err := events.Event(ctx, "feature_used", map[string]any{"feature": "export", "plan": "pro"})
+//	// {"level":"info","logger":"logger.events","message":"feature_used","user_id":"user123","event":"feature_used","event_properties":{"feature":"export","plan":"pro"}}
+func (e *Events) Event(ctx context.Context, name string, props map[string]any) error {
+	allowed, ok := e.registry[name]
+	if !ok {
+		return fmt.Errorf("event %q is not registered", name)
+	}
+
+	var errs []error
+	for k, v := range props {
+		switch {
+		case !snakeCase.MatchString(k):
+			errs = append(errs, fmt.Errorf("property %q of event %q is not snake_case", k, name))
+		case allowed != nil && !allowed[k]:
+			errs = append(errs, fmt.Errorf("property %q is not registered for event %q", k, name))
+		case !isEventValue(v):
+			errs = append(errs, fmt.Errorf("property %q of event %q has unsupported type %T", k, name, v))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+		return errors.Join(errs...)
+	}
+
+	fields := []Field{zap.String(FieldKeyEvent, name)}
+	if len(props) > 0 {
+		fields = append(fields, zap.Object(FieldKeyEventProperties, eventProperties(props)))
+	}
+	e.base.withContext(e.log, ctx).Info(name, fields...)
+	return nil
+}
+
+// Close flushes and closes the analytics sinks.
+func (e *Events) Close() error {
+	err := e.log.Sync()
+	e.closeSinks()
+	if err != nil && !isIgnorableSyncError(err) {
+		return err
+	}
+	return nil
+}
+
+// MarshalLogObject writes the properties sorted by key.
+func (p eventProperties) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(p))
+	for k := range p {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		zap.Any(k, p[k]).AddTo(enc)
+	}
+	return nil
+}
+
+// isEventValue reports whether v is a scalar value allowed as an event property.
+func isEventValue(v any) bool {
+	switch v.(type) {
+	case string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64,
+		time.Time, time.Duration:
+		return true
+	default:
+		return false
+	}
+}
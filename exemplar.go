@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+// exemplarLabels returns the exemplar labels linking a metric update to the trace of
+// the entry: its trace_id and, when it fits, its span_id. It returns nil for entries
+// without a trace ID or whose IDs exceed the exemplar size limit.
+func exemplarLabels(entry Entry) prometheus.Labels {
+	traceID, ok := stringField(entry, ContextKeyTraceID.String())
+	if !ok {
+		return nil
+	}
+	size := len(ContextKeyTraceID) + utf8.RuneCountInString(traceID)
+	if size > prometheus.ExemplarMaxRunes || !utf8.ValidString(traceID) {
+		return nil
+	}
+	labels := prometheus.Labels{ContextKeyTraceID.String(): traceID}
+
+	if spanID, ok := stringField(entry, ContextKeySpanID.String()); ok && utf8.ValidString(spanID) &&
+		size+len(ContextKeySpanID)+utf8.RuneCountInString(spanID) <= prometheus.ExemplarMaxRunes {
+		labels[ContextKeySpanID.String()] = spanID
+	}
+	return labels
+}
+
+// stringField returns the value of the non-empty string field of the entry with the key.
+func stringField(entry Entry, key string) (string, bool) {
+	f, ok := entry.Field(key)
+	if !ok || f.Type != zapcore.StringType || f.String == "" {
+		return "", false
+	}
+	return f.String, true
+}
+
+// addWithExemplar adds v to the counter, with the exemplar when there is one.
+func addWithExemplar(counter prometheus.Counter, v float64, exemplar prometheus.Labels) {
+	if adder, ok := counter.(prometheus.ExemplarAdder); ok && exemplar != nil {
+		adder.AddWithExemplar(v, exemplar)
+		return
+	}
+	counter.Add(v)
+}
+
+// observeWithExemplar observes v, with the exemplar when there is one.
+func observeWithExemplar(observer prometheus.Observer, v float64, exemplar prometheus.Labels) {
+	if eo, ok := observer.(prometheus.ExemplarObserver); ok && exemplar != nil {
+		eo.ObserveWithExemplar(v, exemplar)
+		return
+	}
+	observer.Observe(v)
+}
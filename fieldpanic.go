@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// writeRecovered writes the entry to the core and recovers a panic raised while
+// encoding the fields, e.g. by a MarshalLogObject, MarshalJSON or Error method of user
+// data. The panicking fields are replaced by PANIC= placeholders, each panic is reported
+// as a field_panic diagnostic, and the entry is written again.
+func writeRecovered(core zapcore.Core, diag *diagnostics, ent zapcore.Entry, fields []Field) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			sanitized, ok := sanitizeFields(fields, diag, ent.Message)
+			if !ok {
+				diag.report(DiagnosticFieldPanic, panicError(r), zap.String("entry_message", ent.Message))
+				err = nil
+				return
+			}
+			err = core.Write(ent, sanitized)
+		}
+	}()
+	return core.Write(ent, fields)
+}
+
+// withRecovered adds the fields to a child of the core like writeRecovered writes them.
+func withRecovered(core zapcore.Core, diag *diagnostics, fields []Field) (child zapcore.Core) {
+	defer func() {
+		if r := recover(); r != nil {
+			sanitized, ok := sanitizeFields(fields, diag, "")
+			if !ok {
+				diag.report(DiagnosticFieldPanic, panicError(r))
+				child = core
+				return
+			}
+			child = core.With(sanitized)
+		}
+	}()
+	return core.With(fields)
+}
+
+// sanitizeFields returns the fields with every field that panics when encoded replaced
+// by a placeholder, and reports each panic. It reports false when no field panics.
+func sanitizeFields(fields []Field, diag *diagnostics, msg string) ([]Field, bool) {
+	var out []Field
+	for i, f := range fields {
+		r := encodePanic(f)
+		if r == nil {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, zap.String(f.Key, fmt.Sprintf("PANIC=%v", r)))
+
+		reportFields := []Field{zap.String("field", f.Key)}
+		if msg != "" {
+			reportFields = append(reportFields, zap.String("entry_message", msg))
+		}
+		diag.report(DiagnosticFieldPanic, panicError(r), reportFields...)
+	}
+	return out, out != nil
+}
+
+// encodePanic encodes the field with a JSON encoder and returns the recovered panic
+// value, or nil when the field encodes without panicking.
+func encodePanic(f Field) (r any) {
+	switch f.Type {
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType, zapcore.ArrayMarshalerType,
+		zapcore.ReflectType, zapcore.StringerType, zapcore.ErrorType:
+	default:
+		return nil
+	}
+
+	defer func() {
+		r = recover()
+	}()
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	f.AddTo(enc)
+	return nil
+}
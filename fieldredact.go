@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// redactedFields holds the keys of the fields whose values are replaced with
+	// [REDACTED]. The keys can be changed at runtime.
+	redactedFields struct {
+		keys atomic.Pointer[map[string]struct{}]
+	}
+
+	// redactCore wraps a zapcore.Core and redacts fields right before encoding.
+	redactCore struct {
+		zapcore.Core
+		redacted *redactedFields
+	}
+)
+
+// newRedactedFields returns the redacted fields holding the keys.
+func newRedactedFields(keys []string) *redactedFields {
+	r := &redactedFields{}
+	r.set(keys)
+	return r
+}
+
+// set replaces the redacted keys.
+func (r *redactedFields) set(keys []string) {
+	if len(keys) == 0 {
+		r.keys.Store(nil)
+		return
+	}
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+	r.keys.Store(&set)
+}
+
+// list returns the redacted keys in order.
+func (r *redactedFields) list() []string {
+	set := r.keys.Load()
+	if set == nil {
+		return []string{}
+	}
+	keys := make([]string, 0, len(*set))
+	for key := range *set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String describes the redacted keys for audit entries.
+func (r *redactedFields) String() string {
+	keys := r.list()
+	if len(keys) == 0 {
+		return "none"
+	}
+	return strings.Join(keys, ",")
+}
+
+// redact returns the fields with the values of redacted keys replaced. The input slice
+// is never modified.
+func (r *redactedFields) redact(fields []Field) []Field {
+	set := r.keys.Load()
+	if set == nil {
+		return fields
+	}
+
+	var out []Field
+	for i, f := range fields {
+		if _, ok := (*set)[f.Key]; !ok {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, zap.String(f.Key, redactedValue))
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// With returns a child core with the redacted fields. Fields are redacted with the keys
+// in effect when the child is created.
+func (c *redactCore) With(fields []Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redacted.redact(fields)), redacted: c.redacted}
+}
+
+// lowered returns a copy of the core sharing the redacted fields.
+func (c *redactCore) lowered() zapcore.Core {
+	return &redactCore{Core: lowerCore(c.Core), redacted: c.redacted}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry with the redacted fields.
+func (c *redactCore) Write(ent zapcore.Entry, fields []Field) error {
+	return c.Core.Write(ent, c.redacted.redact(fields))
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// TestRedactedFieldsReachNoOutput logs redacted keys as With and entry fields and checks
+// that no output of the logger receives their values: the sinks, a capture window, the
+// dedicated sink of a tenant and the New Relic forwarder.
+func TestRedactedFieldsReachNoOutput(t *testing.T) {
+	const secret, withSecret = "hunter2", "with-hunter2"
+
+	app, observed := observeNewRelic(t)
+
+	dir := t.TempDir()
+	mainPath, tenantPath := filepath.Join(dir, "app.log"), filepath.Join(dir, "tenant.log")
+	log, err := NewLogger(
+		WithEncoding(EncodingJson),
+		WithOutputPaths([]string{mainPath}),
+		WithRedactedFields("password"),
+		WithNewRelicApp(app),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenants, err := NewTenantLoggers(log, TenantConfig{OutputPaths: []string{tenantPath}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(20 * time.Millisecond)
+		log.With(zap.String("password", withSecret)).Info(ctx, "login", zap.String("password", secret))
+		tenants.Get("acme").With(zap.String("password", withSecret)).Info(ctx, "tenant login", zap.String("password", secret))
+	}()
+	snapshot, err := CaptureWindow(ctx, log, 200*time.Millisecond)
+	<-done
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshot.Close()
+	tenants.Close()
+	if err := Close(ctx, log); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := map[string]string{}
+	for name, path := range map[string]string{"sink": mainPath, "tenant sink": tenantPath} {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs[name] = string(b)
+	}
+	entries, err := snapshot.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(entries)
+	entries.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs["capture"] = string(b)
+
+	var nr strings.Builder
+	for _, entry := range observed.All() {
+		nr.WriteString(entry.Message)
+		for key, value := range entry.ContextMap() {
+			fmt.Fprintf(&nr, " %s=%v", key, value)
+		}
+		nr.WriteByte('\n')
+	}
+	outputs["new relic"] = nr.String()
+
+	for name, out := range outputs {
+		if !strings.Contains(out, "login") {
+			t.Errorf("%s received no entry:\n%s", name, out)
+		}
+		if strings.Contains(out, secret) {
+			t.Errorf("%s received the value of a redacted field:\n%s", name, out)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// Filter decides whether a log entry is dropped.
+	// It returns true for entries that must not be written.
+	// Filters run before any sink, capture, crash report, metric or New Relic sees the
+	// entry, so dropped entries cost almost nothing.
+	Filter func(Entry) bool
+
+	// filterCore wraps the cores of a logger and drops entries matched by any filter.
+	filterCore struct {
+		zapcore.Core
+		filters []Filter
+		fields  []Field
+	}
+
+	// filterSink is added to checked entries in place of the cores that checked the
+	// entry, and writes it to them when no filter matches it.
+	filterSink struct {
+		core    *filterCore
+		checked *zapcore.CheckedEntry
+	}
+)
+
+// newFilterCore wraps core with the filters. fields are the fields already added to core,
+// which filters see with the fields of each entry. It returns core unchanged when there
+// are no filters.
+func newFilterCore(core zapcore.Core, filters []Filter, fields []Field) zapcore.Core {
+	if len(filters) == 0 {
+		return core
+	}
+	return &filterCore{Core: core, filters: filters, fields: fields}
+}
+
+// With returns a child core remembering the fields so filters can inspect them.
+func (c *filterCore) With(fields []Field) zapcore.Core {
+	return &filterCore{
+		Core:    c.Core.With(fields),
+		filters: c.filters,
+		fields:  append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+// lowered returns a copy of the core keeping the filters and fields.
+func (c *filterCore) lowered() zapcore.Core {
+	return &filterCore{Core: lowerCore(c.Core), filters: c.filters, fields: c.fields}
+}
+
+// Check lets the wrapped cores check the entry into a checked entry of their own and
+// adds a filterSink in their place. The fields of an entry are only known when it is
+// written, so the sink runs the filters before any wrapped core writes the entry.
+func (c *filterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	checked := c.Core.Check(ent, nil)
+	if checked == nil {
+		return ce
+	}
+	return ce.AddCore(ent, &filterSink{core: c, checked: checked})
+}
+
+// Write writes the entry unless a filter matches it.
+func (c *filterCore) Write(ent zapcore.Entry, fields []Field) error {
+	if c.drops(ent, fields) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// drops reports whether a filter matches the entry.
+func (c *filterCore) drops(ent zapcore.Entry, fields []Field) bool {
+	entry := Entry{Entry: ent, Fields: fields}
+	if len(c.fields) > 0 {
+		entry.Fields = append(c.fields[:len(c.fields):len(c.fields)], fields...)
+	}
+
+	for _, filter := range c.filters {
+		if filter(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports true; the sink is only added to entries the wrapped cores checked.
+func (s *filterSink) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With returns the sink unchanged; it is never used as a logger core.
+func (s *filterSink) With([]Field) zapcore.Core {
+	return s
+}
+
+// Check returns ce unchanged; the sink is added by filterCore.Check.
+func (s *filterSink) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+
+// Write writes the entry to the cores that checked it unless a filter matches it. The
+// entry carries the caller and stack trace added by the logger after the check.
+func (s *filterSink) Write(ent zapcore.Entry, fields []Field) error {
+	if s.core.drops(ent, fields) {
+		return nil
+	}
+	s.checked.Entry = ent
+	s.checked.Write(fields...)
+	return nil
+}
+
+// Sync does nothing; the wrapped cores are flushed by the logger.
+func (s *filterSink) Sync() error {
+	return nil
+}
+
+// FilterMessage returns a Filter dropping entries with exactly the given message.
+//
+// Example:
+//
+//	logger := NewLogger(WithFilter(FilterMessage("health check")))
+func FilterMessage(msg string) Filter {
+	return func(e Entry) bool {
+		return e.Message == msg
+	}
+}
+
+// FilterLoggerName returns a Filter dropping entries from the named logger.
+// Use it to silence a noisy third-party component.
+//
+// Example:
+//
+//	logger := NewLogger(WithFilter(FilterLoggerName("kafka.consumer")))
+func FilterLoggerName(name string) Filter {
+	return func(e Entry) bool {
+		return e.LoggerName == name
+	}
+}
+
+// FilterFieldEquals returns a Filter dropping entries whose string field equals value.
+//
+// Example:
+//
+//	logger := NewLogger(WithFilter(FilterFieldEquals("path", "/healthz")))
+func FilterFieldEquals(key, value string) Filter {
+	return func(e Entry) bool {
+		f, ok := e.Field(key)
+		return ok && f.Type == zapcore.StringType && f.String == value
+	}
+}
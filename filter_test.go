@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// TestFilteredEntriesReachNoOutput logs a filtered and a kept entry during a capture and
+// checks that the filtered one reaches neither the sink, the capture, the metric rules
+// nor New Relic.
+func TestFilteredEntriesReachNoOutput(t *testing.T) {
+	app, observed := observeNewRelic(t)
+	registry := prometheus.NewRegistry()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := NewLogger(
+		WithEncoding(EncodingJson),
+		WithOutputPaths([]string{path}),
+		WithNewRelicApp(app),
+		WithFilter(FilterFieldEquals("path", "/healthz")),
+		WithMetricRules(registry, MetricRule{Name: "requests_total", Match: FilterMessage("request")}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	type result struct {
+		snapshot *Snapshot
+		err      error
+	}
+	captured := make(chan result)
+	go func() {
+		snapshot, err := CaptureWindow(ctx, log, 200*time.Millisecond)
+		captured <- result{snapshot, err}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	log.With(zap.String("path", "/healthz")).Info(ctx, "request")
+	log.Info(ctx, "request", zap.String("path", "/orders"))
+	capture := <-captured
+	if capture.err != nil {
+		t.Fatal(capture.err)
+	}
+	defer capture.snapshot.Close()
+	if err := Close(ctx, log); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local := string(b); strings.Contains(local, "/healthz") || !strings.Contains(local, "/orders") {
+		t.Errorf("sink received %q, want only the kept entry", local)
+	}
+
+	r, err := capture.snapshot.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	b, err = io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded := string(b); strings.Contains(recorded, "/healthz") || !strings.Contains(recorded, "/orders") {
+		t.Errorf("capture recorded %q, want only the kept entry", recorded)
+	}
+
+	if n := observed.FilterMessage("request").Len(); n != 1 {
+		t.Errorf("forwarded %d request entries to New Relic, want 1", n)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var count float64
+	for _, family := range families {
+		if family.GetName() == "requests_total" {
+			for _, metric := range family.GetMetric() {
+				count += metric.GetCounter().GetValue()
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("requests_total is %v, want 1", count)
+	}
+}
@@ -0,0 +1,432 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// FlagProvider supplies the logging controls of a feature flag system. WatchFlags
+	// polls it; providers that can push changes also implement FlagNotifier.
+	FlagProvider interface {
+		// Controls returns the current logging controls.
+		Controls(ctx context.Context) (FlagControls, error)
+	}
+
+	// FlagNotifier is implemented by flag providers that signal changes, so WatchFlags
+	// reads the controls right away instead of waiting for the next poll.
+	FlagNotifier interface {
+		// Changed returns a channel receiving a value whenever the controls change.
+		Changed() <-chan struct{}
+	}
+
+	// FlagControls are the logging controls read from a flag system. Controls left
+	// empty reset the logger to its settings when the watch started.
+	FlagControls struct {
+		// Level is the minimum level of the logger.
+		Level Level `json:"level,omitempty"`
+		// LoggerLevels are minimum levels per logger name. A name also applies to its
+		// children, e.g. "kafka" to "kafka.consumer", unless they have their own level.
+		LoggerLevels map[string]Level `json:"logger_levels,omitempty"`
+		// DebugTenants are tenant IDs whose debug entries are written whatever the level.
+		// They apply to loggers carrying a tenant_id field, such as tenant loggers.
+		DebugTenants []string `json:"debug_tenants,omitempty"`
+		// Sampling is the sampling of the logger; zero for both disables sampling.
+		Sampling *FlagSampling `json:"sampling,omitempty"`
+	}
+
+	// FlagSampling is the sampling of FlagControls, as in SetSampling.
+	FlagSampling struct {
+		Initial    int `json:"initial"`
+		Thereafter int `json:"thereafter"`
+	}
+
+	// HTTPFlagProvider reads logging controls as a JSON document from an HTTP endpoint.
+	// It sends the ETag of the last response, so unchanged controls cost a 304.
+	HTTPFlagProvider struct {
+		url    string
+		client *http.Client
+
+		mu   sync.Mutex
+		etag string
+		last FlagControls
+	}
+
+	// levelOverrides holds the levels per logger name and the debug tenants of a logger
+	// and its children, which can be changed at runtime.
+	levelOverrides struct {
+		current atomic.Pointer[overrideSettings]
+	}
+
+	// overrideSettings is a snapshot of the level overrides.
+	overrideSettings struct {
+		names   map[string]zapcore.Level
+		tenants map[string]struct{}
+		// min is the lowest level enabled by any override.
+		min zapcore.Level
+	}
+
+	// overrideCore wraps the core of a logger and applies the level overrides. Like
+	// levelCore it can lower the level; lowered entries are checked by the lowered
+	// wrapped core.
+	overrideCore struct {
+		zapcore.Core
+		overrides *levelOverrides
+		// tenant is the tenant_id field added with With, if any.
+		tenant string
+		// open returns the lowered wrapped core, built for the first lowered entry.
+		open func() zapcore.Core
+	}
+
+	// flagWatcher applies the controls of a flag provider to a logger.
+	flagWatcher struct {
+		z        *zapLogger
+		provider FlagProvider
+		// base holds the settings of the logger when the watch started.
+		base configSettings
+		// last holds the controls last applied, nil before the first read.
+		last *FlagControls
+		// lastErr is the last provider error, logged once until it changes.
+		lastErr string
+	}
+)
+
+// WatchFlags applies the logging controls of a flag provider to the logger and all its
+// children, polling the provider every interval until the context is done, and returns
+// the context error. Verbosity can then be changed from a feature flag system: the
+// minimum level, levels per logger name, debug entries of single tenants and sampling.
+// Every change is recorded by an audit entry like in SetLevel, with "flags" as
+// changed_by unless the context carries a user ID. Controls are only applied when they
+// change, so runtime changes made in between are kept; provider errors are logged and
+// leave the logger unchanged. A panicking provider is reported as a goroutine_panic
+// diagnostic and the watch restarts after a backoff.
+//
+// Parameters:
+//   - ctx: The context stopping the watch
+//   - log: The logger created by NewLogger
+//   - provider: The source of the logging controls
+//   - interval: The time between polls of the provider
+//
+// Returns:
+//   - error: An error if the logger was not created by NewLogger, or the context error
+//     once the watch stops
+//
+// Example:
+//
+//	provider := logger.NewHTTPFlagProvider("http://flags.internal/logging/checkout", nil)
+//	go func() {
+//	    _ = logger.WatchFlags(ctx, log, provider, 30*time.Second)
+//	}()
+func WatchFlags(ctx context.Context, log Logger, provider FlagProvider, interval time.Duration) error {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return errRuntimeUnsupported
+	}
+	if _, ok := UserIDFromContext(ctx); !ok {
+		ctx = WithUserID(ctx, "flags")
+	}
+
+	var changed <-chan struct{}
+	if notifier, ok := provider.(FlagNotifier); ok {
+		changed = notifier.Changed()
+	}
+
+	w := &flagWatcher{z: z, provider: provider, base: currentSettings(z)}
+	z.state.supervisor.supervise("flags", ctx.Done(), func() {
+		w.watch(ctx, interval, changed)
+	})
+	return ctx.Err()
+}
+
+// watch polls the provider every interval, and whenever it signals a change, until the
+// context is done.
+func (w *flagWatcher) watch(ctx context.Context, interval time.Duration, changed <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-changed:
+		}
+	}
+}
+
+// poll reads the controls and applies them when they changed.
+func (w *flagWatcher) poll(ctx context.Context) {
+	controls, err := w.provider.Controls(ctx)
+	if err != nil {
+		if ctx.Err() == nil && err.Error() != w.lastErr {
+			w.lastErr = err.Error()
+			w.z.Error(ctx, "failed to read logging flags", zap.Error(err))
+		}
+		return
+	}
+	w.lastErr = ""
+	if w.last != nil && reflect.DeepEqual(controls, *w.last) {
+		return
+	}
+	w.last = &controls
+
+	settings, overrides, err := w.parse(controls)
+	if err != nil {
+		w.z.Error(ctx, "invalid logging flags", zap.Error(err))
+		return
+	}
+
+	current := currentSettings(w.z)
+	if settings.level != current.level {
+		setLevel(ctx, w.z, settings.level)
+	}
+	if settings.sampling != current.sampling {
+		_ = SetSampling(ctx, w.z, settings.sampling.Initial, settings.sampling.Thereafter)
+	}
+	old := w.z.state.overrides.load()
+	w.z.state.overrides.store(overrides)
+	if before, after := old.namesString(), overrides.namesString(); before != after {
+		auditChange(ctx, w.z, "logger_levels", before, after)
+	}
+	if before, after := old.tenantsString(), overrides.tenantsString(); before != after {
+		auditChange(ctx, w.z, "debug_tenants", before, after)
+	}
+}
+
+// parse validates the controls and returns the settings and overrides they ask for,
+// falling back to the settings the watch started with.
+func (w *flagWatcher) parse(controls FlagControls) (configSettings, *overrideSettings, error) {
+	settings := w.base
+	if controls.Level != "" {
+		level, err := parseLevel(controls.Level)
+		if err != nil {
+			return configSettings{}, nil, err
+		}
+		settings.level = level.Level()
+	}
+	if controls.Sampling != nil {
+		if controls.Sampling.Initial < 0 || controls.Sampling.Thereafter < 0 {
+			return configSettings{}, nil, errors.New("sampling values cannot be negative")
+		}
+		settings.sampling = configFileSampling{Initial: controls.Sampling.Initial, Thereafter: controls.Sampling.Thereafter}
+	}
+
+	overrides := &overrideSettings{names: map[string]zapcore.Level{}, tenants: map[string]struct{}{}}
+	for name, l := range controls.LoggerLevels {
+		level, err := parseLevel(l)
+		if err != nil {
+			return configSettings{}, nil, fmt.Errorf("logger %q: %w", name, err)
+		}
+		overrides.names[name] = level.Level()
+	}
+	for _, tenant := range controls.DebugTenants {
+		overrides.tenants[tenant] = struct{}{}
+	}
+	return settings, overrides, nil
+}
+
+// NewHTTPFlagProvider creates a flag provider reading the controls from url with GET
+// requests. The endpoint returns FlagControls as JSON, for example:
+//
+//	{"level":"info","logger_levels":{"kafka":"debug"},"debug_tenants":["acme"],
+//	 "sampling":{"initial":100,"thereafter":100}}
+//
+// Parameters:
+//   - url: The endpoint serving the controls
+//   - client: The HTTP client; nil uses a client with a 10 second timeout
+//
+// Returns:
+//   - *HTTPFlagProvider: The flag provider
+//
+// Example:
+//
+//	provider := logger.NewHTTPFlagProvider("http://flags.internal/logging/checkout", nil)
+func NewHTTPFlagProvider(url string, client *http.Client) *HTTPFlagProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTPFlagProvider{url: url, client: client}
+}
+
+// Controls fetches the controls, or returns the last ones when the endpoint answers
+// 304 Not Modified.
+//
+// Parameters:
+//   - ctx: The context of the request
+//
+// Returns:
+//   - FlagControls: The logging controls
+//   - error: An error if the request fails, the status is not 200 or 304 or the body
+//     is not valid JSON
+func (p *HTTPFlagProvider) Controls(ctx context.Context) (FlagControls, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return FlagControls{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return FlagControls{}, fmt.Errorf("failed to fetch logging flags: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return p.last, nil
+	case http.StatusOK:
+	default:
+		return FlagControls{}, fmt.Errorf("failed to fetch logging flags: unexpected status %s", resp.Status)
+	}
+
+	var controls FlagControls
+	if err := json.NewDecoder(resp.Body).Decode(&controls); err != nil {
+		return FlagControls{}, fmt.Errorf("failed to decode logging flags: %w", err)
+	}
+	p.etag = resp.Header.Get("ETag")
+	p.last = controls
+	return controls, nil
+}
+
+// load returns the current overrides, or nil when there are none.
+func (o *levelOverrides) load() *overrideSettings {
+	return o.current.Load()
+}
+
+// store replaces the overrides; empty overrides are stored as nil so cores skip them.
+func (o *levelOverrides) store(settings *overrideSettings) {
+	if len(settings.names) == 0 && len(settings.tenants) == 0 {
+		o.current.Store(nil)
+		return
+	}
+	settings.min = zapcore.InvalidLevel
+	for _, level := range settings.names {
+		if settings.min == zapcore.InvalidLevel || level < settings.min {
+			settings.min = level
+		}
+	}
+	if len(settings.tenants) > 0 {
+		settings.min = zapcore.DebugLevel
+	}
+	o.current.Store(settings)
+}
+
+// level returns the override of the logger name or of its closest parent.
+func (s *overrideSettings) level(name string) (zapcore.Level, bool) {
+	for name != "" {
+		if level, ok := s.names[name]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return zapcore.InvalidLevel, false
+}
+
+// namesString describes the levels per logger name for audit entries.
+func (s *overrideSettings) namesString() string {
+	if s == nil || len(s.names) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(s.names))
+	for name, level := range s.names {
+		names = append(names, name+"="+level.String())
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// tenantsString describes the debug tenants for audit entries.
+func (s *overrideSettings) tenantsString() string {
+	if s == nil || len(s.tenants) == 0 {
+		return "none"
+	}
+	tenants := make([]string, 0, len(s.tenants))
+	for tenant := range s.tenants {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	return strings.Join(tenants, ",")
+}
+
+// newOverrideCore wraps core with the level overrides.
+func newOverrideCore(core zapcore.Core, overrides *levelOverrides) *overrideCore {
+	return &overrideCore{
+		Core:      core,
+		overrides: overrides,
+		open:      sync.OnceValue(func() zapcore.Core { return lowerCore(core) }),
+	}
+}
+
+// Enabled reports whether the level is enabled by the wrapped core or an override.
+func (c *overrideCore) Enabled(level zapcore.Level) bool {
+	if c.Core.Enabled(level) {
+		return true
+	}
+	s := c.overrides.load()
+	return s != nil && level >= s.min
+}
+
+// With returns a child core remembering the tenant_id field.
+func (c *overrideCore) With(fields []Field) zapcore.Core {
+	child := newOverrideCore(c.Core.With(fields), c.overrides)
+	child.tenant = c.tenant
+	for _, f := range fields {
+		if f.Key == tenantIDKey && f.Type == zapcore.StringType {
+			child.tenant = f.String
+		}
+	}
+	return child
+}
+
+// lowered returns the lowered wrapped core; overrides only decide levels, which the
+// lowered core enables anyway.
+func (c *overrideCore) lowered() zapcore.Core {
+	return lowerCore(c.Core)
+}
+
+// Check applies the override of the logger name or tenant. Entries without override,
+// and entries the wrapped core enables anyway, are checked by the wrapped core.
+func (c *overrideCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	s := c.overrides.load()
+	if s == nil {
+		return c.Core.Check(ent, ce)
+	}
+
+	level, ok := s.level(ent.LoggerName)
+	if _, debug := s.tenants[c.tenant]; debug && c.tenant != "" {
+		level, ok = zapcore.DebugLevel, true
+	}
+	if !ok {
+		return c.Core.Check(ent, ce)
+	}
+	if ent.Level < level {
+		return ce
+	}
+	if c.Core.Enabled(ent.Level) {
+		return c.Core.Check(ent, ce)
+	}
+	return c.open().Check(ent, ce)
+}
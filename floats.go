@@ -0,0 +1,225 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// NonFiniteMode controls how NaN and ±Inf floats are encoded.
+type NonFiniteMode string
+
+const (
+	// NonFiniteDefault keeps zap's behavior: float fields are written as the strings
+	// "NaN", "+Inf" and "-Inf", while reflected values containing them fail to encode.
+	NonFiniteDefault NonFiniteMode = ""
+	// NonFiniteString writes NaN and ±Inf as the strings "NaN", "+Inf" and "-Inf"
+	// everywhere, reflected values included.
+	NonFiniteString NonFiniteMode = "string"
+	// NonFiniteNull writes NaN and ±Inf as null everywhere.
+	NonFiniteNull NonFiniteMode = "null"
+)
+
+type (
+	// nullFloatEncoder wraps an encoder and writes non-finite floats as null.
+	nullFloatEncoder struct {
+		zapcore.Encoder
+	}
+
+	// nullFloatObjectEncoder writes the non-finite floats of nested objects as null.
+	nullFloatObjectEncoder struct {
+		zapcore.ObjectEncoder
+	}
+
+	// nullFloatArrayEncoder writes the non-finite floats of nested arrays as null.
+	nullFloatArrayEncoder struct {
+		zapcore.ArrayEncoder
+	}
+
+	// nullFloatObject marshals an object with its non-finite floats written as null.
+	nullFloatObject struct {
+		zapcore.ObjectMarshaler
+	}
+
+	// nullFloatArray marshals an array with its non-finite floats written as null.
+	nullFloatArray struct {
+		zapcore.ArrayMarshaler
+	}
+)
+
+// validateNonFiniteMode checks that the mode exists.
+func validateNonFiniteMode(mode NonFiniteMode) error {
+	switch mode {
+	case NonFiniteDefault, NonFiniteString, NonFiniteNull:
+		return nil
+	default:
+		return fmt.Errorf("invalid non-finite float mode %q", mode)
+	}
+}
+
+// newNonFiniteEncoder wraps the encoder for the mode. Only NonFiniteNull needs a
+// wrapper; zap already writes non-finite float fields as strings.
+func newNonFiniteEncoder(encoder zapcore.Encoder, mode NonFiniteMode) zapcore.Encoder {
+	if mode != NonFiniteNull {
+		return encoder
+	}
+	return &nullFloatEncoder{Encoder: encoder}
+}
+
+// nonFiniteValue returns the replacement of a non-finite float in reflected values.
+func nonFiniteValue(f float64, mode NonFiniteMode) any {
+	if mode == NonFiniteNull {
+		return nil
+	}
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case f > 0:
+		return "+Inf"
+	default:
+		return "-Inf"
+	}
+}
+
+// isNonFinite reports whether f is NaN or ±Inf.
+func isNonFinite(f float64) bool {
+	return math.IsNaN(f) || math.IsInf(f, 0)
+}
+
+// Clone returns a copy of the encoder that still writes non-finite floats as null.
+func (e *nullFloatEncoder) Clone() zapcore.Encoder {
+	return &nullFloatEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// AddFloat64 adds a float, or null when it is not finite; used for fields added with With.
+func (e *nullFloatEncoder) AddFloat64(key string, v float64) {
+	if isNonFinite(v) {
+		_ = e.Encoder.AddReflected(key, nil)
+		return
+	}
+	e.Encoder.AddFloat64(key, v)
+}
+
+// AddFloat32 adds a float, or null when it is not finite.
+func (e *nullFloatEncoder) AddFloat32(key string, v float32) {
+	e.AddFloat64(key, float64(v))
+}
+
+// AddObject adds an object with its non-finite floats written as null.
+func (e *nullFloatEncoder) AddObject(key string, m zapcore.ObjectMarshaler) error {
+	return e.Encoder.AddObject(key, nullFloatObject{m})
+}
+
+// AddArray adds an array with its non-finite floats written as null.
+func (e *nullFloatEncoder) AddArray(key string, m zapcore.ArrayMarshaler) error {
+	return e.Encoder.AddArray(key, nullFloatArray{m})
+}
+
+// EncodeEntry encodes the entry with non-finite floats written as null.
+func (e *nullFloatEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	var out []Field
+	for i, f := range fields {
+		converted, ok := nullFloatField(f)
+		if !ok {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, converted)
+	}
+	if out != nil {
+		fields = out
+	}
+	return e.Encoder.EncodeEntry(ent, fields)
+}
+
+// nullFloatField converts a non-finite float field to null and wraps object and array
+// fields; it reports false for fields left unchanged.
+func nullFloatField(f Field) (Field, bool) {
+	switch f.Type {
+	case zapcore.Float64Type:
+		if isNonFinite(math.Float64frombits(uint64(f.Integer))) {
+			return Field{Key: f.Key, Type: zapcore.ReflectType}, true
+		}
+	case zapcore.Float32Type:
+		if isNonFinite(float64(math.Float32frombits(uint32(f.Integer)))) {
+			return Field{Key: f.Key, Type: zapcore.ReflectType}, true
+		}
+	case zapcore.ObjectMarshalerType, zapcore.InlineMarshalerType:
+		if m, ok := f.Interface.(zapcore.ObjectMarshaler); ok {
+			f.Interface = nullFloatObject{m}
+			return f, true
+		}
+	case zapcore.ArrayMarshalerType:
+		if m, ok := f.Interface.(zapcore.ArrayMarshaler); ok {
+			f.Interface = nullFloatArray{m}
+			return f, true
+		}
+	}
+	return f, false
+}
+
+// MarshalLogObject marshals the object through an encoder writing non-finite floats as null.
+func (o nullFloatObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.ObjectMarshaler.MarshalLogObject(&nullFloatObjectEncoder{enc})
+}
+
+// MarshalLogArray marshals the array through an encoder writing non-finite floats as null.
+func (a nullFloatArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.ArrayMarshaler.MarshalLogArray(&nullFloatArrayEncoder{enc})
+}
+
+// AddFloat64 adds a float, or null when it is not finite.
+func (e *nullFloatObjectEncoder) AddFloat64(key string, v float64) {
+	if isNonFinite(v) {
+		_ = e.ObjectEncoder.AddReflected(key, nil)
+		return
+	}
+	e.ObjectEncoder.AddFloat64(key, v)
+}
+
+// AddFloat32 adds a float, or null when it is not finite.
+func (e *nullFloatObjectEncoder) AddFloat32(key string, v float32) {
+	e.AddFloat64(key, float64(v))
+}
+
+// AddObject adds a nested object with its non-finite floats written as null.
+func (e *nullFloatObjectEncoder) AddObject(key string, m zapcore.ObjectMarshaler) error {
+	return e.ObjectEncoder.AddObject(key, nullFloatObject{m})
+}
+
+// AddArray adds a nested array with its non-finite floats written as null.
+func (e *nullFloatObjectEncoder) AddArray(key string, m zapcore.ArrayMarshaler) error {
+	return e.ObjectEncoder.AddArray(key, nullFloatArray{m})
+}
+
+// AppendFloat64 appends a float, or null when it is not finite.
+func (e *nullFloatArrayEncoder) AppendFloat64(v float64) {
+	if isNonFinite(v) {
+		_ = e.ArrayEncoder.AppendReflected(nil)
+		return
+	}
+	e.ArrayEncoder.AppendFloat64(v)
+}
+
+// AppendFloat32 appends a float, or null when it is not finite.
+func (e *nullFloatArrayEncoder) AppendFloat32(v float32) {
+	e.AppendFloat64(float64(v))
+}
+
+// AppendObject appends a nested object with its non-finite floats written as null.
+func (e *nullFloatArrayEncoder) AppendObject(m zapcore.ObjectMarshaler) error {
+	return e.ArrayEncoder.AppendObject(nullFloatObject{m})
+}
+
+// AppendArray appends a nested array with its non-finite floats written as null.
+func (e *nullFloatArrayEncoder) AppendArray(m zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(nullFloatArray{m})
+}
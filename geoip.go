@@ -0,0 +1,188 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+	"go.uber.org/zap"
+)
+
+// Field keys added by the GeoIP enricher.
+const (
+	FieldKeyGeoCountry = "geo_country"
+	FieldKeyGeoRegion  = "geo_region"
+)
+
+// Defaults of NewGeoIPEnricher.
+const (
+	defaultGeoIPCacheSize = 4096
+	defaultGeoIPTimeout   = time.Millisecond
+)
+
+type (
+	// GeoLocation is the location of an IP address.
+	GeoLocation struct {
+		// Country is the ISO 3166-1 alpha-2 code of the country, e.g. "DE".
+		Country string
+		// Region is the ISO 3166-2 code of the subdivision without the country prefix, e.g. "BE".
+		Region string
+	}
+
+	// GeoIPDatabase looks up the location of IP addresses. Implementations must be
+	// safe for concurrent use.
+	GeoIPDatabase interface {
+		Lookup(ip net.IP) (GeoLocation, error)
+	}
+
+	// MaxMindDB is a GeoIPDatabase backed by a MaxMind GeoIP2 or GeoLite2 Country or City database.
+	MaxMindDB struct {
+		reader *geoip2.Reader
+		city   bool
+	}
+
+	// geoIPEnricher adds the location of the ip_address context value to entries.
+	geoIPEnricher struct {
+		db      GeoIPDatabase
+		cache   *lruCache[string, []Field]
+		timeout time.Duration
+	}
+)
+
+// OpenMaxMindDB opens a MaxMind database file (.mmdb) for NewGeoIPEnricher.
+// Country and City databases are supported; regions are only known by City databases.
+// Close the database once the logger is closed, e.g. with OnShutdown.
+//
+// Parameters:
+//   - path: The path of the database file, e.g. GeoLite2-City.mmdb
+//
+// Returns:
+//   - *MaxMindDB: The opened database
+//   - error: An error if the file cannot be opened or is not a MaxMind database
+//
+// Example:
+//
+//	db, err := logger.OpenMaxMindDB("/usr/share/GeoIP/GeoLite2-City.mmdb")
+//	if err != nil {
+//	    return err
+//	}
+//	log, err := logger.NewLogger(logger.WithEnricher(logger.NewGeoIPEnricher(db, 0, 0)))
+//	_ = logger.OnShutdown(log, func(context.Context) error { return db.Close() })
+func OpenMaxMindDB(path string) (*MaxMindDB, error) {
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open MaxMind database %s: %w", path, err)
+	}
+	dbType := reader.Metadata().DatabaseType
+	return &MaxMindDB{
+		reader: reader,
+		city:   strings.Contains(dbType, "City") || strings.Contains(dbType, "Enterprise"),
+	}, nil
+}
+
+// Lookup returns the location of the IP address.
+func (m *MaxMindDB) Lookup(ip net.IP) (GeoLocation, error) {
+	if !m.city {
+		record, err := m.reader.Country(ip)
+		if err != nil {
+			return GeoLocation{}, err
+		}
+		return GeoLocation{Country: record.Country.IsoCode}, nil
+	}
+
+	record, err := m.reader.City(ip)
+	if err != nil {
+		return GeoLocation{}, err
+	}
+	loc := GeoLocation{Country: record.Country.IsoCode}
+	if len(record.Subdivisions) > 0 {
+		loc.Region = record.Subdivisions[0].IsoCode
+	}
+	return loc, nil
+}
+
+// Close closes the database file.
+func (m *MaxMindDB) Close() error {
+	return m.reader.Close()
+}
+
+// NewGeoIPEnricher creates an Enricher adding geo_country and geo_region to entries
+// whose context carries an address under ContextKeyIpAddress, with or without a port.
+// Locations are cached per address, including addresses without a location. A lookup
+// taking longer than timeout is not waited for: the entry is written without location
+// and the result is cached for later entries once the lookup completes, so the
+// database never slows logging down by more than timeout.
+//
+// Parameters:
+//   - db: The database looking up locations, e.g. from OpenMaxMindDB
+//   - cacheSize: The number of addresses cached; zero or less uses 4096
+//   - timeout: The maximum time an entry waits for a lookup; zero or less uses 1ms
+//
+// Returns:
+//   - Enricher: The enricher to pass to WithEnricher
+//
+// Example:
+//
+//	log, err := logger.NewLogger(logger.WithEnricher(logger.NewGeoIPEnricher(db, 0, 0)))
+//	// {"message":"order created","ip_address":"81.2.69.142","geo_country":"GB","geo_region":"ENG",...}
+func NewGeoIPEnricher(db GeoIPDatabase, cacheSize int, timeout time.Duration) Enricher {
+	if cacheSize <= 0 {
+		cacheSize = defaultGeoIPCacheSize
+	}
+	if timeout <= 0 {
+		timeout = defaultGeoIPTimeout
+	}
+	return &geoIPEnricher{db: db, cache: newLRUCache[string, []Field](cacheSize), timeout: timeout}
+}
+
+// Enrich returns the location fields of the ip_address of the context.
+func (g *geoIPEnricher) Enrich(ctx context.Context) []Field {
+	addr, ok := getStringFromContext(ctx, ContextKeyIpAddress)
+	if !ok || addr == "" {
+		return nil
+	}
+	if fields, ok := g.cache.Get(addr); ok {
+		return fields
+	}
+
+	done := make(chan []Field, 1)
+	go func() {
+		fields := g.lookup(addr)
+		g.cache.Set(addr, fields)
+		done <- fields
+	}()
+
+	timer := time.NewTimer(g.timeout)
+	defer timer.Stop()
+	select {
+	case fields := <-done:
+		return fields
+	case <-timer.C:
+		return nil
+	}
+}
+
+// lookup returns the location fields of the address, or nil when it has no location.
+func (g *geoIPEnricher) lookup(addr string) []Field {
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil
+	}
+
+	loc, err := g.db.Lookup(ip)
+	if err != nil || loc.Country == "" {
+		return nil
+	}
+	fields := []Field{zap.String(FieldKeyGeoCountry, loc.Country)}
+	if loc.Region != "" {
+		fields = append(fields, zap.String(FieldKeyGeoRegion, loc.Region))
+	}
+	return fields
+}
@@ -0,0 +1,168 @@
+// Package gormlogger provides a GORM logger backed by go-logger. Queries slower than
+// the threshold are written as slow_query events by the slow query log of go-logger,
+// with their bind parameters redacted by its rules, failed queries are logged at
+// ErrorLevel and, at the Info log mode of GORM, every query is logged at DebugLevel.
+package gormlogger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// FieldKeySQLCaller is the field holding the call site of the query in the application.
+const FieldKeySQLCaller = "sql_caller"
+
+// Messages of the entries written for queries.
+const (
+	queryMessage       = "query"
+	queryFailedMessage = "query failed"
+)
+
+type (
+	// Config configures a Logger.
+	Config struct {
+		// SlowQuery configures the slow query log. GORM interpolates the bind
+		// parameters into the query before logging it, so the redaction rules apply to
+		// the values interpolated, and Explain receives the query with the values in
+		// place and no arguments. Explain is disabled when redaction rules are set,
+		// since the query would hold the redacted values instead of the actual ones.
+		SlowQuery logger.SlowQueryConfig
+		// LogLevel is the initial log mode of GORM (default: gorm logger.Warn, logging
+		// failed and slow queries).
+		LogLevel glogger.LogLevel
+		// IgnoreRecordNotFoundError does not log gorm.ErrRecordNotFound as a failure.
+		IgnoreRecordNotFoundError bool
+	}
+
+	// Logger implements the logger.Interface of GORM and its ParamsFilter.
+	Logger struct {
+		log                       logger.Logger
+		slow                      *logger.SlowQueryLogger
+		level                     glogger.LogLevel
+		ignoreRecordNotFoundError bool
+	}
+)
+
+// New creates a GORM logger writing to log.
+//
+// Parameters:
+//   - log: The logger receiving the entries
+//   - config: The slow query log and the log mode
+//
+// Returns:
+//   - *Logger: The GORM logger
+//
+// Example:
+//
+//	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+//	    Logger: gormlogger.New(log, gormlogger.Config{
+//	        SlowQuery: logger.SlowQueryConfig{
+//	            Threshold: 500 * time.Millisecond,
+//	            Redact:    []logger.ParamRedactor{logger.RedactAllParams()},
+//	        },
+//	        IgnoreRecordNotFoundError: true,
+//	    }),
+//	})
+//	// {"level":"WARN","message":"slow query","event":"slow_query","sql_query":"SELECT * FROM \"users\" WHERE email = '[REDACTED]'","duration":"812ms","sql_rows_affected":1,"sql_caller":"/app/users/repo.go:42"}
+func New(log logger.Logger, config Config) *Logger {
+	if config.LogLevel == 0 {
+		config.LogLevel = glogger.Warn
+	}
+	if len(config.SlowQuery.Redact) > 0 {
+		config.SlowQuery.Explain = nil
+	}
+	return &Logger{
+		log:                       log,
+		slow:                      logger.NewSlowQueryLogger(log, config.SlowQuery),
+		level:                     config.LogLevel,
+		ignoreRecordNotFoundError: config.IgnoreRecordNotFoundError,
+	}
+}
+
+// LogMode returns a logger with the log mode of GORM, e.g. set by db.Debug().
+func (l *Logger) LogMode(level glogger.LogLevel) glogger.Interface {
+	child := *l
+	child.level = level
+	return &child
+}
+
+// Info logs a message of GORM at InfoLevel.
+func (l *Logger) Info(ctx context.Context, msg string, data ...any) {
+	if l.level >= glogger.Info {
+		l.log.Info(ctx, fmt.Sprintf(msg, data...), zap.String(logger.FieldKeyComponent, "gorm"))
+	}
+}
+
+// Warn logs a message of GORM at WarnLevel.
+func (l *Logger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.level >= glogger.Warn {
+		l.log.Warn(ctx, fmt.Sprintf(msg, data...), zap.String(logger.FieldKeyComponent, "gorm"))
+	}
+}
+
+// Error logs a message of GORM at ErrorLevel.
+func (l *Logger) Error(ctx context.Context, msg string, data ...any) {
+	if l.level >= glogger.Error {
+		l.log.Error(ctx, fmt.Sprintf(msg, data...), zap.String(logger.FieldKeyComponent, "gorm"))
+	}
+}
+
+// Trace logs a query: failed queries at ErrorLevel, slow queries as slow_query events
+// and, at the Info log mode, other queries at DebugLevel.
+func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= glogger.Silent {
+		return
+	}
+
+	failed := err != nil && l.level >= glogger.Error &&
+		!(l.ignoreRecordNotFoundError && errors.Is(err, gorm.ErrRecordNotFound))
+	slow := l.level >= glogger.Warn && time.Since(begin) >= l.slow.Threshold()
+	if !failed && !slow && l.level < glogger.Info {
+		return
+	}
+
+	query, rows := fc()
+	caller := zap.String(FieldKeySQLCaller, utils.FileWithLineNum())
+	if failed {
+		l.log.Error(ctx, queryFailedMessage,
+			zap.String(logger.FieldKeySQLQuery, query),
+			zap.Duration(logger.FieldKeyDuration, time.Since(begin)),
+			zap.Int64(logger.FieldKeySQLRowsAffected, rows),
+			caller,
+			zap.Error(err),
+		)
+	}
+	if slow && l.slow.Observe(ctx, query, nil, begin, rows, err, caller) {
+		return
+	}
+	if !failed && l.level >= glogger.Info {
+		l.log.Debug(ctx, queryMessage,
+			zap.String(logger.FieldKeySQLQuery, query),
+			zap.Duration(logger.FieldKeyDuration, time.Since(begin)),
+			zap.Int64(logger.FieldKeySQLRowsAffected, rows),
+			caller,
+		)
+	}
+}
+
+// ParamsFilter redacts the bind parameters of a query before GORM interpolates them
+// into the query it logs.
+func (l *Logger) ParamsFilter(_ context.Context, query string, params ...any) (string, []any) {
+	sqlParams := make([]logger.SQLParam, len(params))
+	for i, param := range params {
+		sqlParams[i] = logger.SQLParam{Ordinal: i + 1, Value: param}
+		if named, ok := param.(sql.NamedArg); ok {
+			sqlParams[i].Name, sqlParams[i].Value = named.Name, named.Value
+		}
+	}
+	return query, l.slow.RedactParams(query, sqlParams)
+}
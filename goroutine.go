@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// Field keys identifying the goroutine that wrote an entry.
+const (
+	FieldKeyGoroutineID = "goroutine_id"
+	FieldKeyWorker      = "worker"
+)
+
+// workerLabelKey is the context key of the worker label.
+type workerLabelKey struct{}
+
+// LabelGoroutine returns a copy of the context labeled with a worker name.
+// Entries logged with the context carry the label under the worker field, so the
+// interleaved output of worker pools can be untangled. Labels are stable across
+// restarts and readable, unlike goroutine IDs; prefer them over WithGoroutineID.
+// DetachContext does not keep the label, as detached work runs on another goroutine.
+//
+// Parameters:
+//   - ctx: The context of the worker goroutine
+//   - label: The name of the worker, e.g. consumer-3
+//
+// Returns:
+//   - context.Context: The labeled context
+//
+// Example:
+//
+//	for i := 0; i < workers; i++ {
+//	    go func(ctx context.Context) {
+//	        for msg := range queue {
+//	            log.Info(ctx, "message consumed") // {"worker":"consumer-3",...}
+//	        }
+//	    }(LabelGoroutine(ctx, fmt.Sprintf("consumer-%d", i)))
+//	}
+func LabelGoroutine(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, workerLabelKey{}, label)
+}
+
+// appendGoroutineFields appends the worker label of the context and, when enabled,
+// the ID of the calling goroutine to fields.
+func appendGoroutineFields(fields []Field, ctx context.Context, withID bool) []Field {
+	if ctx != nil {
+		if label, ok := ctx.Value(workerLabelKey{}).(string); ok && label != "" {
+			fields = append(fields, zap.String(FieldKeyWorker, label))
+		}
+	}
+	if withID {
+		if id, ok := goroutineID(); ok {
+			fields = append(fields, zap.Uint64(FieldKeyGoroutineID, id))
+		}
+	}
+	return fields
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the header of
+// its stack trace ("goroutine 42 [running]:"). The runtime does not expose the ID
+// otherwise; reading it costs about a microsecond per entry.
+func goroutineID() (uint64, bool) {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	if end := bytes.IndexByte(header, ' '); end > 0 {
+		header = header[:end]
+	}
+	id, err := strconv.ParseUint(string(header), 10, 64)
+	return id, err == nil
+}
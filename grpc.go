@@ -0,0 +1,210 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Field keys written by the gRPC and Connect interceptors.
+const (
+	FieldKeyRPCMethod   = "rpc_method"
+	FieldKeyRPCCode     = "rpc_code"
+	FieldKeyRPCProtocol = "rpc_protocol"
+)
+
+// grpcMetadataPrefix is the prefix grpc-gateway uses for headers forwarded as metadata.
+const grpcMetadataPrefix = "Grpc-Metadata-"
+
+// serverStream wraps a grpc.ServerStream to replace its context.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor writing one access log entry per unary call.
+// The request ID and trace context are read from the x-request-id and traceparent
+// metadata, so calls proxied by grpc-gateway keep the IDs of the original HTTP request.
+// The request ID is echoed in the x-request-id response header.
+//
+// Parameters:
+//   - log: The logger writing the access log entries
+//
+// Returns:
+//   - grpc.UnaryServerInterceptor: The interceptor
+//
+// Example:
+//
+//	server := grpc.NewServer(grpc.ChainUnaryInterceptor(UnaryServerInterceptor(log)))
+func UnaryServerInterceptor(log Logger) grpc.UnaryServerInterceptor {
+	newID := idGenerator(log)
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, requestID := grpcContext(ctx, newID)
+		_ = grpc.SetHeader(ctx, requestIDMetadata(requestID))
+
+		resp, err := handler(ctx, req)
+
+		logRPC(ctx, log, "grpc", info.FullMethod, status.Code(err), start, grpcPeer(ctx), err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor writing one access log entry per stream.
+// Correlation IDs are read and the request ID echoed like in UnaryServerInterceptor.
+//
+// Parameters:
+//   - log: The logger writing the access log entries
+//
+// Returns:
+//   - grpc.StreamServerInterceptor: The interceptor
+//
+// Example:
+//
+//	server := grpc.NewServer(grpc.ChainStreamInterceptor(StreamServerInterceptor(log)))
+func StreamServerInterceptor(log Logger) grpc.StreamServerInterceptor {
+	newID := idGenerator(log)
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, requestID := grpcContext(ss.Context(), newID)
+		_ = ss.SetHeader(requestIDMetadata(requestID))
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+
+		logRPC(ctx, log, "grpc", info.FullMethod, status.Code(err), start, grpcPeer(ctx), err)
+		return err
+	}
+}
+
+// UnaryClientInterceptor returns a gRPC client interceptor propagating the correlation IDs
+// of the call context as outgoing metadata, like InjectMetadata, so the server interceptors
+// of the called service log the same IDs.
+//
+// Returns:
+//   - grpc.UnaryClientInterceptor: The interceptor
+//
+// Example:
+//
+//	conn, err := grpc.NewClient(target, grpc.WithChainUnaryInterceptor(UnaryClientInterceptor()))
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(InjectMetadata(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor returns a gRPC client interceptor propagating the correlation IDs
+// of the stream context like UnaryClientInterceptor.
+//
+// Returns:
+//   - grpc.StreamClientInterceptor: The interceptor
+//
+// Example:
+//
+//	conn, err := grpc.NewClient(target, grpc.WithChainStreamInterceptor(StreamClientInterceptor()))
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(InjectMetadata(ctx), desc, cc, method, opts...)
+	}
+}
+
+// InjectMetadata returns a context whose outgoing gRPC metadata carries the correlation IDs
+// of ctx as the x-request-id, traceparent and x-log-dimensions keys. Keys already present
+// in the outgoing metadata are kept.
+//
+// Parameters:
+//   - ctx: The context holding the correlation IDs
+//
+// Returns:
+//   - context.Context: The context to make the call with
+//
+// Example:
+//
+//	resp, err := client.GetItem(logger.InjectMetadata(ctx), req)
+func InjectMetadata(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	var kv []string
+	headersFromContext(ctx, func(key, value string) {
+		key = strings.ToLower(key)
+		if len(md.Get(key)) == 0 {
+			kv = append(kv, key, value)
+		}
+	})
+	if len(kv) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+// GatewayHeaderMatcher forwards the correlation headers of HTTP requests to gRPC metadata.
+// Its signature matches grpc-gateway's runtime.HeaderMatcherFunc. It forwards
+// X-Request-ID, traceparent and X-Log-Dimensions unchanged and strips the
+// Grpc-Metadata- prefix from other headers, like the default grpc-gateway matcher.
+//
+// Example:
+//
+//	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(GatewayHeaderMatcher))
+//	handler := HTTPMiddleware(log)(mux)
+func GatewayHeaderMatcher(key string) (string, bool) {
+	switch {
+	case strings.EqualFold(key, HeaderRequestID), strings.EqualFold(key, HeaderTraceparent),
+		strings.EqualFold(key, HeaderDimensions):
+		return strings.ToLower(key), true
+	case len(key) > len(grpcMetadataPrefix) && strings.EqualFold(key[:len(grpcMetadataPrefix)], grpcMetadataPrefix):
+		return key[len(grpcMetadataPrefix):], true
+	default:
+		return "", false
+	}
+}
+
+// Context returns the context carrying the correlation IDs.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// grpcContext stores the correlation IDs of the incoming metadata in the context,
+// generating the request ID with newID when there is none. It returns the request ID.
+func grpcContext(ctx context.Context, newID func() string) (context.Context, string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	return contextFromHeaders(ctx, func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}, newID)
+}
+
+// requestIDMetadata returns the response header echoing the request ID.
+func requestIDMetadata(requestID string) metadata.MD {
+	return metadata.Pairs(strings.ToLower(HeaderRequestID), requestID)
+}
+
+// grpcPeer returns the address of the calling peer.
+func grpcPeer(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+// logRPC writes the access log entry of an RPC at the level matching its status code.
+func logRPC(ctx context.Context, log Logger, protocol, method string, code codes.Code, start time.Time, remoteAddr string, err error) {
+	fields := []Field{
+		zap.String(FieldKeyRPCProtocol, protocol),
+		zap.String(FieldKeyRPCMethod, method),
+		zap.String(FieldKeyRPCCode, code.String()),
+		zap.Duration(FieldKeyDuration, time.Since(start)),
+		zap.String(FieldKeyRemoteAddr, remoteAddr),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+
+	logAt(ctx, log, LevelFromGRPCCode(code), "rpc request", fields...)
+}
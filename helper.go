@@ -3,6 +3,10 @@ package logger
 import (
 	"context"
 	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -50,6 +54,91 @@ func parseLevel(level Level) (zap.AtomicLevel, error) {
 	return zapLevel, nil
 }
 
+// newEncoder creates the zapcore.Encoder matching the zap configuration encoding.
+// Unknown encodings fall back to the console encoder, mirroring Encoding.String.
+//
+// Parameters:
+//   - zapConfig: The zap configuration holding the encoding and encoder settings
+//   - cfg: The logger configuration holding the console settings
+//
+// Returns:
+//   - zapcore.Encoder: The encoder used to serialize log entries
+func newEncoder(zapConfig zap.Config, cfg *config) zapcore.Encoder {
+	var encoder zapcore.Encoder
+	switch {
+	case zapConfig.Encoding == EncodingJson.String():
+		encoder = zapcore.NewJSONEncoder(zapConfig.EncoderConfig)
+	case cfg.consoleTemplate != nil:
+		encoder = newTemplateEncoder(zapConfig.EncoderConfig, cfg.consoleTemplate, cfg.ConsoleHumanize, cfg.callerFormatter)
+	default:
+		encoder = zapcore.NewConsoleEncoder(zapConfig.EncoderConfig)
+		if cfg.ConsoleHumanize {
+			encoder = &humanEncoder{Encoder: encoder}
+		}
+		if cfg.colored() {
+			encoder = cfg.ColorTheme.wrap(encoder)
+		}
+	}
+	encoder = newNonFiniteEncoder(encoder, cfg.NonFiniteFloats)
+	encoder = newMultilineEncoder(encoder, cfg.MultilineMode, zapConfig.Encoding, zapConfig.EncoderConfig.StacktraceKey)
+	encoder = newStackEncoder(encoder, cfg.StacktraceDepth, cfg.StacktraceFilter)
+	if cfg.ContainerOutput {
+		encoder = newContainerEncoder(encoder, zapConfig.EncoderConfig, cfg.ContainerMaxLine)
+	}
+	return encoder
+}
+
+// buildOptions translates the zap configuration into zap.Options.
+// It mirrors zap.Config.Build so that loggers built from custom sinks behave
+// exactly like loggers built by zap itself, except that sampling is done by a
+// sampler whose settings can be changed at runtime.
+//
+// Parameters:
+//   - zapConfig: The zap configuration to translate
+//   - errSink: The destination for zap's internal errors
+//   - sampler: The sampler applied to every entry
+//
+// Returns:
+//   - []zap.Option: The options to pass to zap.New
+func buildOptions(zapConfig zap.Config, errSink zapcore.WriteSyncer, sampler *sampler) []zap.Option {
+	opts := []zap.Option{zap.ErrorOutput(errSink)}
+
+	if zapConfig.Development {
+		opts = append(opts, zap.Development())
+	}
+
+	if !zapConfig.DisableCaller {
+		opts = append(opts, zap.AddCaller(), zap.AddCallerSkip(callerSkip))
+	}
+
+	stackLevel := zap.ErrorLevel
+	if zapConfig.Development {
+		stackLevel = zap.WarnLevel
+	}
+	if !zapConfig.DisableStacktrace {
+		opts = append(opts, zap.AddStacktrace(stackLevel))
+	}
+
+	opts = append(opts, zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &samplingCore{Core: core, sampler: sampler}
+	}))
+
+	return opts
+}
+
+// unwrapLogger returns the zap based implementation behind a Logger created by this package.
+// It reports false for other Logger implementations such as mocks.
+func unwrapLogger(l Logger) (*zapLogger, bool) {
+	switch v := l.(type) {
+	case *logger:
+		return unwrapLogger(v.logger)
+	case *zapLogger:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
 // getStringFromContext safely extracts a string value from context using the provided key.
 // This function performs safe type assertion to prevent runtime panics when extracting context values.
 //
@@ -110,9 +199,10 @@ func GetLoggingFields(ctx context.Context) []LoggingField {
 	}
 
 	// Pre-allocate slice with estimated capacity to reduce memory allocations
-	fields := make([]LoggingField, 0, len(contextKeys))
+	keys := contextKeys()
+	fields := make([]LoggingField, 0, len(keys))
 
-	for _, key := range contextKeys {
+	for _, key := range keys {
 		if key == "" {
 			continue // Skip empty keys to avoid potential issues
 		}
@@ -128,6 +218,175 @@ func GetLoggingFields(ctx context.Context) []LoggingField {
 	return fields
 }
 
+// GetDurationFields extracts the context keys carrying a time.Duration.
+//
+// Parameters:
+//   - ctx: The context to extract the fields from
+//
+// Returns:
+//   - []DurationField: The keys and durations found, empty when there are none
+//
+// Example:
+//
+//	AppendContextKeys("upstream_latency")
+//	ctx = context.WithValue(ctx, ContextKey("upstream_latency"), elapsed)
+//	fields := GetDurationFields(ctx) // [{upstream_latency 212ms}]
+func GetDurationFields(ctx context.Context) []DurationField {
+	fields := make([]DurationField, 0)
+	for _, key := range contextKeys() {
+		if value, ok := getTypedFromContext[time.Duration](ctx, key); ok {
+			fields = append(fields, DurationField{Key: key, Value: value})
+		}
+	}
+	return fields
+}
+
+// GetTimeFields extracts the context keys carrying a time.Time.
+//
+// Parameters:
+//   - ctx: The context to extract the fields from
+//
+// Returns:
+//   - []TimeField: The keys and times found, empty when there are none
+func GetTimeFields(ctx context.Context) []TimeField {
+	fields := make([]TimeField, 0)
+	for _, key := range contextKeys() {
+		if value, ok := getTypedFromContext[time.Time](ctx, key); ok {
+			fields = append(fields, TimeField{Key: key, Value: value})
+		}
+	}
+	return fields
+}
+
+// GetIntFields extracts the context keys carrying an int, int32 or int64.
+//
+// Parameters:
+//   - ctx: The context to extract the fields from
+//
+// Returns:
+//   - []IntField: The keys and integers found, empty when there are none
+func GetIntFields(ctx context.Context) []IntField {
+	fields := make([]IntField, 0)
+	for _, key := range contextKeys() {
+		if value, ok := getIntFromContext(ctx, key); ok {
+			fields = append(fields, IntField{Key: key, Value: value})
+		}
+	}
+	return fields
+}
+
+// GetErrorFields extracts the context keys carrying an error.
+//
+// Parameters:
+//   - ctx: The context to extract the fields from
+//
+// Returns:
+//   - []ErrorField: The keys and errors found, empty when there are none
+func GetErrorFields(ctx context.Context) []ErrorField {
+	fields := make([]ErrorField, 0)
+	for _, key := range contextKeys() {
+		if value, ok := getTypedFromContext[error](ctx, key); ok {
+			fields = append(fields, ErrorField{Key: key, Value: value})
+		}
+	}
+	return fields
+}
+
+// appendContextKeyFields appends the fields of every context key carrying a string, duration,
+// time, integer or error, in the order of the context keys. Durations are written as
+// milliseconds, so they can be queried numerically. It reads each key once, which is
+// what the logger needs on every entry.
+func appendContextKeyFields(fields []Field, ctx context.Context) []Field {
+	if ctx == nil {
+		return fields
+	}
+
+	for _, key := range contextKeys() {
+		if key == "" {
+			continue
+		}
+		switch value := ctx.Value(key).(type) {
+		case string:
+			fields = append(fields, zap.String(key.String(), value))
+		case time.Duration:
+			fields = append(fields, zap.Float64(key.String(), float64(value)/float64(time.Millisecond)))
+		case time.Time:
+			fields = append(fields, zap.Time(key.String(), value))
+		case int:
+			fields = append(fields, zap.Int(key.String(), value))
+		case int32:
+			fields = append(fields, zap.Int32(key.String(), value))
+		case int64:
+			fields = append(fields, zap.Int64(key.String(), value))
+		case error:
+			fields = append(fields, zap.NamedError(key.String(), value))
+		}
+	}
+	return fields
+}
+
+// getTypedFromContext returns the value of the context key when it has type T.
+func getTypedFromContext[T any](ctx context.Context, key ContextKey) (T, bool) {
+	var zero T
+	if ctx == nil || key == "" {
+		return zero, false
+	}
+	value, ok := ctx.Value(key).(T)
+	if !ok {
+		return zero, false
+	}
+	return value, true
+}
+
+// getIntFromContext returns the value of the context key when it is an int, int32 or int64.
+func getIntFromContext(ctx context.Context, key ContextKey) (int64, bool) {
+	if ctx == nil || key == "" {
+		return 0, false
+	}
+	switch value := ctx.Value(key).(type) {
+	case int:
+		return int64(value), true
+	case int32:
+		return int64(value), true
+	case int64:
+		return value, true
+	}
+	return 0, false
+}
+
+var (
+	// appendedContextKeysMu serializes AppendContextKeys.
+	appendedContextKeysMu sync.Mutex
+	// appendedContextKeys holds the predefined context keys and the keys added by
+	// AppendContextKeys, nil until keys are added. The slice is replaced, never modified,
+	// so it is read on every entry without locking.
+	appendedContextKeys atomic.Pointer[[]ContextKey]
+)
+
+// contextKeys returns the context keys extracted from contexts. The returned slice must
+// not be modified.
+func contextKeys() []ContextKey {
+	if keys := appendedContextKeys.Load(); keys != nil {
+		return *keys
+	}
+	return defaultContextKeys
+}
+
+// AppendContextKeys adds context keys to the keys extracted from the context of every
+// entry and by GetLoggingFields and its siblings. It is safe to call while other
+// goroutines log; entries logged concurrently may or may not carry the new keys.
+//
+// Parameters:
+//   - keys: The context keys to add
+//
+// Example:
+//
+//	AppendContextKeys("tenant_id", "upstream_latency")
+//	ctx = context.WithValue(ctx, ContextKey("tenant_id"), "acme")
+//	log.Info(ctx, "order placed") // {"tenant_id":"acme",...}
 func AppendContextKeys(keys ...ContextKey) {
-	contextKeys = append(contextKeys, keys...)
+	appendedContextKeysMu.Lock()
+	defer appendedContextKeysMu.Unlock()
+	updated := slices.Concat(contextKeys(), keys)
+	appendedContextKeys.Store(&updated)
 }
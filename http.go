@@ -0,0 +1,501 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Field keys written by the HTTP middleware.
+const (
+	FieldKeyHTTPMethod            = "http_method"
+	FieldKeyHTTPPath              = "http_path"
+	FieldKeyHTTPRoute             = "http_route"
+	FieldKeyHTTPStatus            = "http_status"
+	FieldKeyDuration              = "duration"
+	FieldKeyResponseBytes         = "response_bytes"
+	FieldKeyRemoteAddr            = "remote_addr"
+	FieldKeyUserAgent             = "user_agent"
+	FieldKeyRequestBody           = "request_body"
+	FieldKeyRequestBodyTruncated  = "request_body_truncated"
+	FieldKeyResponseBody          = "response_body"
+	FieldKeyResponseBodyTruncated = "response_body_truncated"
+)
+
+// httpRequestMessage is the message of the access log entries written by HTTPMiddleware.
+const httpRequestMessage = "http request"
+
+// HeaderRequestID is the header carrying the request ID between services.
+const HeaderRequestID = "X-Request-ID"
+
+type (
+	// HTTPOption represents a configuration option of the HTTP middleware.
+	HTTPOption func(*httpConfig)
+
+	// httpConfig holds the configuration of the HTTP middleware.
+	httpConfig struct {
+		// BodyCaptureLimit is the maximum number of body bytes logged. Zero disables body capture.
+		BodyCaptureLimit int
+		// BodyCaptureRoutes restricts body capture to paths with one of these prefixes.
+		BodyCaptureRoutes []string
+		// BodyRedactor masks sensitive data in captured bodies.
+		BodyRedactor BodyRedactor
+		// BodyRedactedKeys are the keys masked by BodyRedactor when it is a key
+		// redactor set by default or with WithBodyRedactedKeys; nil otherwise.
+		BodyRedactedKeys []string
+		// AccessLog additionally writes classic access log lines when set.
+		AccessLog *accessLogWriter
+		// PprofLabels runs handlers with pprof labels matching the logged request fields.
+		PprofLabels bool
+		// Routes override the logging of matching requests.
+		Routes []routeRule
+		// ProblemCapture logs problem details responses as the problem field.
+		ProblemCapture bool
+		// TwoPhase logs a begin entry when a request starts, correlated by txn_id.
+		TwoPhase bool
+		// diag reports redactor panics; nil for loggers not created by NewLogger.
+		diag *diagnostics
+	}
+
+	// responseRecorder wraps an http.ResponseWriter to record the status, size and body of the response.
+	// It implements http.Flusher, http.Hijacker and io.ReaderFrom by passing through to the
+	// wrapped writer, so streaming, WebSocket upgrades and sendfile keep working.
+	responseRecorder struct {
+		http.ResponseWriter
+		status      int
+		written     int64
+		body        *limitedBuffer
+		wroteHeader bool
+		// captureProblem captures problem details responses into problem.
+		captureProblem bool
+		problem        *limitedBuffer
+	}
+
+	// captureBody wraps a request body and keeps a copy of the bytes the handler reads.
+	captureBody struct {
+		io.ReadCloser
+		body *limitedBuffer
+	}
+
+	// writerOnly hides the io.ReaderFrom of a writer so io.Copy does not call it again.
+	writerOnly struct {
+		io.Writer
+	}
+
+	// limitedBuffer keeps the first limit bytes written to it and remembers whether more were written.
+	limitedBuffer struct {
+		buf       bytes.Buffer
+		limit     int
+		truncated bool
+	}
+)
+
+// HTTPMiddleware returns net/http middleware writing one access log entry per request.
+// The request ID is taken from the X-Request-ID header or generated, stored in the
+// request context under ContextKeyRequestID and echoed in the response header.
+// A generated ID is also set on the request header so proxies such as grpc-gateway
+// forward it. The trace and span IDs of a traceparent header are stored in the context.
+// Entries are logged at ErrorLevel for 5xx responses, WarnLevel for 4xx responses
+// and InfoLevel otherwise.
+//
+// Parameters:
+//   - log: The logger writing the access log entries
+//   - opts: Variable number of HTTPOption functions to configure the middleware
+//
+// Returns:
+//   - func(http.Handler) http.Handler: The middleware
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	handler := HTTPMiddleware(log, WithBodyCapture(4096))(mux)
+//	http.ListenAndServe(":8080", handler)
+func HTTPMiddleware(log Logger, opts ...HTTPOption) func(http.Handler) http.Handler {
+	cfg := &httpConfig{
+		BodyRedactor:     DefaultBodyRedactor,
+		BodyRedactedKeys: defaultRedactedKeys,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if z, ok := unwrapLogger(log); ok {
+		cfg.diag = z.state.diag
+		if cfg.BodyCaptureLimit > 0 {
+			z.state.bodyRedactions.add(cfg.bodyRedaction())
+		}
+	}
+	newID := idGenerator(log)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			ctx, requestID := contextFromHeaders(r.Context(), r.Header.Get, newID)
+			route, _ := cfg.route(r)
+			if route.Debug {
+				ctx = contextWithForcedDebug(ctx)
+			}
+			w.Header().Set(HeaderRequestID, requestID)
+			r = r.WithContext(ctx)
+			if r.Header.Get(HeaderRequestID) == "" {
+				r.Header.Set(HeaderRequestID, requestID)
+			}
+
+			// Skipped and sampled routes cannot know at the start whether they end with
+			// an entry, so only routes logged unconditionally get a begin entry.
+			var txn *Txn
+			if cfg.TwoPhase && route.alwaysLogged() {
+				txn = BeginRequest(ctx, log, zap.String(FieldKeyHTTPMethod, r.Method), zap.String(FieldKeyHTTPPath, r.URL.Path))
+			}
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK, captureProblem: cfg.ProblemCapture}
+
+			var reqBody *limitedBuffer
+			if cfg.captureBody(r.URL.Path) {
+				reqBody = &limitedBuffer{limit: cfg.BodyCaptureLimit}
+				rec.body = &limitedBuffer{limit: cfg.BodyCaptureLimit}
+				if r.Body != nil && r.Body != http.NoBody {
+					r.Body = &captureBody{ReadCloser: r.Body, body: reqBody}
+				}
+			}
+
+			if cfg.PprofLabels {
+				DoWithPprofLabels(ctx, r.Method+" "+r.URL.Path, func(ctx context.Context) {
+					labeled := r.WithContext(ctx)
+					next.ServeHTTP(rec, labeled)
+					r.Pattern = labeled.Pattern
+				})
+			} else {
+				next.ServeHTTP(rec, r)
+			}
+
+			if !route.logged(rec.status) {
+				return
+			}
+			if cfg.AccessLog != nil {
+				cfg.AccessLog.write(r, start, rec.status, rec.written)
+			}
+
+			fields := []Field{
+				zap.String(FieldKeyHTTPMethod, r.Method),
+				zap.String(FieldKeyHTTPPath, r.URL.Path),
+				zap.Int(FieldKeyHTTPStatus, rec.status),
+				zap.Duration(FieldKeyDuration, time.Since(start)),
+				zap.Int64(FieldKeyResponseBytes, rec.written),
+				zap.String(FieldKeyRemoteAddr, r.RemoteAddr),
+				zap.String(FieldKeyUserAgent, r.UserAgent()),
+			}
+			if r.Pattern != "" {
+				fields = append(fields, zap.String(FieldKeyHTTPRoute, r.Pattern))
+			}
+
+			if rec.problem != nil && !rec.problem.truncated {
+				if problem, ok := parseProblem(rec.problem.buf.Bytes()); ok {
+					fields = append(fields, ProblemField(problem))
+				}
+			}
+
+			if reqBody != nil {
+				fields = append(fields,
+					zap.String(FieldKeyRequestBody, cfg.redact(r.Header.Get("Content-Type"), reqBody)),
+					zap.Bool(FieldKeyRequestBodyTruncated, reqBody.truncated),
+					zap.String(FieldKeyResponseBody, cfg.redact(rec.Header().Get("Content-Type"), rec.body)),
+					zap.Bool(FieldKeyResponseBodyTruncated, rec.body.truncated),
+				)
+			}
+
+			if txn != nil {
+				txn.finish(zapLevel(LevelFromHTTPStatus(rec.status)), httpRequestMessage,
+					append([]Field{zap.String(FieldKeyTxnStatus, strconv.Itoa(rec.status))}, fields...))
+				return
+			}
+
+			switch LevelFromHTTPStatus(rec.status) {
+			case LevelError:
+				log.Error(ctx, httpRequestMessage, fields...)
+			case LevelWarning:
+				log.Warn(ctx, httpRequestMessage, fields...)
+			default:
+				log.Info(ctx, httpRequestMessage, fields...)
+			}
+		})
+	}
+}
+
+// WithBodyCapture enables logging of request and response bodies.
+// At most limit bytes of each body are logged; longer bodies are cut and marked
+// as truncated. Captured bodies pass through the body redactor before logging.
+//
+// Parameters:
+//   - limit: The maximum number of bytes logged per body
+//
+// Example:
+//
+//	middleware := HTTPMiddleware(log, WithBodyCapture(4096))
+func WithBodyCapture(limit int) HTTPOption {
+	return func(c *httpConfig) {
+		c.BodyCaptureLimit = limit
+	}
+}
+
+// WithBodyCaptureRoutes restricts body capture to request paths starting with one of the prefixes.
+// Without this option bodies are captured for every route once WithBodyCapture is set.
+//
+// Parameters:
+//   - prefixes: The path prefixes for which bodies are captured
+//
+// Example:
+//
+//	middleware := HTTPMiddleware(log, WithBodyCapture(4096), WithBodyCaptureRoutes("/api/partners/"))
+func WithBodyCaptureRoutes(prefixes ...string) HTTPOption {
+	return func(c *httpConfig) {
+		c.BodyCaptureRoutes = append(c.BodyCaptureRoutes, prefixes...)
+	}
+}
+
+// WithBodyRedactor replaces the redactor applied to captured bodies. LogConfig reports
+// it as a custom redactor; use WithBodyRedactedKeys to mask keys so they are reported.
+//
+// Parameters:
+//   - redactor: The function masking sensitive data (default: DefaultBodyRedactor)
+//
+// Example:
+//
+//	middleware := HTTPMiddleware(log, WithBodyCapture(4096), WithBodyRedactor(NewKeyRedactor("iban", "password")))
+func WithBodyRedactor(redactor BodyRedactor) HTTPOption {
+	return func(c *httpConfig) {
+		c.BodyRedactor = redactor
+		c.BodyRedactedKeys = nil
+	}
+}
+
+// WithBodyRedactedKeys masks the values of the keys in captured bodies with a
+// redactor created by NewKeyRedactor, replacing the default keys. LogConfig reports
+// the keys as body_redacted_keys.
+//
+// Parameters:
+//   - keys: The keys whose values are replaced with [REDACTED]
+//
+// Example:
+//
+//	middleware := HTTPMiddleware(log, WithBodyCapture(4096), WithBodyRedactedKeys("password", "iban"))
+func WithBodyRedactedKeys(keys ...string) HTTPOption {
+	return func(c *httpConfig) {
+		c.BodyRedactor = NewKeyRedactor(keys...)
+		c.BodyRedactedKeys = append([]string{}, keys...)
+	}
+}
+
+// WithTwoPhaseLogging additionally writes a "request started" entry with the method and
+// path when a request starts. It shares a txn_id with the access log entry, which gets
+// txn_phase and txn_status, so requests that hang or crash the process can be found as
+// begin entries without an end. Routes that are skipped or sampled with WithRoute get
+// no begin entry. See BeginRequest.
+//
+// Example:
+//
+//	middleware := HTTPMiddleware(log, WithTwoPhaseLogging())
+func WithTwoPhaseLogging() HTTPOption {
+	return func(c *httpConfig) {
+		c.TwoPhase = true
+	}
+}
+
+// WithPprofLabels runs handlers with pprof labels matching the logged request fields:
+// request_id and endpoint (method and path). CPU profiles taken during an incident
+// can then be filtered by a request ID found in the logs. See DoWithPprofLabels.
+//
+// Example:
+//
+//	middleware := HTTPMiddleware(log, WithPprofLabels())
+func WithPprofLabels() HTTPOption {
+	return func(c *httpConfig) {
+		c.PprofLabels = true
+	}
+}
+
+// bodyRedaction describes the body redactor for LogConfig.
+func (c *httpConfig) bodyRedaction() *bodyRedaction {
+	switch {
+	case c.BodyRedactor == nil:
+		return &bodyRedaction{kind: bodyRedactorNone}
+	case c.BodyRedactedKeys == nil:
+		return &bodyRedaction{kind: bodyRedactorCustom}
+	default:
+		return &bodyRedaction{kind: bodyRedactorKeys, keys: c.BodyRedactedKeys}
+	}
+}
+
+// captureBody reports whether bodies are captured for the path.
+func (c *httpConfig) captureBody(path string) bool {
+	if c.BodyCaptureLimit <= 0 {
+		return false
+	}
+	if len(c.BodyCaptureRoutes) == 0 {
+		return true
+	}
+	for _, prefix := range c.BodyCaptureRoutes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact renders a captured body for logging.
+// A panicking redactor is reported to the diagnostics channel and the body is omitted.
+func (c *httpConfig) redact(contentType string, body *limitedBuffer) (out string) {
+	if body.buf.Len() == 0 {
+		return ""
+	}
+	if !isTextContent(contentType) {
+		return "[binary body omitted]"
+	}
+	if c.BodyRedactor == nil {
+		return body.buf.String()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if c.diag != nil {
+				c.diag.report(DiagnosticRedactorPanic, panicError(r), PanicValue(r))
+			}
+			out = "[body omitted: redactor failed]"
+		}
+	}()
+	return string(c.BodyRedactor(contentType, body.buf.Bytes()))
+}
+
+// WriteHeader records the status code.
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	if !r.wroteHeader && status >= http.StatusOK {
+		r.headerWritten()
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the response size and captures the body when enabled.
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if !r.wroteHeader {
+		r.headerWritten()
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.written += int64(n)
+	if r.body != nil {
+		_, _ = r.body.Write(p[:n])
+	}
+	if r.problem != nil {
+		_, _ = r.problem.Write(p[:n])
+	}
+	return n, err
+}
+
+// headerWritten starts capturing problem details once the response headers are final.
+func (r *responseRecorder) headerWritten() {
+	r.wroteHeader = true
+	if r.captureProblem && isProblemContent(r.Header().Get("Content-Type")) {
+		r.problem = &limitedBuffer{limit: maxProblemSize}
+	}
+}
+
+// Flush sends the buffered response to the client when the wrapped writer supports it.
+func (r *responseRecorder) Flush() {
+	if !r.wroteHeader {
+		r.headerWritten()
+	}
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Hijack takes over the connection when the wrapped writer supports it, e.g. for a
+// WebSocket upgrade, and records the response as 101 Switching Protocols when no
+// header was written. It returns an error wrapping http.ErrNotSupported otherwise.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil && !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// ReadFrom copies the response from src with the io.ReaderFrom of the wrapped writer,
+// e.g. sendfile for files, and records its size. While the body or a problem is
+// captured, the response is copied through Write instead.
+func (r *responseRecorder) ReadFrom(src io.Reader) (int64, error) {
+	if !r.wroteHeader {
+		r.headerWritten()
+	}
+	rf, ok := r.ResponseWriter.(io.ReaderFrom)
+	if !ok || r.body != nil || r.problem != nil {
+		return io.Copy(writerOnly{r}, src)
+	}
+	n, err := rf.ReadFrom(src)
+	r.written += n
+	return n, err
+}
+
+// Unwrap returns the wrapped ResponseWriter so http.ResponseController can reach it.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Read reads from the request body and keeps a copy of the data.
+func (c *captureBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = c.body.Write(p[:n])
+	}
+	return n, err
+}
+
+// Write keeps the bytes that fit into the limit and marks the buffer as truncated otherwise.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buf.Len(); remaining < len(p) {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// isTextContent reports whether a content type is safe to log as text.
+// Missing content types are treated as text.
+func isTextContent(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	ct := strings.ToLower(contentType)
+	return strings.HasPrefix(ct, "text/") ||
+		strings.Contains(ct, "json") ||
+		strings.Contains(ct, "xml") ||
+		strings.Contains(ct, "x-www-form-urlencoded")
+}
+
+// newRequestID generates a random 128 bit request ID.
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// idGenerator returns the ID generator set with WithIDGenerator, or newRequestID when
+// none is set or the logger was not created by NewLogger.
+func idGenerator(log Logger) func() string {
+	if z, ok := unwrapLogger(log); ok && z.state.cfg.IDGenerator != nil {
+		return z.state.cfg.IDGenerator
+	}
+	return newRequestID
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// humanEncoder wraps the console encoder and renders byte counts and durations for humans.
+// Integer fields named bytes or ending in _bytes are written as B/KiB/MiB/GiB and
+// durations are rounded to three significant digits, e.g. "1.23s" or "340ms".
+// The JSON encoder is never wrapped, so machine-readable output keeps raw numbers.
+type humanEncoder struct {
+	zapcore.Encoder
+}
+
+// Clone returns a copy of the encoder that still humanizes values.
+func (e *humanEncoder) Clone() zapcore.Encoder {
+	return &humanEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// EncodeEntry encodes the entry with humanized fields.
+func (e *humanEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	return e.Encoder.EncodeEntry(ent, humanizeFields(fields))
+}
+
+// AddDuration adds a humanized duration; used for fields added with With.
+func (e *humanEncoder) AddDuration(key string, d time.Duration) {
+	e.Encoder.AddString(key, humanDuration(d))
+}
+
+// AddInt adds an integer, humanized when the key names a byte count.
+func (e *humanEncoder) AddInt(key string, v int) {
+	e.AddInt64(key, int64(v))
+}
+
+// AddInt64 adds an integer, humanized when the key names a byte count.
+func (e *humanEncoder) AddInt64(key string, v int64) {
+	if isBytesKey(key) {
+		e.Encoder.AddString(key, humanBytes(float64(v)))
+		return
+	}
+	e.Encoder.AddInt64(key, v)
+}
+
+// AddUint64 adds an unsigned integer, humanized when the key names a byte count.
+func (e *humanEncoder) AddUint64(key string, v uint64) {
+	if isBytesKey(key) {
+		e.Encoder.AddString(key, humanBytes(float64(v)))
+		return
+	}
+	e.Encoder.AddUint64(key, v)
+}
+
+// humanizeFields returns the fields with byte counts and durations converted to strings.
+// The input slice is never modified.
+func humanizeFields(fields []Field) []Field {
+	var out []Field
+	for i, f := range fields {
+		converted, ok := humanizeField(f)
+		if !ok {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, converted)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// humanizeField converts a byte count or duration field to a string field.
+func humanizeField(f Field) (Field, bool) {
+	switch f.Type {
+	case zapcore.DurationType:
+		return Field{Key: f.Key, Type: zapcore.StringType, String: humanDuration(time.Duration(f.Integer))}, true
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		if isBytesKey(f.Key) {
+			return Field{Key: f.Key, Type: zapcore.StringType, String: humanBytes(float64(f.Integer))}, true
+		}
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		if isBytesKey(f.Key) {
+			return Field{Key: f.Key, Type: zapcore.StringType, String: humanBytes(float64(uint64(f.Integer)))}, true
+		}
+	}
+	return f, false
+}
+
+// isBytesKey reports whether a field key names a byte count.
+func isBytesKey(key string) bool {
+	return key == "bytes" || strings.HasSuffix(key, "_bytes")
+}
+
+// humanBytes formats a byte count with binary units, e.g. "512 B" or "1.5 MiB".
+func humanBytes(n float64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := -1
+	for (n >= unit || n <= -unit) && i < len(units)-1 {
+		n /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", n, units[i])
+}
+
+// humanDuration rounds a duration to three significant digits, e.g. "1.23s" or "340ms".
+func humanDuration(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	precision := time.Duration(1)
+	for abs/precision >= 1000 {
+		precision *= 10
+	}
+	return d.Round(precision).String()
+}
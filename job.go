@@ -0,0 +1,248 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys written to the entries of job runs.
+const (
+	FieldKeyJobName         = "job_name"
+	FieldKeyJobRunID        = "job_run_id"
+	FieldKeyJobStatus       = "job_status"
+	FieldKeyJobCounters     = "job_counters"
+	FieldKeyJobFailureClass = "job_failure_class"
+)
+
+// Messages of the entries written for job runs.
+const (
+	JobStartedMessage   = "job_started"
+	JobCompletedMessage = "job_completed"
+)
+
+// Statuses of completed job runs, written as job_status.
+const (
+	JobStatusSuccess = "success"
+	JobStatusFailure = "failure"
+)
+
+// Failure classes of job runs, written as job_failure_class.
+const (
+	// JobFailureTimeout is a run that exceeded its deadline.
+	JobFailureTimeout = "timeout"
+	// JobFailureCanceled is a run whose context was canceled, e.g. on shutdown.
+	JobFailureCanceled = "canceled"
+	// JobFailureTransient is a run failing with an error that may pass on a retry.
+	JobFailureTransient = "transient"
+	// JobFailureError is any other failed run.
+	JobFailureError = "error"
+)
+
+type (
+	// Job is a run of a scheduled task, such as a cron job or a batch import, logged as
+	// a job_started and a job_completed entry sharing a job_run_id. Counters
+	// incremented during the run, such as records processed, are written on completion.
+	// A Job is safe for concurrent use; only its first completion is logged.
+	Job struct {
+		ctx       context.Context
+		log       Logger
+		id        string
+		start     time.Time
+		completed atomic.Bool
+
+		mu       sync.Mutex
+		counters map[string]int64
+	}
+
+	// jobError classifies the failure of a job run.
+	jobError struct {
+		err   error
+		class string
+	}
+
+	// jobCounters encodes the counters of a job run as an object.
+	jobCounters map[string]int64
+)
+
+// StartJob logs the start of a job run and returns the job to complete once the run
+// is done. The run gets a job_run_id, generated like request IDs. Use Job.Context for
+// the work of the run, so its entries carry job_name and job_run_id.
+//
+// Parameters:
+//   - ctx: The context of the run
+//   - log: The logger writing the entries
+//   - name: The name of the job, e.g. "nightly-export"
+//
+// Returns:
+//   - *Job: The job run
+//
+// Example:
+//
+//	func exportOrders(ctx context.Context) (err error) {
+//	    job := logger.StartJob(ctx, log, "nightly-export")
+//	    defer func() { job.Complete(err) }()
+//
+//	    for _, order := range orders {
+//	        if err := export(job.Context(), order); err != nil {
+//	            return err
+//	        }
+//	        job.Add("records_processed", 1)
+//	    }
+//	    return nil
+//	}
+//	// {"message":"job_completed","job_name":"nightly-export","job_run_id":"9a3e...","duration":"4m2s","job_counters":{"records_processed":52113},"job_status":"success"}
+func StartJob(ctx context.Context, log Logger, name string) *Job {
+	j := &Job{log: log, id: idGenerator(log)(), start: time.Now(), counters: map[string]int64{}}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	bag := &fieldBag{fields: bagFields(ctx)}
+	j.ctx = context.WithValue(ctx, fieldBagKey{}, bag)
+	AddField(j.ctx, zap.String(FieldKeyJobName, name), zap.String(FieldKeyJobRunID, j.id))
+
+	log.Info(j.ctx, JobStartedMessage)
+	return j
+}
+
+// Context returns the context of the run. Entries logged with it carry job_name and
+// job_run_id, and fields added with AddField are written on completion as well.
+func (j *Job) Context() context.Context {
+	return j.ctx
+}
+
+// ID returns the job_run_id of the run.
+func (j *Job) ID() string {
+	return j.id
+}
+
+// Add increments a counter of the run, such as records_processed or records_skipped.
+//
+// Parameters:
+//   - counter: The name of the counter
+//   - delta: The amount to add
+func (j *Job) Add(counter string, delta int64) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.counters[counter] += delta
+}
+
+// Complete logs the completion of the run with its duration and counters. A nil err
+// logs a success at InfoLevel. Otherwise the failure is classified, see
+// JobFailureClass, and logged at ErrorLevel, or at WarnLevel when canceled.
+//
+// Parameters:
+//   - err: The error the run failed with, nil on success
+func (j *Job) Complete(err error) {
+	if j.completed.Swap(true) {
+		return
+	}
+
+	j.mu.Lock()
+	counters := make(jobCounters, len(j.counters))
+	for name, n := range j.counters {
+		counters[name] = n
+	}
+	j.mu.Unlock()
+
+	fields := []Field{
+		zap.Duration(FieldKeyDuration, time.Since(j.start)),
+		zap.Object(FieldKeyJobCounters, counters),
+	}
+	if err == nil {
+		j.log.Info(j.ctx, JobCompletedMessage, append(fields, zap.String(FieldKeyJobStatus, JobStatusSuccess))...)
+		return
+	}
+
+	class := JobFailureClass(err)
+	fields = append(fields,
+		zap.String(FieldKeyJobStatus, JobStatusFailure),
+		zap.String(FieldKeyJobFailureClass, class),
+		zap.Error(err),
+	)
+	if class == JobFailureCanceled {
+		j.log.Warn(j.ctx, JobCompletedMessage, fields...)
+		return
+	}
+	j.log.Error(j.ctx, JobCompletedMessage, fields...)
+}
+
+// ClassifyJobError returns err marked with a failure class, which JobFailureClass
+// reports instead of deriving one, e.g. to mark errors of a flaky upstream as
+// transient.
+//
+// Parameters:
+//   - err: The error to classify
+//   - class: The failure class, e.g. JobFailureTransient or a custom class
+//
+// Returns:
+//   - error: The classified error, nil when err is nil
+//
+// Example:
+//
+//	if resp.StatusCode == http.StatusServiceUnavailable {
+//	    return logger.ClassifyJobError(errUpstream, logger.JobFailureTransient)
+//	}
+func ClassifyJobError(err error, class string) error {
+	if err == nil {
+		return nil
+	}
+	return &jobError{err: err, class: class}
+}
+
+// JobFailureClass classifies the error of a failed job run: the class set with
+// ClassifyJobError, JobFailureTimeout for exceeded deadlines and errors reporting
+// Timeout, JobFailureCanceled for canceled contexts, JobFailureTransient for errors
+// reporting Temporary and JobFailureError otherwise.
+//
+// Parameters:
+//   - err: The error of the run
+//
+// Returns:
+//   - string: The failure class
+func JobFailureClass(err error) string {
+	var classified *jobError
+	var timeout interface{ Timeout() bool }
+	var temporary interface{ Temporary() bool }
+	switch {
+	case errors.As(err, &classified):
+		return classified.class
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &timeout) && timeout.Timeout():
+		return JobFailureTimeout
+	case errors.Is(err, context.Canceled):
+		return JobFailureCanceled
+	case errors.As(err, &temporary) && temporary.Temporary():
+		return JobFailureTransient
+	default:
+		return JobFailureError
+	}
+}
+
+// Error returns the message of the classified error.
+func (e *jobError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the classified error.
+func (e *jobError) Unwrap() error {
+	return e.err
+}
+
+// MarshalLogObject writes the counters in the order of their names.
+func (c jobCounters) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		enc.AddInt64(name, c[name])
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ladderRetryInterval is how long a degraded sink waits before retrying its primary.
+const ladderRetryInterval = 30 * time.Second
+
+// Field keys of the entries written about degraded sinks.
+const (
+	FieldKeySinkFrom = "sink_from"
+	FieldKeySinkTo   = "sink_to"
+)
+
+type (
+	// SinkLadderStats is a snapshot of the degradation ladder of an output path.
+	SinkLadderStats struct {
+		// Active is the sink entries are currently written to.
+		Active string
+		// Level is the position of Active in the ladder; zero is the primary.
+		Level int
+		// Degradations counts the moves down the ladder.
+		Degradations uint64
+		// Recoveries counts the returns to the primary.
+		Recoveries uint64
+	}
+
+	// sinkFallback pairs an output path with its fallback paths.
+	sinkFallback struct {
+		sink      string
+		fallbacks []string
+	}
+
+	// sinkLadder writes to the first working sink of a degradation ladder: the primary
+	// output path followed by its fallbacks. It moves down when a write fails and retries
+	// the primary every ladderRetryInterval.
+	sinkLadder struct {
+		paths   []string
+		writers []zapcore.WriteSyncer
+		diag    *diagnostics
+
+		degradations atomic.Uint64
+		recoveries   atomic.Uint64
+
+		mu         sync.Mutex
+		level      int
+		degradedAt time.Time
+	}
+)
+
+// newSinkLadders replaces the writers of output paths with fallbacks by ladders,
+// opening the fallback sinks. It returns the ladders for GetStats and a function
+// closing the fallback sinks.
+func newSinkLadders(paths []string, writers []zapcore.WriteSyncer, fallbacks []sinkFallback, cfg *config, diag *diagnostics) ([]*sinkLadder, func(), error) {
+	var (
+		ladders []*sinkLadder
+		closers []func()
+	)
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	for _, f := range fallbacks {
+		i := slices.Index(paths, f.sink)
+		if i < 0 {
+			closeAll()
+			return nil, nil, fmt.Errorf("sink fallback for unknown sink %q: it must be one of the output paths", f.sink)
+		}
+		if len(f.fallbacks) == 0 {
+			closeAll()
+			return nil, nil, fmt.Errorf("sink fallback for %q needs at least one fallback", f.sink)
+		}
+		fallbackWriters, closeSinks, err := openSinkList(f.fallbacks, cfg)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("failed to open fallbacks of sink %q: %w", f.sink, err)
+		}
+		closers = append(closers, closeSinks)
+
+		ladder := &sinkLadder{
+			paths:   append([]string{f.sink}, f.fallbacks...),
+			writers: append([]zapcore.WriteSyncer{writers[i]}, fallbackWriters...),
+			diag:    diag,
+		}
+		writers[i] = ladder
+		ladders = append(ladders, ladder)
+	}
+	return ladders, closeAll, nil
+}
+
+// Write writes p to the active sink, moving down the ladder while writes fail. The
+// primary is retried once the retry interval has passed since the ladder degraded.
+func (l *sinkLadder) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.level > 0 && time.Since(l.degradedAt) >= ladderRetryInterval {
+		if n, err := l.writers[0].Write(p); err == nil {
+			l.transition(0, nil)
+			return n, nil
+		}
+		l.degradedAt = time.Now()
+	}
+
+	var errs []error
+	for level := l.level; level < len(l.writers); level++ {
+		n, err := l.writers[level].Write(p)
+		if err == nil {
+			if level != l.level {
+				l.transition(level, errors.Join(errs...))
+			}
+			return n, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", sanitizePath(l.paths[level]), err))
+	}
+	return 0, errors.Join(errs...)
+}
+
+// Sync flushes the active sink.
+func (l *sinkLadder) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.writers[l.level].Sync()
+}
+
+// transition moves the ladder to the level and writes an entry about it. It must be
+// called with the lock held.
+func (l *sinkLadder) transition(level int, cause error) {
+	from, to := sanitizePath(l.paths[l.level]), sanitizePath(l.paths[level])
+	if level == 0 {
+		l.recoveries.Add(1)
+	} else {
+		l.degradations.Add(1)
+		l.degradedAt = time.Now()
+	}
+	l.level = level
+
+	fields := []Field{zap.String(FieldKeySinkFrom, from), zap.String(FieldKeySinkTo, to)}
+	if level == 0 {
+		l.diag.notice(DiagnosticSinkRecovered, "log sink recovered", fields...)
+		return
+	}
+	l.diag.report(DiagnosticSinkDegraded, cause, fields...)
+}
+
+// stats returns a snapshot of the ladder.
+func (l *sinkLadder) stats() SinkLadderStats {
+	l.mu.Lock()
+	level := l.level
+	l.mu.Unlock()
+	return SinkLadderStats{
+		Active:       l.paths[level],
+		Level:        level,
+		Degradations: l.degradations.Load(),
+		Recoveries:   l.recoveries.Load(),
+	}
+}
+
+// fallbackPaths returns the fallback paths of every ladder.
+func fallbackPaths(fallbacks []sinkFallback) []string {
+	var paths []string
+	for _, f := range fallbacks {
+		paths = append(paths, f.fallbacks...)
+	}
+	return paths
+}
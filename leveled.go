@@ -0,0 +1,184 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// badKey is the key of a trailing value without a key in a key-value list.
+const badKey = "!BADKEY"
+
+// Frames of the adapters between the application and the Logger call, skipped so
+// entries report the call site in the application.
+const (
+	// leveledLoggerCallerSkip covers the level method, write and logAt.
+	leveledLoggerCallerSkip = 3
+	// redisLoggerCallerSkip covers Printf.
+	redisLoggerCallerSkip = 1
+	// kitLoggerCallerSkip covers Log and logAt.
+	kitLoggerCallerSkip = 2
+)
+
+// LeveledLogger adapts a Logger to the leveled key-value interface used by client libraries.
+// It implements the LeveledLogger interface of hashicorp/go-retryablehttp:
+// Error, Warn, Info and Debug taking a message and alternating keys and values.
+type LeveledLogger struct {
+	log       Logger
+	component string
+}
+
+// RedisLogger adapts a Logger to go-redis' internal.Logging interface.
+// Lines are logged at WarnLevel since go-redis only logs connection pool and
+// cluster problems. Trace and request IDs are taken from the context.
+type RedisLogger struct {
+	log Logger
+}
+
+// NewLeveledLogger creates a LeveledLogger.
+//
+// Parameters:
+//   - log: The logger receiving the entries
+//   - component: The value of the component field, omitted when empty
+//
+// Returns:
+//   - *LeveledLogger: The adapter
+//
+// Example:
+//
+//	client := retryablehttp.NewClient()
+//	client.Logger = NewLeveledLogger(log, "retryablehttp")
+func NewLeveledLogger(log Logger, component string) *LeveledLogger {
+	return &LeveledLogger{log: AddCallerSkip(log, leveledLoggerCallerSkip), component: component}
+}
+
+// NewRedisLogger creates an adapter for go-redis' package-level logger.
+//
+// Example:
+//
+//	redis.SetLogger(NewRedisLogger(log))
+func NewRedisLogger(log Logger) *RedisLogger {
+	return &RedisLogger{log: AddCallerSkip(log, redisLoggerCallerSkip)}
+}
+
+// NewElasticLoggers creates the error, info and trace loggers of olivere/elastic clients.
+// Errors are logged at ErrorLevel, info lines at InfoLevel and request traces at DebugLevel.
+//
+// Example:
+//
+//	errorLog, infoLog, traceLog := NewElasticLoggers(log)
+//	client, err := elastic.NewClient(
+//	    elastic.SetErrorLog(errorLog),
+//	    elastic.SetInfoLog(infoLog),
+//	    elastic.SetTraceLog(traceLog),
+//	)
+func NewElasticLoggers(log Logger) (errorLog, infoLog, traceLog *StdLogger) {
+	return NewStdLogger(log, LevelError, "elastic"),
+		NewStdLogger(log, LevelInfo, "elastic"),
+		NewStdLogger(log, LevelDebug, "elastic")
+}
+
+// Error logs a message at ErrorLevel.
+func (l *LeveledLogger) Error(msg string, keysAndValues ...any) {
+	l.write(LevelError, msg, keysAndValues)
+}
+
+// Warn logs a message at WarnLevel.
+func (l *LeveledLogger) Warn(msg string, keysAndValues ...any) {
+	l.write(LevelWarning, msg, keysAndValues)
+}
+
+// Info logs a message at InfoLevel.
+func (l *LeveledLogger) Info(msg string, keysAndValues ...any) {
+	l.write(LevelInfo, msg, keysAndValues)
+}
+
+// Debug logs a message at DebugLevel.
+func (l *LeveledLogger) Debug(msg string, keysAndValues ...any) {
+	l.write(LevelDebug, msg, keysAndValues)
+}
+
+// write logs a message with the key-value pairs converted to fields.
+func (l *LeveledLogger) write(level Level, msg string, keysAndValues []any) {
+	fields := keysAndValuesToFields(keysAndValues)
+	if l.component != "" {
+		fields = append(fields, zap.String(FieldKeyComponent, l.component))
+	}
+	logAt(context.Background(), l.log, level, msg, fields...)
+}
+
+// Printf logs the operands formatted like fmt.Sprintf.
+func (r *RedisLogger) Printf(ctx context.Context, format string, v ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	msg := strings.TrimRight(fmt.Sprintf(format, v...), "\r\n")
+	r.log.Warn(ctx, msg, zap.String(FieldKeyComponent, "go-redis"))
+}
+
+// keysAndValuesToFields converts alternating keys and values to fields.
+// Keys that are not strings are formatted with fmt.Sprint; a trailing value
+// without a key is logged under !BADKEY.
+func keysAndValuesToFields(keysAndValues []any) []Field {
+	fields := make([]Field, 0, len(keysAndValues)/2+1)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			fields = append(fields, zap.Any(badKey, keysAndValues[i]))
+			break
+		}
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		fields = append(fields, zap.Any(key, keysAndValues[i+1]))
+	}
+	return fields
+}
+
+// KitLogger adapts a Logger to the go-kit log.Logger interface.
+// The level is read from the "level" key written by go-kit's level package and the
+// message from the "msg" key; the remaining pairs become fields. Entries without a
+// level are logged at InfoLevel.
+type KitLogger struct {
+	log Logger
+}
+
+// NewKitLogger creates a KitLogger.
+//
+// Parameters:
+//   - log: The logger receiving the entries
+//
+// Returns:
+//   - *KitLogger: The adapter
+//
+// Example:
+//
+//	var kitlog kitlog.Logger = NewKitLogger(log)
+//	level.Info(kitlog).Log("msg", "listening", "addr", ":8080")
+func NewKitLogger(log Logger) *KitLogger {
+	return &KitLogger{log: AddCallerSkip(log, kitLoggerCallerSkip)}
+}
+
+// Log logs the key-value pairs. It never returns an error.
+func (k *KitLogger) Log(keyvals ...any) error {
+	level, msg := LevelInfo, ""
+	rest := make([]any, 0, len(keyvals))
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 == len(keyvals) {
+			rest = append(rest, keyvals[i])
+			break
+		}
+		switch fmt.Sprint(keyvals[i]) {
+		case "level":
+			level = LevelFromName(fmt.Sprint(keyvals[i+1]))
+		case "msg":
+			msg = fmt.Sprint(keyvals[i+1])
+		default:
+			rest = append(rest, keyvals[i], keyvals[i+1])
+		}
+	}
+	logAt(context.Background(), k.log, level, msg, keysAndValuesToFields(rest)...)
+	return nil
+}
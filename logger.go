@@ -6,9 +6,12 @@ package logger
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
-	"github.com/newrelic/go-agent/v3/integrations/logcontext-v2/nrzap"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // Logger defines the interface for structured logging operations.
@@ -27,8 +30,14 @@ type Logger interface {
 	Fatal(ctx context.Context, msg string, fields ...Field)
 	// Panic logs a message at PanicLevel with optional structured fields, then panics
 	Panic(ctx context.Context, msg string, fields ...Field)
+	// Once logs a message at InfoLevel only the first time the key is seen
+	Once(ctx context.Context, key string, msg string, fields ...Field)
+	// Every logs a message at InfoLevel at most once per interval for the key
+	Every(ctx context.Context, key string, interval time.Duration, msg string, fields ...Field)
 	// With creates a child logger with additional structured fields
 	With(fields ...Field) Logger
+	// WithOptions derives a logger reconfigured by the options from this logger
+	WithOptions(opts ...Option) (Logger, error)
 	// GetLogger returns the underlying zap.Logger instance for advanced usage
 	GetLogger() *zap.Logger
 }
@@ -73,6 +82,23 @@ func NewLogger(opts ...Option) (Logger, error) {
 		opt(cfg)
 	}
 
+	// The defaults of a registered mode apply before the options, which are only known
+	// to select the mode once applied.
+	if preset, ok := appModePreset(cfg.AppMode); ok {
+		cfg = &config{}
+		WithDefaultConfig()(cfg)
+		cfg.applyPreset(preset())
+		for _, opt := range opts {
+			opt(cfg)
+		}
+	}
+
+	return newLogger(cfg, inheritedState{})
+}
+
+// newLogger builds a logger from cfg. Runtime state set in inherited is shared with
+// the logger it was inherited from instead of being created.
+func newLogger(cfg *config, inherited inheritedState) (Logger, error) {
 	var (
 		zapConfig zap.Config
 	)
@@ -83,7 +109,23 @@ func NewLogger(opts ...Option) (Logger, error) {
 	case AppModeProduction:
 		zapConfig = zap.NewProductionConfig()
 	default:
-		return nil, errors.New("invalid app mode")
+		preset, ok := appModePreset(cfg.AppMode)
+		if !ok {
+			return nil, errors.New("invalid app mode")
+		}
+		zapConfig = preset()
+	}
+
+	if cfg.ContainerOutput {
+		if err := validateContainerLineBytes(cfg.ContainerMaxLine); err != nil {
+			return nil, err
+		}
+		cfg.Encoding = EncodingJson
+		cfg.AutoEncoding = false
+		cfg.ColorTheme = nil
+	}
+	if cfg.AutoEncoding {
+		cfg.resolveAutoEncoding()
 	}
 
 	level, err := parseLevel(cfg.Level)
@@ -91,7 +133,29 @@ func NewLogger(opts ...Option) (Logger, error) {
 		return nil, err
 	}
 
+	if cfg.ConsoleTemplate != "" {
+		cfg.consoleTemplate, err = parseConsoleTemplate(cfg.ConsoleTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid console template: %w", err)
+		}
+	}
+
+	if err := validateMultilineMode(cfg.MultilineMode, cfg.Encoding.String()); err != nil {
+		return nil, err
+	}
+	if err := validateNonFiniteMode(cfg.NonFiniteFloats); err != nil {
+		return nil, err
+	}
+	if cfg.DualWrite != nil {
+		if err := validateDualWrite(cfg.DualWrite); err != nil {
+			return nil, err
+		}
+	}
+
 	zapConfig.Level = level
+	if inherited.level != nil {
+		zapConfig.Level = *inherited.level
+	}
 	zapConfig.Encoding = cfg.Encoding.String()
 	zapConfig.EncoderConfig.TimeKey = cfg.TimeKey
 	zapConfig.EncoderConfig.LevelKey = cfg.LevelKey
@@ -99,27 +163,241 @@ func NewLogger(opts ...Option) (Logger, error) {
 	zapConfig.EncoderConfig.CallerKey = cfg.CallerKey
 	zapConfig.EncoderConfig.MessageKey = cfg.MessageKey
 	zapConfig.EncoderConfig.StacktraceKey = cfg.StacktraceKey
+	if cfg.MaxFieldDepth > 0 || cfg.NonFiniteFloats != NonFiniteDefault {
+		zapConfig.EncoderConfig.NewReflectedEncoder = newSafeReflectedEncoder(cfg.MaxFieldDepth, cfg.NonFiniteFloats)
+	}
+	if cfg.CallerModuleRelative {
+		cfg.callerFormatter = newCallerFormatter(cfg.CallerModule, cfg.CallerFunction)
+		if cfg.callerFormatter != nil {
+			zapConfig.EncoderConfig.EncodeCaller = cfg.callerFormatter.encode
+		}
+	}
 	zapConfig.DisableStacktrace = cfg.DisableStacktrace
 	zapConfig.DisableCaller = cfg.DisableCaller
+	if cfg.DisableCaller && (cfg.CallSiteRateLimit > 0 || cfg.CorrelationKeys != nil) {
+		// Call sites are needed to rate limit and report them, so they are captured but not written.
+		zapConfig.DisableCaller = false
+		zapConfig.EncoderConfig.CallerKey = zapcore.OmitKey
+	}
+	if cfg.Deterministic {
+		zapConfig.DisableCaller = true
+		zapConfig.DisableStacktrace = true
+		zapConfig.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	} else if cfg.colored() {
+		cfg.ColorTheme.apply(&zapConfig.EncoderConfig)
+	}
+	if cfg.ContainerOutput {
+		zapConfig.EncoderConfig.EncodeTime = utcRFC3339NanoTimeEncoder
+		zapConfig.EncoderConfig.LineEnding = zapcore.DefaultLineEnding
+	}
+	reserveDimensionKeys(zapConfig.EncoderConfig)
 	zapConfig.OutputPaths = cfg.OutputPaths
 	zapConfig.ErrorOutputPaths = cfg.ErrorOutputPaths
-	zaplog, err := zapConfig.Build()
+
+	cfg.SinkPool = inherited.sinks
+	if cfg.SinkPool == nil {
+		cfg.SinkPool = newSinkPool()
+	}
+	writers, closeSinks, err := openSinkList(cfg.OutputPaths, cfg)
 	if err != nil {
 		return nil, err
 	}
 
+	errSink, closeErrSinks, err := openSinks(cfg.ErrorOutputPaths, cfg)
+	if err != nil {
+		closeSinks()
+		return nil, err
+	}
+	// The error output is released after the output paths, so failures flushing them
+	// are still reported.
+	closeOutputSinks := closeSinks
+	closeSinks = func() {
+		closeOutputSinks()
+		closeErrSinks()
+	}
+
+	sizes := newEntrySizes(cfg.EntrySizeTracking, cfg.EntrySizeThreshold)
+	encoder := sizes.wrap(newEncoder(zapConfig, cfg))
+
+	diag := newDiagnostics(encoder.Clone(), errSink)
+	supervisor := newSupervisor(diag)
+	ladders, closeFallbacks, err := newSinkLadders(cfg.OutputPaths, writers, cfg.SinkFallbacks, cfg, diag)
+	if err != nil {
+		closeSinks()
+		return nil, err
+	}
+	if len(ladders) > 0 {
+		closeOutputs := closeSinks
+		closeSinks = func() {
+			closeOutputs()
+			closeFallbacks()
+		}
+	}
+	closeBatches, err := newSinkBatches(cfg.OutputPaths, writers, cfg.SinkBatches, cfg, diag, supervisor)
+	if err != nil {
+		closeSinks()
+		return nil, err
+	}
+	if len(cfg.SinkBatches) > 0 {
+		closeUnbatched := closeSinks
+		closeSinks = func() {
+			closeBatches()
+			closeUnbatched()
+		}
+	}
+
+	if err := validateSinkPolicies(cfg.SinkPolicies, append(slices.Clone(cfg.OutputPaths), residencyPaths(cfg.Residency)...), cfg.NewRelicApp != nil); err != nil {
+		closeSinks()
+		return nil, err
+	}
+	if err := validateSinkProfiles(cfg.SinkProfiles, cfg.OutputPaths); err != nil {
+		closeSinks()
+		return nil, err
+	}
+	profiled := newProfiledSinks(cfg.SinkProfiles, zapConfig, func(profileConfig zap.Config) zapcore.Encoder {
+		return sizes.wrap(newEncoder(profileConfig, cfg))
+	})
+	core, budgets, err := newOutputCore(encoder, cfg.OutputPaths, writers, zapConfig.Level, cfg.IngestBudgets, cfg.SinkPolicies, profiled)
+	if err != nil {
+		closeSinks()
+		return nil, err
+	}
+	core, residency, closeRegions, err := newResidencyCore(core, encoder, zapConfig.Level, cfg)
+	if err != nil {
+		closeSinks()
+		return nil, err
+	}
+	if closeRegions != nil {
+		closeOutputs := closeSinks
+		closeSinks = func() {
+			closeOutputs()
+			closeRegions()
+		}
+	}
+	if cfg.FsyncOnError {
+		core = &syncCore{Core: core, level: zapcore.ErrorLevel}
+	}
+	if cfg.Deterministic {
+		core = &sortedCore{Core: core}
+	}
+	sinkPaths := slices.Concat(cfg.OutputPaths, cfg.ErrorOutputPaths, fallbackPaths(cfg.SinkFallbacks), residencyPaths(cfg.Residency))
+	if cfg.StrictStartup {
+		if err := checkSinks(context.Background(), sinkPaths); err != nil {
+			closeSinks()
+			return nil, fmt.Errorf("log sinks failed startup checks: %w", err)
+		}
+	} else {
+		go func() {
+			if err := checkSinks(context.Background(), sinkPaths); err != nil {
+				diag.report(DiagnosticSinkCheck, err)
+			}
+		}()
+	}
+	core = &diagnosticsCore{Core: core, diag: diag, counted: true}
+	// Entries are forwarded to New Relic next to the sinks, so sampling, filters,
+	// renames, storm suppression and redaction apply to both.
 	if cfg.NewRelicApp != nil {
-		backgroundCore, err := nrzap.WrapBackgroundCore(zaplog.Core(), cfg.NewRelicApp)
+		forwardCore, err := newNewRelicCore(cfg.NewRelicApp, cfg.NewRelicLevel, zapConfig.Level)
 		if err != nil {
+			closeSinks()
 			return nil, err
 		}
+		policy, _ := findSinkPolicy(cfg.SinkPolicies, NewRelicSink)
+		core = teeCore{core, &diagnosticsCore{Core: newClassCore(forwardCore, policy), diag: diag}}
+	}
+	core = newRenameCore(core, cfg.FieldRenames)
+	redacted := inherited.redacted
+	if redacted == nil {
+		redacted = newRedactedFields(cfg.RedactedFields)
+	}
+	core = &redactCore{Core: core, redacted: redacted}
+	core = newUserAgentCore(core, cfg.UserAgentParsing, cfg.UserAgentCacheSize)
+	core, storms := newStormCore(core, cfg.ErrorStormThreshold, cfg.ErrorStormWindow, cfg.ErrorStormInterval)
+	core, sites := newSiteLimitCore(core, cfg.CallSiteRateLimit, cfg.CallSiteBurst)
+	core, correlation := newCorrelationCore(core, cfg.CorrelationKeys, diag, cfg.AppMode == AppModeDevelopment)
 
-		zaplog = zap.New(backgroundCore)
+	sampler := inherited.sampler
+	if sampler == nil {
+		sampler = newSampler(0, 0, false)
+		if zapConfig.Sampling != nil {
+			sampler.set(zapConfig.Sampling.Initial, zapConfig.Sampling.Thereafter)
+		}
 	}
 
-	return &logger{
-		logger: &zapLogger{zapLogger: zaplog},
-	}, nil
+	var processors []metricProcessor
+	if cfg.LatencyHistogram {
+		histogram, err := newLatencyHistogram(cfg.MetricsRegisterer, cfg.LatencyBuckets)
+		if err != nil {
+			closeSinks()
+			return nil, fmt.Errorf("failed to register latency histogram: %w", err)
+		}
+		processors = append(processors, histogram)
+	}
+	for _, rule := range cfg.MetricRules {
+		processor, err := newRuleProcessor(cfg.MetricsRegisterer, rule)
+		if err != nil {
+			closeSinks()
+			return nil, err
+		}
+		processors = append(processors, processor)
+	}
+
+	zaplog := zap.New(core, buildOptions(zapConfig, errSink, sampler)...)
+	if cfg.Deterministic {
+		zaplog = zaplog.WithOptions(zap.WithClock(fixedClock{}))
+	}
+	zaplog = zaplog.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newMetricsCore(core, processors)
+	}))
+	overrides := &levelOverrides{}
+	zaplog = zaplog.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newOverrideCore(core, overrides)
+	}))
+	// Crash reports record the entries the logger writes, before captures add the
+	// entries recorded regardless of the level.
+	crash := newCrashReport(cfg.CrashReportPath, cfg.CrashReportEntries, zapConfig.EncoderConfig, redacted, diag)
+	if crash != nil {
+		zaplog = zaplog.WithOptions(zap.WrapCore(crash.wrap), zap.WithPanicHook(crashPanicHook{report: crash}))
+	}
+	capture := newCaptureState(zapcore.NewJSONEncoder(zapConfig.EncoderConfig), redacted)
+	zaplog = zaplog.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &captureCore{Core: core, state: capture}
+	}))
+	var baseFields []Field
+	if cfg.SchemaVersion != "" {
+		baseFields = append(baseFields, zap.String(FieldKeySchemaVersion, cfg.SchemaVersion))
+		zaplog = zaplog.With(zap.String(FieldKeySchemaVersion, cfg.SchemaVersion))
+	}
+	if len(cfg.ResourceFields) > 0 {
+		baseFields = append(baseFields, cfg.ResourceFields...)
+		zaplog = zaplog.With(cfg.ResourceFields...)
+	}
+	if cfg.CloudMetadataTimeout > 0 {
+		cfg.CloudFields = cloudMetadataFields(cfg.CloudMetadataTimeout, diag)
+		baseFields = append(baseFields, cfg.CloudFields...)
+		zaplog = zaplog.With(cfg.CloudFields...)
+	}
+	// Copies are teed after the schema version, resource and cloud fields of the primary logger
+	// are added, so the secondary logger stamps its own.
+	dualWrite := newDualWrite(cfg.DualWrite)
+	if dualWrite != nil {
+		zaplog = zaplog.WithOptions(zap.WrapCore(dualWrite.wrap))
+	}
+	// Filters wrap every other core, so the entries they drop reach no sink, capture,
+	// crash report, metric, copy or New Relic.
+	zaplog = zaplog.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newFilterCore(core, cfg.Filters, baseFields)
+	}))
+
+	shutdown := newShutdown(cfg.ShutdownTimeout, closeSinks)
+	zaplog = zaplog.WithOptions(zap.WithFatalHook(fatalHook{shutdown: shutdown, crash: crash}))
+	shutdown.sync = zaplog.Sync
+
+	z := &zapLogger{zapLogger: zaplog, state: newLoggerState(cfg, zapConfig, encoder, sampler, diag, storms, budgets, ladders, sites, sizes, residency, redacted, overrides, capture, dualWrite, correlation, crash, supervisor, shutdown)}
+	if crash != nil {
+		crash.logger = z
+	}
+	return &logger{logger: z}, nil
 }
 
 // GetLogger returns the underlying zap logger.
@@ -163,9 +441,27 @@ func (l *logger) Fatal(ctx context.Context, msg string, fields ...Field) {
 	l.logger.Fatal(ctx, msg, fields...)
 }
 
+// Once logs a message at InfoLevel only the first time the key is seen.
+// Use this inside hot loops to report a condition without flooding the logs.
+func (l *logger) Once(ctx context.Context, key string, msg string, fields ...Field) {
+	l.logger.Once(ctx, key, msg, fields...)
+}
+
+// Every logs a message at InfoLevel at most once per interval for the key.
+// Use this inside hot loops to report a recurring condition at a bounded rate.
+func (l *logger) Every(ctx context.Context, key string, interval time.Duration, msg string, fields ...Field) {
+	l.logger.Every(ctx, key, interval, msg, fields...)
+}
+
 // With creates a child logger with additional structured fields.
 // The returned logger will include these fields in all subsequent log entries.
 // Example: childLogger := logger.With(zap.String("component", "database"))
 func (l *logger) With(fields ...Field) Logger {
-	return l.logger.With(fields...)
+	return &logger{logger: l.logger.With(fields...)}
+}
+
+// WithOptions derives a logger reconfigured by the options from this logger.
+// Example: auditLogger, err := logger.WithOptions(WithOutputPaths([]string{"/var/log/audit.log"}))
+func (l *logger) WithOptions(opts ...Option) (Logger, error) {
+	return l.logger.WithOptions(opts...)
 }
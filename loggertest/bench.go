@@ -0,0 +1,202 @@
+package loggertest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// BenchmarkPattern is a common logging pattern, logged once with a logger of this
+// package and once the equivalent way with raw zap, to measure the overhead of the
+// package.
+type BenchmarkPattern struct {
+	// Name names the pattern in benchmark and test output.
+	Name string
+	// Logger logs the pattern with a logger of this package.
+	Logger func(ctx context.Context, log logger.Logger)
+	// Zap logs the equivalent entry with raw zap.
+	Zap func(log *zap.Logger)
+}
+
+// benchmarkError is the error logged by the error pattern.
+var benchmarkError = errors.New("connection reset by peer")
+
+// BenchmarkPatterns returns the common logging patterns measured by RunBenchmarks and
+// CheckOverhead: a plain message, a message with typical fields, an error, a child
+// logger, a context carrying a request and user ID, and a disabled debug entry.
+//
+// Returns:
+//   - []BenchmarkPattern: The patterns
+func BenchmarkPatterns() []BenchmarkPattern {
+	return []BenchmarkPattern{
+		{
+			Name:   "message",
+			Logger: func(ctx context.Context, log logger.Logger) { log.Info(ctx, "order placed") },
+			Zap:    func(log *zap.Logger) { log.Info("order placed") },
+		},
+		{
+			Name: "fields",
+			Logger: func(ctx context.Context, log logger.Logger) {
+				log.Info(ctx, "order placed", benchmarkFields()...)
+			},
+			Zap: func(log *zap.Logger) { log.Info("order placed", benchmarkFields()...) },
+		},
+		{
+			Name: "error",
+			Logger: func(ctx context.Context, log logger.Logger) {
+				log.Error(ctx, "payment failed", zap.Error(benchmarkError))
+			},
+			Zap: func(log *zap.Logger) { log.Error("payment failed", zap.Error(benchmarkError)) },
+		},
+		{
+			Name: "child",
+			Logger: func(ctx context.Context, log logger.Logger) {
+				log.With(zap.String("component", "checkout")).Info(ctx, "order placed")
+			},
+			Zap: func(log *zap.Logger) { log.With(zap.String("component", "checkout")).Info("order placed") },
+		},
+		{
+			Name: "context",
+			Logger: func(ctx context.Context, log logger.Logger) {
+				ctx = context.WithValue(ctx, logger.ContextKeyRequestID, "4f1c2a")
+				ctx = context.WithValue(ctx, logger.ContextKeyUserID, "ada")
+				log.Info(ctx, "order placed")
+			},
+			Zap: func(log *zap.Logger) {
+				log.Info("order placed", zap.String("request_id", "4f1c2a"), zap.String("user_id", "ada"))
+			},
+		},
+		{
+			Name: "disabled",
+			Logger: func(ctx context.Context, log logger.Logger) {
+				log.Debug(ctx, "cache lookup", zap.String("key", "sku-1"))
+			},
+			Zap: func(log *zap.Logger) { log.Debug("cache lookup", zap.String("key", "sku-1")) },
+		},
+	}
+}
+
+// RunBenchmarks benchmarks every pattern of BenchmarkPatterns with a logger of this
+// package configured by the options and with raw zap, as sub-benchmarks named
+// <pattern>/go-logger and <pattern>/zap. Both write JSON at InfoLevel and discard it.
+//
+// Parameters:
+//   - b: The benchmark
+//   - opts: Options of the logger of this package
+//
+// Example:
+//
+//	func BenchmarkLogging(b *testing.B) {
+//	    loggertest.RunBenchmarks(b)
+//	}
+//	// go test -bench Logging -benchmem
+func RunBenchmarks(b *testing.B, opts ...logger.Option) {
+	log, err := newBenchmarkLogger(opts)
+	if err != nil {
+		b.Fatalf("failed to create logger: %v", err)
+	}
+	raw := newRawZap()
+
+	for _, pattern := range BenchmarkPatterns() {
+		b.Run(pattern.Name+"/go-logger", func(b *testing.B) {
+			benchmarkLogger(b, log, pattern)
+		})
+		b.Run(pattern.Name+"/zap", func(b *testing.B) {
+			benchmarkZap(b, raw, pattern)
+		})
+	}
+}
+
+// CheckOverhead benchmarks every pattern of BenchmarkPatterns like RunBenchmarks and
+// fails the test when a logger of this package takes more than maxRatio times as long
+// per entry as raw zap, so refactors slowing down the hot path are caught in CI. The
+// measured ratios are logged. The check is skipped with -short, as benchmarks take a
+// few seconds.
+//
+// Parameters:
+//   - t: The test
+//   - maxRatio: The maximum ratio of the time per entry of this package to raw zap
+//   - opts: Options of the logger of this package
+//
+// Example:
+//
+//	func TestLoggingOverhead(t *testing.T) {
+//	    loggertest.CheckOverhead(t, 10)
+//	}
+func CheckOverhead(t *testing.T, maxRatio float64, opts ...logger.Option) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping logging benchmarks in short mode")
+	}
+
+	log, err := newBenchmarkLogger(opts)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	raw := newRawZap()
+
+	for _, pattern := range BenchmarkPatterns() {
+		ours := testing.Benchmark(func(b *testing.B) { benchmarkLogger(b, log, pattern) })
+		theirs := testing.Benchmark(func(b *testing.B) { benchmarkZap(b, raw, pattern) })
+		ratio := float64(ours.NsPerOp()) / float64(max(theirs.NsPerOp(), 1))
+
+		t.Logf("%-10s go-logger %8s/op %3d allocs/op   zap %8s/op %3d allocs/op   ratio %.2f",
+			pattern.Name,
+			time.Duration(ours.NsPerOp()), ours.AllocsPerOp(),
+			time.Duration(theirs.NsPerOp()), theirs.AllocsPerOp(),
+			ratio)
+		if ratio > maxRatio {
+			t.Errorf("pattern %s: go-logger is %.2f times slower than zap, at most %.2f allowed", pattern.Name, ratio, maxRatio)
+		}
+	}
+}
+
+// benchmarkLogger runs the pattern with a logger of this package b.N times.
+func benchmarkLogger(b *testing.B, log logger.Logger, pattern BenchmarkPattern) {
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		pattern.Logger(ctx, log)
+	}
+}
+
+// benchmarkZap runs the pattern with raw zap b.N times.
+func benchmarkZap(b *testing.B, log *zap.Logger, pattern BenchmarkPattern) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		pattern.Zap(log)
+	}
+}
+
+// newBenchmarkLogger creates a logger of this package writing JSON at InfoLevel to a
+// sink discarding the output.
+func newBenchmarkLogger(opts []logger.Option) (logger.Logger, error) {
+	opts = append([]logger.Option{logger.WithLevel(logger.LevelInfo), logger.WithEncoding(logger.EncodingJson)}, opts...)
+	opts = append(opts, logger.WithOutputPaths([]string{discardPath}))
+	return logger.NewLogger(opts...)
+}
+
+// newRawZap creates a zap logger writing JSON at InfoLevel to io.Discard.
+func newRawZap() *zap.Logger {
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	return zap.New(zapcore.NewCore(encoder, zapcore.AddSync(io.Discard), zapcore.InfoLevel))
+}
+
+// benchmarkFields returns the fields of a typical entry.
+func benchmarkFields() []zap.Field {
+	return []zap.Field{
+		zap.String("order_id", "ord_8f2k"),
+		zap.Int("items", 3),
+		zap.Float64("total", 129.95),
+		zap.Bool("gift", false),
+		zap.Duration("duration", 42*time.Millisecond),
+	}
+}
@@ -0,0 +1,18 @@
+package loggertest_test
+
+import (
+	"testing"
+
+	"github.com/andryhardiyanto/go-logger/loggertest"
+)
+
+// maxOverheadRatio is the maximum time per entry of the logger relative to raw zap.
+const maxOverheadRatio = 10
+
+func BenchmarkLogging(b *testing.B) {
+	loggertest.RunBenchmarks(b)
+}
+
+func TestLoggingOverhead(t *testing.T) {
+	loggertest.CheckOverhead(t, maxOverheadRatio)
+}
@@ -0,0 +1,199 @@
+package loggertest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrInjectedFault is the error of the writes and syncs failed by a FaultSink.
+var ErrInjectedFault = errors.New("loggertest: injected sink fault")
+
+type (
+	// Faults are the faults a FaultSink injects. Rates are shares of the writes or syncs,
+	// from 0 to 1, drawn from a generator seeded with Seed, so a failing test can be
+	// replayed.
+	Faults struct {
+		// Down fails every write and sync, as during an outage of the sink.
+		Down bool
+		// ErrorRate is the share of writes failing with ErrInjectedFault, writing nothing.
+		ErrorRate float64
+		// PartialRate is the share of writes writing only the first half of the entry and
+		// failing with io.ErrShortWrite.
+		PartialRate float64
+		// SyncErrorRate is the share of syncs failing with ErrInjectedFault.
+		SyncErrorRate float64
+		// Latency delays every write, as a slow network sink does.
+		Latency time.Duration
+		// Jitter adds a random delay of up to Jitter to every write.
+		Jitter time.Duration
+		// Seed seeds the random faults.
+		Seed uint64
+	}
+
+	// FaultStats counts the writes and injected faults of a FaultSink.
+	FaultStats struct {
+		// Writes counts the writes, failed or not.
+		Writes uint64
+		// Failed counts the writes failed with ErrInjectedFault.
+		Failed uint64
+		// Partial counts the partial writes.
+		Partial uint64
+		// SyncErrors counts the failed syncs.
+		SyncErrors uint64
+	}
+
+	// FaultSink is a zap.Sink injecting errors, latency and partial writes, to test how
+	// a logger's fallbacks, batching and diagnostics behave when a sink fails. Use Path
+	// as an output path of the logger under test.
+	FaultSink struct {
+		path  string
+		inner zapcore.WriteSyncer
+
+		mu     sync.Mutex
+		faults Faults
+		rand   *rand.Rand
+		out    bytes.Buffer
+		stats  FaultStats
+	}
+)
+
+// NewFaultSink returns a sink injecting the faults into the writes to inner. The bytes
+// written successfully, including those of partial writes, are kept and returned by
+// Output, so inner can be nil.
+//
+// Parameters:
+//   - inner: The sink written to, e.g. a file opened with zap.Open; nil only keeps the
+//     output in memory
+//   - faults: The faults to inject; SetFaults changes them while the test runs
+//
+// Returns:
+//   - *FaultSink: The sink
+//
+// Example:
+//
+//	primary := loggertest.NewFaultSink(nil, loggertest.Faults{ErrorRate: 0.3, Seed: 1})
+//	spool := filepath.Join(t.TempDir(), "spool.json")
+//	log, _ := logger.NewLogger(
+//	    logger.WithOutputPaths([]string{primary.Path()}),
+//	    logger.WithSinkFallback(primary.Path(), spool),
+//	)
+//	// ... log, then check the spool holds the entries the primary sink lost
+func NewFaultSink(inner zapcore.WriteSyncer, faults Faults) *FaultSink {
+	s := &FaultSink{inner: inner}
+	s.SetFaults(faults)
+	_, s.path = registerSink(s)
+	return s
+}
+
+// Path returns the output path writing to the sink.
+//
+// Returns:
+//   - string: The output path, for logger.WithOutputPaths
+func (s *FaultSink) Path() string {
+	return s.path
+}
+
+// SetFaults replaces the faults injected from now on and reseeds the random faults,
+// e.g. to end an outage and test the recovery of the sink.
+//
+// Parameters:
+//   - faults: The faults to inject
+func (s *FaultSink) SetFaults(faults Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = faults
+	s.rand = rand.New(rand.NewPCG(faults.Seed, faults.Seed))
+}
+
+// Output returns the bytes written successfully so far.
+//
+// Returns:
+//   - []byte: The output
+func (s *FaultSink) Output() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return bytes.Clone(s.out.Bytes())
+}
+
+// Stats returns the counts of writes and injected faults so far.
+//
+// Returns:
+//   - FaultStats: The counts
+func (s *FaultSink) Stats() FaultStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Write writes p after the latency unless a fault is injected.
+func (s *FaultSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	faults := s.faults
+	delay := faults.Latency
+	if faults.Jitter > 0 {
+		delay += time.Duration(s.rand.Int64N(int64(faults.Jitter)))
+	}
+	s.mu.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Writes++
+	switch {
+	case faults.Down || s.chance(faults.ErrorRate):
+		s.stats.Failed++
+		return 0, ErrInjectedFault
+	case s.chance(faults.PartialRate):
+		s.stats.Partial++
+		n, err := s.write(p[:len(p)/2])
+		if err != nil {
+			return n, err
+		}
+		return n, io.ErrShortWrite
+	default:
+		return s.write(p)
+	}
+}
+
+// Sync syncs the inner sink unless a fault is injected.
+func (s *FaultSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.faults.Down || s.chance(s.faults.SyncErrorRate) {
+		s.stats.SyncErrors++
+		return ErrInjectedFault
+	}
+	if s.inner != nil {
+		return s.inner.Sync()
+	}
+	return nil
+}
+
+// Close does nothing; the inner sink is closed by the test.
+func (s *FaultSink) Close() error {
+	return nil
+}
+
+// write writes p to the inner sink and keeps the bytes written.
+func (s *FaultSink) write(p []byte) (int, error) {
+	n := len(p)
+	var err error
+	if s.inner != nil {
+		n, err = s.inner.Write(p)
+	}
+	s.out.Write(p[:n])
+	return n, err
+}
+
+// chance reports true with the probability rate.
+func (s *FaultSink) chance(rate float64) bool {
+	return rate > 0 && s.rand.Float64() < rate
+}
@@ -0,0 +1,286 @@
+package loggertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// seeds are the corpus seeds of the fuzz harnesses: strings that tend to break
+// encoders, escaping and redaction.
+var seeds = []string{
+	"",
+	"plain",
+	"\x00",
+	"\x00\x01\x02\x1f\x7f",
+	"line\nbreak\r\nand\ttab",
+	"\u2028line and paragraph\u2029separators",
+	"\ufeffbom",
+	"\u202eright-to-left override",
+	"\u200b\u200c\u200d zero width",
+	"e\u0301\u0301\u0301 combining",
+	"\U0001F469\u200d\U0001F469\u200d\U0001F467 zwj emoji",
+	"\xff\xfe invalid utf-8",
+	"\xed\xa0\x80 surrogate half",
+	"\xc0\x80 overlong nul",
+	"\x1b[31mansi\x1b[0m",
+	`"quoted" \"escaped\" \\ backslash`,
+	`{"json":"inside"}`,
+	"</script><script>alert(1)</script>",
+	"[REDACTED]",
+	"NaN",
+	"-Inf",
+	"password",
+	"\uff30\uff21\uff33\uff33\uff37\uff2f\uff32\uff24",
+	strings.Repeat("long ", 2048),
+}
+
+// Seeds returns the corpus seeds of the fuzz harnesses, for fuzz targets of their own:
+// control characters, line and paragraph separators, invalid UTF-8, bidirectional and
+// zero-width characters, combining marks, escapes and very long values.
+//
+// Returns:
+//   - []string: The seeds
+//
+// Example:
+//
+//	func FuzzMyEnricher(f *testing.F) {
+//	    for _, seed := range loggertest.Seeds() {
+//	        f.Add(seed)
+//	    }
+//	    f.Fuzz(func(t *testing.T, value string) { ... })
+//	}
+func Seeds() []string {
+	return slices.Clone(seeds)
+}
+
+// FuzzLogger fuzzes the whole pipeline of a logger configured by the options, such as
+// its redaction, field sanitization and encoder, with field keys, values and messages
+// seeded by Seeds. Each input is logged as a message and as string, byte, nested,
+// error and reflected fields, with and without With. The output must be written
+// without panics, end with a newline and, when the logger writes JSON, consist of
+// valid JSON objects, one per line.
+//
+// Parameters:
+//   - f: The fuzz test
+//   - opts: Options of the logger under test
+//
+// Example:
+//
+//	func FuzzPipeline(f *testing.F) {
+//	    loggertest.FuzzLogger(f,
+//	        logger.WithRedactedFields("password"),
+//	        logger.WithMultilineMode(logger.MultilineArray),
+//	        logger.WithNonFiniteFloats(logger.NonFiniteNull),
+//	    )
+//	}
+func FuzzLogger(f *testing.F, opts ...logger.Option) {
+	f.Helper()
+
+	log, sink, err := newLogger(opts)
+	if err != nil {
+		f.Fatalf("failed to create logger: %v", err)
+	}
+	addSeedPairs(f)
+
+	f.Fuzz(func(t *testing.T, key, value string) {
+		ctx := context.Background()
+		log.Info(ctx, value, fuzzFields(key, value)...)
+		log.With(zap.String(key, value)).Warn(ctx, key, zap.String("value", value))
+		_ = log.GetLogger().Sync()
+
+		checkOutput(t, sink.take())
+	})
+}
+
+// FuzzRedaction fuzzes the field redaction of a logger, see logger.WithRedactedFields:
+// fields with one of the keys must be written as [REDACTED] whatever their value, at
+// the top level of the entry and when added with With, while other fields keep their
+// value.
+//
+// Parameters:
+//   - f: The fuzz test
+//   - keys: The keys of the redacted fields
+//   - opts: Further options of the logger under test
+//
+// Example:
+//
+//	func FuzzRedaction(f *testing.F) {
+//	    loggertest.FuzzRedaction(f, []string{"password", "card_number"})
+//	}
+func FuzzRedaction(f *testing.F, keys []string, opts ...logger.Option) {
+	f.Helper()
+	if len(keys) == 0 {
+		f.Fatal("FuzzRedaction needs the keys of the redacted fields")
+	}
+
+	opts = append([]logger.Option{logger.WithEncoding(logger.EncodingJson)}, opts...)
+	opts = append(opts, logger.WithRedactedFields(keys...))
+	log, sink, err := newLogger(opts)
+	if err != nil {
+		f.Fatalf("failed to create logger: %v", err)
+	}
+	for i, seed := range seeds {
+		f.Add(uint(i), seed)
+	}
+
+	f.Fuzz(func(t *testing.T, index uint, value string) {
+		key := keys[index%uint(len(keys))]
+		ctx := context.Background()
+		log.Info(ctx, "fuzz", zap.String(key, value), zap.String("kept", value))
+		log.With(zap.String(key, value)).Info(ctx, "fuzz with")
+		_ = log.GetLogger().Sync()
+
+		out := sink.take()
+		checkOutput(t, out)
+		for _, line := range bytes.Split(bytes.TrimSuffix(out, []byte("\n")), []byte("\n")) {
+			var entry map[string]any
+			if err := json.Unmarshal(line, &entry); err != nil {
+				t.Fatalf("invalid JSON entry %q: %v", line, err)
+			}
+			if got := entry[key]; got != "[REDACTED]" {
+				t.Errorf("field %q written as %q, want [REDACTED]", key, got)
+			}
+			if got, ok := entry["kept"]; ok && key != "kept" && got != sanitizedUTF8(value) {
+				t.Errorf("field kept written as %q, want %q", got, value)
+			}
+		}
+	})
+}
+
+// FuzzCore fuzzes a core wrapping the cores of this package, such as a custom
+// processor of downstream code. The wrapped core writes JSON to memory; entries
+// written through the core must not panic and must stay valid JSON, one per line.
+//
+// Parameters:
+//   - f: The fuzz test
+//   - wrap: Wraps the core writing the entries with the core under test
+//
+// Example:
+//
+//	func FuzzMaskingCore(f *testing.F) {
+//	    loggertest.FuzzCore(f, func(core zapcore.Core) zapcore.Core {
+//	        return &maskingCore{Core: core}
+//	    })
+//	}
+func FuzzCore(f *testing.F, wrap func(zapcore.Core) zapcore.Core) {
+	f.Helper()
+
+	var buf bytes.Buffer
+	encoder := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	core := wrap(zapcore.NewCore(encoder, zapcore.AddSync(&buf), zapcore.DebugLevel))
+	log := zap.New(core)
+	addSeedPairs(f)
+
+	f.Fuzz(func(t *testing.T, key, value string) {
+		buf.Reset()
+		log.Info(value, fuzzFields(key, value)...)
+		log.With(zap.String(key, value)).Warn(key)
+		_ = log.Sync()
+
+		checkOutput(t, buf.Bytes())
+	})
+}
+
+// FuzzEncoder fuzzes an encoder, such as a custom encoder of downstream code, with
+// entries whose message, logger name and fields are seeded by Seeds. Encoding must
+// not panic and the output must end with a newline; output starting with { must be a
+// valid JSON object.
+//
+// Parameters:
+//   - f: The fuzz test
+//   - encoder: The encoder under test
+//
+// Example:
+//
+//	func FuzzLogfmtEncoder(f *testing.F) {
+//	    loggertest.FuzzEncoder(f, newLogfmtEncoder(zap.NewProductionEncoderConfig()))
+//	}
+func FuzzEncoder(f *testing.F, encoder zapcore.Encoder) {
+	f.Helper()
+	addSeedPairs(f)
+
+	f.Fuzz(func(t *testing.T, key, value string) {
+		ent := zapcore.Entry{
+			Level:      zapcore.InfoLevel,
+			Time:       logger.DeterministicTime,
+			LoggerName: key,
+			Message:    value,
+		}
+		buf, err := encoder.Clone().EncodeEntry(ent, fuzzFields(key, value))
+		if err != nil {
+			return
+		}
+		defer buf.Free()
+
+		checkOutput(t, buf.Bytes())
+	})
+}
+
+// addSeedPairs adds every seed as a value and as a key to the corpus.
+func addSeedPairs(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add("field", seed)
+		f.Add(seed, "value")
+	}
+}
+
+// fuzzFields returns fields of every common type carrying the key and value.
+func fuzzFields(key, value string) []zap.Field {
+	return []zap.Field{
+		zap.String(key, value),
+		zap.ByteString("bytes", []byte(value)),
+		zap.Strings("strings", []string{value, key}),
+		zap.Any("nested", map[string]any{key: value, "depth": map[string]any{key: []any{value}}}),
+		zap.Error(errors.New(value)),
+		zap.Duration("duration", time.Duration(len(value))),
+		zap.Float64("ratio", float64(len(key))/float64(len(value))),
+	}
+}
+
+// sanitizedUTF8 returns s with every invalid byte replaced by U+FFFD, as zap encodes
+// strings.
+func sanitizedUTF8(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.WriteRune(utf8.RuneError)
+		} else {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// checkOutput fails the test when output is not newline terminated or, for JSON
+// output, when a line is not a valid JSON object.
+func checkOutput(t testing.TB, out []byte) {
+	t.Helper()
+
+	if len(out) == 0 {
+		return
+	}
+	if out[len(out)-1] != '\n' {
+		t.Fatalf("output is not newline terminated: %q", out)
+	}
+	if out[0] != '{' {
+		return
+	}
+	for _, line := range bytes.Split(out[:len(out)-1], []byte("\n")) {
+		if !json.Valid(line) || len(line) == 0 || line[0] != '{' {
+			t.Fatalf("invalid JSON entry: %q", line)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package loggertest_test
+
+import (
+	"testing"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"github.com/andryhardiyanto/go-logger/loggertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func FuzzRedaction(f *testing.F) {
+	loggertest.FuzzRedaction(f, []string{"password", "card_number"})
+}
+
+func FuzzFieldSanitization(f *testing.F) {
+	loggertest.FuzzLogger(f,
+		logger.WithEncoding(logger.EncodingJson),
+		logger.WithRedactedFields("password"),
+		logger.WithMultilineMode(logger.MultilineArray),
+		logger.WithNonFiniteFloats(logger.NonFiniteNull),
+		logger.WithMaxFieldDepth(2),
+	)
+}
+
+func FuzzJSONEncoder(f *testing.F) {
+	loggertest.FuzzLogger(f, logger.WithEncoding(logger.EncodingJson))
+}
+
+func FuzzConsoleEncoder(f *testing.F) {
+	loggertest.FuzzLogger(f,
+		logger.WithEncoding(logger.EncodingConsole),
+		logger.WithMultilineMode(logger.MultilineEscaped),
+	)
+}
+
+func FuzzContainerEncoder(f *testing.F) {
+	loggertest.FuzzLogger(f, logger.WithContainerOutput(1024))
+}
+
+func FuzzZapJSONEncoder(f *testing.F) {
+	loggertest.FuzzEncoder(f, zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()))
+}
@@ -0,0 +1,111 @@
+// Package loggertest provides helpers for testing the logging behavior of code using
+// go-logger, such as golden-file tests of redaction and schema changes.
+package loggertest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/andryhardiyanto/go-logger"
+)
+
+// update rewrites golden files with the current output instead of comparing it.
+var update = flag.Bool("update-golden", false, "rewrite the golden files of loggertest.Golden")
+
+// Golden runs fn with a logger writing deterministic output, see
+// logger.WithDeterministicOutput, and compares the output with the golden file
+// testdata/<test name>.golden. Run the tests with -update-golden to write the golden
+// files after reviewing a change of the output. The options configure the logger under
+// test, e.g. its redacted fields or schema version; its output paths are set by Golden.
+//
+// Parameters:
+//   - t: The test
+//   - fn: Logs the entries to compare
+//   - opts: Options of the logger under test
+//
+// Example:
+//
+//	func TestLoginRedaction(t *testing.T) {
+//	    loggertest.Golden(t, func(log logger.Logger) {
+//	        log.Info(context.Background(), "login", zap.String("password", "hunter2"))
+//	    }, logger.WithRedactedFields("password"))
+//	}
+func Golden(t testing.TB, fn func(logger.Logger), opts ...logger.Option) {
+	t.Helper()
+
+	got := Capture(t, fn, opts...)
+	path := filepath.Join("testdata", filepath.FromSlash(t.Name())+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run with -update-golden to create it: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("log output differs from %s, run with -update-golden to accept it:\n%s", path, lineDiff(want, got))
+	}
+}
+
+// Capture runs fn with a logger writing deterministic output and returns the output.
+//
+// Parameters:
+//   - t: The test
+//   - fn: Logs the entries to capture
+//   - opts: Options of the logger under test
+//
+// Returns:
+//   - []byte: The entries written by fn, one per line
+func Capture(t testing.TB, fn func(logger.Logger), opts ...logger.Option) []byte {
+	t.Helper()
+
+	log, sink, err := newLogger(opts)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	fn(log)
+	_ = log.GetLogger().Sync()
+	return sink.take()
+}
+
+// newLogger creates a logger writing deterministic output to an in-memory sink.
+func newLogger(opts []logger.Option) (logger.Logger, *memorySink, error) {
+	sink, path := newMemorySink()
+	opts = append([]logger.Option{logger.WithDeterministicOutput()}, opts...)
+	opts = append(opts, logger.WithOutputPaths([]string{path}))
+	log, err := logger.NewLogger(opts...)
+	return log, sink, err
+}
+
+// lineDiff describes the lines of want and got that differ.
+func lineDiff(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+
+	var b strings.Builder
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			b.WriteString("- " + w + "\n+ " + g + "\n")
+		}
+	}
+	return b.String()
+}
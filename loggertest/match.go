@@ -0,0 +1,489 @@
+package loggertest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys of the entries written by the loggers of Capture and CaptureEntries.
+const (
+	entryLevelKey   = "level"
+	entryMessageKey = "message"
+)
+
+type (
+	// Entry is a JSON entry decoded for assertions with matchers.
+	Entry struct {
+		// Level is the level of the entry; InfoLevel when it has none.
+		Level zapcore.Level
+		// Message is the message of the entry.
+		Message string
+		// Fields are the keys and values of the entry, including its level and message.
+		// Numbers are float64, objects map[string]any and arrays []any.
+		Fields map[string]any
+		// Raw is the entry as written.
+		Raw string
+	}
+
+	// Matcher is a condition on an entry, combined with All, Any and Not and checked by
+	// AssertLogged and AssertNotLogged.
+	Matcher interface {
+		// Match reports whether the entry matches and, when it does not, describes what
+		// the entry holds instead.
+		Match(e Entry) (ok bool, got string)
+		// String describes the condition.
+		String() string
+	}
+
+	// matcher implements Matcher with a function.
+	matcher struct {
+		desc  string
+		match func(e Entry) (bool, string)
+	}
+)
+
+// CaptureEntries runs fn with a logger writing deterministic JSON output, like Capture,
+// and returns the decoded entries.
+//
+// Parameters:
+//   - t: The test
+//   - fn: Logs the entries to capture
+//   - opts: Options of the logger under test
+//
+// Returns:
+//   - []Entry: The entries written by fn
+//
+// Example:
+//
+//	entries := loggertest.CaptureEntries(t, func(log logger.Logger) { svc.Run(ctx, log) })
+//	loggertest.AssertLogged(t, entries, loggertest.LevelAtLeast(logger.LevelWarning), loggertest.HasTraceID())
+func CaptureEntries(t testing.TB, fn func(logger.Logger), opts ...logger.Option) []Entry {
+	t.Helper()
+	return ParseEntries(t, Capture(t, fn, opts...))
+}
+
+// ParseEntries decodes JSON entries, one per line, e.g. the output of Capture or of a
+// log file. Blank lines are skipped; other lines that are not JSON objects fail t.
+//
+// Parameters:
+//   - t: The test
+//   - out: The entries, one JSON object per line
+//
+// Returns:
+//   - []Entry: The decoded entries
+func ParseEntries(t testing.TB, out []byte) []Entry {
+	t.Helper()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(nil, len(out)+1)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		entry, err := parseEntry(line)
+		if err != nil {
+			t.Fatalf("line %d is not a JSON entry: %v\n%s", n, err, line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parseEntry decodes a JSON entry.
+func parseEntry(line string) (Entry, error) {
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(line), &fields); err != nil {
+		return Entry{}, err
+	}
+	entry := Entry{Level: zapcore.InfoLevel, Fields: fields, Raw: line}
+	if level, ok := fields[entryLevelKey].(string); ok {
+		parsed, err := zapcore.ParseLevel(level)
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid level %q", level)
+		}
+		entry.Level = parsed
+	}
+	entry.Message, _ = fields[entryMessageKey].(string)
+	return entry, nil
+}
+
+// Field returns the value of the field with the key. Keys not found at the top level
+// are looked up as dotted paths into objects, e.g. http.status in {"http":{"status":200}}.
+//
+// Parameters:
+//   - key: The key of the field
+//
+// Returns:
+//   - any: The value of the field
+//   - bool: Whether the entry has the field
+func (e Entry) Field(key string) (any, bool) {
+	if value, ok := e.Fields[key]; ok {
+		return value, true
+	}
+	var value any = e.Fields
+	for _, part := range strings.Split(key, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Match calls the function of the matcher.
+func (m matcher) Match(e Entry) (bool, string) {
+	return m.match(e)
+}
+
+// String returns the description of the matcher.
+func (m matcher) String() string {
+	return m.desc
+}
+
+// FieldEquals matches entries whose field has the value, compared after a JSON round
+// trip, so FieldEquals("status", 200) matches the decoded number 200.
+//
+// Parameters:
+//   - key: The key of the field, or a dotted path into objects
+//   - value: The expected value
+//
+// Returns:
+//   - Matcher: The matcher
+func FieldEquals(key string, value any) Matcher {
+	want, err := normalize(value)
+	return matcher{
+		desc: fmt.Sprintf("field %s = %s", key, formatValue(value)),
+		match: func(e Entry) (bool, string) {
+			got, ok := e.Field(key)
+			if !ok {
+				return false, fmt.Sprintf("field %s missing", key)
+			}
+			if err != nil || !reflect.DeepEqual(got, want) {
+				return false, fmt.Sprintf("field %s = %s", key, formatValue(got))
+			}
+			return true, ""
+		},
+	}
+}
+
+// FieldMatchesRegexp matches entries whose field, a string or the JSON of another value,
+// matches the regular expression.
+//
+// Parameters:
+//   - key: The key of the field, or a dotted path into objects
+//   - pattern: The regular expression; an invalid one panics
+//
+// Returns:
+//   - Matcher: The matcher
+func FieldMatchesRegexp(key, pattern string) Matcher {
+	re := regexp.MustCompile(pattern)
+	return matcher{
+		desc: fmt.Sprintf("field %s =~ /%s/", key, pattern),
+		match: func(e Entry) (bool, string) {
+			got, ok := e.Field(key)
+			if !ok {
+				return false, fmt.Sprintf("field %s missing", key)
+			}
+			s, isString := got.(string)
+			if !isString {
+				s = formatValue(got)
+			}
+			if !re.MatchString(s) {
+				return false, fmt.Sprintf("field %s = %s", key, formatValue(got))
+			}
+			return true, ""
+		},
+	}
+}
+
+// HasField matches entries having the field, whatever its value.
+//
+// Parameters:
+//   - key: The key of the field, or a dotted path into objects
+//
+// Returns:
+//   - Matcher: The matcher
+func HasField(key string) Matcher {
+	return matcher{
+		desc: fmt.Sprintf("field %s present", key),
+		match: func(e Entry) (bool, string) {
+			if _, ok := e.Field(key); !ok {
+				return false, fmt.Sprintf("field %s missing", key)
+			}
+			return true, ""
+		},
+	}
+}
+
+// HasTraceID matches entries with a non-empty trace_id field, i.e. entries logged with
+// a context carrying a trace.
+//
+// Returns:
+//   - Matcher: The matcher
+func HasTraceID() Matcher {
+	return FieldMatchesRegexp(string(logger.ContextKeyTraceID), `.`)
+}
+
+// LevelAtLeast matches entries at the level or above.
+//
+// Parameters:
+//   - level: The minimum level
+//
+// Returns:
+//   - Matcher: The matcher
+func LevelAtLeast(level logger.Level) Matcher {
+	minLevel := zapLevel(level)
+	return matcher{
+		desc: fmt.Sprintf("level >= %s", minLevel.CapitalString()),
+		match: func(e Entry) (bool, string) {
+			if e.Level < minLevel {
+				return false, fmt.Sprintf("level %s", e.Level.CapitalString())
+			}
+			return true, ""
+		},
+	}
+}
+
+// MessageEquals matches entries with the message.
+//
+// Parameters:
+//   - message: The expected message
+//
+// Returns:
+//   - Matcher: The matcher
+func MessageEquals(message string) Matcher {
+	return matcher{
+		desc: fmt.Sprintf("message = %q", message),
+		match: func(e Entry) (bool, string) {
+			if e.Message != message {
+				return false, fmt.Sprintf("message = %q", e.Message)
+			}
+			return true, ""
+		},
+	}
+}
+
+// All matches entries matching every matcher.
+//
+// Parameters:
+//   - matchers: The matchers
+//
+// Returns:
+//   - Matcher: The matcher
+func All(matchers ...Matcher) Matcher {
+	return matcher{
+		desc: joinMatchers(matchers, " and "),
+		match: func(e Entry) (bool, string) {
+			var mismatches []string
+			for _, m := range matchers {
+				if ok, got := m.Match(e); !ok {
+					mismatches = append(mismatches, got)
+				}
+			}
+			return len(mismatches) == 0, strings.Join(mismatches, ", ")
+		},
+	}
+}
+
+// Any matches entries matching at least one matcher.
+//
+// Parameters:
+//   - matchers: The matchers
+//
+// Returns:
+//   - Matcher: The matcher
+func Any(matchers ...Matcher) Matcher {
+	return matcher{
+		desc: joinMatchers(matchers, " or "),
+		match: func(e Entry) (bool, string) {
+			var mismatches []string
+			for _, m := range matchers {
+				ok, got := m.Match(e)
+				if ok {
+					return true, ""
+				}
+				mismatches = append(mismatches, got)
+			}
+			return false, strings.Join(mismatches, ", ")
+		},
+	}
+}
+
+// Not matches entries not matching the matcher.
+//
+// Parameters:
+//   - m: The matcher
+//
+// Returns:
+//   - Matcher: The matcher
+func Not(m Matcher) Matcher {
+	return matcher{
+		desc: "not (" + m.String() + ")",
+		match: func(e Entry) (bool, string) {
+			if ok, _ := m.Match(e); ok {
+				return false, m.String()
+			}
+			return true, ""
+		},
+	}
+}
+
+// AssertLogged fails the test unless an entry matches every matcher. The failure lists
+// the conditions and, for the entry matching the most of them, a diff of what was
+// expected and what the entry holds.
+//
+// Parameters:
+//   - t: The test
+//   - entries: The entries, e.g. from CaptureEntries
+//   - matchers: The conditions the entry must meet
+//
+// Returns:
+//   - Entry: The first matching entry
+//   - bool: Whether an entry matched
+//
+// Example:
+//
+//	loggertest.AssertLogged(t, entries,
+//	    loggertest.MessageEquals("payment failed"),
+//	    loggertest.LevelAtLeast(logger.LevelError),
+//	    loggertest.FieldEquals("order_id", "o-42"),
+//	)
+//	// no entry matches:
+//	//   message = "payment failed"
+//	//   level >= ERROR
+//	//   field order_id = "o-42"
+//	// closest entry #3: {"level":"WARN","message":"payment failed","order_id":"o-41"}
+//	//   - level >= ERROR
+//	//   + level WARN
+//	//   - field order_id = "o-42"
+//	//   + field order_id = "o-41"
+func AssertLogged(t testing.TB, entries []Entry, matchers ...Matcher) (Entry, bool) {
+	t.Helper()
+
+	best, bestScore := -1, -1
+	for i, e := range entries {
+		score := 0
+		for _, m := range matchers {
+			if ok, _ := m.Match(e); ok {
+				score++
+			}
+		}
+		if score == len(matchers) {
+			return e, true
+		}
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("no entry matches:\n")
+	for _, m := range matchers {
+		b.WriteString("  " + m.String() + "\n")
+	}
+	if best < 0 {
+		b.WriteString("no entries were logged")
+	} else {
+		e := entries[best]
+		fmt.Fprintf(&b, "closest entry #%d: %s\n", best+1, e.Raw)
+		for _, m := range matchers {
+			if ok, got := m.Match(e); !ok {
+				b.WriteString("  - " + m.String() + "\n  + " + got + "\n")
+			}
+		}
+	}
+	t.Errorf("%s", strings.TrimSuffix(b.String(), "\n"))
+	return Entry{}, false
+}
+
+// AssertNotLogged fails the test if an entry matches every matcher, listing the
+// matching entries.
+//
+// Parameters:
+//   - t: The test
+//   - entries: The entries, e.g. from CaptureEntries
+//   - matchers: The conditions no entry may meet together
+//
+// Returns:
+//   - bool: Whether no entry matched
+//
+// Example:
+//
+//	loggertest.AssertNotLogged(t, entries, loggertest.LevelAtLeast(logger.LevelError))
+func AssertNotLogged(t testing.TB, entries []Entry, matchers ...Matcher) bool {
+	t.Helper()
+
+	m := All(matchers...)
+	var matching []string
+	for i, e := range entries {
+		if ok, _ := m.Match(e); ok {
+			matching = append(matching, fmt.Sprintf("  entry #%d: %s", i+1, e.Raw))
+		}
+	}
+	if len(matching) == 0 {
+		return true
+	}
+	t.Errorf("entries matching %s:\n%s", m, strings.Join(matching, "\n"))
+	return false
+}
+
+// joinMatchers describes matchers joined by sep.
+func joinMatchers(matchers []Matcher, sep string) string {
+	descs := make([]string, len(matchers))
+	for i, m := range matchers {
+		descs[i] = m.String()
+	}
+	return "(" + strings.Join(descs, sep) + ")"
+}
+
+// normalize returns value as decoded from its JSON, as entry fields are.
+func normalize(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+// formatValue writes a value as JSON, or with %v when it cannot be.
+func formatValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// zapLevel returns the zap level of a logger level.
+func zapLevel(level logger.Level) zapcore.Level {
+	switch logger.LevelFromName(string(level)) {
+	case logger.LevelDebug:
+		return zapcore.DebugLevel
+	case logger.LevelWarning:
+		return zapcore.WarnLevel
+	case logger.LevelError:
+		return zapcore.ErrorLevel
+	case logger.LevelPanic:
+		return zapcore.PanicLevel
+	case logger.LevelFatal:
+		return zapcore.FatalLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
@@ -0,0 +1,156 @@
+package loggertest
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// sinkScheme is the URL scheme of the in-memory sinks loggers under test write to.
+const sinkScheme = "loggertest"
+
+// discardPath is the output path of the sink discarding the output, for benchmarks.
+const discardPath = sinkScheme + ":discard"
+
+type (
+	// memorySink is an in-memory zap.Sink collecting the output of a logger under test.
+	memorySink struct {
+		mu  sync.Mutex
+		buf bytes.Buffer
+	}
+
+	// discardSink is a zap.Sink discarding the output.
+	discardSink struct{}
+
+	// testSink is a zap.Sink writing every entry to the log of a test with t.Logf.
+	testSink struct {
+		mu   sync.Mutex
+		t    testing.TB
+		done bool
+	}
+)
+
+var (
+	sinksMu   sync.Mutex
+	sinks     = map[string]zap.Sink{}
+	sinkCount atomic.Uint64
+)
+
+func init() {
+	_ = zap.RegisterSink(sinkScheme, func(u *url.URL) (zap.Sink, error) {
+		if u.Opaque == "discard" {
+			return discardSink{}, nil
+		}
+
+		sinksMu.Lock()
+		defer sinksMu.Unlock()
+		sink, ok := sinks[u.Opaque]
+		if !ok {
+			return nil, fmt.Errorf("unknown loggertest sink %q", u.Opaque)
+		}
+		return sink, nil
+	})
+}
+
+// newMemorySink returns a new in-memory sink and the output path writing to it.
+func newMemorySink() (*memorySink, string) {
+	sink := &memorySink{}
+	_, path := registerSink(sink)
+	return sink, path
+}
+
+// newTestSink returns a sink writing to the log of t and the output path writing to it.
+// The sink is removed and stops writing when the test ends, as t.Logf panics once it has.
+func newTestSink(t testing.TB) string {
+	sink := &testSink{t: t}
+	name, path := registerSink(sink)
+	t.Cleanup(func() {
+		sink.mu.Lock()
+		sink.done = true
+		sink.mu.Unlock()
+
+		sinksMu.Lock()
+		delete(sinks, name)
+		sinksMu.Unlock()
+	})
+	return path
+}
+
+// registerSink registers a sink under a new name and returns the name and the output
+// path writing to it.
+func registerSink(sink zap.Sink) (string, string) {
+	name := fmt.Sprintf("sink%d", sinkCount.Add(1))
+
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks[name] = sink
+	return name, sinkScheme + ":" + name
+}
+
+// Write appends p to the output.
+func (s *memorySink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.Write(p)
+}
+
+// Sync does nothing, as the output is in memory.
+func (s *memorySink) Sync() error {
+	return nil
+}
+
+// Close does nothing; the output stays readable.
+func (s *memorySink) Close() error {
+	return nil
+}
+
+// take returns the output written so far and resets it.
+func (s *memorySink) take() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := bytes.Clone(s.buf.Bytes())
+	s.buf.Reset()
+	return out
+}
+
+// Write writes p to the log of the test, without the trailing line ending t.Logf adds.
+// Entries written once the test has ended are dropped.
+func (s *testSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.done {
+		s.t.Logf("%s", strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+// Sync does nothing, as t.Logf is unbuffered.
+func (s *testSink) Sync() error {
+	return nil
+}
+
+// Close does nothing; the sink is removed when the test ends.
+func (s *testSink) Close() error {
+	return nil
+}
+
+// Write discards p.
+func (discardSink) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// Sync does nothing.
+func (discardSink) Sync() error {
+	return nil
+}
+
+// Close does nothing.
+func (discardSink) Close() error {
+	return nil
+}
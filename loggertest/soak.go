@@ -0,0 +1,271 @@
+package loggertest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"go.uber.org/zap"
+)
+
+// Defaults of SoakConfig.
+const (
+	defaultSoakDuration   = 10 * time.Second
+	defaultSoakEntryBytes = 256
+	// soakSamples is the number of enqueue latencies sampled per goroutine.
+	soakSamples = 10000
+	// soakCloseTimeout bounds the flush of the pipeline once the load stops.
+	soakCloseTimeout = 30 * time.Second
+)
+
+type (
+	// SoakConfig configures the load Soak pushes through a batched pipeline.
+	SoakConfig struct {
+		// Rate is the number of entries per second over all goroutines; zero logs as fast
+		// as possible.
+		Rate int
+		// Duration is how long the load runs; zero runs 10s, a tenth with -short.
+		Duration time.Duration
+		// Goroutines is the number of goroutines logging; zero uses GOMAXPROCS.
+		Goroutines int
+		// EntryBytes is the size of the payload field of every entry; zero uses 256.
+		EntryBytes int
+		// Batch is the batching of the sink, the queue being tuned.
+		Batch logger.BatchConfig
+		// SinkLatency delays every write of the sink, as a slow collector does.
+		SinkLatency time.Duration
+		// Options are further options of the logger, e.g. an ingest budget or sampling.
+		// Its output paths and batching are set by Soak.
+		Options []logger.Option
+	}
+
+	// SoakReport is the result of a soak test.
+	SoakReport struct {
+		// Duration is how long the load ran.
+		Duration time.Duration
+		// Entries counts the entries logged.
+		Entries uint64
+		// Written counts the entries that reached the sink once the pipeline was flushed.
+		Written uint64
+		// DropRate is the share of the logged entries that never reached the sink.
+		DropRate float64
+		// DroppedBatches counts the batches dropped by the pipeline.
+		DroppedBatches uint64
+		// Throughput is the number of entries logged per second.
+		Throughput float64
+		// BytesPerSecond is the number of bytes written to the sink per second.
+		BytesPerSecond float64
+		// EnqueueP50 is the median time a log call took to hand its entry to the pipeline.
+		EnqueueP50 time.Duration
+		// EnqueueP99 is the 99th percentile of the time of log calls.
+		EnqueueP99 time.Duration
+		// EnqueueMax is the longest log call.
+		EnqueueMax time.Duration
+	}
+
+	// soakSink counts the entries and bytes written to it, after an optional latency.
+	soakSink struct {
+		latency time.Duration
+		entries atomic.Uint64
+		bytes   atomic.Uint64
+	}
+
+	// soakSampler keeps a uniform sample of the enqueue latencies of a goroutine.
+	soakSampler struct {
+		rand    *rand.Rand
+		seen    int
+		samples []time.Duration
+		max     time.Duration
+	}
+)
+
+// Soak pushes entries at the configured rate and size through a logger whose sink is
+// batched, then flushes the pipeline and reports the throughput, the latency of log
+// calls and the share of entries lost, so the batch size and flush interval can be
+// tuned with data rather than guesses. Run it with different BatchConfig values and
+// sink latencies and compare the reports. The report is also written to the test log.
+//
+// Parameters:
+//   - t: The test or benchmark
+//   - config: The load, the batching and the options of the logger
+//
+// Returns:
+//   - SoakReport: The measurements
+//
+// Example:
+//
+//	func TestBatchCapacity(t *testing.T) {
+//	    report := loggertest.Soak(t, loggertest.SoakConfig{
+//	        Rate:        50000,
+//	        Duration:    30 * time.Second,
+//	        Batch:       logger.BatchConfig{MaxEntries: 1000, FlushInterval: 200 * time.Millisecond},
+//	        SinkLatency: 5 * time.Millisecond,
+//	    })
+//	    if report.DropRate > 0 || report.EnqueueP99 > time.Millisecond {
+//	        t.Errorf("batching cannot keep up: %s", report)
+//	    }
+//	}
+func Soak(t testing.TB, config SoakConfig) SoakReport {
+	t.Helper()
+
+	duration := config.Duration
+	if duration <= 0 {
+		duration = defaultSoakDuration
+		if testing.Short() {
+			duration /= 10
+		}
+	}
+	goroutines := config.Goroutines
+	if goroutines <= 0 {
+		goroutines = runtime.GOMAXPROCS(0)
+	}
+	entryBytes := config.EntryBytes
+	if entryBytes <= 0 {
+		entryBytes = defaultSoakEntryBytes
+	}
+
+	sink := &soakSink{latency: config.SinkLatency}
+	_, path := registerSink(sink)
+	opts := append(slices.Clone(config.Options),
+		logger.WithEncoding(logger.EncodingJson),
+		logger.WithOutputPaths([]string{path}),
+		logger.WithBatching(path, config.Batch),
+	)
+	log, err := logger.NewLogger(opts...)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		entries  atomic.Uint64
+		samplers = make([]*soakSampler, goroutines)
+		payload  = strings.Repeat("x", entryBytes)
+		interval time.Duration
+	)
+	if config.Rate > 0 {
+		interval = time.Duration(float64(time.Second) * float64(goroutines) / float64(config.Rate))
+	}
+	start := time.Now()
+	deadline := start.Add(duration)
+	for g := range goroutines {
+		samplers[g] = newSoakSampler(uint64(g))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.Background()
+			for i := 0; ; i++ {
+				now := time.Now()
+				if !now.Before(deadline) {
+					return
+				}
+				// Paced goroutines sleep while ahead of their schedule, so the rate holds
+				// even when log calls stall.
+				if interval > 0 {
+					if ahead := start.Add(time.Duration(i) * interval).Sub(now); ahead > 0 {
+						time.Sleep(ahead)
+					}
+				}
+				begin := time.Now()
+				log.Info(ctx, "soak entry", zap.Int("goroutine", g), zap.Int("seq", i), zap.String("payload", payload))
+				samplers[g].add(time.Since(begin))
+				entries.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	ctx, cancel := context.WithTimeout(context.Background(), soakCloseTimeout)
+	defer cancel()
+	droppedBatches := logger.GetStats(log).DroppedBatches
+	if err := logger.Close(ctx, log); err != nil {
+		t.Errorf("failed to flush the pipeline: %v", err)
+	}
+	droppedBatches = max(droppedBatches, logger.GetStats(log).DroppedBatches)
+
+	report := SoakReport{
+		Duration:       elapsed,
+		Entries:        entries.Load(),
+		Written:        sink.entries.Load(),
+		DroppedBatches: droppedBatches,
+		Throughput:     float64(entries.Load()) / elapsed.Seconds(),
+		BytesPerSecond: float64(sink.bytes.Load()) / elapsed.Seconds(),
+	}
+	if report.Entries > 0 && report.Written < report.Entries {
+		report.DropRate = float64(report.Entries-report.Written) / float64(report.Entries)
+	}
+	report.EnqueueP50, report.EnqueueP99, report.EnqueueMax = soakLatencies(samplers)
+	t.Logf("soak: %s", report)
+	return report
+}
+
+// String summarizes the report on one line.
+func (r SoakReport) String() string {
+	return fmt.Sprintf("%d entries in %s (%.0f/s, %.1f MB/s), enqueue p50 %s p99 %s max %s, %d written, drop rate %.4f%%, %d dropped batches",
+		r.Entries, r.Duration.Round(time.Millisecond), r.Throughput, r.BytesPerSecond/1e6,
+		r.EnqueueP50, r.EnqueueP99, r.EnqueueMax, r.Written, r.DropRate*100, r.DroppedBatches)
+}
+
+// newSoakSampler creates the latency sampler of a goroutine.
+func newSoakSampler(seed uint64) *soakSampler {
+	return &soakSampler{rand: rand.New(rand.NewPCG(seed, seed)), samples: make([]time.Duration, 0, soakSamples)}
+}
+
+// add samples a latency, keeping a uniform sample of at most soakSamples latencies.
+func (s *soakSampler) add(d time.Duration) {
+	s.seen++
+	s.max = max(s.max, d)
+	if len(s.samples) < soakSamples {
+		s.samples = append(s.samples, d)
+		return
+	}
+	if i := s.rand.IntN(s.seen); i < soakSamples {
+		s.samples[i] = d
+	}
+}
+
+// soakLatencies returns the median, 99th percentile and maximum of the sampled latencies.
+// Goroutines are weighted by their samples, which is exact while no goroutine logs more
+// than soakSamples entries and close otherwise.
+func soakLatencies(samplers []*soakSampler) (p50, p99, maxLatency time.Duration) {
+	var all []time.Duration
+	for _, s := range samplers {
+		all = append(all, s.samples...)
+		maxLatency = max(maxLatency, s.max)
+	}
+	if len(all) == 0 {
+		return 0, 0, 0
+	}
+	slices.Sort(all)
+	return all[len(all)/2], all[(len(all)*99)/100], maxLatency
+}
+
+// Write counts the entries of p after the latency of the sink.
+func (s *soakSink) Write(p []byte) (int, error) {
+	if s.latency > 0 {
+		time.Sleep(s.latency)
+	}
+	s.entries.Add(uint64(bytes.Count(p, []byte{'\n'})))
+	s.bytes.Add(uint64(len(p)))
+	return len(p), nil
+}
+
+// Sync does nothing.
+func (s *soakSink) Sync() error {
+	return nil
+}
+
+// Close does nothing.
+func (s *soakSink) Close() error {
+	return nil
+}
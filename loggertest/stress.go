@@ -0,0 +1,169 @@
+package loggertest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// stressGoroutines is the number of goroutines started by Stress.
+	stressGoroutines = 256
+	// stressIterations is the number of operations of each goroutine, a tenth with -short.
+	stressIterations = 200
+	// stressContextKeys is the number of context keys registered by Stress.
+	stressContextKeys = 8
+)
+
+// stressConfigs are the configuration files a Stress hot-reload goroutine alternates
+// between, see logger.WatchConfigFile.
+var stressConfigs = []string{
+	"level: debug\nsampling:\n  initial: 0\n  thereafter: 0\nredacted_fields: [secret]\n",
+	"level: warning\nsampling:\n  initial: 10\n  thereafter: 5\n",
+	"level: info\nredacted_fields: [secret, token]\n",
+}
+
+// stressRequestKey is the context key Stress sets on every entry.
+const stressRequestKey = logger.ContextKey("request_id")
+
+// Stress is a concurrency stress test of a logger configured by the options, meant to
+// run with -race. Hundreds of goroutines log at every level through the logger, child
+// loggers created with With, Named and WithOptions and the named logger registry, while
+// others change the level, sampling and redaction at runtime, hot-reload them from a
+// configuration file with WatchConfigFile, set per-name levels, register context keys
+// with AppendContextKeys and read GetStats. The test fails on a data race reported by
+// the race detector, a panic, or output that is not valid JSON, one entry per line.
+//
+// Stress makes the logger under test the root of the named logger registry, see
+// logger.SetRoot, and registers the context keys stress_key_0 to stress_key_7. With
+// -short, each goroutine does a tenth of the work.
+//
+// Parameters:
+//   - t: The test
+//   - opts: Options of the logger under test; its output paths and encoding are set by
+//     Stress
+//
+// Example:
+//
+//	func TestLoggerConcurrency(t *testing.T) {
+//	    loggertest.Stress(t, logger.WithRedactedFields("password"))
+//	}
+//	// go test -race -run LoggerConcurrency
+func Stress(t *testing.T, opts ...logger.Option) {
+	t.Helper()
+
+	sink, path := newMemorySink()
+	opts = append(opts, logger.WithEncoding(logger.EncodingJson), logger.WithOutputPaths([]string{path}))
+	log, err := logger.NewLogger(opts...)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	logger.SetRoot(log)
+
+	iterations := stressIterations
+	if testing.Short() {
+		iterations /= 10
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watched := make(chan struct{})
+	config := filepath.Join(t.TempDir(), "logger.yaml")
+	go func() {
+		defer close(watched)
+		_ = logger.WatchConfigFile(ctx, log, config, time.Millisecond)
+	}()
+
+	var (
+		wg     sync.WaitGroup
+		panics = make(chan string, stressGoroutines)
+	)
+	for g := range stressGoroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					panics <- fmt.Sprintf("goroutine %d: %v", g, r)
+				}
+			}()
+			for i := range iterations {
+				stressStep(t, log, config, g, i)
+			}
+		}()
+	}
+	wg.Wait()
+	cancel()
+	<-watched
+	close(panics)
+
+	for p := range panics {
+		t.Errorf("panic under concurrency: %s", p)
+	}
+	_ = log.GetLogger().Sync()
+	out := sink.take()
+	if len(out) == 0 {
+		t.Fatal("no entries written")
+	}
+	checkOutput(t, out)
+}
+
+// stressStep runs the i-th operation of goroutine g; the role of a goroutine is chosen
+// by g, so every operation runs concurrently with all the others.
+func stressStep(t *testing.T, log logger.Logger, config string, g, i int) {
+	ctx := context.WithValue(context.Background(), stressRequestKey, fmt.Sprintf("req-%d-%d", g, i))
+	ctx = context.WithValue(ctx, logger.ContextKey(fmt.Sprintf("stress_key_%d", i%stressContextKeys)), i)
+	fields := []zap.Field{zap.Int("goroutine", g), zap.Int("iteration", i), zap.String("secret", "hunter2")}
+
+	switch g % 10 {
+	case 0:
+		log.Info(ctx, "stress info", fields...)
+		log.Debug(ctx, "stress debug", fields...)
+	case 1:
+		log.With(zap.Int("child", g)).With(zap.String("token", "t0k3n")).Warn(ctx, "stress child", fields...)
+	case 2:
+		name := fmt.Sprintf("stress.worker%d", g%4)
+		logger.Get(name).Info(ctx, "stress named", fields...)
+		log.GetLogger().Named(name).Info("stress zap named", fields...)
+	case 3:
+		child, err := log.WithOptions(logger.WithRedactedFields("token"), logger.WithLevel(logger.LevelDebug))
+		if err != nil {
+			t.Errorf("WithOptions failed: %v", err)
+			return
+		}
+		child.Debug(ctx, "stress derived", fields...)
+		_ = child.GetLogger().Sync()
+	case 4:
+		levels := []logger.Level{logger.LevelDebug, logger.LevelInfo, logger.LevelWarning}
+		_ = logger.SetLevel(ctx, log, levels[i%len(levels)])
+		log.Error(ctx, "stress error", fields...)
+	case 5:
+		_ = logger.SetSampling(ctx, log, i%3, i%5)
+		_ = logger.SetRedactedFields(ctx, log, "secret", fmt.Sprintf("key%d", i%3))
+	case 6:
+		if err := os.WriteFile(config, []byte(stressConfigs[i%len(stressConfigs)]), 0o644); err != nil {
+			t.Errorf("failed to write config file: %v", err)
+		}
+	case 7:
+		levels := []logger.Level{logger.LevelDebug, logger.LevelError, ""}
+		_ = logger.SetLoggerLevel(ctx, fmt.Sprintf("stress.worker%d", g%4), levels[i%len(levels)])
+		_ = logger.Loggers()
+	case 8:
+		if i < stressContextKeys {
+			logger.AppendContextKeys(logger.ContextKey(fmt.Sprintf("stress_key_%d", i)))
+		}
+		_ = logger.GetLoggingFields(ctx)
+		log.Info(ctx, "stress context keys", fields...)
+	default:
+		log.Once(ctx, fmt.Sprintf("once-%d", i%16), "stress once", fields...)
+		log.Every(ctx, "every", time.Millisecond, "stress every", fields...)
+		_ = logger.GetStats(log)
+	}
+}
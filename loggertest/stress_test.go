@@ -0,0 +1,22 @@
+package loggertest_test
+
+import (
+	"testing"
+	"time"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"github.com/andryhardiyanto/go-logger/loggertest"
+)
+
+// Run with go test -race -run Stress ./loggertest.
+func TestStress(t *testing.T) {
+	loggertest.Stress(t)
+}
+
+func TestStressWithRedactionAndRateLimits(t *testing.T) {
+	loggertest.Stress(t,
+		logger.WithRedactedFields("password"),
+		logger.WithErrorStormSuppression(10, time.Second, time.Second),
+		logger.WithCallSiteRateLimit(1000, 100),
+	)
+}
@@ -0,0 +1,134 @@
+package loggertest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// TBOption configures a logger created by NewTB.
+	TBOption func(*tbConfig)
+
+	// tbConfig is the configuration of NewTB.
+	tbConfig struct {
+		// failOnError fails the test on entries at error level and above.
+		failOnError bool
+		// options are the options of the logger.
+		options []logger.Option
+	}
+
+	// tbFailer fails a test on error entries until the test ends.
+	tbFailer struct {
+		mu   sync.Mutex
+		tb   testing.TB
+		done bool
+	}
+)
+
+// NewTB returns a logger writing to the log of a test with tb.Logf, so entries are
+// shown under the test that logged them, only for failing tests and in -v runs. Every
+// test gets its own logger, closed when the test ends; entries logged afterwards, e.g.
+// by goroutines the test leaked, are dropped instead of panicking. t.Logf attributes
+// lines to the adapter, so entries are console encoded with the caller of the log call
+// and without a time. The options of the logger are set with WithOptions.
+//
+// Parameters:
+//   - tb: The test
+//   - opts: Variable number of TBOption functions, e.g. WithFailOnError
+//
+// Returns:
+//   - logger.Logger: The logger; its creation failing fails tb
+//
+// Example:
+//
+//	func TestOrderFlow(t *testing.T) {
+//	    log := loggertest.NewTB(t, loggertest.WithFailOnError())
+//	    srv := orders.NewServer(log)
+//	    // ... an unexpected log.Error in srv fails the test
+//	}
+func NewTB(tb testing.TB, opts ...TBOption) logger.Logger {
+	tb.Helper()
+
+	cfg := &tbConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	path := newTestSink(tb)
+	options := []logger.Option{
+		logger.WithEncoding(logger.EncodingConsole),
+		logger.WithLevel(logger.LevelDebug),
+		logger.WithTimeKey(""),
+		logger.WithDisableCaller(false),
+	}
+	options = append(options, cfg.options...)
+	options = append(options, logger.WithOutputPaths([]string{path}), logger.WithErrorOutputPaths([]string{path}))
+	if cfg.failOnError {
+		failer := &tbFailer{tb: tb}
+		tb.Cleanup(failer.stop)
+		options = append(options, logger.WithFilter(failer.check))
+	}
+
+	log, err := logger.NewLogger(options...)
+	if err != nil {
+		tb.Fatalf("failed to create logger: %v", err)
+	}
+	tb.Cleanup(func() { _ = logger.Close(context.Background(), log) })
+	return log
+}
+
+// WithFailOnError fails the test when an entry is logged at error level or above, to
+// catch errors the code under test logs but does not return. The entry is still
+// written, and the test continues.
+//
+// Returns:
+//   - TBOption: The option
+func WithFailOnError() TBOption {
+	return func(c *tbConfig) {
+		c.failOnError = true
+	}
+}
+
+// WithOptions sets options of the logger created by NewTB. Its output paths are set by
+// NewTB.
+//
+// Parameters:
+//   - opts: Options of the logger, e.g. its level or redacted fields
+//
+// Returns:
+//   - TBOption: The option
+func WithOptions(opts ...logger.Option) TBOption {
+	return func(c *tbConfig) {
+		c.options = append(c.options, opts...)
+	}
+}
+
+// check fails the test on entries at error level and above. It is a filter that never
+// drops entries.
+func (f *tbFailer) check(e logger.Entry) bool {
+	if e.Level < zapcore.ErrorLevel {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.done {
+		return false
+	}
+	if e.Caller.Defined {
+		f.tb.Errorf("unexpected %s entry at %s: %s", e.Level.CapitalString(), e.Caller.TrimmedPath(), e.Message)
+	} else {
+		f.tb.Errorf("unexpected %s entry: %s", e.Level.CapitalString(), e.Message)
+	}
+	return false
+}
+
+// stop stops failing the test once it has ended.
+func (f *tbFailer) stop() {
+	f.mu.Lock()
+	f.done = true
+	f.mu.Unlock()
+}
@@ -0,0 +1,83 @@
+package loggertest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	logger "github.com/andryhardiyanto/go-logger"
+)
+
+// Environment variables setting the level of NewLoggerForTests, GO_LOG taking
+// precedence.
+const (
+	EnvGoLog    = "GO_LOG"
+	EnvLogLevel = "LOG_LEVEL"
+)
+
+// NewLoggerForTests returns a logger for the code under test that keeps test output
+// readable. With a test, entries are written to the test log with t.Log, so go test
+// shows them only for failing tests and in -v runs, next to the output of the test
+// they belong to. Without a test, entries are discarded, unless the tests run with -v
+// and GO_LOG or LOG_LEVEL is set, in which case they are written to stderr. The level
+// is taken from GO_LOG or LOG_LEVEL, e.g. GO_LOG=debug, and is info otherwise; the
+// environment takes precedence over the options. Entries are console encoded.
+//
+// Parameters:
+//   - t: The test the entries belong to; nil discards them
+//   - opts: Options of the logger, e.g. the redacted fields of the application
+//
+// Returns:
+//   - logger.Logger: The logger; its creation failing fails t, or panics without t
+//
+// Example:
+//
+//	func TestCheckout(t *testing.T) {
+//	    svc := checkout.New(loggertest.NewLoggerForTests(t))
+//	    // GO_LOG=debug go test -v -run TestCheckout ./...
+//	}
+func NewLoggerForTests(t testing.TB, opts ...logger.Option) logger.Logger {
+	if t != nil {
+		t.Helper()
+	}
+
+	level, fromEnv := envLevel()
+	path := discardPath
+	switch {
+	case t != nil:
+		path = newTestSink(t)
+	case fromEnv && verbose():
+		path = "stderr"
+	}
+
+	opts = append([]logger.Option{logger.WithEncoding(logger.EncodingConsole), logger.WithLevel(level)}, opts...)
+	opts = append(opts, logger.WithOutputPaths([]string{path}), logger.WithErrorOutputPaths([]string{path}))
+	if fromEnv {
+		opts = append(opts, logger.WithLevel(level))
+	}
+
+	log, err := logger.NewLogger(opts...)
+	if err != nil {
+		if t == nil {
+			panic(fmt.Sprintf("loggertest: failed to create logger: %v", err))
+		}
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	return log
+}
+
+// envLevel returns the level set by GO_LOG or LOG_LEVEL and whether one is set.
+func envLevel() (logger.Level, bool) {
+	for _, key := range []string{EnvGoLog, EnvLogLevel} {
+		if value := os.Getenv(key); value != "" {
+			return logger.LevelFromName(value), true
+		}
+	}
+	return logger.LevelInfo, false
+}
+
+// verbose reports whether the test binary runs with -v; false outside tests.
+func verbose() bool {
+	return testing.Testing() && flag.Parsed() && testing.Verbose()
+}
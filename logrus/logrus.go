@@ -0,0 +1,187 @@
+// Package logrus provides a logrus-compatible API backed by go-logger.
+// Services migrating from github.com/sirupsen/logrus can switch the import path to
+// this package and keep calls such as log.WithField("order_id", id).Infof(...)
+// while entries are written by a go-logger Logger.
+package logrus
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	logger "github.com/andryhardiyanto/go-logger"
+	"go.uber.org/zap"
+)
+
+// ErrorKey is the field key used by WithError.
+const ErrorKey = "error"
+
+// callerSkip is the number of frames between the application and the go-logger
+// call: the Entry method and write.
+const callerSkip = 2
+
+type (
+	// Fields holds the structured fields of an entry.
+	Fields map[string]any
+
+	// Logger mirrors logrus.Logger. All Entry methods are available on it.
+	Logger struct {
+		*Entry
+	}
+
+	// Entry mirrors logrus.Entry: a set of fields and a context logged together.
+	Entry struct {
+		log     logger.Logger
+		Data    Fields
+		Context context.Context
+	}
+)
+
+// New creates a Logger writing to the go-logger Logger.
+//
+// Parameters:
+//   - log: The logger receiving the entries
+//
+// Returns:
+//   - *Logger: The logrus-compatible logger
+//
+// Example:
+//
+//	log := logrus.New(base)
+//	log.WithFields(logrus.Fields{"order_id": id}).Info("order created")
+func New(log logger.Logger) *Logger {
+	return &Logger{Entry: &Entry{log: logger.AddCallerSkip(log, callerSkip), Data: Fields{}, Context: context.Background()}}
+}
+
+// WithField returns an entry with an additional field.
+func (e *Entry) WithField(key string, value any) *Entry {
+	return e.WithFields(Fields{key: value})
+}
+
+// WithFields returns an entry with additional fields.
+func (e *Entry) WithFields(fields Fields) *Entry {
+	data := make(Fields, len(e.Data)+len(fields))
+	for k, v := range e.Data {
+		data[k] = v
+	}
+	for k, v := range fields {
+		data[k] = v
+	}
+	return &Entry{log: e.log, Data: data, Context: e.Context}
+}
+
+// WithError returns an entry with the error stored under ErrorKey.
+func (e *Entry) WithError(err error) *Entry {
+	return e.WithField(ErrorKey, err)
+}
+
+// WithContext returns an entry logging with the context, so trace and request IDs are extracted.
+func (e *Entry) WithContext(ctx context.Context) *Entry {
+	return &Entry{log: e.log, Data: e.Data, Context: ctx}
+}
+
+// Trace logs at DebugLevel, go-logger has no trace level.
+func (e *Entry) Trace(args ...any) { e.write(logger.LevelDebug, fmt.Sprint(args...)) }
+
+// Debug logs at DebugLevel.
+func (e *Entry) Debug(args ...any) { e.write(logger.LevelDebug, fmt.Sprint(args...)) }
+
+// Print logs at InfoLevel.
+func (e *Entry) Print(args ...any) { e.write(logger.LevelInfo, fmt.Sprint(args...)) }
+
+// Info logs at InfoLevel.
+func (e *Entry) Info(args ...any) { e.write(logger.LevelInfo, fmt.Sprint(args...)) }
+
+// Warn logs at WarnLevel.
+func (e *Entry) Warn(args ...any) { e.write(logger.LevelWarning, fmt.Sprint(args...)) }
+
+// Warning logs at WarnLevel.
+func (e *Entry) Warning(args ...any) { e.write(logger.LevelWarning, fmt.Sprint(args...)) }
+
+// Error logs at ErrorLevel.
+func (e *Entry) Error(args ...any) { e.write(logger.LevelError, fmt.Sprint(args...)) }
+
+// Fatal logs at FatalLevel, then calls os.Exit(1).
+func (e *Entry) Fatal(args ...any) { e.write(logger.LevelFatal, fmt.Sprint(args...)) }
+
+// Panic logs at PanicLevel, then panics.
+func (e *Entry) Panic(args ...any) { e.write(logger.LevelPanic, fmt.Sprint(args...)) }
+
+// Tracef logs at DebugLevel, go-logger has no trace level.
+func (e *Entry) Tracef(format string, args ...any) {
+	e.write(logger.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+// Debugf logs at DebugLevel.
+func (e *Entry) Debugf(format string, args ...any) {
+	e.write(logger.LevelDebug, fmt.Sprintf(format, args...))
+}
+
+// Printf logs at InfoLevel.
+func (e *Entry) Printf(format string, args ...any) {
+	e.write(logger.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Infof logs at InfoLevel.
+func (e *Entry) Infof(format string, args ...any) {
+	e.write(logger.LevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Warnf logs at WarnLevel.
+func (e *Entry) Warnf(format string, args ...any) {
+	e.write(logger.LevelWarning, fmt.Sprintf(format, args...))
+}
+
+// Warningf logs at WarnLevel.
+func (e *Entry) Warningf(format string, args ...any) {
+	e.write(logger.LevelWarning, fmt.Sprintf(format, args...))
+}
+
+// Errorf logs at ErrorLevel.
+func (e *Entry) Errorf(format string, args ...any) {
+	e.write(logger.LevelError, fmt.Sprintf(format, args...))
+}
+
+// Fatalf logs at FatalLevel, then calls os.Exit(1).
+func (e *Entry) Fatalf(format string, args ...any) {
+	e.write(logger.LevelFatal, fmt.Sprintf(format, args...))
+}
+
+// Panicf logs at PanicLevel, then panics.
+func (e *Entry) Panicf(format string, args ...any) {
+	e.write(logger.LevelPanic, fmt.Sprintf(format, args...))
+}
+
+// write logs the message with the fields of the entry in key order.
+func (e *Entry) write(level logger.Level, msg string) {
+	keys := make([]string, 0, len(e.Data))
+	for k := range e.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	fields := make([]logger.Field, 0, len(keys))
+	for _, k := range keys {
+		fields = append(fields, zap.Any(k, e.Data[k]))
+	}
+
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	switch level {
+	case logger.LevelDebug:
+		e.log.Debug(ctx, msg, fields...)
+	case logger.LevelWarning:
+		e.log.Warn(ctx, msg, fields...)
+	case logger.LevelError:
+		e.log.Error(ctx, msg, fields...)
+	case logger.LevelFatal:
+		e.log.Fatal(ctx, msg, fields...)
+	case logger.LevelPanic:
+		e.log.Panic(ctx, msg, fields...)
+	default:
+		e.log.Info(ctx, msg, fields...)
+	}
+}
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"container/list"
+	"sync"
+)
+
+type (
+	// lruCache is a concurrency-safe, fixed size cache evicting the least recently used entries.
+	// It bounds the memory used by per-key bookkeeping such as Once and Every.
+	lruCache[K comparable, V any] struct {
+		mu       sync.Mutex
+		capacity int
+		items    map[K]*list.Element
+		order    *list.List
+	}
+
+	// lruItem is a single key-value pair stored in the cache.
+	lruItem[K comparable, V any] struct {
+		key   K
+		value V
+	}
+)
+
+// newLRUCache creates a cache holding at most capacity entries.
+// A capacity below one is treated as one.
+func newLRUCache[K comparable, V any](capacity int) *lruCache[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &lruCache[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// Get returns the value stored for key and marks it as recently used.
+func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.order.MoveToFront(el)
+		return el.Value.(*lruItem[K, V]).value, true
+	}
+
+	var zero V
+	return zero, false
+}
+
+// Set stores the value for key, evicting the least recently used entry when full.
+func (c *lruCache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.set(key, value)
+}
+
+// Update atomically replaces the value stored for key with the result of fn.
+// fn receives the current value and whether it exists; its result is stored
+// when keep is true. Update returns the value produced by fn.
+func (c *lruCache[K, V]) Update(key K, fn func(current V, exists bool) (value V, keep bool)) V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var (
+		current V
+		exists  bool
+	)
+	if el, ok := c.items[key]; ok {
+		current, exists = el.Value.(*lruItem[K, V]).value, true
+	}
+
+	value, keep := fn(current, exists)
+	if keep {
+		c.set(key, value)
+	}
+	return value
+}
+
+// Len returns the number of cached entries.
+func (c *lruCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// set stores the value; the caller must hold the lock.
+func (c *lruCache[K, V]) set(key K, value V) {
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem[K, V]).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruItem[K, V]{key: key, value: value})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem[K, V]).key)
+	}
+}
@@ -0,0 +1,200 @@
+package logger
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+// Labels of the latency histogram recorded from access log entries.
+const (
+	MetricLabelMethod = "method"
+	MetricLabelRoute  = "route"
+	MetricLabelStatus = "status"
+)
+
+// unmatchedRoute is the route label of requests not matched by a route pattern.
+// Raw paths are never used as labels, as they would create a series per ID.
+const unmatchedRoute = "unmatched"
+
+type (
+	// metricProcessor derives metrics from log entries.
+	metricProcessor interface {
+		// wants reports whether the processor observes entries like ent. It is called
+		// for every entry and must be cheap.
+		wants(ent zapcore.Entry) bool
+		// observe records the metrics of an entry.
+		observe(entry Entry)
+	}
+
+	// metricsCore wraps a zapcore.Core and feeds entries to metric processors.
+	// Processors see every entry at InfoLevel and above that they want, including
+	// entries below the minimum level and entries dropped by sampling or filters,
+	// so metrics stay exact when logs are thinned out.
+	metricsCore struct {
+		zapcore.Core
+		observer *metricsObserver
+	}
+
+	// metricsObserver is the core added to checked entries wanted by a processor.
+	// It writes nothing and only hands the entry to the processors.
+	metricsObserver struct {
+		processors []metricProcessor
+		// fields holds the fields added with With, so predicates can match them.
+		fields []Field
+	}
+
+	// latencyHistogram records the duration of access log entries written by HTTPMiddleware.
+	latencyHistogram struct {
+		durations *prometheus.HistogramVec
+	}
+)
+
+// newMetricsCore wraps core with the processors, or returns core unchanged when there are none.
+func newMetricsCore(core zapcore.Core, processors []metricProcessor) zapcore.Core {
+	if len(processors) == 0 {
+		return core
+	}
+	return &metricsCore{Core: core, observer: &metricsObserver{processors: processors}}
+}
+
+// Enabled reports true from InfoLevel, as processors may want entries the wrapped core drops.
+// Debug entries are left to the wrapped core so disabled debug logging stays cheap.
+func (c *metricsCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= zapcore.InfoLevel || c.Core.Enabled(lvl)
+}
+
+// With returns a child core whose processors also see the fields.
+func (c *metricsCore) With(fields []Field) zapcore.Core {
+	observer := &metricsObserver{
+		processors: c.observer.processors,
+		fields:     append(c.observer.fields[:len(c.observer.fields):len(c.observer.fields)], fields...),
+	}
+	return &metricsCore{Core: c.Core.With(fields), observer: observer}
+}
+
+// lowered returns a copy of the core sharing the processors.
+func (c *metricsCore) lowered() zapcore.Core {
+	return &metricsCore{Core: lowerCore(c.Core), observer: c.observer}
+}
+
+// Check adds the wrapped core as it decides, and the observer when a processor wants the entry.
+func (c *metricsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.Core.Check(ent, ce)
+	for _, p := range c.observer.processors {
+		if p.wants(ent) {
+			return ce.AddCore(ent, c.observer)
+		}
+	}
+	return ce
+}
+
+// Enabled reports true; the observer is only added to entries a processor wants.
+func (o *metricsObserver) Enabled(zapcore.Level) bool {
+	return true
+}
+
+// With returns the observer unchanged; fields are tracked by metricsCore.
+func (o *metricsObserver) With([]Field) zapcore.Core {
+	return o
+}
+
+// Check returns the checked entry unchanged; the observer is added by metricsCore.
+func (o *metricsObserver) Check(_ zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce
+}
+
+// Write hands the entry to the processors that want it.
+func (o *metricsObserver) Write(ent zapcore.Entry, fields []Field) error {
+	entry := Entry{Entry: ent, Fields: fields}
+	if len(o.fields) > 0 {
+		entry.Fields = append(o.fields[:len(o.fields):len(o.fields)], fields...)
+	}
+	for _, p := range o.processors {
+		if p.wants(ent) {
+			p.observe(entry)
+		}
+	}
+	return nil
+}
+
+// Sync does nothing; the observer has no buffers.
+func (o *metricsObserver) Sync() error {
+	return nil
+}
+
+// newLatencyHistogram creates the histogram and registers it with the registerer.
+// A histogram already registered by another logger is reused.
+func newLatencyHistogram(registerer prometheus.Registerer, buckets []float64) (*latencyHistogram, error) {
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	if len(buckets) == 0 {
+		buckets = prometheus.DefBuckets
+	}
+
+	durations := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_server_request_duration_seconds",
+		Help:    "Duration of HTTP requests, recorded from access log entries.",
+		Buckets: buckets,
+	}, []string{MetricLabelMethod, MetricLabelRoute, MetricLabelStatus})
+
+	durations, err := registerCollector(registerer, durations)
+	if err != nil {
+		return nil, err
+	}
+	return &latencyHistogram{durations: durations}, nil
+}
+
+// registerCollector registers the collector, returning the collector registered
+// before when an identical one already exists, e.g. from another logger.
+func registerCollector[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	if err := registerer.Register(collector); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if !errors.As(err, &already) {
+			return collector, err
+		}
+		existing, ok := already.ExistingCollector.(T)
+		if !ok {
+			return collector, err
+		}
+		return existing, nil
+	}
+	return collector, nil
+}
+
+// wants reports whether the entry is an access log entry.
+func (h *latencyHistogram) wants(ent zapcore.Entry) bool {
+	return ent.Message == httpRequestMessage
+}
+
+// observe records the duration of the request under its method, route and status.
+func (h *latencyHistogram) observe(entry Entry) {
+	var (
+		method, status string
+		route          = unmatchedRoute
+		duration       time.Duration
+		hasDuration    bool
+	)
+	for _, f := range entry.Fields {
+		switch f.Key {
+		case FieldKeyHTTPMethod:
+			method = f.String
+		case FieldKeyHTTPRoute:
+			route = f.String
+		case FieldKeyHTTPStatus:
+			status = strconv.FormatInt(f.Integer, 10)
+		case FieldKeyDuration:
+			if f.Type == zapcore.DurationType {
+				duration, hasDuration = time.Duration(f.Integer), true
+			}
+		}
+	}
+	if !hasDuration || status == "" {
+		return
+	}
+	observeWithExemplar(h.durations.WithLabelValues(method, route, status), duration.Seconds(), exemplarLabels(entry))
+}
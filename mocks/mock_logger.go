@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	go_logger "github.com/andryhardiyanto/go-logger"
 	gomock "github.com/golang/mock/gomock"
@@ -70,6 +71,23 @@ func (mr *MockLoggerMockRecorder) Error(ctx, msg interface{}, fields ...interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockLogger)(nil).Error), varargs...)
 }
 
+// Every mocks base method.
+func (m *MockLogger) Every(ctx context.Context, key string, interval time.Duration, msg string, fields ...go_logger.Field) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, key, interval, msg}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Every", varargs...)
+}
+
+// Every indicates an expected call of Every.
+func (mr *MockLoggerMockRecorder) Every(ctx, key, interval, msg interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, key, interval, msg}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Every", reflect.TypeOf((*MockLogger)(nil).Every), varargs...)
+}
+
 // Fatal mocks base method.
 func (m *MockLogger) Fatal(ctx context.Context, msg string, fields ...go_logger.Field) {
 	m.ctrl.T.Helper()
@@ -118,6 +136,23 @@ func (mr *MockLoggerMockRecorder) Info(ctx, msg interface{}, fields ...interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockLogger)(nil).Info), varargs...)
 }
 
+// Once mocks base method.
+func (m *MockLogger) Once(ctx context.Context, key, msg string, fields ...go_logger.Field) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, key, msg}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Once", varargs...)
+}
+
+// Once indicates an expected call of Once.
+func (mr *MockLoggerMockRecorder) Once(ctx, key, msg interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, key, msg}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Once", reflect.TypeOf((*MockLogger)(nil).Once), varargs...)
+}
+
 // Panic mocks base method.
 func (m *MockLogger) Panic(ctx context.Context, msg string, fields ...go_logger.Field) {
 	m.ctrl.T.Helper()
@@ -169,3 +204,22 @@ func (mr *MockLoggerMockRecorder) With(fields ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "With", reflect.TypeOf((*MockLogger)(nil).With), fields...)
 }
+
+// WithOptions mocks base method.
+func (m *MockLogger) WithOptions(opts ...go_logger.Option) (go_logger.Logger, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WithOptions", varargs...)
+	ret0, _ := ret[0].(go_logger.Logger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithOptions indicates an expected call of WithOptions.
+func (mr *MockLoggerMockRecorder) WithOptions(opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithOptions", reflect.TypeOf((*MockLogger)(nil).WithOptions), opts...)
+}
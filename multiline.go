@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// MultilineMode controls how multi-line strings and stack traces are encoded.
+type MultilineMode string
+
+const (
+	// MultilineDefault keeps the behavior of the encoding: JSON escapes newlines, console
+	// writes the message and stack trace raw and escapes newlines in fields.
+	MultilineDefault MultilineMode = ""
+	// MultilineEscaped writes every entry on a single line by escaping newlines, for
+	// strict line-based shippers. Console stack traces become a stack trace field.
+	MultilineEscaped MultilineMode = "escaped"
+	// MultilineRaw writes multi-line string fields and stack traces raw below the entry
+	// line, for reading in a terminal. It requires console encoding.
+	MultilineRaw MultilineMode = "raw"
+	// MultilineArray writes multi-line string fields and stack traces as arrays of lines
+	// and escapes newlines in the message.
+	MultilineArray MultilineMode = "array"
+)
+
+// multilineEncoder wraps an encoder and applies a MultilineMode other than the default.
+type multilineEncoder struct {
+	zapcore.Encoder
+	mode     MultilineMode
+	console  bool
+	stackKey string
+}
+
+// newMultilineEncoder wraps the encoder for the mode. The default mode, and the escaped
+// mode of JSON encoding, which already escapes newlines, return the encoder unchanged.
+func newMultilineEncoder(encoder zapcore.Encoder, mode MultilineMode, encoding, stackKey string) zapcore.Encoder {
+	if mode == MultilineDefault || (mode == MultilineEscaped && encoding == EncodingJson.String()) {
+		return encoder
+	}
+	return &multilineEncoder{Encoder: encoder, mode: mode, console: encoding != EncodingJson.String(), stackKey: stackKey}
+}
+
+// validateMultilineMode checks that the mode exists and suits the encoding.
+func validateMultilineMode(mode MultilineMode, encoding string) error {
+	switch mode {
+	case MultilineDefault, MultilineEscaped, MultilineArray:
+		return nil
+	case MultilineRaw:
+		if encoding != EncodingConsole.String() {
+			return fmt.Errorf("multiline mode %q requires console encoding", mode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid multiline mode %q", mode)
+	}
+}
+
+// Clone returns a copy of the encoder that keeps the multiline mode.
+func (e *multilineEncoder) Clone() zapcore.Encoder {
+	return &multilineEncoder{Encoder: e.Encoder.Clone(), mode: e.mode, console: e.console, stackKey: e.stackKey}
+}
+
+// AddString adds a string; used for fields added with With. Multi-line strings become
+// arrays of lines in array mode.
+func (e *multilineEncoder) AddString(key, value string) {
+	if e.mode == MultilineArray && isMultiline(value) {
+		_ = e.Encoder.AddArray(key, lines(value))
+		return
+	}
+	e.Encoder.AddString(key, value)
+}
+
+// EncodeEntry encodes the entry with multi-line values folded for the mode.
+func (e *multilineEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	switch e.mode {
+	case MultilineEscaped:
+		ent.Message = escapeNewlines(ent.Message)
+		if ent.Stack != "" && e.stackKey != "" {
+			fields = append(fields[:len(fields):len(fields)], zap.String(e.stackKey, ent.Stack))
+			ent.Stack = ""
+		}
+		return e.Encoder.EncodeEntry(ent, fields)
+
+	case MultilineArray:
+		if e.console {
+			ent.Message = escapeNewlines(ent.Message)
+		}
+		if ent.Stack != "" && e.stackKey != "" {
+			fields = append(fields[:len(fields):len(fields)], zap.Array(e.stackKey, lines(ent.Stack)))
+			ent.Stack = ""
+		}
+		return e.Encoder.EncodeEntry(ent, foldFields(fields, func(f Field) Field {
+			return zap.Array(f.Key, lines(f.String))
+		}))
+
+	default:
+		var blocks []Field
+		fields = foldFields(fields, func(f Field) Field {
+			blocks = append(blocks, f)
+			return zap.Skip()
+		})
+		buf, err := e.Encoder.EncodeEntry(ent, fields)
+		if err != nil || len(blocks) == 0 {
+			return buf, err
+		}
+		raw := buf.String()
+		body := strings.TrimSuffix(raw, "\n")
+		buf.Reset()
+		buf.AppendString(body)
+		for _, f := range blocks {
+			buf.AppendString("\n" + f.Key + ":\n" + strings.TrimSuffix(f.String, "\n"))
+		}
+		buf.AppendString(raw[len(body):])
+		return buf, nil
+	}
+}
+
+// foldFields returns the fields with multi-line string fields replaced by fold.
+// The input slice is never modified.
+func foldFields(fields []Field, fold func(Field) Field) []Field {
+	var out []Field
+	for i, f := range fields {
+		if f.Type != zapcore.StringType || !isMultiline(f.String) {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, fold(f))
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// isMultiline reports whether s spans several lines.
+func isMultiline(s string) bool {
+	return strings.ContainsAny(s, "\r\n")
+}
+
+// escapeNewlines replaces carriage returns and newlines with their escape sequences.
+func escapeNewlines(s string) string {
+	if !isMultiline(s) {
+		return s
+	}
+	return strings.NewReplacer("\r", `\r`, "\n", `\n`).Replace(s)
+}
+
+// lines splits s into its lines, without line endings or a trailing empty line.
+func lines(s string) zapcore.ArrayMarshaler {
+	s = strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	return stringArray(strings.Split(s, "\n"))
+}
+
+// stringArray logs a slice of strings as an array.
+type stringArray []string
+
+// MarshalLogArray writes the strings.
+func (a stringArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, s := range a {
+		enc.AppendString(s)
+	}
+	return nil
+}
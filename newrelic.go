@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/newrelic/go-agent/v3/integrations/logcontext-v2/nrzap"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap/zapcore"
+)
+
+// wrapNewRelicCore wraps a core to forward its entries to the New Relic application;
+// tests replace it to observe the forwarded entries.
+var wrapNewRelicCore = func(core zapcore.Core, app *newrelic.Application) (zapcore.Core, error) {
+	forwardCore, err := nrzap.WrapBackgroundCore(core, app)
+	if err != nil {
+		return nil, err
+	}
+	return forwardCore, nil
+}
+
+// newNewRelicCore creates the core forwarding entries to New Relic. It forwards entries
+// at or above level, or, when level is empty, entries enabled by the local level, so
+// runtime level changes apply to both. A given level is kept for entries lowered by
+// tenant levels or forced debug, while an empty level forwards them like the local
+// output. The core writes nothing locally; NewLogger tees it with the output core,
+// inside the cores processing entries.
+func newNewRelicCore(app *newrelic.Application, level Level, local zapcore.LevelEnabler) (zapcore.Core, error) {
+	forwardCore, err := wrapNewRelicCore(zapcore.NewNopCore(), app)
+	if err != nil {
+		return nil, err
+	}
+
+	enabler := local
+	if level != "" {
+		forwardLevel, err := parseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("invalid New Relic level: %w", err)
+		}
+		enabler = forwardLevel
+	}
+	return &levelCore{Core: forwardCore, level: enabler, fixed: level != ""}, nil
+}
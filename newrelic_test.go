@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observeNewRelic replaces the New Relic forwarder for the test and returns a disabled
+// application and the entries forwarded to it.
+func observeNewRelic(t *testing.T) (*newrelic.Application, *observer.ObservedLogs) {
+	t.Helper()
+
+	forwarded, observed := observer.New(zapcore.DebugLevel)
+	wrap := wrapNewRelicCore
+	wrapNewRelicCore = func(zapcore.Core, *newrelic.Application) (zapcore.Core, error) {
+		return forwarded, nil
+	}
+	t.Cleanup(func() { wrapNewRelicCore = wrap })
+
+	app, err := newrelic.NewApplication(
+		newrelic.ConfigAppName("logger-test"),
+		newrelic.ConfigLicense(strings.Repeat("0", 40)),
+		newrelic.ConfigEnabled(false),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app, observed
+}
+
+// TestNewRelicForwardingFollowsProcessing checks that forwarded entries go through the
+// filters of the logger and that the New Relic level neither reaches the local sinks nor
+// is limited by the local level.
+func TestNewRelicForwardingFollowsProcessing(t *testing.T) {
+	app, observed := observeNewRelic(t)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, err := NewLogger(
+		WithEncoding(EncodingJson),
+		WithOutputPaths([]string{path}),
+		WithLevel(LevelInfo),
+		WithNewRelicApp(app),
+		WithNewRelicLevel(LevelDebug),
+		WithFilter(func(e Entry) bool { return e.Message == "noise" }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	log.Debug(ctx, "verbose")
+	log.Info(ctx, "noise")
+	log.Info(ctx, "kept")
+	if err := Close(ctx, log); err != nil {
+		t.Fatal(err)
+	}
+
+	var forwarded []string
+	for _, entry := range observed.All() {
+		forwarded = append(forwarded, entry.Message)
+	}
+	if want := []string{"verbose", "kept"}; !slices.Equal(forwarded, want) {
+		t.Errorf("forwarded %q, want %q", forwarded, want)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := string(b)
+	if !strings.Contains(local, "kept") || strings.Contains(local, "verbose") || strings.Contains(local, "noise") {
+		t.Errorf("local sink received %q, want only the kept entry", local)
+	}
+}
@@ -3,7 +3,15 @@
 // that allow flexible logger setup and customization.
 package logger
 
-import "github.com/newrelic/go-agent/v3/newrelic"
+import (
+	"os"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 type (
 	// Option represents a configuration option function.
@@ -21,12 +29,22 @@ type (
 		// Encoding determines the output format (JSON or console).
 		// JSON is recommended for production, console for development.
 		Encoding Encoding
+		// AutoEncoding picks Encoding when the logger is created, depending on whether
+		// the output goes to a terminal.
+		AutoEncoding bool
+		// ContainerOutput writes one uncolored JSON object per line with UTC timestamps,
+		// splitting lines longer than ContainerMaxLine.
+		ContainerOutput bool
+		// ContainerMaxLine is the maximum line length of ContainerOutput in bytes; zero uses 16 KiB.
+		ContainerMaxLine int
 		// AppMode sets the application environment mode.
 		// Different modes have different default configurations.
 		AppMode AppMode
 		// NewRelicApp enables New Relic integration when provided.
 		// Allows automatic forwarding of logs to New Relic for monitoring.
 		NewRelicApp *newrelic.Application
+		// NewRelicLevel is the minimum level of entries forwarded to New Relic; empty uses Level.
+		NewRelicLevel Level
 		// TimeKey specifies the key name for timestamp in log output.
 		TimeKey string
 		// LevelKey specifies the key name for log level in log output.
@@ -39,14 +57,146 @@ type (
 		MessageKey string
 		// StacktraceKey specifies the key name for stack trace in log output.
 		StacktraceKey string
+		// MaxFieldDepth limits the nesting depth of reflected fields and enables cycle detection.
+		MaxFieldDepth int
+		// NonFiniteFloats controls how NaN and ±Inf floats are encoded.
+		NonFiniteFloats NonFiniteMode
 		// DisableStacktrace controls whether stack traces are included.
 		DisableStacktrace bool
+		// StacktraceDepth limits the number of frames of stack traces; zero keeps every frame.
+		StacktraceDepth int
+		// StacktraceFilter skips runtime, zap and logger frames and trims GOPATH and GOROOT prefixes.
+		StacktraceFilter bool
 		// DisableCaller controls whether caller information is included.
 		DisableCaller bool
 		// OutputPaths specifies where normal log messages are written.
 		OutputPaths []string
 		// ErrorOutputPaths specifies where error-level messages are written.
 		ErrorOutputPaths []string
+		// FileMode sets the permission bits applied to log files.
+		FileMode os.FileMode
+		// DirMode sets the permission bits of directories created for log files.
+		DirMode os.FileMode
+		// CreateDirs controls whether missing parent directories of log files are created.
+		CreateDirs bool
+		// FileUID sets the owner of log files. A negative value keeps the current owner.
+		FileUID int
+		// FileGID sets the group of log files. A negative value keeps the current group.
+		FileGID int
+		// FsyncOnError controls whether sinks are synced after every error-level entry.
+		FsyncOnError bool
+		// FileEncryption enables encryption at rest for file sinks when provided.
+		FileEncryption KeyWrapper
+		// SharedFileWrites guarantees whole-line writes to files shared by several processes.
+		SharedFileWrites bool
+		// MaxRecordSize is the maximum size of a record written to a shared file.
+		MaxRecordSize int
+		// OnceCacheSize bounds the number of keys remembered by Once and Every.
+		OnceCacheSize int
+		// Filters drop matching entries before they are encoded.
+		Filters []Filter
+		// DebugWhenSampled enables debug entries for sampled traces regardless of Level.
+		DebugWhenSampled bool
+		// SchemaVersion is stamped on every entry when set.
+		SchemaVersion string
+		// CorrelationKeys are the fields checked by WithCorrelationCheck; nil disables the check.
+		CorrelationKeys []ContextKey
+		// DualWrite copies entries to a secondary logger during a migration; nil disables it.
+		DualWrite *DualWrite
+		// ResourceFields are the attributes of the OpenTelemetry resource stamped on every entry.
+		ResourceFields []Field
+		// CloudMetadataTimeout bounds the detection of the cloud instance metadata stamped on
+		// every entry; zero disables it.
+		CloudMetadataTimeout time.Duration
+		// CloudFields are the detected cloud metadata fields, set by NewLogger.
+		CloudFields []Field
+		// SinkPool holds the opened sinks, shared with derived loggers inheriting them; set
+		// by NewLogger.
+		SinkPool *sinkPool
+		// CrashReportPath is the file the crash report is written to; empty disables it.
+		CrashReportPath string
+		// CrashReportEntries is the number of last entries kept for the crash report.
+		CrashReportEntries int
+		// IDGenerator generates the IDs created by the package, such as request IDs; nil uses random hex IDs.
+		IDGenerator func() string
+		// FieldRenames rename field keys right before encoding.
+		FieldRenames []fieldRename
+		// RedactedFields are the keys of fields whose values are replaced with [REDACTED].
+		RedactedFields []string
+		// ConsoleHumanize renders byte counts and durations for humans in console encoding.
+		ConsoleHumanize bool
+		// ConsoleTemplate is the text/template layout of console lines; empty uses zap's layout.
+		ConsoleTemplate string
+		// ColorTheme colors console output; nil writes it uncolored.
+		ColorTheme *ColorTheme
+		// MultilineMode controls how multi-line strings and stack traces are encoded.
+		MultilineMode MultilineMode
+		// consoleTemplate is the parsed ConsoleTemplate, set by NewLogger.
+		consoleTemplate *template.Template
+		// CallerModuleRelative writes callers relative to the module root.
+		CallerModuleRelative bool
+		// CallerFunction appends the function name to module relative callers.
+		CallerFunction bool
+		// CallerModule is the import path of the module root; empty detects the main module.
+		CallerModule string
+		// callerFormatter renders module relative callers, set by NewLogger.
+		callerFormatter *callerFormatter
+		// GoroutineID adds the ID of the logging goroutine to every entry.
+		GoroutineID bool
+		// ContextDeadline adds the remaining deadline and cancellation of the context to every entry.
+		ContextDeadline bool
+		// SpanEvents records warning and error entries on the span of the context.
+		SpanEvents bool
+		// BaggageKeys are the OpenTelemetry baggage members added to every entry.
+		BaggageKeys []string
+		// Enrichers add fields derived from the context to every entry.
+		Enrichers []Enricher
+		// UserAgentParsing adds structured browser, OS and device fields next to user_agent fields.
+		UserAgentParsing bool
+		// UserAgentCacheSize is the number of parsed user agents cached.
+		UserAgentCacheSize int
+		// LatencyHistogram records access log durations into a Prometheus histogram.
+		LatencyHistogram bool
+		// LatencyBuckets are the histogram buckets in seconds; empty uses prometheus.DefBuckets.
+		LatencyBuckets []float64
+		// MetricsRegisterer registers derived metrics; nil uses prometheus.DefaultRegisterer.
+		MetricsRegisterer prometheus.Registerer
+		// MetricRules turn matching entries into counters and gauges.
+		MetricRules []MetricRule
+		// ErrorStormThreshold is the number of identical error entries written per window; zero disables suppression.
+		ErrorStormThreshold int
+		// ErrorStormWindow is the window in which identical error entries are counted.
+		ErrorStormWindow time.Duration
+		// ErrorStormInterval is the interval between summaries of suppressed entries.
+		ErrorStormInterval time.Duration
+		// CallSiteRateLimit is the number of entries per second written per call site; zero disables the limit.
+		CallSiteRateLimit float64
+		// CallSiteBurst is the number of entries a call site may write at once before CallSiteRateLimit applies.
+		CallSiteBurst int
+		// EntrySizeTracking counts the sizes of encoded entries for GetStats.
+		EntrySizeTracking bool
+		// EntrySizeThreshold is the size in bytes above which entries are flagged; zero disables flagging.
+		EntrySizeThreshold int
+		// IngestBudgets limit the bytes written to output paths per period.
+		IngestBudgets []sinkBudget
+		// Residency routes entries to the sinks of their data residency region when set.
+		Residency *ResidencyConfig
+		// SinkPolicies decide per sink what happens to fields of each classification.
+		SinkPolicies []sinkPolicy
+		// SinkProfiles map the keys and level names of entries per sink to the conventions of a vendor.
+		SinkProfiles []sinkProfile
+		// SinkFallbacks are the sinks written to when an output path fails, in order.
+		SinkFallbacks []sinkFallback
+		// SinkBatches are the output paths written to in batches.
+		SinkBatches []sinkBatch
+		// StrictStartup makes NewLogger fail when a sink fails its startup checks.
+		StrictStartup bool
+		// ShutdownTimeout bounds the shutdown run by Fatal before the process exits.
+		ShutdownTimeout time.Duration
+		// Deterministic freezes the clock, drops callers and stack traces and sorts fields.
+		Deterministic bool
+		// Uninherited are the parts a logger derived by WithOptions does not take from its parent.
+		Uninherited []InheritedPart
 	}
 )
 
@@ -77,6 +227,57 @@ func WithLevel(level Level) Option {
 func WithEncoding(encoding Encoding) Option {
 	return func(c *config) {
 		c.Encoding = encoding
+		c.AutoEncoding = false
+	}
+}
+
+// WithAutoEncoding picks the encoding when the logger is created, so one binary
+// behaves well both locally and in containers: colored console output when the
+// standard streams among the output paths are terminals, and JSON otherwise, e.g. when
+// output is piped or collected from a container. In CI, detected by the CI environment
+// variable, output is console without colors. Colors follow the theme set with
+// WithColorTheme, or else DarkTheme, or LightTheme when the COLORFGBG environment
+// variable names a light background; NO_COLOR turns them off. A later WithEncoding
+// overrides the choice.
+//
+// Example:
+//
+//	log, err := logger.NewLogger(logger.WithAutoEncoding())
+//	// go run .           -> 2026-10-16T19:20:00.000Z  INFO  server started  {"port": 8080}
+//	// ./server | jq .    -> {"level":"INFO","time":"2026-10-16T19:20:00.000Z","message":"server started","port":8080}
+func WithAutoEncoding() Option {
+	return func(c *config) {
+		c.AutoEncoding = true
+	}
+}
+
+// WithContainerOutput makes the output conform to what container log drivers and
+// collectors expect from stdout, such as the Docker json-file driver, containerd's CRI
+// log format read by Kubernetes and the Fluent Bit or Vector agents tailing it: one
+// JSON object per line terminated by a newline, no ANSI escape codes and UTC RFC 3339
+// timestamps with nanoseconds. It overrides WithEncoding, WithAutoEncoding and
+// WithColorTheme.
+//
+// Runtimes split lines longer than 16 KiB themselves, leaving fragments that are not
+// valid JSON. Entries longer than maxLineBytes are therefore split by the logger into
+// parts that are each a JSON object of at most maxLineBytes, with the level and time of
+// the entry, an empty message and the fields partial_id, shared by the parts of an entry, partial_index,
+// partial_last, true on the last part, and partial_message, a piece of the encoded
+// entry. Concatenating the partial_message values in partial_index order restores the
+// entry.
+//
+// Parameters:
+//   - maxLineBytes: The maximum length of a line including the newline; zero uses
+//     16384, and values below 512 make NewLogger fail
+//
+// Example:
+//
+//	log, err := logger.NewLogger(logger.WithContainerOutput(0))
+//	// {"level":"INFO","time":"2026-10-16T19:20:00.123456789Z","message":"server started","port":8080}
+func WithContainerOutput(maxLineBytes int) Option {
+	return func(c *config) {
+		c.ContainerOutput = true
+		c.ContainerMaxLine = maxLineBytes
 	}
 }
 
@@ -84,7 +285,8 @@ func WithEncoding(encoding Encoding) Option {
 // Different modes have optimized defaults for their use cases.
 //
 // Parameters:
-//   - appMode: The application mode (development, staging, production)
+//   - appMode: The application mode (development, staging, production) or a mode
+//     registered with RegisterAppMode
 //
 // Example:
 //
@@ -95,6 +297,28 @@ func WithAppMode(appMode AppMode) Option {
 	}
 }
 
+// WithCLIFlags sets the level and format selected by the logging flags of a
+// command-line tool, see AddFlags and BindCLIFlags. The flags are read when the logger
+// is created, so parse them first.
+//
+// Parameters:
+//   - flags: The flags returned by AddFlags or BindCLIFlags
+//
+// Example:
+//
+//	cli := logger.BindCLIFlags(nil)
+//	flag.Parse()
+//	log, err := logger.NewLogger(logger.WithAppMode(logger.AppModeCLI), logger.WithCLIFlags(cli))
+func WithCLIFlags(flags *CLIFlags) Option {
+	return func(c *config) {
+		c.Level = flags.Level()
+		if flags.Format != "" {
+			c.Encoding = flags.Format
+			c.AutoEncoding = false
+		}
+	}
+}
+
 // WithNewRelicApp enables New Relic integration for log forwarding.
 // When provided, logs will be automatically sent to New Relic for monitoring.
 //
@@ -111,6 +335,28 @@ func WithNewRelicApp(newRelicApp *newrelic.Application) Option {
 	}
 }
 
+// WithNewRelicLevel sets a separate minimum level for entries forwarded to New Relic,
+// while local output keeps the level set with WithLevel. Forwarding only warnings and
+// errors keeps ingest costs down without losing local detail. Without this option New
+// Relic receives every entry written locally. Forwarded entries are sampled, filtered,
+// renamed and redacted like local ones. It has no effect without WithNewRelicApp.
+//
+// Parameters:
+//   - level: The minimum level forwarded (debug, info, warning, error, panic, fatal)
+//
+// Example:
+//
+//	logger := NewLogger(
+//	    WithLevel(LevelInfo),
+//	    WithNewRelicApp(app),
+//	    WithNewRelicLevel(LevelWarning), // info stays local, warn+ is forwarded
+//	)
+func WithNewRelicLevel(level Level) Option {
+	return func(c *config) {
+		c.NewRelicLevel = level
+	}
+}
+
 // WithDefaultConfig applies sensible default configuration values.
 // This provides a good starting point for most applications.
 //
@@ -120,6 +366,8 @@ func WithNewRelicApp(newRelicApp *newrelic.Application) Option {
 //   - AppMode: Development (includes caller info)
 //   - Output: stdout for logs, stderr for errors
 //   - Stacktrace and caller info: disabled for performance
+//   - Log files: mode 0644, parent directories are not created, no fsync
+//   - Reflected fields: cycles detected, nested at most 32 levels deep
 //
 // Example:
 //
@@ -139,6 +387,15 @@ func WithDefaultConfig() Option {
 		c.DisableCaller = true
 		c.OutputPaths = []string{"stdout"}
 		c.ErrorOutputPaths = []string{"stderr"}
+		c.FileMode = 0o644
+		c.DirMode = 0o755
+		c.CreateDirs = false
+		c.FileUID = -1
+		c.FileGID = -1
+		c.FsyncOnError = false
+		c.OnceCacheSize = 1024
+		c.ConsoleHumanize = true
+		c.MaxFieldDepth = defaultMaxFieldDepth
 	}
 }
 
@@ -247,6 +504,40 @@ func WithDisableStacktrace(disableStacktrace bool) Option {
 	}
 }
 
+// WithStacktraceDepth keeps at most n frames of stack traces, starting from the
+// innermost frame, so stack traces are shorter and cheaper to store. Combined with
+// WithStacktraceFilter, n counts the frames left after filtering.
+//
+// Parameters:
+//   - n: The maximum number of frames; zero keeps every frame (default: 0)
+//
+// Example:
+//
+//	logger := NewLogger(WithDisableStacktrace(false), WithStacktraceDepth(5))
+func WithStacktraceDepth(n int) Option {
+	return func(c *config) {
+		c.StacktraceDepth = n
+	}
+}
+
+// WithStacktraceFilter removes frames that are never relevant to the application from
+// stack traces: frames of the Go runtime, zap and this module. File paths in the module
+// cache, GOPATH and GOROOT are trimmed to their module or package relative path, e.g.
+// github.com/lib/pq@v1.10.9/conn.go instead of /home/app/go/pkg/mod/github.com/lib/pq@v1.10.9/conn.go.
+// A stack trace whose frames would all be removed is kept unchanged.
+//
+// Parameters:
+//   - enabled: Whether stack traces are filtered (default: false)
+//
+// Example:
+//
+//	logger := NewLogger(WithDisableStacktrace(false), WithStacktraceFilter(true))
+func WithStacktraceFilter(enabled bool) Option {
+	return func(c *config) {
+		c.StacktraceFilter = enabled
+	}
+}
+
 // WithDisableCaller controls whether caller information is included in logs.
 // Caller info shows file name and line number but adds performance overhead.
 //
@@ -291,3 +582,1033 @@ func WithErrorOutputPaths(errorOutputPaths []string) Option {
 		c.ErrorOutputPaths = errorOutputPaths
 	}
 }
+
+// WithFileMode sets the permission bits applied to log files the logger creates.
+// The mode is not affected by the process umask. Existing files, and devices such as
+// /dev/null, keep their mode.
+//
+// Parameters:
+//   - mode: The file permission bits (default: 0644)
+//
+// Example:
+//
+//	logger := NewLogger(WithFileMode(0o600)) // Only the owner can read the logs
+func WithFileMode(mode os.FileMode) Option {
+	return func(c *config) {
+		c.FileMode = mode
+	}
+}
+
+// WithCreateDirs controls whether missing parent directories of log files are created.
+// Created directories use the mode given by WithDirMode.
+//
+// Parameters:
+//   - createDirs: true to create missing directories, false to fail instead
+//
+// Example:
+//
+//	logger := NewLogger(WithCreateDirs(true), WithOutputPaths([]string{"/var/log/app/app.log"}))
+func WithCreateDirs(createDirs bool) Option {
+	return func(c *config) {
+		c.CreateDirs = createDirs
+	}
+}
+
+// WithDirMode sets the permission bits of directories created for log files.
+// Only used when WithCreateDirs is enabled.
+//
+// Parameters:
+//   - mode: The directory permission bits (default: 0755)
+//
+// Example:
+//
+//	logger := NewLogger(WithCreateDirs(true), WithDirMode(0o750))
+func WithDirMode(mode os.FileMode) Option {
+	return func(c *config) {
+		c.DirMode = mode
+	}
+}
+
+// WithFileOwner sets the owner and group of log files the logger creates.
+// Pass -1 for either value to leave it unchanged. Changing ownership usually
+// requires elevated privileges and is not supported on Windows.
+//
+// Parameters:
+//   - uid: The numeric user ID of the owner
+//   - gid: The numeric group ID
+//
+// Example:
+//
+//	logger := NewLogger(WithFileOwner(-1, 4)) // Hand the files to the adm group
+func WithFileOwner(uid, gid int) Option {
+	return func(c *config) {
+		c.FileUID = uid
+		c.FileGID = gid
+	}
+}
+
+// WithFsyncOnError controls whether sinks are synced after every error-level entry.
+// Syncing guarantees that errors reach stable storage before the application
+// continues, at the cost of an fsync per error entry. stdout and stderr are only synced
+// when redirected to a file, since terminals and pipes cannot be synced.
+//
+// Parameters:
+//   - fsyncOnError: true to sync after error, panic and fatal entries
+//
+// Example:
+//
+//	logger := NewLogger(WithFsyncOnError(true)) // Never lose an error on power loss
+func WithFsyncOnError(fsyncOnError bool) Option {
+	return func(c *config) {
+		c.FsyncOnError = fsyncOnError
+	}
+}
+
+// WithFileEncryption enables envelope encryption for file sinks.
+// Each time a file is opened a random AES-256-GCM data key is generated and stored
+// in the file wrapped by the KeyWrapper; every entry is then encrypted with that key and
+// tagged with its id, so several writers can share a file.
+// Use NewAESKeyWrapper for a locally configured key or implement KeyWrapper to use a KMS.
+// Encrypted files can be read back with DecryptLog or the go-logger decrypt command.
+//
+// Parameters:
+//   - wrapper: The KeyWrapper protecting the data keys
+//
+// Example:
+//
+//	wrapper, _ := NewAESKeyWrapper(key)
+//	logger := NewLogger(WithOutputPaths([]string{"/var/log/app.log"}), WithFileEncryption(wrapper))
+func WithFileEncryption(wrapper KeyWrapper) Option {
+	return func(c *config) {
+		c.FileEncryption = wrapper
+	}
+}
+
+// WithSharedFileWrites makes file sinks safe to share between processes, e.g. several
+// workers writing to one file per host. Every entry is written to the file, opened
+// with O_APPEND, in a single write call, so lines of different processes never
+// interleave. Entries longer than maxRecordSize bytes are cut and end with
+// " [truncated]". It cannot be combined with WithFileEncryption.
+//
+// Parameters:
+//   - maxRecordSize: The maximum size of an entry in bytes; zero or less uses 64 KiB
+//
+// Example:
+//
+//	logger := NewLogger(WithOutputPaths([]string{"/var/log/workers.log"}), WithSharedFileWrites(16*1024))
+func WithSharedFileWrites(maxRecordSize int) Option {
+	return func(c *config) {
+		c.SharedFileWrites = true
+		c.MaxRecordSize = maxRecordSize
+	}
+}
+
+// WithOnceCacheSize sets how many keys Once and Every remember.
+// Keys are evicted least recently used first; an evicted key is treated as new.
+//
+// Parameters:
+//   - size: The maximum number of tracked keys (default: 1024)
+//
+// Example:
+//
+//	logger := NewLogger(WithOnceCacheSize(10000)) // Many distinct keys per process
+func WithOnceCacheSize(size int) Option {
+	return func(c *config) {
+		c.OnceCacheSize = size
+	}
+}
+
+// WithFilter adds a filter dropping entries for which it returns true.
+// Filters are evaluated before encoding and see every field of the entry,
+// including fields added with With and extracted from the context. Dropped
+// entries reach no output: no sink, capture, crash report, metric, dual-write
+// copy or New Relic.
+// The option can be repeated; an entry is dropped when any filter matches.
+//
+// Parameters:
+//   - filter: The predicate selecting entries to drop
+//
+// Example:
+//
+//	logger := NewLogger(
+//	    WithFilter(FilterFieldEquals("path", "/healthz")),
+//	    WithFilter(func(e Entry) bool { return e.LoggerName == "noisy" && e.Level < zapcore.WarnLevel }),
+//	)
+func WithFilter(filter Filter) Option {
+	return func(c *config) {
+		c.Filters = append(c.Filters, filter)
+	}
+}
+
+// WithDebugWhenSampled emits debug entries for requests whose trace is sampled.
+// The configured level stays in effect for every other context, so debug output
+// is available exactly for the requests that also have a full trace. The sampling
+// decision is read from the OpenTelemetry span, the New Relic transaction or the
+// flags of an incoming traceparent header, in that order.
+//
+// Parameters:
+//   - enabled: Whether sampled traces log at DebugLevel (default: false)
+//
+// Example:
+//
+//	logger := NewLogger(WithLevel(LevelInfo), WithDebugWhenSampled(true))
+//	logger.Debug(ctx, "cache miss", zap.String("key", key)) // written only when ctx is sampled
+func WithDebugWhenSampled(enabled bool) Option {
+	return func(c *config) {
+		c.DebugWhenSampled = enabled
+	}
+}
+
+// WithLogSchemaVersion stamps a schema_version field on every entry.
+// Bump the version whenever field names or types change so downstream parsers
+// can select the right mapping; combine it with WithFieldRename to migrate fields.
+//
+// Parameters:
+//   - version: The schema version of the entries (e.g., "2")
+//
+// Example:
+//
+//	logger := NewLogger(WithLogSchemaVersion("2"))
+func WithLogSchemaVersion(version string) Option {
+	return func(c *config) {
+		c.SchemaVersion = version
+	}
+}
+
+// WithCorrelationCheck reports the call sites of entries written without any of the
+// correlation keys, trace_id and request_id by default, whether they come from the
+// context, With or the log call. It finds the code paths that lost the correlation
+// context, e.g. a goroutine started with context.Background() in a request handler.
+// Each call site is reported once on the diagnostics channel as a missing_correlation
+// warning; in AppModeDevelopment it is reported as an internal failure at error level,
+// also shown as the last error of GetStats. Entries are written either way, and
+// GetStats counts them in UncorrelatedEntries. Startup and background job entries are
+// reported as well; read the report as a list of places to check.
+//
+// Parameters:
+//   - keys: The correlation keys, any of which makes an entry correlated; none uses
+//     ContextKeyTraceID and ContextKeyRequestID
+//
+// Example:
+//
+//	logger := NewLogger(WithAppMode(AppModeDevelopment), WithCorrelationCheck())
+//	go func() { log.Info(context.Background(), "charged") }()
+//	// stderr: {"level":"ERROR","logger":"logger.diagnostics","message":"logger internal failure","diagnostic":"missing_correlation",
+//	//          "error":"log entry without correlation fields","call_site":"billing/charge.go:42","entry_message":"charged",...}
+func WithCorrelationCheck(keys ...ContextKey) Option {
+	if len(keys) == 0 {
+		keys = []ContextKey{ContextKeyTraceID, ContextKeyRequestID}
+	}
+	return func(c *config) {
+		c.CorrelationKeys = slices.Clone(keys)
+	}
+}
+
+// WithDualWrite copies entries to a secondary logger during a migration between log
+// schemas or vendors, so the new pipeline can be checked against the old one with real
+// traffic and then cut over without changing call sites. The secondary logger keeps its
+// own level, sampling, redaction, encoding, sinks, field renames and schema version,
+// and receives the fields of the call, the context and With. The share of copied entries ramps up linearly over Ramp to Percent and
+// copying stops after Duration. Progress is reported in the DualWrite field of GetStats.
+//
+// Parameters:
+//   - migration: The secondary logger, period and ramp of the migration
+//
+// Example:
+//
+//	vendorB, err := logger.NewLogger(
+//	    logger.WithOutputPaths([]string{"https://logs.vendor-b.example/ingest"}),
+//	    logger.WithFieldRename("user_id", "usr.id", false),
+//	    logger.WithLogSchemaVersion("3"),
+//	)
+//	log, err := logger.NewLogger(logger.WithDualWrite(logger.DualWrite{
+//	    Secondary: vendorB,
+//	    Ramp:      24 * time.Hour,     // 0% to 100% of entries over a day
+//	    Duration:  14 * 24 * time.Hour, // then two weeks before the cutover
+//	}))
+func WithDualWrite(migration DualWrite) Option {
+	return func(c *config) {
+		if migration.Start.IsZero() {
+			migration.Start = time.Now()
+		}
+		c.DualWrite = &migration
+	}
+}
+
+// WithOTelResource stamps the attributes of an OpenTelemetry resource on every entry,
+// such as service.name, service.version, deployment.environment, k8s.pod.name or
+// cloud.region, keyed by the attribute keys. Passing the resource of the tracer
+// provider makes logs and traces describe the workload identically, with the same
+// detectors run once. The telemetry.sdk.* attributes, which describe the SDK rather
+// than the workload, are skipped. Attributes are read when the option is applied.
+//
+// Parameters:
+//   - res: The resource, e.g. a *resource.Resource of go.opentelemetry.io/otel/sdk;
+//     nil adds no fields
+//
+// Example:
+//
+//	res, err := resource.New(ctx, resource.WithFromEnv(), resource.WithHost(), resource.WithContainer())
+//	tp := sdktrace.NewTracerProvider(sdktrace.WithResource(res))
+//	log, err := logger.NewLogger(logger.WithOTelResource(res))
+//	// {"level":"INFO","message":"started","service.name":"checkout","k8s.pod.name":"checkout-7d9f","host.name":"node-3"}
+func WithOTelResource(res OTelResource) Option {
+	fields := resourceFields(res)
+	return func(c *config) {
+		c.ResourceFields = fields
+	}
+}
+
+// WithCloudMetadata stamps the region, zone, instance ID and instance type of the cloud
+// instance on every entry, as cloud_provider, cloud_region, cloud_zone, instance_id and
+// instance_type, so entries can be correlated with infrastructure events without
+// configuring the log agent. The AWS, GCP and Azure instance metadata services are
+// queried once per process when the first logger is created, within the timeout;
+// the result is cached for later loggers. Off the cloud no fields are added and a
+// cloud_metadata_unavailable diagnostic is written.
+//
+// Parameters:
+//   - timeout: The time allowed for the detection, delaying the creation of the first
+//     logger off the cloud; zero uses 500ms
+//
+// Example:
+//
+//	log, err := logger.NewLogger(logger.WithCloudMetadata(0))
+//	// {"level":"INFO","message":"started","cloud_provider":"aws","cloud_region":"eu-west-1",
+//	//  "cloud_zone":"eu-west-1b","instance_id":"i-0abc123","instance_type":"m6i.large"}
+func WithCloudMetadata(timeout time.Duration) Option {
+	if timeout <= 0 {
+		timeout = defaultCloudMetadataTimeout
+	}
+	return func(c *config) {
+		c.CloudMetadataTimeout = timeout
+	}
+}
+
+// WithCrashReport writes a JSON crash report to path when the process crashes through
+// Fatal, Panic or an unhandled panic caught by ReportCrash, so the last moments of the
+// process survive when the log pipeline loses the entries written during the crash. The
+// report holds the last entries logged, kept in a ring buffer after redaction, the stack
+// of the crashing goroutine and the configuration summary of LogConfig. It is written
+// atomically before the logger shuts down, replacing the report of an earlier crash.
+// Keeping the entries costs one extra encoding per entry.
+//
+// Parameters:
+//   - path: The report file; its directory must exist
+//   - entries: The number of last entries in the report; zero keeps 100
+//
+// Example:
+//
+//	log, err := logger.NewLogger(logger.WithCrashReport("/var/log/app/crash.json", 200))
+//	defer logger.ReportCrash(ctx, log)
+func WithCrashReport(path string, entries int) Option {
+	return func(c *config) {
+		c.CrashReportPath = path
+		c.CrashReportEntries = entries
+	}
+}
+
+// WithIDGenerator replaces the generator of the IDs the package creates: the request
+// IDs of HTTPMiddleware, the gRPC and Connect interceptors and ConsumerMiddleware for
+// requests and messages without an X-Request-ID, the txn_id of BeginRequest and the
+// job_run_id of StartJob. IDs can then follow the conventions of the organization:
+// ULIDs, UUIDv7, Snowflake IDs or prefixed IDs. The generator must be safe for
+// concurrent use. By default IDs are 128 bit random hex strings.
+//
+// Parameters:
+//   - generator: Returns a new ID on every call
+//
+// Example:
+//
+//	logger := NewLogger(WithIDGenerator(func() string {
+//	    return "req_" + ulid.Make().String()
+//	}))
+func WithIDGenerator(generator func() string) Option {
+	return func(c *config) {
+		c.IDGenerator = generator
+	}
+}
+
+// WithFieldRename renames a field key right before encoding, wherever the field comes from.
+// With dualWrite the field is written under both keys, which lets parsers move to
+// the new name while old dashboards keep working. A migration typically runs in
+// three steps: dual-write and bump the schema version, move consumers to the new
+// key, then drop dualWrite.
+//
+// Parameters:
+//   - from: The current field key
+//   - to: The new field key
+//   - dualWrite: Whether the field is also kept under the current key
+//
+// Example:
+//
+//	logger := NewLogger(
+//	    WithLogSchemaVersion("3"),
+//	    WithFieldRename("user_id", "usr.id", true),
+//	)
+func WithFieldRename(from, to string, dualWrite bool) Option {
+	return func(c *config) {
+		c.FieldRenames = append(c.FieldRenames, fieldRename{From: from, To: to, DualWrite: dualWrite})
+	}
+}
+
+// WithRedactedFields replaces the values of fields with the keys by [REDACTED], wherever
+// the field comes from. Only top-level field keys are matched, before any rename. The
+// keys can be changed at runtime with SetRedactedFields or WatchConfigFile.
+//
+// Parameters:
+//   - keys: The keys of the fields to redact
+//
+// Example:
+//
+//	logger := NewLogger(WithRedactedFields("password", "card_number"))
+//	log.Info(ctx, "login", zap.String("password", "hunter2")) // "password":"[REDACTED]"
+func WithRedactedFields(keys ...string) Option {
+	return func(c *config) {
+		c.RedactedFields = append(c.RedactedFields, keys...)
+	}
+}
+
+// WithConsoleHumanize controls whether console encoding renders values for humans.
+// When enabled, integer fields named bytes or ending in _bytes are written as
+// KiB/MiB and durations are rounded to three significant digits ("1.23s", "340ms").
+// JSON encoding always keeps raw numbers.
+//
+// Parameters:
+//   - enabled: Whether console output is humanized (default: true)
+//
+// Example:
+//
+//	logger := NewLogger(WithEncoding(EncodingConsole), WithConsoleHumanize(false)) // Raw numbers
+func WithConsoleHumanize(enabled bool) Option {
+	return func(c *config) {
+		c.ConsoleHumanize = enabled
+	}
+}
+
+// WithConsoleTemplate sets a text/template layout for console lines.
+// The template receives a ConsoleLine with Time, Level, Logger, Caller, Message,
+// Fields (a JSON object) and Stack, plus the helpers upper, lower and pad.
+// A trailing newline is added when the template has none. NewLogger returns an
+// error if the template does not parse. JSON encoding ignores the template.
+//
+// Parameters:
+//   - layout: The template of a console line
+//
+// Example:
+//
+//	logger := NewLogger(
+//	    WithEncoding(EncodingConsole),
+//	    WithConsoleTemplate(`{{.Time}} [{{pad 5 .Level}}] {{.Caller}} {{.Message}} {{.Fields}}`),
+//	)
+func WithConsoleTemplate(layout string) Option {
+	return func(c *config) {
+		c.ConsoleTemplate = layout
+	}
+}
+
+// WithColorTheme colors console output with a theme: per-level colors of the level
+// names, colors of the time, logger name, caller and message, and per-key colors of
+// fields. DarkTheme, LightTheme and HighContrastTheme are built in; NoColorTheme turns
+// colors off. Whatever the theme, output stays uncolored when the NO_COLOR environment
+// variable is set, with JSON encoding, with a console template and with
+// WithDeterministicOutput.
+//
+// Parameters:
+//   - theme: The color theme
+//
+// Example:
+//
+//	theme := logger.LightTheme()
+//	theme.Keys["request_id"] = "38;5;90"
+//	log, err := logger.NewLogger(logger.WithEncoding(logger.EncodingConsole), logger.WithColorTheme(theme))
+func WithColorTheme(theme ColorTheme) Option {
+	return func(c *config) {
+		c.ColorTheme = &theme
+	}
+}
+
+// WithMultilineMode controls how multi-line strings and stack traces are encoded,
+// instead of the default that differs between encodings: JSON escapes newlines while
+// console writes the message and stack trace raw. MultilineEscaped keeps every entry on
+// one line for strict line-based shippers, MultilineRaw writes multi-line fields raw
+// below the console line, and MultilineArray writes multi-line fields and stack traces
+// as arrays of lines. NewLogger returns an error for unknown modes and for
+// MultilineRaw with JSON encoding.
+//
+// Parameters:
+//   - mode: The multiline mode (default: MultilineDefault)
+//
+// Example:
+//
+//	logger := NewLogger(WithEncoding(EncodingConsole), WithMultilineMode(MultilineEscaped))
+//	log.Error(ctx, "query failed\nSELECT 1") // 2026-10-16T18:30:00.000Z	ERROR	query failed\nSELECT 1	{"stack_trace": "main.main\n\t/app/main.go:12"}
+func WithMultilineMode(mode MultilineMode) Option {
+	return func(c *config) {
+		c.MultilineMode = mode
+	}
+}
+
+// WithModuleRelativeCaller writes callers relative to the module root instead of
+// zap's short package/file.go form, which is ambiguous when packages in different
+// directories share a name. A caller in internal/billing/db/repo.go is written as
+// internal/billing/db/repo.go:42 instead of db/repo.go:42; callers in other modules
+// keep their full import path. The module root is the main module of the binary
+// unless set with WithCallerModule. Without build info, e.g. in binaries built
+// outside module mode, zap's short format is kept.
+//
+// Parameters:
+//   - includeFunction: Whether to append the function name, e.g. internal/db/repo.go:42 (*Repo).Get
+//
+// Example:
+//
+//	logger := NewLogger(WithModuleRelativeCaller(true))
+func WithModuleRelativeCaller(includeFunction bool) Option {
+	return func(c *config) {
+		c.CallerModuleRelative = true
+		c.CallerFunction = includeFunction
+	}
+}
+
+// WithCallerModule sets the module root used by WithModuleRelativeCaller.
+// Use it when the logger is created by a library or test binary whose main module
+// is not the module the callers should be relative to.
+//
+// Parameters:
+//   - modulePath: The import path of the module root, e.g. github.com/acme/billing
+//
+// Example:
+//
+//	logger := NewLogger(WithModuleRelativeCaller(false), WithCallerModule("github.com/acme/billing"))
+func WithCallerModule(modulePath string) Option {
+	return func(c *config) {
+		c.CallerModule = modulePath
+	}
+}
+
+// WithGoroutineID adds the ID of the logging goroutine to every entry as goroutine_id.
+// It untangles interleaved output of concurrent goroutines when no worker label is
+// set with LabelGoroutine. Reading the ID requires a short stack capture per entry,
+// so keep it disabled on hot paths in production.
+//
+// Parameters:
+//   - enabled: Whether to add the goroutine ID (default: false)
+//
+// Example:
+//
+//	logger := NewLogger(WithGoroutineID(true))
+func WithGoroutineID(enabled bool) Option {
+	return func(c *config) {
+		c.GoroutineID = enabled
+	}
+}
+
+// WithLatencyHistogram records the duration of every access log entry written by
+// HTTPMiddleware into the http_server_request_duration_seconds histogram, labeled
+// by method, route and status. The metric is derived from the logged fields, so
+// handlers need no second instrumentation middleware. Durations are recorded for
+// every request, including entries dropped by sampling, filters or the level.
+// Routes come from the http.ServeMux pattern; requests without a pattern are
+// labeled "unmatched", as raw paths would create a series per ID. Observations of
+// requests with a trace ID carry it as exemplar, so dashboards can jump from a
+// latency spike to a trace and its logs. Loggers sharing a registerer share the
+// histogram.
+//
+// Parameters:
+//   - registerer: The registerer of the histogram; nil uses prometheus.DefaultRegisterer
+//   - buckets: The bucket upper bounds in seconds; empty uses prometheus.DefBuckets
+//
+// Example:
+//
+//	logger := NewLogger(WithLatencyHistogram(nil, 0.01, 0.05, 0.1, 0.5, 1, 5))
+func WithLatencyHistogram(registerer prometheus.Registerer, buckets ...float64) Option {
+	return func(c *config) {
+		c.LatencyHistogram = true
+		if registerer != nil {
+			c.MetricsRegisterer = registerer
+		}
+		c.LatencyBuckets = buckets
+	}
+}
+
+// WithMetricRules turns log entries into Prometheus metrics without a separate
+// pipeline. Each rule increments a counter or sets a gauge when an entry matches
+// its predicate, with labels read from the entry's fields. Rules see entries at
+// InfoLevel and above, including entries dropped by sampling, filters or the level,
+// and run on the logging goroutine, so keep predicates cheap. NewLogger returns an
+// error for invalid rules. Metrics are registered with the registerer passed to
+// this option or WithLatencyHistogram; rules of loggers sharing a registerer share
+// their metrics. Counter increments of entries with a trace ID carry the trace_id
+// and span_id as exemplar, linking an error-rate spike to a trace and its logs.
+// The option can be repeated.
+//
+// Parameters:
+//   - registerer: The registerer of the metrics; nil uses prometheus.DefaultRegisterer
+//   - rules: The metric rules
+//
+// Example:
+//
+//	logger := NewLogger(WithMetricRules(nil, MetricRule{
+//	    Name:   "payment_failed_total",
+//	    Match:  FilterMessage("payment failed"),
+//	    Labels: []string{"provider"},
+//	}))
+func WithMetricRules(registerer prometheus.Registerer, rules ...MetricRule) Option {
+	return func(c *config) {
+		if registerer != nil {
+			c.MetricsRegisterer = registerer
+		}
+		c.MetricRules = append(c.MetricRules, rules...)
+	}
+}
+
+// WithErrorStormSuppression protects log pipelines during incident storms.
+// Once more than threshold identical error entries (same logger name and message)
+// are logged within window, further occurrences are suppressed. Every interval a
+// summary entry with the same message is written instead, carrying suppressed_count,
+// first_suppressed_at and last_suppressed_at. The storm ends when an interval passes
+// without occurrences. Pending summaries are also written when the logger is synced.
+// Only ErrorLevel entries are suppressed; panic and fatal entries are always written.
+//
+// Parameters:
+//   - threshold: The number of identical error entries written per window
+//   - window: The window in which identical entries are counted
+//   - interval: The interval between summaries while a storm lasts
+//
+// Example:
+//
+//	logger := NewLogger(WithErrorStormSuppression(20, time.Minute, 30*time.Second))
+func WithErrorStormSuppression(threshold int, window, interval time.Duration) Option {
+	return func(c *config) {
+		c.ErrorStormThreshold = threshold
+		c.ErrorStormWindow = window
+		c.ErrorStormInterval = interval
+	}
+}
+
+// WithCallSiteRateLimit rate limits entries per call site (file:line), so one noisy
+// loop cannot flood the sinks and crowd out the entries of the rest of the program.
+// Every call site gets its own token bucket; entries beyond its budget are dropped and
+// counted per call site by GetStats. It complements WithErrorStormSuppression, which
+// groups entries by message. Call sites are captured even when callers are disabled,
+// without being written. At most 1024 call sites are limited; entries of other call
+// sites, as well as panic and fatal entries, are always written.
+//
+// Parameters:
+//   - rate: The sustained number of entries per second per call site
+//   - burst: The number of entries a call site may write at once
+//
+// Example:
+//
+//	logger := NewLogger(WithCallSiteRateLimit(50, 100))
+//	dropped := GetStats(logger).CallSiteDropped // e.g. {"worker/poll.go:87": 12840}
+func WithCallSiteRateLimit(rate float64, burst int) Option {
+	return func(c *config) {
+		c.CallSiteRateLimit = rate
+		c.CallSiteBurst = burst
+	}
+}
+
+// WithShutdownTimeout bounds the shutdown run when Fatal exits the process.
+// Fatal runs the hooks registered with OnShutdown, syncs the logger and closes its
+// sinks before calling os.Exit(1); hooks still running after the timeout are abandoned.
+//
+// Parameters:
+//   - timeout: The maximum duration of the shutdown (default: 5s)
+//
+// Example:
+//
+//	logger := NewLogger(WithShutdownTimeout(2 * time.Second))
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.ShutdownTimeout = timeout
+	}
+}
+
+// WithStrictStartup makes NewLogger fail when a sink fails its startup checks, rather
+// than discovering a broken pipeline hours later. The hosts of URL sinks are resolved
+// and the checks registered with RegisterSinkCheck run, bounded by 10 seconds; files
+// are opened for writing in any case. Without strict startup the checks run in the
+// background and failures are reported as sink_check_error diagnostics.
+//
+// Example:
+//
+//	logger, err := NewLogger(
+//	    WithOutputPaths([]string{"stdout", "kafka://broker:9092/app-logs"}),
+//	    WithStrictStartup(),
+//	)
+func WithStrictStartup() Option {
+	return func(c *config) {
+		c.StrictStartup = true
+	}
+}
+
+// WithContextDeadline annotates every entry with the remaining time until the
+// deadline of its context (deadline_remaining) and, once the context is done, with
+// context_canceled and the cause (context_cause). This shows where the time budget
+// of a request went when debugging timeout cascades across services.
+//
+// Parameters:
+//   - enabled: Whether to annotate entries (default: false)
+//
+// Example:
+//
+//	logger := NewLogger(WithContextDeadline(true))
+//	// {"message":"calling inventory","deadline_remaining":"212ms"}
+//	// {"message":"inventory failed","deadline_remaining":"-3ms","context_canceled":true,"context_cause":"context deadline exceeded"}
+func WithContextDeadline(enabled bool) Option {
+	return func(c *config) {
+		c.ContextDeadline = enabled
+	}
+}
+
+// WithUserAgentParsing parses every user_agent field, such as the one written by
+// HTTPMiddleware, into ua_browser, ua_browser_version, ua_os, ua_os_version and
+// ua_device (bot, mobile or desktop), so client analytics can be done downstream
+// without re-parsing strings. Parsed user agents are cached in an LRU cache, as a
+// service sees few distinct clients. The fields are added before filters run, so
+// filters can match them.
+//
+// Parameters:
+//   - cacheSize: The number of parsed user agents cached; zero or less uses 1024
+//
+// Example:
+//
+//	logger := NewLogger(WithUserAgentParsing(0))
+//	// {"message":"http request","user_agent":"Mozilla/5.0 ...","ua_browser":"Firefox","ua_os":"Linux","ua_device":"desktop",...}
+func WithUserAgentParsing(cacheSize int) Option {
+	return func(c *config) {
+		c.UserAgentParsing = true
+		c.UserAgentCacheSize = cacheSize
+	}
+}
+
+// WithEnricher adds an enricher whose fields, derived from the context, are added to
+// every entry logged with a context, next to the context keys and dimensions. The
+// option can be repeated; enrichers run in the order of the options.
+//
+// Parameters:
+//   - enricher: The enricher, e.g. from NewGeoIPEnricher
+//
+// Example:
+//
+//	logger := NewLogger(
+//	    WithEnricher(NewGeoIPEnricher(db, 0, 0)),
+//	    WithEnricher(EnricherFunc(func(ctx context.Context) []Field {
+//	        return []Field{zap.String("region", os.Getenv("REGION"))}
+//	    })),
+//	)
+func WithEnricher(enricher Enricher) Option {
+	return func(c *config) {
+		c.Enrichers = append(c.Enrichers, enricher)
+	}
+}
+
+// WithIngestBudget limits the bytes written to an output path per hour, day or other
+// period, to keep runaway debug logging from driving up the observability bill. Once
+// the budget of a period is exhausted, a warning is written and the action applies
+// until the next period: BudgetDegrade writes only warnings and errors, BudgetSample
+// writes one of every SampleEvery entries and every error, and BudgetDrop drops every
+// entry. The number of dropped entries is written as a summary when the period ends
+// and when the logger is synced. Other output paths are not affected. Usage is
+// reported by GetStats. The option can be repeated for different output paths.
+//
+// Parameters:
+//   - sink: The output path the budget applies to, as passed to WithOutputPaths
+//   - budget: The budget and the action applied once it is exhausted
+//
+// Example:
+//
+//	logger := NewLogger(
+//	    WithOutputPaths([]string{"stdout", "/var/log/app.log"}),
+//	    WithIngestBudget("stdout", IngestBudget{Bytes: 5 << 30, Period: 24 * time.Hour, Action: BudgetDegrade}),
+//	)
+func WithIngestBudget(sink string, budget IngestBudget) Option {
+	return func(c *config) {
+		c.IngestBudgets = append(c.IngestBudgets, sinkBudget{sink: sink, budget: budget})
+	}
+}
+
+// WithResidencyRouting routes entries to the sinks of their data residency region,
+// e.g. EU entries to the EU collector. The region of an entry is the value of its
+// data_residency field, or of its region field, whether added with With, by the log
+// call or from the ContextKeyDataResidency context key. Entries of a region are written
+// only to its sinks. Unclassified entries, without a region or with an unknown one, go
+// to the Default region, are dropped with RejectUnclassified or else go to the output
+// paths. Rejected entries are counted by GetStats. NewLogger returns an error when a
+// region has no sinks or its sinks cannot be opened.
+//
+// Parameters:
+//   - residency: The sinks per region and the handling of unclassified entries
+//
+// Example:
+//
+//	logger := NewLogger(WithResidencyRouting(ResidencyConfig{
+//	    Regions: map[string][]string{
+//	        "eu": {"https://collector.eu.example.com/logs"},
+//	        "us": {"https://collector.us.example.com/logs"},
+//	    },
+//	    RejectUnclassified: true,
+//	}))
+//	ctx = context.WithValue(ctx, ContextKeyDataResidency, "eu")
+//	log.Info(ctx, "profile updated") // written to the EU collector only
+func WithResidencyRouting(residency ResidencyConfig) Option {
+	return func(c *config) {
+		c.Residency = &residency
+	}
+}
+
+// WithSinkPolicy sets what an output path, or the New Relic forwarder named by
+// NewRelicSink, does with fields classified by RegisterFieldClass: write them, mask
+// their value or leave them out. Sinks without a policy write every field except
+// secrets, which are masked. The option can be repeated for different sinks;
+// NewLogger returns an error for sinks that are not configured.
+//
+// Parameters:
+//   - sink: The output path, as passed to WithOutputPaths, or NewRelicSink
+//   - policy: The action per classification
+//
+// Example:
+//
+//	_ = RegisterFieldClass(ClassPII, "email", "phone")
+//	logger := NewLogger(
+//	    WithOutputPaths([]string{"/var/log/app.log", "https://logs.vendor.example/ingest"}),
+//	    WithSinkPolicy("/var/log/app.log", SinkPolicy{ClassPII: PolicyAllow}),
+//	    WithSinkPolicy("https://logs.vendor.example/ingest", SinkPolicy{ClassPII: PolicyMask, ClassInternal: PolicyDrop}),
+//	)
+func WithSinkPolicy(sink string, policy SinkPolicy) Option {
+	return func(c *config) {
+		c.SinkPolicies = append(c.SinkPolicies, sinkPolicy{sink: sink, policy: policy})
+	}
+}
+
+// WithSinkProfile writes the entries of an output path with the keys and level names of
+// a log vendor or schema, so one code base feeds several vendors, each with the field
+// names it understands: DatadogProfile, SplunkCIMProfile, ECSProfile, GCPProfile or a
+// custom FieldProfile. The profile renames the time, level, message and other entry
+// keys, writes the vendor's level names and renames fields, after the renames of
+// WithFieldRename and after sink policies, which therefore match the keys as logged.
+// Other output paths are unaffected. NewLogger returns an error for sinks that are not
+// output paths or have more than one profile.
+//
+// Parameters:
+//   - sink: The output path, as passed to WithOutputPaths
+//   - profile: The key and level mapping of the sink
+//
+// Example:
+//
+//	logger := NewLogger(
+//	    WithOutputPaths([]string{"stdout", "https://http-intake.logs.datadoghq.com/api/v2/logs"}),
+//	    WithSinkProfile("stdout", GCPProfile()),
+//	    WithSinkProfile("https://http-intake.logs.datadoghq.com/api/v2/logs", DatadogProfile()),
+//	)
+//	// stdout:  {"severity":"WARNING","message":"slow query","logging.googleapis.com/trace":"4bf9..."}
+//	// Datadog: {"status":"warning","message":"slow query","dd.trace_id":"4bf9..."}
+func WithSinkProfile(sink string, profile FieldProfile) Option {
+	return func(c *config) {
+		c.SinkProfiles = append(c.SinkProfiles, sinkProfile{sink: sink, profile: profile})
+	}
+}
+
+// WithSinkFallback sets the degradation ladder of an output path: the sinks entries
+// are written to, in order, while the output path fails, e.g. a local spool file and
+// then stderr. A failed write moves down the ladder and is retried on the next sink,
+// so the entry is not lost; the output path is retried every 30 seconds and written
+// to again once it recovers. Each transition is written to the error output paths as
+// a sink_degraded or sink_recovered diagnostic, and the active sink of every ladder is
+// reported by GetStats. Only when every sink of the ladder fails is the entry counted
+// as a sink write error. The option can be repeated for different output paths;
+// NewLogger returns an error for sinks that are not output paths.
+//
+// Parameters:
+//   - sink: The output path, as passed to WithOutputPaths
+//   - fallbacks: The sinks written to when the output path fails, in order
+//
+// Example:
+//
+//	logger := NewLogger(
+//	    WithOutputPaths([]string{"https://collector.example.com/logs"}),
+//	    WithSinkFallback("https://collector.example.com/logs", "/var/spool/app/logs.json", "stderr"),
+//	)
+func WithSinkFallback(sink string, fallbacks ...string) Option {
+	return func(c *config) {
+		c.SinkFallbacks = append(c.SinkFallbacks, sinkFallback{sink: sink, fallbacks: fallbacks})
+	}
+}
+
+// WithBatching writes the entries of an output path in batches, e.g. for a network
+// sink where a request per entry is too costly. A batch is sent once it holds
+// MaxEntries entries, after FlushInterval, on Sync and when the logger shuts down.
+// Each JSON entry keeps its event time and is stamped with sent_at, the time its batch
+// was sent, and, when BatchConfig.ClockSkew is set, with clock_skew_ms, the offset of
+// the collector's clock; together they tell entries written late from entries of a
+// host whose clock drifted when debugging out-of-order ingestion. The stamped keys are
+// not renamed by WithFieldRename. Batches that cannot be written are dropped and
+// reported as dropped_batch diagnostics; with WithSinkFallback the whole batch moves
+// down the ladder first. The option can be repeated for different output paths;
+// NewLogger returns an error for sinks that are not output paths.
+//
+// Parameters:
+//   - sink: The output path, as passed to WithOutputPaths
+//   - batch: The batch size, flush interval and clock skew measurement
+//
+// Example:
+//
+//	logger := NewLogger(
+//	    WithOutputPaths([]string{"stdout", "https://collector.example.com/logs"}),
+//	    WithBatching("https://collector.example.com/logs", BatchConfig{
+//	        MaxEntries: 500,
+//	        ClockSkew:  NewHTTPClockSkew("https://collector.example.com/health", nil),
+//	    }),
+//	)
+//	// {"time":"2024-05-01T12:00:00.120Z","message":"order placed","sent_at":"2024-05-01T12:00:00.981Z","clock_skew_ms":-1432.5}
+func WithBatching(sink string, batch BatchConfig) Option {
+	return func(c *config) {
+		c.SinkBatches = append(c.SinkBatches, sinkBatch{sink: sink, batch: batch})
+	}
+}
+
+// WithEntrySizeTracking records the size of every encoded entry, reported by GetStats
+// along with the call site of the largest entry. Entries larger than threshold bytes
+// are written with entry_size_bytes and entry_oversized fields, so call sites that log
+// entire payloads by accident can be found by searching for entry_oversized. Flagged
+// entries are encoded twice, which only costs time for the outliers.
+//
+// Parameters:
+//   - threshold: The size in bytes above which entries are flagged; zero only tracks sizes
+//
+// Example:
+//
+//	logger := NewLogger(WithEntrySizeTracking(16 * 1024))
+//	// {"message":"order received","caller":"orders/handler.go:42","order":{...},"entry_size_bytes":48213,"entry_oversized":true}
+func WithEntrySizeTracking(threshold int) Option {
+	return func(c *config) {
+		c.EntrySizeTracking = true
+		c.EntrySizeThreshold = threshold
+	}
+}
+
+// WithBaggageFields adds the allowed members of the OpenTelemetry baggage of the context
+// to every entry, so business dimensions propagated between services via baggage show
+// up in logs without custom code. Only the listed keys are logged, as baggage may carry
+// members that do not belong in logs. Members are logged under their own keys. The
+// baggage is read from the context as set by OpenTelemetry propagators, e.g. otelhttp.
+// The option can be repeated.
+//
+// Parameters:
+//   - keys: The baggage member keys to log
+//
+// Example:
+//
+//	logger := NewLogger(WithBaggageFields("tenant_id", "experiment"))
+//	// baggage: tenant_id=acme,experiment=checkout-v2,session=secret
+//	// {"message":"cart loaded","tenant_id":"acme","experiment":"checkout-v2"}
+func WithBaggageFields(keys ...string) Option {
+	return func(c *config) {
+		c.BaggageKeys = append(c.BaggageKeys, keys...)
+	}
+}
+
+// WithSpanEvents mirrors Warn and Error entries onto the active OpenTelemetry span and
+// New Relic transaction of the context, so traces carry the error detail without a
+// second instrumentation call. On OpenTelemetry spans, errors carrying an error field
+// are recorded with RecordError and other entries are added as "log" events, with the
+// message, severity and the fields of the call as attributes. New Relic receives
+// errors as noticed errors and the message of the last warning as the
+// log.warning.message attribute. Entries are only mirrored when the level is enabled.
+//
+// Parameters:
+//   - enabled: Whether entries are mirrored onto spans
+//
+// Example:
+//
+//	logger := NewLogger(WithSpanEvents(true))
+//	log.Error(ctx, "payment declined", zap.Error(err)) // also an exception event on the span of ctx
+func WithSpanEvents(enabled bool) Option {
+	return func(c *config) {
+		c.SpanEvents = enabled
+	}
+}
+
+// WithMaxFieldDepth limits the nesting depth of fields encoded by reflection, such as
+// zap.Any with maps, slices and structs. Values nested deeper are replaced by
+// DepthMarker, and values referring back to one of their parents, e.g. a
+// self-referential struct or map, are replaced by CycleMarker instead of failing or
+// producing gigantic entries. Otherwise fields are encoded like encoding/json, honoring
+// json tags, omitempty and MarshalJSON methods.
+//
+// Parameters:
+//   - depth: The maximum nesting depth; zero disables the limit and, unless
+//     WithNonFiniteFloats is set, cycle detection (default: 32)
+//
+// Example:
+//
+//	logger := NewLogger(WithMaxFieldDepth(8))
+//	node := &Node{Name: "root"}
+//	node.Parent = node
+//	log.Info(ctx, "tree", zap.Any("node", node)) // "node":{"Name":"root","Parent":"<cycle>"}
+func WithMaxFieldDepth(depth int) Option {
+	return func(c *config) {
+		c.MaxFieldDepth = depth
+	}
+}
+
+// WithNonFiniteFloats encodes NaN and ±Inf floats consistently, so strict JSON parsers
+// such as BigQuery or SIEM ingestion never reject an entry. NonFiniteString writes the
+// strings "NaN", "+Inf" and "-Inf" and NonFiniteNull writes null, for float fields,
+// nested objects and arrays and reflected values alike. By default float fields are
+// written as strings while reflected values containing them fail to encode.
+// NewLogger returns an error for unknown modes.
+//
+// Parameters:
+//   - mode: The encoding of non-finite floats (default: NonFiniteDefault)
+//
+// Example:
+//
+//	logger := NewLogger(WithNonFiniteFloats(NonFiniteNull))
+//	log.Info(ctx, "ratio", zap.Float64("ratio", math.NaN())) // "ratio":null
+func WithNonFiniteFloats(mode NonFiniteMode) Option {
+	return func(c *config) {
+		c.NonFiniteFloats = mode
+	}
+}
+
+// WithoutInheriting resets parts of the configuration to their defaults when deriving
+// a logger with Logger.WithOptions, instead of taking them from the parent logger.
+// Parts not listed are inherited: the derived logger starts from the parent's current
+// level, sinks, redacted fields and sampling, and options passed along override them.
+// Ignored by NewLogger.
+//
+// Parameters:
+//   - parts: The parts to reset, e.g. InheritLevel or InheritSinks
+//
+// Example:
+//
+//	// Debug entries of the payment client go to their own file only.
+//	paymentLog, err := log.WithOptions(
+//	    WithoutInheriting(InheritSinks),
+//	    WithOutputPaths([]string{"/var/log/payment-debug.log"}),
+//	    WithLevel(LevelDebug),
+//	)
+func WithoutInheriting(parts ...InheritedPart) Option {
+	return func(c *config) {
+		c.Uninherited = append(c.Uninherited, parts...)
+	}
+}
+
+// WithDeterministicOutput makes the output of the logger reproducible, for golden-file
+// tests of logging behavior such as redaction or schema changes: every entry carries
+// DeterministicTime, callers and stack traces are left out, levels are written without
+// colors and fields are written in the order of their keys. Values that change between
+// runs, such as durations, still do. See loggertest.Golden.
+//
+// Example:
+//
+//	log, _ := logger.NewLogger(logger.WithDeterministicOutput(), logger.WithRedactedFields("password"))
+//	log.Info(ctx, "login", zap.String("user", "ada"), zap.String("password", "hunter2"))
+//	// {"level":"INFO","time":"2000-01-01T00:00:00.000Z","message":"login","password":"[REDACTED]","user":"ada"}
+func WithDeterministicOutput() Option {
+	return func(c *config) {
+		c.Deterministic = true
+	}
+}
@@ -0,0 +1,289 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys written to audit entries shipped by the audit outbox relay.
+const (
+	FieldKeyAuditID       = "audit_id"
+	FieldKeyAuditAction   = "audit_action"
+	FieldKeyAuditActor    = "audit_actor"
+	FieldKeyAuditResource = "audit_resource"
+	FieldKeyAuditTime     = "audit_time"
+	FieldKeyAuditDetails  = "audit_details"
+)
+
+// auditEventMessage is the message of audit entries shipped by the relay.
+const auditEventMessage = "audit event"
+
+// defaultRelayBatchSize is the number of audit events shipped per relay transaction.
+const defaultRelayBatchSize = 100
+
+// Placeholder styles of SQL drivers.
+const (
+	// PlaceholderQuestion numbers nothing: "?", used by MySQL and SQLite.
+	PlaceholderQuestion SQLPlaceholder = iota
+	// PlaceholderDollar numbers parameters: "$1", used by PostgreSQL.
+	PlaceholderDollar
+)
+
+// sqlIdentifier matches table names accepted by NewAuditOutbox, optionally schema qualified.
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+type (
+	// SQLPlaceholder is the bind parameter style of a SQL driver.
+	SQLPlaceholder int
+
+	// AuditEvent is an application audit record written through an AuditOutbox.
+	AuditEvent struct {
+		// Action is what happened, e.g. "order.refunded".
+		Action string `json:"action"`
+		// Actor identifies who did it; empty uses the user_id of the context.
+		Actor string `json:"actor,omitempty"`
+		// Resource identifies what it was done to, e.g. "order/1234".
+		Resource string `json:"resource,omitempty"`
+		// Time is when it happened; zero uses the time of Write.
+		Time time.Time `json:"time"`
+		// Details holds additional JSON-serializable attributes.
+		Details map[string]any `json:"details,omitempty"`
+	}
+
+	// AuditOutbox writes audit events into an outbox table inside the application's
+	// database transaction and relays committed events to the audit log. Events of
+	// rolled-back transactions are never logged, and committed events are logged at
+	// least once, even if the process dies right after the commit.
+	//
+	// The table needs an auto-incrementing integer id column and a text payload column:
+	//
+	//	CREATE TABLE audit_outbox (id BIGSERIAL PRIMARY KEY, payload TEXT NOT NULL)
+	AuditOutbox struct {
+		table       string
+		placeholder SQLPlaceholder
+		batchSize   int
+	}
+)
+
+// NewAuditOutbox creates an audit outbox backed by the table.
+//
+// Parameters:
+//   - table: The outbox table, optionally schema qualified
+//   - placeholder: The bind parameter style of the SQL driver
+//
+// Returns:
+//   - *AuditOutbox: The outbox
+//   - error: An error if the table name is not a plain SQL identifier
+//
+// Example:
+//
+//	outbox, err := logger.NewAuditOutbox("audit_outbox", logger.PlaceholderDollar)
+//	if err != nil {
+//	    return err
+//	}
+//	go outbox.Relay(ctx, db, log, time.Second)
+func NewAuditOutbox(table string, placeholder SQLPlaceholder) (*AuditOutbox, error) {
+	if !sqlIdentifier.MatchString(table) {
+		return nil, fmt.Errorf("invalid audit outbox table name %q", table)
+	}
+	return &AuditOutbox{table: table, placeholder: placeholder, batchSize: defaultRelayBatchSize}, nil
+}
+
+// Write stores the audit event in the outbox as part of the transaction. The event is
+// only logged if the transaction commits, so audit entries are never emitted for
+// operations that were rolled back.
+//
+// Parameters:
+//   - ctx: The context of the operation, providing the actor when the event has none
+//   - tx: The transaction of the audited operation
+//   - event: The audit event
+//
+// Returns:
+//   - error: An error if the event cannot be encoded or inserted
+//
+// Example:
+//
+//	tx, _ := db.BeginTx(ctx, nil)
+//	if err := refund(ctx, tx, order); err != nil {
+//	    tx.Rollback() // no audit entry
+//	    return err
+//	}
+//	if err := outbox.Write(ctx, tx, logger.AuditEvent{Action: "order.refunded", Resource: "order/" + order.ID}); err != nil {
+//	    tx.Rollback()
+//	    return err
+//	}
+//	return tx.Commit()
+func (o *AuditOutbox) Write(ctx context.Context, tx *sql.Tx, event AuditEvent) error {
+	if event.Action == "" {
+		return errors.New("audit event without action")
+	}
+	if event.Actor == "" {
+		event.Actor, _ = UserIDFromContext(ctx)
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	query := "INSERT INTO " + o.table + " (payload) VALUES (" + o.param(1) + ")"
+	if _, err := tx.ExecContext(ctx, query, string(payload)); err != nil {
+		return fmt.Errorf("failed to write audit event to outbox: %w", err)
+	}
+	return nil
+}
+
+// Relay ships committed audit events to the audit log every interval until the
+// context is done, and returns the context error. Failures are logged and retried at
+// the next interval. Run a single relay per outbox table: concurrent relays may log
+// events twice.
+//
+// Parameters:
+//   - ctx: The context stopping the relay
+//   - db: The database holding the outbox table
+//   - log: The logger writing the audit entries
+//   - interval: The time between polls of the outbox
+//
+// Returns:
+//   - error: The context error once the relay stops
+func (o *AuditOutbox) Relay(ctx context.Context, db *sql.DB, log Logger, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			n, err := o.RelayOnce(ctx, db, log)
+			if err != nil {
+				log.Error(ctx, "audit outbox relay failed", zap.Error(err))
+			}
+			if err != nil || n < o.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce ships one batch of committed audit events to the audit log and removes
+// them from the outbox. Audit entries are written regardless of the level and
+// sampling of the logger and synced before the events are removed.
+//
+// Parameters:
+//   - ctx: The context of the relay
+//   - db: The database holding the outbox table
+//   - log: The logger writing the audit entries
+//
+// Returns:
+//   - int: The number of events shipped
+//   - error: An error if the outbox cannot be read or cleaned up
+func (o *AuditOutbox) RelayOnce(ctx context.Context, db *sql.DB, log Logger) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin audit outbox transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, payload FROM "+o.table+" ORDER BY id LIMIT "+strconv.Itoa(o.batchSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read audit outbox: %w", err)
+	}
+
+	type record struct {
+		id      int64
+		payload string
+	}
+	var records []record
+	for rows.Next() {
+		var r record
+		if err := rows.Scan(&r.id, &r.payload); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("failed to read audit outbox: %w", err)
+		}
+		records = append(records, r)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, fmt.Errorf("failed to read audit outbox: %w", err)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read audit outbox: %w", err)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	audit := auditLogger(log)
+	for _, r := range records {
+		var event AuditEvent
+		if err := json.Unmarshal([]byte(r.payload), &event); err != nil {
+			audit.Error(ctx, "malformed audit event", zap.Int64(FieldKeyAuditID, r.id), zap.String(FieldKeyAuditDetails, r.payload))
+			continue
+		}
+		audit.Info(ctx, auditEventMessage,
+			zap.Int64(FieldKeyAuditID, r.id),
+			zap.String(FieldKeyAuditAction, event.Action),
+			zap.String(FieldKeyAuditActor, event.Actor),
+			zap.String(FieldKeyAuditResource, event.Resource),
+			zap.Time(FieldKeyAuditTime, event.Time),
+			zap.Any(FieldKeyAuditDetails, event.Details),
+		)
+	}
+	if z, ok := unwrapLogger(log); ok {
+		if err := z.zapLogger.Sync(); err != nil && !isIgnorableSyncError(err) {
+			return 0, fmt.Errorf("failed to sync audit entries: %w", err)
+		}
+	}
+
+	last := records[len(records)-1].id
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+o.table+" WHERE id <= "+o.param(1), last); err != nil {
+		return 0, fmt.Errorf("failed to clean up audit outbox: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to clean up audit outbox: %w", err)
+	}
+	return len(records), nil
+}
+
+// param returns the n-th bind parameter in the placeholder style of the outbox.
+func (o *AuditOutbox) param(n int) string {
+	if o.placeholder == PlaceholderDollar {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// auditLogger returns the logger writing audit entries: a child named logger.audit
+// that bypasses the level and sampling of the logger, so audit entries are never lost.
+// The sampler recognizes its entries with isAuditEntry. Loggers not created by
+// NewLogger are returned unchanged.
+func auditLogger(log Logger) Logger {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return log
+	}
+	audit := z.zapLogger.Named(AuditLoggerName).WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return newLevelCore(core, zapcore.DebugLevel)
+	}))
+	return &logger{logger: &zapLogger{zapLogger: audit, state: z.state}}
+}
+
+// isAuditEntry reports whether the entry was written by an audit logger, named
+// logger.audit below the name of its parent.
+func isAuditEntry(ent zapcore.Entry) bool {
+	return ent.LoggerName == AuditLoggerName || strings.HasSuffix(ent.LoggerName, "."+AuditLoggerName)
+}
@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldKeyPanic is the field holding a recovered panic value.
+const FieldKeyPanic = "panic"
+
+// panicValue encodes a recovered panic value as a structured object.
+type panicValue struct {
+	value any
+}
+
+// PanicValue returns a field encoding a recovered panic value without flattening it.
+// The object always holds the Go type of the value. Errors are written with their
+// message and unwrapped chain (including errors.Join branches), fmt.Stringers with
+// String(), structs with one entry per field (unexported fields included) and other
+// values as JSON where possible.
+//
+// Parameters:
+//   - v: The value returned by recover()
+//
+// Returns:
+//   - Field: The panic field
+//
+// Example:
+//
+//	defer func() {
+//	    if r := recover(); r != nil {
+//	        log.Error(ctx, "job crashed", PanicValue(r))
+//	    }
+//	}()
+//	// {"panic":{"type":"*fs.PathError","message":"open /data: permission denied","chain":[...]}}
+func PanicValue(v any) Field {
+	return zap.Object(FieldKeyPanic, panicValue{value: v})
+}
+
+// Recover recovers a panic of the calling goroutine and logs it at ErrorLevel with
+// the structured panic value. The stack trace of error entries leads to the panicking
+// function unless stack traces are disabled. It must be deferred directly.
+// The panic is not re-raised, so use it at the top of goroutines and jobs whose
+// failure must not crash the process.
+//
+// Parameters:
+//   - ctx: The context whose fields are added to the entry
+//   - log: The logger writing the entry
+//   - msg: The message of the entry
+//
+// Example:
+//
+//	go func() {
+//	    defer Recover(ctx, log, "consumer crashed")
+//	    consume(ctx)
+//	}()
+func Recover(ctx context.Context, log Logger, msg string) {
+	if r := recover(); r != nil {
+		log.Error(ctx, msg, PanicValue(r))
+	}
+}
+
+// MarshalLogObject writes the type and the structured value of the panic.
+func (p panicValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("type", fmt.Sprintf("%T", p.value))
+	switch v := p.value.(type) {
+	case nil:
+		return nil
+	case error:
+		enc.AddString("message", v.Error())
+		if chain := errorChain(v); len(chain) > 0 {
+			return enc.AddArray("chain", chain)
+		}
+		return nil
+	case fmt.Stringer:
+		enc.AddString("value", v.String())
+		return nil
+	case string:
+		enc.AddString("value", v)
+		return nil
+	}
+
+	rv := reflect.ValueOf(p.value)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		return enc.AddObject("fields", structFields{value: rv})
+	}
+	if err := enc.AddReflected("value", p.value); err != nil {
+		enc.AddString("value", fmt.Sprintf("%+v", p.value))
+	}
+	return nil
+}
+
+type (
+	// errorChainArray encodes the errors wrapped by an error.
+	errorChainArray []error
+
+	// structFields encodes the fields of a struct value.
+	structFields struct {
+		value reflect.Value
+	}
+)
+
+// errorChain returns the errors wrapped by err, depth first, without err itself.
+func errorChain(err error) errorChainArray {
+	var chain errorChainArray
+	var walk func(error)
+	walk = func(err error) {
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			if next := u.Unwrap(); next != nil {
+				chain = append(chain, next)
+				walk(next)
+			}
+		case interface{ Unwrap() []error }:
+			for _, next := range u.Unwrap() {
+				if next != nil {
+					chain = append(chain, next)
+					walk(next)
+				}
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// MarshalLogArray writes the type and message of every error in the chain.
+func (c errorChainArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, err := range c {
+		if e := enc.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("type", fmt.Sprintf("%T", err))
+			enc.AddString("message", err.Error())
+			return nil
+		})); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// MarshalLogObject writes every field of the struct. Exported fields are encoded
+// as JSON where possible; unexported fields, which cannot be reflected into JSON,
+// are written as formatted strings.
+func (s structFields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	t := s.value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), s.value.Field(i)
+		if field.IsExported() {
+			if err := enc.AddReflected(field.Name, value.Interface()); err == nil {
+				continue
+			}
+		}
+		enc.AddString(field.Name, fmt.Sprintf("%+v", value))
+	}
+	return nil
+}
+
+// panicError returns an error describing a recovered panic value.
+func panicError(v any) error {
+	if err, ok := v.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return errors.New("panic: " + fmt.Sprint(v))
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledFields is the capacity above which field slices are not returned to the
+// pool, so an occasional entry with a huge context does not pin its slice forever.
+const maxPooledFields = 256
+
+// fieldPool recycles the field slices the logger builds from the context of every
+// entry: context keys, context structs, dimensions, baggage, the field bag, enrichers,
+// deadlines and worker labels. The slices only live until the fields are added to the
+// zap logger of the entry, whose cores encode or copy them.
+var fieldPool = newFieldSlicePool()
+
+type (
+	// fieldSlicePool is a sync.Pool of field slices counting how often a slice is reused.
+	fieldSlicePool struct {
+		pool sync.Pool
+		// gets counts the slices taken from the pool.
+		gets atomic.Uint64
+		// misses counts the slices allocated because the pool was empty.
+		misses atomic.Uint64
+		// discarded counts the slices not returned because they grew too large.
+		discarded atomic.Uint64
+	}
+
+	// PoolStats reports how well the field slices built from the context of entries are
+	// reused. The pool is shared by all loggers of the process.
+	PoolStats struct {
+		// Hits counts the slices reused from the pool.
+		Hits uint64
+		// Misses counts the slices allocated because the pool was empty.
+		Misses uint64
+		// Discarded counts the slices dropped instead of pooled because they grew larger
+		// than 256 fields.
+		Discarded uint64
+	}
+)
+
+// newFieldSlicePool creates an empty pool of field slices.
+func newFieldSlicePool() *fieldSlicePool {
+	p := &fieldSlicePool{}
+	p.pool.New = func() any {
+		p.misses.Add(1)
+		fields := make([]Field, 0, 16)
+		return &fields
+	}
+	return p
+}
+
+// get returns an empty field slice of the pool.
+func (p *fieldSlicePool) get() *[]Field {
+	p.gets.Add(1)
+	return p.pool.Get().(*[]Field)
+}
+
+// put returns a field slice to the pool. The fields are cleared first, so the pool
+// does not keep the values of logged entries alive.
+func (p *fieldSlicePool) put(fields *[]Field) {
+	if cap(*fields) > maxPooledFields {
+		p.discarded.Add(1)
+		return
+	}
+	clear(*fields)
+	*fields = (*fields)[:0]
+	p.pool.Put(fields)
+}
+
+// stats returns the counters of the pool.
+func (p *fieldSlicePool) stats() PoolStats {
+	misses := p.misses.Load()
+	gets := p.gets.Load()
+	return PoolStats{
+		Hits:      gets - min(misses, gets),
+		Misses:    misses,
+		Discarded: p.discarded.Load(),
+	}
+}
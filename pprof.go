@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// PprofLabelEndpoint is the pprof label naming the endpoint of a request.
+// The request ID is labeled under ContextKeyRequestID, the key it is logged under.
+const PprofLabelEndpoint = "endpoint"
+
+// DoWithPprofLabels runs f with pprof labels matching the logged request fields:
+// the request ID of the context and the endpoint. Samples of CPU and goroutine
+// profiles taken while f runs carry the labels, so a slow request found in the logs
+// can be located in a profile with `go tool pprof -tagfocus request_id=<id>` and
+// vice versa. Goroutines started by f inherit the labels.
+//
+// Parameters:
+//   - ctx: The request context, typically prepared by HTTPMiddleware or an interceptor
+//   - endpoint: The endpoint handling the request, e.g. "GET /orders" or a gRPC method
+//   - f: The function handling the request, called with the labeled context
+//
+// Example:
+//
+//	func (s *server) Process(ctx context.Context, req *pb.Request) (resp *pb.Response, err error) {
+//	    DoWithPprofLabels(ctx, "Process", func(ctx context.Context) {
+//	        resp, err = s.process(ctx, req)
+//	    })
+//	    return resp, err
+//	}
+func DoWithPprofLabels(ctx context.Context, endpoint string, f func(context.Context)) {
+	labels := []string{PprofLabelEndpoint, endpoint}
+	if requestID, ok := getStringFromContext(ctx, ContextKeyRequestID); ok {
+		labels = append(labels, string(ContextKeyRequestID), requestID)
+	}
+	pprof.Do(ctx, pprof.Labels(labels...), f)
+}
@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ContentTypeProblemJSON is the media type of RFC 7807 problem details responses.
+const ContentTypeProblemJSON = "application/problem+json"
+
+// FieldKeyProblem is the field key of logged problem details.
+const FieldKeyProblem = "problem"
+
+// maxProblemSize bounds the size of problem details responses captured by WithProblemCapture.
+const maxProblemSize = 64 << 10
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	// Type is a URI reference identifying the problem type.
+	Type string `json:"type,omitempty"`
+	// Title is a short, human-readable summary of the problem type.
+	Title string `json:"title,omitempty"`
+	// Status is the HTTP status code of the response.
+	Status int `json:"status,omitempty"`
+	// Detail is a human-readable explanation of this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI reference identifying this occurrence of the problem.
+	Instance string `json:"instance,omitempty"`
+	// Extensions holds the extension members of the problem.
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalLogObject writes the members of the problem that are set, extension members
+// included, so problems are logged as one nested object.
+func (p Problem) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if p.Type != "" {
+		enc.AddString("type", p.Type)
+	}
+	if p.Title != "" {
+		enc.AddString("title", p.Title)
+	}
+	if p.Status != 0 {
+		enc.AddInt("status", p.Status)
+	}
+	if p.Detail != "" {
+		enc.AddString("detail", p.Detail)
+	}
+	if p.Instance != "" {
+		enc.AddString("instance", p.Instance)
+	}
+
+	keys := make([]string, 0, len(p.Extensions))
+	for k := range p.Extensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := enc.AddReflected(k, p.Extensions[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProblemField returns the problem as the nested problem field.
+//
+// Parameters:
+//   - p: The problem details
+//
+// Returns:
+//   - Field: The problem field
+func ProblemField(p Problem) Field {
+	return zap.Object(FieldKeyProblem, p)
+}
+
+// LogProblem logs problem details consistently: the title is the message, the problem
+// is the nested problem field with type, title, status, detail and instance, and the
+// level follows the status: ErrorLevel for 5xx, WarnLevel for 4xx and InfoLevel
+// otherwise. Problems without a title use the status text.
+//
+// Parameters:
+//   - ctx: The request context
+//   - log: The logger writing the entry
+//   - p: The problem details returned to the client
+//   - fields: Additional fields
+//
+// Example:
+//
+//	problem := logger.Problem{
+//	    Type:   "https://example.com/problems/out-of-credit",
+//	    Title:  "You do not have enough credit.",
+//	    Status: http.StatusForbidden,
+//	    Detail: "Your current balance is 30, but that costs 50.",
+//	}
+//	logger.LogProblem(ctx, log, problem, zap.String("account_id", id))
+//	// {"level":"WARN","message":"You do not have enough credit.","problem":{"type":"https://example.com/problems/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50."},"account_id":"12345"}
+func LogProblem(ctx context.Context, log Logger, p Problem, fields ...Field) {
+	msg := p.Title
+	if msg == "" {
+		msg = http.StatusText(p.Status)
+	}
+	if msg == "" {
+		msg = "problem details"
+	}
+
+	all := make([]Field, 0, len(fields)+1)
+	all = append(all, ProblemField(p))
+	all = append(all, fields...)
+
+	switch {
+	case p.Status >= http.StatusInternalServerError:
+		log.Error(ctx, msg, all...)
+	case p.Status >= http.StatusBadRequest:
+		log.Warn(ctx, msg, all...)
+	default:
+		log.Info(ctx, msg, all...)
+	}
+}
+
+// WithProblemCapture adds the problem field to access log entries of responses with
+// the application/problem+json content type, so problems returned by handlers are
+// logged without calling LogProblem. Problem bodies up to 64 KiB are parsed; larger
+// or malformed bodies are not logged.
+//
+// Example:
+//
+//	middleware := HTTPMiddleware(log, WithProblemCapture())
+//	// {"message":"http request","http_status":404,"problem":{"type":"about:blank","title":"Not Found","status":404},...}
+func WithProblemCapture() HTTPOption {
+	return func(c *httpConfig) {
+		c.ProblemCapture = true
+	}
+}
+
+// isProblemContent reports whether the content type is application/problem+json.
+func isProblemContent(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == ContentTypeProblemJSON
+}
+
+// parseProblem parses a problem details body. Members other than the standard
+// ones become extensions.
+func parseProblem(data []byte) (Problem, bool) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return Problem{}, false
+	}
+
+	var p Problem
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Problem{}, false
+	}
+	for k, raw := range members {
+		switch k {
+		case "type", "title", "status", "detail", "instance":
+			continue
+		}
+		var v any
+		if json.Unmarshal(raw, &v) == nil {
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]any)
+			}
+			p.Extensions[k] = v
+		}
+	}
+	return p, true
+}
@@ -0,0 +1,266 @@
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// FieldProfile maps the keys and level names of entries to the conventions of a log
+	// vendor or schema, see WithSinkProfile. Empty keys and missing levels keep those of
+	// the logger.
+	FieldProfile struct {
+		// Name identifies the profile, e.g. "datadog".
+		Name string
+		// TimeKey is the key of the time.
+		TimeKey string
+		// LevelKey is the key of the level.
+		LevelKey string
+		// NameKey is the key of the logger name.
+		NameKey string
+		// CallerKey is the key of the caller.
+		CallerKey string
+		// MessageKey is the key of the message.
+		MessageKey string
+		// StacktraceKey is the key of the stack trace.
+		StacktraceKey string
+		// Levels are the level names written, e.g. "WARNING" for LevelWarning.
+		Levels map[Level]string
+		// Fields rename field keys, e.g. "trace_id" to "trace.id". Renames apply to
+		// top-level fields after those of WithFieldRename.
+		Fields map[string]string
+	}
+
+	// sinkProfile pairs a FieldProfile with the sink it applies to.
+	sinkProfile struct {
+		sink    string
+		profile FieldProfile
+	}
+
+	// profiledSink is the encoder and field renames of a sink with a profile.
+	profiledSink struct {
+		encoder zapcore.Encoder
+		renames []fieldRename
+	}
+)
+
+// DatadogProfile returns the profile of Datadog's standard attributes: status, the
+// dd.trace_id and dd.span_id correlation keys, and the http.*, network.*, usr.* and
+// error.* attributes.
+//
+// Returns:
+//   - FieldProfile: The profile
+func DatadogProfile() FieldProfile {
+	return FieldProfile{
+		Name:          "datadog",
+		TimeKey:       "timestamp",
+		LevelKey:      "status",
+		NameKey:       "logger.name",
+		MessageKey:    "message",
+		StacktraceKey: "error.stack",
+		Levels: map[Level]string{
+			LevelDebug:   "debug",
+			LevelInfo:    "info",
+			LevelWarning: "warning",
+			LevelError:   "error",
+			LevelPanic:   "critical",
+			LevelFatal:   "emergency",
+		},
+		Fields: map[string]string{
+			string(ContextKeyTraceID):                "dd.trace_id",
+			string(ContextKeySpanID):                 "dd.span_id",
+			string(ContextKeyUserID):                 "usr.id",
+			string(ContextKeyApplicationName):        "service",
+			string(ContextKeyApplicationEnvironment): "env",
+			string(ContextKeyHostname):               "host",
+			FieldKeyHTTPMethod:                       "http.method",
+			FieldKeyHTTPStatus:                       "http.status_code",
+			FieldKeyHTTPPath:                         "http.url_details.path",
+			FieldKeyHTTPRoute:                        "http.route",
+			FieldKeyUserAgent:                        "http.useragent",
+			FieldKeyRemoteAddr:                       "network.client.ip",
+			"error":                                  "error.message",
+		},
+	}
+}
+
+// SplunkCIMProfile returns the profile of the Splunk Common Information Model, with
+// the field names of the Web data model and the severities of the Alerts data model.
+//
+// Returns:
+//   - FieldProfile: The profile
+func SplunkCIMProfile() FieldProfile {
+	return FieldProfile{
+		Name:     "splunk_cim",
+		LevelKey: "severity",
+		Levels: map[Level]string{
+			LevelDebug:   "informational",
+			LevelInfo:    "informational",
+			LevelWarning: "medium",
+			LevelError:   "high",
+			LevelPanic:   "critical",
+			LevelFatal:   "critical",
+		},
+		Fields: map[string]string{
+			string(ContextKeyUserID):          "user",
+			string(ContextKeyHostname):        "dest",
+			string(ContextKeyApplicationName): "app",
+			FieldKeyHTTPStatus:                "status",
+			FieldKeyHTTPPath:                  "uri_path",
+			FieldKeyUserAgent:                 "http_user_agent",
+			FieldKeyRemoteAddr:                "src",
+			FieldKeyResponseBytes:             "bytes_out",
+		},
+	}
+}
+
+// ECSProfile returns the profile of the Elastic Common Schema: @timestamp, log.level,
+// log.logger, and the trace.*, http.*, url.*, user.*, client.*, service.*, cloud.* and
+// error.* fields.
+//
+// Returns:
+//   - FieldProfile: The profile
+func ECSProfile() FieldProfile {
+	return FieldProfile{
+		Name:          "ecs",
+		TimeKey:       "@timestamp",
+		LevelKey:      "log.level",
+		NameKey:       "log.logger",
+		MessageKey:    "message",
+		StacktraceKey: "error.stack_trace",
+		Levels: map[Level]string{
+			LevelDebug:   "debug",
+			LevelInfo:    "info",
+			LevelWarning: "warn",
+			LevelError:   "error",
+			LevelPanic:   "panic",
+			LevelFatal:   "fatal",
+		},
+		Fields: map[string]string{
+			string(ContextKeyTraceID):                "trace.id",
+			string(ContextKeySpanID):                 "span.id",
+			string(ContextKeyRequestID):              "http.request.id",
+			string(ContextKeyUserID):                 "user.id",
+			string(ContextKeyIpAddress):              "client.ip",
+			string(ContextKeyHostname):               "host.hostname",
+			string(ContextKeyApplicationName):        "service.name",
+			string(ContextKeyApplicationEnvironment): "service.environment",
+			FieldKeyHTTPMethod:                       "http.request.method",
+			FieldKeyHTTPStatus:                       "http.response.status_code",
+			FieldKeyHTTPPath:                         "url.path",
+			FieldKeyUserAgent:                        "user_agent.original",
+			FieldKeyRemoteAddr:                       "client.address",
+			FieldKeyResponseBytes:                    "http.response.body.bytes",
+			"error":                                  "error.message",
+			FieldKeyCloudProvider:                    "cloud.provider",
+			FieldKeyCloudRegion:                      "cloud.region",
+			FieldKeyCloudZone:                        "cloud.availability_zone",
+			FieldKeyInstanceID:                       "cloud.instance.id",
+			FieldKeyInstanceType:                     "cloud.machine.type",
+		},
+	}
+}
+
+// GCPProfile returns the profile of Google Cloud Logging structured logs: severity
+// with Cloud Logging's names, and the trace and span keys Cloud Logging correlates with
+// Cloud Trace. Stack traces keep the stack_trace key read by Error Reporting.
+//
+// Returns:
+//   - FieldProfile: The profile
+func GCPProfile() FieldProfile {
+	return FieldProfile{
+		Name:          "gcp",
+		TimeKey:       "timestamp",
+		LevelKey:      "severity",
+		MessageKey:    "message",
+		StacktraceKey: "stack_trace",
+		Levels: map[Level]string{
+			LevelDebug:   "DEBUG",
+			LevelInfo:    "INFO",
+			LevelWarning: "WARNING",
+			LevelError:   "ERROR",
+			LevelPanic:   "ALERT",
+			LevelFatal:   "EMERGENCY",
+		},
+		Fields: map[string]string{
+			string(ContextKeyTraceID): "logging.googleapis.com/trace",
+			string(ContextKeySpanID):  "logging.googleapis.com/spanId",
+		},
+	}
+}
+
+// validateSinkProfiles checks that every profile applies to an output path, once.
+func validateSinkProfiles(profiles []sinkProfile, paths []string) error {
+	seen := make(map[string]bool, len(profiles))
+	for _, p := range profiles {
+		if !slices.Contains(paths, p.sink) {
+			return fmt.Errorf("sink profile for unknown sink %q: it must be one of the output paths", p.sink)
+		}
+		if seen[p.sink] {
+			return fmt.Errorf("sink %q has more than one profile", p.sink)
+		}
+		seen[p.sink] = true
+	}
+	return nil
+}
+
+// newProfiledSinks builds the encoder and renames of every sink with a profile. The
+// encoders are built by build from zapConfig with the keys and levels of the profile.
+func newProfiledSinks(profiles []sinkProfile, zapConfig zap.Config, build func(zap.Config) zapcore.Encoder) map[string]*profiledSink {
+	if len(profiles) == 0 {
+		return nil
+	}
+	sinks := make(map[string]*profiledSink, len(profiles))
+	for _, p := range profiles {
+		sinks[p.sink] = &profiledSink{
+			encoder: build(p.profile.zapConfig(zapConfig)),
+			renames: p.profile.renames(),
+		}
+	}
+	return sinks
+}
+
+// zapConfig returns zapConfig with the keys and level names of the profile.
+func (p FieldProfile) zapConfig(zapConfig zap.Config) zap.Config {
+	encoderConfig := &zapConfig.EncoderConfig
+	for _, key := range []struct {
+		dst *string
+		src string
+	}{
+		{&encoderConfig.TimeKey, p.TimeKey},
+		{&encoderConfig.LevelKey, p.LevelKey},
+		{&encoderConfig.NameKey, p.NameKey},
+		{&encoderConfig.CallerKey, p.CallerKey},
+		{&encoderConfig.MessageKey, p.MessageKey},
+		{&encoderConfig.StacktraceKey, p.StacktraceKey},
+	} {
+		if key.src != "" {
+			*key.dst = key.src
+		}
+	}
+	if len(p.Levels) > 0 {
+		encodeLevel := encoderConfig.EncodeLevel
+		levels := maps.Clone(p.Levels)
+		encoderConfig.EncodeLevel = func(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+			if name, ok := levels[levelName(level)]; ok {
+				enc.AppendString(name)
+				return
+			}
+			encodeLevel(level, enc)
+		}
+	}
+	return zapConfig
+}
+
+// renames returns the field renames of the profile in key order.
+func (p FieldProfile) renames() []fieldRename {
+	renames := make([]fieldRename, 0, len(p.Fields))
+	for _, from := range slices.Sorted(maps.Keys(p.Fields)) {
+		renames = append(renames, fieldRename{From: from, To: p.Fields[from]})
+	}
+	return renames
+}
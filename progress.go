@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Field keys written to progress entries.
+const (
+	FieldKeyProgressName    = "progress_name"
+	FieldKeyProgressDone    = "progress_done"
+	FieldKeyProgressTotal   = "progress_total"
+	FieldKeyProgressPercent = "progress_percent"
+	FieldKeyProgressRate    = "progress_rate"
+	FieldKeyProgressETA     = "progress_eta"
+	FieldKeyProgressElapsed = "progress_elapsed"
+)
+
+// Messages of progress entries.
+const (
+	progressMessage         = "progress"
+	progressCompleteMessage = "progress complete"
+)
+
+// Defaults of the progress options.
+const (
+	defaultProgressInterval = 30 * time.Second
+	defaultProgressStep     = 10
+)
+
+type (
+	// ProgressOption represents a configuration option of Progress.
+	ProgressOption func(*progressConfig)
+
+	// progressConfig holds the configuration of a progress tracker.
+	progressConfig struct {
+		// Interval is the longest time between progress entries.
+		Interval time.Duration
+		// Step is the percentage after which a progress entry is written.
+		Step float64
+	}
+
+	// ProgressTracker logs the progress of a long operation, such as a batch import,
+	// every interval or percentage step instead of per item. It is safe for concurrent
+	// use by workers processing items in parallel.
+	ProgressTracker struct {
+		ctx    context.Context
+		log    Logger
+		name   string
+		total  int64
+		config progressConfig
+		start  time.Time
+		done   atomic.Int64
+		// next is the count at which the next percentage step is reached.
+		next atomic.Int64
+		// lastAt is the time of the last entry in Unix nanoseconds.
+		lastAt atomic.Int64
+
+		mu       sync.Mutex
+		finished bool
+	}
+)
+
+// Progress returns a tracker logging the progress of a long operation of total items.
+// An entry is written whenever another step of the items is done, by default 10%,
+// and at least every interval, by default 30 seconds, while items are being done.
+// Entries carry the items done, the percentage, the rate per second and the estimated
+// time remaining, so a million-row import writes a dozen entries instead of a million.
+// A total of zero or less means the total is unknown; entries are then written every
+// interval without a percentage or estimate.
+//
+// Parameters:
+//   - ctx: The context of the operation, used for every entry
+//   - log: The logger writing the entries
+//   - name: The name of the operation, written as progress_name
+//   - total: The number of items of the operation
+//   - opts: Variable number of ProgressOption functions
+//
+// Returns:
+//   - *ProgressTracker: The tracker to add done items to
+//
+// Example:
+//
+//	progress := logger.Progress(ctx, log, "backfill-invoices", int64(len(rows)), logger.WithProgressStep(5))
+//	for _, row := range rows {
+//	    backfill(ctx, row)
+//	    progress.Add(1)
+//	}
+//	progress.Done()
+//	// {"message":"progress","progress_name":"backfill-invoices","progress_done":50000,"progress_total":1000000,"progress_percent":5,"progress_rate":1204.5,"progress_eta":"13m8.7s","progress_elapsed":"41.5s"}
+func Progress(ctx context.Context, log Logger, name string, total int64, opts ...ProgressOption) *ProgressTracker {
+	cfg := progressConfig{Interval: defaultProgressInterval, Step: defaultProgressStep}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	now := time.Now()
+	p := &ProgressTracker{ctx: ctx, log: log, name: name, total: total, config: cfg, start: now}
+	p.lastAt.Store(now.UnixNano())
+	p.next.Store(p.stepCount(1))
+	return p
+}
+
+// WithProgressInterval sets the longest time between progress entries.
+//
+// Parameters:
+//   - interval: The interval; zero or less writes entries on percentage steps only
+func WithProgressInterval(interval time.Duration) ProgressOption {
+	return func(c *progressConfig) {
+		c.Interval = interval
+	}
+}
+
+// WithProgressStep sets the percentage of the total after which a progress entry is written.
+//
+// Parameters:
+//   - percent: The step in percent, e.g. 5; zero or less writes entries every interval only
+func WithProgressStep(percent float64) ProgressOption {
+	return func(c *progressConfig) {
+		c.Step = percent
+	}
+}
+
+// Add adds done items and writes a progress entry when a step is reached or the
+// interval has passed.
+//
+// Parameters:
+//   - n: The number of items done
+func (p *ProgressTracker) Add(n int64) {
+	done := p.done.Add(n)
+	if !p.due(done, time.Now()) {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Another worker may have written the entry of this step or interval meanwhile.
+	now := time.Now()
+	if p.finished || !p.due(done, now) {
+		return
+	}
+	p.lastAt.Store(now.UnixNano())
+	p.next.Store(p.nextStep(done))
+	p.log.Info(p.ctx, progressMessage, p.fields(done, now)...)
+}
+
+// Done writes the final progress entry of the operation. Later calls to Add and Done
+// write nothing.
+func (p *ProgressTracker) Done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished {
+		return
+	}
+	p.finished = true
+	p.log.Info(p.ctx, progressCompleteMessage, p.fields(p.done.Load(), time.Now())...)
+}
+
+// due reports whether a progress entry is due because the next step is reached or the
+// interval has passed.
+func (p *ProgressTracker) due(done int64, now time.Time) bool {
+	if next := p.next.Load(); next > 0 && done >= next {
+		return true
+	}
+	return p.config.Interval > 0 && now.UnixNano()-p.lastAt.Load() >= int64(p.config.Interval)
+}
+
+// stepCount returns the number of items done at the given step, or zero when steps
+// are disabled or the total is unknown.
+func (p *ProgressTracker) stepCount(step int64) int64 {
+	if p.total <= 0 || p.config.Step <= 0 {
+		return 0
+	}
+	count := int64(float64(p.total) * p.config.Step * float64(step) / 100)
+	return max(count, 1)
+}
+
+// nextStep returns the count of the first step beyond done.
+func (p *ProgressTracker) nextStep(done int64) int64 {
+	first := p.stepCount(1)
+	if first == 0 {
+		return 0
+	}
+	step := int64(float64(done)*100/(float64(p.total)*p.config.Step)) + 1
+	next := p.stepCount(step)
+	for next <= done {
+		step++
+		next = p.stepCount(step)
+	}
+	return next
+}
+
+// fields returns the fields of a progress entry.
+func (p *ProgressTracker) fields(done int64, now time.Time) []Field {
+	elapsed := now.Sub(p.start)
+	fields := []Field{
+		zap.String(FieldKeyProgressName, p.name),
+		zap.Int64(FieldKeyProgressDone, done),
+	}
+	var rate float64
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		rate = float64(done) / seconds
+	}
+	if p.total > 0 {
+		fields = append(fields,
+			zap.Int64(FieldKeyProgressTotal, p.total),
+			zap.Float64(FieldKeyProgressPercent, math.Round(float64(done)*10000/float64(p.total))/100),
+		)
+	}
+	fields = append(fields, zap.Float64(FieldKeyProgressRate, math.Round(rate*100)/100))
+	if p.total > 0 && rate > 0 && done < p.total {
+		eta := time.Duration(float64(p.total-done) / rate * float64(time.Second))
+		fields = append(fields, zap.Duration(FieldKeyProgressETA, eta.Round(100*time.Millisecond)))
+	}
+	return append(fields, zap.Duration(FieldKeyProgressElapsed, elapsed))
+}
@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// HeaderTraceparent is the W3C Trace Context header carrying the trace and parent span IDs.
+const HeaderTraceparent = "traceparent"
+
+// contextFromHeaders stores the correlation IDs carried by incoming headers in the context.
+// The request ID is taken from X-Request-ID or generated with newID when missing; the trace and
+// span IDs are taken from a valid traceparent header and dimensions from the
+// X-Log-Dimensions header. Values already present in the context are kept.
+// A field bag for AddField is added when the context carries none.
+//
+// Parameters:
+//   - ctx: The request context
+//   - get: Returns the first value of a header, case-insensitively
+//   - newID: Generates the request ID when there is none
+//
+// Returns:
+//   - context.Context: The context holding the correlation IDs
+//   - string: The request ID of the request
+func contextFromHeaders(ctx context.Context, get func(key string) string, newID func() string) (context.Context, string) {
+	requestID, ok := getStringFromContext(ctx, ContextKeyRequestID)
+	if !ok {
+		if requestID = get(HeaderRequestID); requestID == "" {
+			requestID = newID()
+		}
+		ctx = WithRequestID(ctx, requestID)
+	}
+
+	if _, ok := getStringFromContext(ctx, ContextKeyTraceID); !ok {
+		if traceID, spanID, sampled, ok := parseTraceparent(get(HeaderTraceparent)); ok {
+			ctx = WithSpanID(WithTraceID(ctx, traceID), spanID)
+			ctx = context.WithValue(ctx, traceSampledKey{}, sampled)
+		}
+	}
+
+	if dimensionsFromContext(ctx) == nil {
+		ctx = contextWithDimensionsHeader(ctx, get(HeaderDimensions))
+	}
+
+	return ContextWithFieldBag(ctx), requestID
+}
+
+// headersFromContext writes the correlation IDs of the context as outgoing headers.
+// The traceparent header is only written when both a trace and a span ID are present.
+//
+// Parameters:
+//   - ctx: The context holding the correlation IDs
+//   - set: Sets an outgoing header
+func headersFromContext(ctx context.Context, set func(key, value string)) {
+	if requestID, ok := RequestIDFromContext(ctx); ok {
+		set(HeaderRequestID, requestID)
+	}
+
+	traceID, hasTrace := getStringFromContext(ctx, ContextKeyTraceID)
+	spanID, hasSpan := getStringFromContext(ctx, ContextKeySpanID)
+	if hasTrace && hasSpan && isHex(traceID, 32) && isHex(spanID, 16) {
+		flags := "01"
+		if sampled, ok := ctx.Value(traceSampledKey{}).(bool); ok && !sampled {
+			flags = "00"
+		}
+		set(HeaderTraceparent, "00-"+traceID+"-"+spanID+"-"+flags)
+	}
+
+	if dimensions := DimensionsHeader(ctx); dimensions != "" {
+		set(HeaderDimensions, dimensions)
+	}
+}
+
+// InjectHeaders writes the correlation IDs of the context to the headers of an outgoing
+// request: X-Request-ID, traceparent when the context holds valid trace and span IDs, and
+// X-Log-Dimensions. Services behind HTTPMiddleware or the gRPC and Connect interceptors
+// read them back, so their entries carry the same IDs. Existing values are replaced.
+//
+// Parameters:
+//   - ctx: The context holding the correlation IDs
+//   - header: The headers of the outgoing request
+//
+// Example:
+//
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://inventory/items", nil)
+//	logger.InjectHeaders(ctx, req.Header)
+func InjectHeaders(ctx context.Context, header http.Header) {
+	headersFromContext(ctx, header.Set)
+}
+
+// propagatingTransport is an http.RoundTripper adding the correlation headers of the
+// request context to outgoing requests.
+type propagatingTransport struct {
+	base http.RoundTripper
+}
+
+// HTTPTransport returns an http.RoundTripper that writes the correlation IDs of the request
+// context to every outgoing request like InjectHeaders, then sends it with base. Headers
+// already set on the request are kept.
+//
+// Parameters:
+//   - base: The transport sending the requests; nil uses http.DefaultTransport
+//
+// Returns:
+//   - http.RoundTripper: The propagating transport
+//
+// Example:
+//
+//	client := &http.Client{Transport: logger.HTTPTransport(nil)}
+//	resp, err := client.Do(req.WithContext(ctx))
+func HTTPTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &propagatingTransport{base: base}
+}
+
+// RoundTrip sends a copy of the request holding the correlation headers, as a RoundTripper
+// must not modify the request.
+func (t *propagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	header := req.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	injected := false
+	headersFromContext(req.Context(), func(key, value string) {
+		if header.Get(key) == "" {
+			header.Set(key, value)
+			injected = true
+		}
+	})
+	if !injected {
+		return t.base.RoundTrip(req)
+	}
+
+	out := new(http.Request)
+	*out = *req
+	out.Header = header
+	return t.base.RoundTrip(out)
+}
+
+// parseTraceparent extracts the trace and parent span IDs and the sampled flag from a W3C
+// traceparent header. It rejects malformed headers and the all-zero IDs forbidden by
+// the specification. Version 00 has exactly four parts; later versions may append
+// parts, which are ignored.
+func parseTraceparent(value string) (traceID, spanID string, sampled, ok bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" {
+		return "", "", false, false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", "", false, false
+	}
+
+	traceID, spanID = strings.ToLower(parts[1]), strings.ToLower(parts[2])
+	if !isHex(traceID, 32) || !isHex(spanID, 16) || !isHex(parts[3], 2) {
+		return "", "", false, false
+	}
+	if strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false, false
+	}
+
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return "", "", false, false
+	}
+
+	return traceID, spanID, flags&0x01 == 0x01, true
+}
+
+// isHex reports whether s consists of exactly n hexadecimal characters.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces sensitive values in logged content.
+const redactedValue = "[REDACTED]"
+
+// BodyRedactor masks sensitive data in a captured HTTP body before it is logged.
+// It receives the content type of the body and returns the body to log.
+type BodyRedactor func(contentType string, body []byte) []byte
+
+// Body redactors reported by LogConfig in body_redactors.
+const (
+	bodyRedactorNone   = "none"
+	bodyRedactorKeys   = "keys"
+	bodyRedactorCustom = "custom"
+)
+
+type (
+	// bodyRedaction describes the body redactor of an HTTP middleware.
+	bodyRedaction struct {
+		// kind is bodyRedactorNone, bodyRedactorKeys or bodyRedactorCustom.
+		kind string
+		// keys are the keys masked by a key redactor.
+		keys []string
+	}
+
+	// bodyRedactions lists the body redactors of the HTTP middlewares capturing bodies
+	// with a logger, in the order the middlewares were created.
+	bodyRedactions struct {
+		mu   sync.Mutex
+		list []*bodyRedaction
+	}
+)
+
+// MarshalLogObject writes the kind of the redactor and the keys of a key redactor.
+func (r *bodyRedaction) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("redactor", r.kind)
+	if r.kind == bodyRedactorKeys {
+		return enc.AddArray("redacted_keys", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+			for _, key := range r.keys {
+				arr.AppendString(key)
+			}
+			return nil
+		}))
+	}
+	return nil
+}
+
+// add records the body redactor of a middleware.
+func (r *bodyRedactions) add(redaction *bodyRedaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.list = append(r.list, redaction)
+}
+
+// MarshalLogArray writes the body redactors of every middleware.
+func (r *bodyRedactions) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, redaction := range r.list {
+		if err := enc.AppendObject(redaction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultRedactedKeys lists the keys masked by DefaultBodyRedactor.
+var defaultRedactedKeys = []string{
+	"password", "passwd", "secret", "token", "access_token", "refresh_token", "id_token",
+	"authorization", "api_key", "apikey", "client_secret", "credit_card", "card_number", "cvv", "pin",
+}
+
+// DefaultBodyRedactor masks the values of common credential and payment keys
+// such as password, token, api_key and card_number in JSON, form and plain text bodies.
+var DefaultBodyRedactor = NewKeyRedactor(defaultRedactedKeys...)
+
+// NewKeyRedactor creates a BodyRedactor masking the values of the given keys.
+// Keys are matched case-insensitively. JSON bodies are masked at any nesting depth;
+// truncated JSON, form encoded and plain text bodies are masked by pattern.
+//
+// Parameters:
+//   - keys: The keys whose values are replaced with [REDACTED]
+//
+// Returns:
+//   - BodyRedactor: The redactor
+//
+// Example:
+//
+//	redactor := NewKeyRedactor("password", "iban", "ssn")
+//	middleware := HTTPMiddleware(log, WithBodyCapture(4096), WithBodyRedactor(redactor))
+func NewKeyRedactor(keys ...string) BodyRedactor {
+	set := make(map[string]struct{}, len(keys))
+	quoted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		set[strings.ToLower(key)] = struct{}{}
+		quoted = append(quoted, regexp.QuoteMeta(key))
+	}
+
+	if len(quoted) == 0 {
+		return func(_ string, body []byte) []byte { return body }
+	}
+
+	alternatives := strings.Join(quoted, "|")
+	jsonPattern := regexp.MustCompile(`(?i)("(?:` + alternatives + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`)
+	pairPattern := regexp.MustCompile(`(?i)(\b(?:` + alternatives + `)=)([^&\s]*)`)
+
+	return func(contentType string, body []byte) []byte {
+		if strings.Contains(strings.ToLower(contentType), "json") || json.Valid(body) {
+			var v any
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.UseNumber()
+			if err := decoder.Decode(&v); err == nil {
+				if out, err := json.Marshal(redactJSON(v, set)); err == nil {
+					return out
+				}
+			}
+			return jsonPattern.ReplaceAll(body, []byte(`${1}"`+redactedValue+`"`))
+		}
+
+		return pairPattern.ReplaceAll(body, []byte("${1}"+redactedValue))
+	}
+}
+
+// redactJSON masks the values of sensitive keys in a decoded JSON document.
+func redactJSON(v any, keys map[string]struct{}) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, child := range value {
+			if _, ok := keys[strings.ToLower(k)]; ok {
+				value[k] = redactedValue
+				continue
+			}
+			value[k] = redactJSON(child, keys)
+		}
+		return value
+	case []any:
+		for i, child := range value {
+			value[i] = redactJSON(child, keys)
+		}
+		return value
+	default:
+		return v
+	}
+}
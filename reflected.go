@@ -0,0 +1,322 @@
+package logger
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Markers replacing values that the reflection encoder does not descend into.
+const (
+	// CycleMarker replaces a value that refers back to one of its parents.
+	CycleMarker = "<cycle>"
+	// DepthMarker replaces a value nested deeper than the maximum field depth.
+	DepthMarker = "<max depth exceeded>"
+)
+
+// defaultMaxFieldDepth is the maximum nesting depth of reflected fields.
+const defaultMaxFieldDepth = 32
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+type (
+	// safeReflectedEncoder encodes reflected fields (zap.Any, maps, slices and structs)
+	// as JSON like zap's default encoder, but replaces cycles and values nested deeper
+	// than the maximum depth with markers.
+	safeReflectedEncoder struct {
+		enc       *json.Encoder
+		maxDepth  int
+		nonFinite NonFiniteMode
+	}
+
+	// reflectedWalker converts a value into a tree of JSON values.
+	reflectedWalker struct {
+		maxDepth  int
+		nonFinite NonFiniteMode
+		// parents holds the pointers, maps and slices being converted.
+		parents map[reflectedParent]bool
+	}
+
+	// reflectedParent identifies a pointer, map or slice being converted. The type and
+	// length tell apart a struct from its first field and a slice from its prefixes.
+	reflectedParent struct {
+		addr uintptr
+		typ  reflect.Type
+		len  int
+	}
+
+	// orderedObject is a JSON object keeping the order of its members, so structs are
+	// encoded in field order.
+	orderedObject []objectMember
+
+	// objectMember is a member of an orderedObject.
+	objectMember struct {
+		key   string
+		value any
+	}
+)
+
+// newSafeReflectedEncoder returns the zapcore.EncoderConfig.NewReflectedEncoder of a
+// logger with the maximum field depth, zero for no limit, and the non-finite float mode.
+func newSafeReflectedEncoder(maxDepth int, nonFinite NonFiniteMode) func(io.Writer) zapcore.ReflectedEncoder {
+	return func(w io.Writer) zapcore.ReflectedEncoder {
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return &safeReflectedEncoder{enc: enc, maxDepth: maxDepth, nonFinite: nonFinite}
+	}
+}
+
+// Encode writes the JSON encoding of the value with cycles and deep values replaced.
+func (e *safeReflectedEncoder) Encode(v any) error {
+	w := &reflectedWalker{maxDepth: e.maxDepth, nonFinite: e.nonFinite, parents: make(map[reflectedParent]bool)}
+	return e.enc.Encode(w.convert(reflect.ValueOf(v), 0))
+}
+
+// convert returns a value encoding to the same JSON as v, except for cycles, values
+// deeper than the maximum depth and non-finite floats.
+func (w *reflectedWalker) convert(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+	if v.CanInterface() {
+		if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+			return v.Interface()
+		}
+		if v.CanAddr() {
+			if pt := reflect.PointerTo(v.Type()); pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType) {
+				return v.Addr().Interface()
+			}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		return w.convert(v.Elem(), depth)
+
+	case reflect.Pointer:
+		return w.enter(v, func() any { return w.convert(v.Elem(), depth) })
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if w.tooDeep(depth) {
+			return DepthMarker
+		}
+		return w.enter(v, func() any {
+			out := make(map[string]any, v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				out[mapKey(iter.Key())] = w.convert(iter.Value(), depth+1)
+			}
+			return out
+		})
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.CanInterface() {
+			return v.Interface()
+		}
+		if w.tooDeep(depth) {
+			return DepthMarker
+		}
+		return w.enter(v, func() any { return w.convertElems(v, depth) })
+
+	case reflect.Array:
+		if w.tooDeep(depth) {
+			return DepthMarker
+		}
+		return w.convertElems(v, depth)
+
+	case reflect.Struct:
+		if w.tooDeep(depth) {
+			return DepthMarker
+		}
+		var out orderedObject
+		w.convertFields(v, depth, &out)
+		return out
+
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); isNonFinite(f) && w.nonFinite != NonFiniteDefault {
+			return nonFiniteValue(f, w.nonFinite)
+		}
+		if !v.CanInterface() {
+			return v.Float()
+		}
+		return v.Interface()
+
+	default:
+		if !v.CanInterface() {
+			return unexportedValue(v)
+		}
+		return v.Interface()
+	}
+}
+
+// tooDeep reports whether a container at the depth exceeds the maximum depth.
+func (w *reflectedWalker) tooDeep(depth int) bool {
+	return w.maxDepth > 0 && depth >= w.maxDepth
+}
+
+// enter converts a pointer, map or slice, or returns CycleMarker when it is one of its
+// own parents.
+func (w *reflectedWalker) enter(v reflect.Value, convert func() any) any {
+	parent := reflectedParent{addr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		parent.len = v.Len()
+	}
+	if parent.addr == 0 {
+		return convert()
+	}
+	if w.parents[parent] {
+		return CycleMarker
+	}
+	w.parents[parent] = true
+	defer delete(w.parents, parent)
+	return convert()
+}
+
+// convertElems converts the elements of a slice or array.
+func (w *reflectedWalker) convertElems(v reflect.Value, depth int) []any {
+	out := make([]any, v.Len())
+	for i := range out {
+		out[i] = w.convert(v.Index(i), depth+1)
+	}
+	return out
+}
+
+// convertFields appends the fields of a struct following the encoding/json rules for
+// names, omitempty, "-" and embedded structs.
+func (w *reflectedWalker) convertFields(v reflect.Value, depth int, out *orderedObject) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		value := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			embedded := value
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct && !embedded.Type().Implements(jsonMarshalerType) {
+				w.convertFields(embedded, depth, out)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && isEmptyValue(value) {
+			continue
+		}
+		*out = append(*out, objectMember{key: name, value: w.convert(value, depth+1)})
+	}
+}
+
+// MarshalJSON encodes the members in order.
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		var value bytes.Buffer
+		enc := json.NewEncoder(&value)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(m.value); err != nil {
+			return nil, err
+		}
+		buf.Write(bytes.TrimSuffix(value.Bytes(), []byte("\n")))
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// unexportedValue returns a value of a basic kind reached through an unexported
+// embedded struct, which cannot be turned into an interface. Other kinds become null.
+func unexportedValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	default:
+		return nil
+	}
+}
+
+// mapKey returns the JSON object key of a map key.
+func mapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10)
+	default:
+		return fmt.Sprint(k.Interface())
+	}
+}
+
+// isEmptyValue reports whether v is empty in the sense of the omitempty option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
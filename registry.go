@@ -0,0 +1,272 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// LoggerLevel is a named logger of the registry and its current level.
+	LoggerLevel struct {
+		Name  string `json:"name"`
+		Level Level  `json:"level"`
+	}
+
+	// RegistryLevels are the levels served by LevelHandler: the level of the root logger
+	// and of every named logger.
+	RegistryLevels struct {
+		Level   Level         `json:"level"`
+		Loggers []LoggerLevel `json:"loggers"`
+	}
+
+	// loggerRegistry holds the root logger and the named loggers created from it.
+	loggerRegistry struct {
+		mu      sync.Mutex
+		root    Logger
+		loggers map[string]Logger
+	}
+)
+
+// registry is the process-wide registry of named loggers.
+var registry = &loggerRegistry{loggers: map[string]Logger{}}
+
+// SetRoot sets the root logger of the process-wide registry, from which Get creates
+// named loggers. Named loggers created from a previous root are dropped, so set the
+// root once at startup, before calling Get.
+//
+// Parameters:
+//   - log: The root logger, created by NewLogger
+//
+// Example:
+//
+//	log, err := logger.NewLogger(logger.WithAppMode(logger.AppModeProduction))
+//	if err != nil {
+//	    panic(err)
+//	}
+//	logger.SetRoot(log)
+func SetRoot(log Logger) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.root = log
+	clear(registry.loggers)
+}
+
+// Get returns the named logger of the process-wide registry, creating it from the root
+// logger on first use. Named loggers write the name as the logger field and share the
+// configuration and runtime changes of the root; their levels can be changed one by one
+// with SetLoggerLevel. Dotted names form a hierarchy: the level of "payments" applies
+// to "payments.stripe" unless it has its own. An empty name returns the root logger.
+// Without SetRoot, the root is created by NewLogger with the default configuration.
+//
+// Parameters:
+//   - name: The name of the logger, e.g. "payments" or "payments.stripe"
+//
+// Returns:
+//   - Logger: The named logger
+//
+// Example:
+//
+//	var log = logger.Get("payments")
+//
+//	log.Info(ctx, "charge captured") // {"level":"INFO","logger":"payments","message":"charge captured"}
+func Get(name string) Logger {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	root := registry.rootLocked()
+	if name == "" {
+		return root
+	}
+	if log, ok := registry.loggers[name]; ok {
+		return log
+	}
+
+	log := root
+	if z, ok := unwrapLogger(root); ok {
+		log = &logger{logger: &zapLogger{zapLogger: z.zapLogger.Named(name), state: z.state, fields: z.fields}}
+	}
+	registry.loggers[name] = log
+	return log
+}
+
+// Loggers returns the named loggers of the process-wide registry with their current
+// levels, ordered by name. Names with a level set by SetLoggerLevel or WatchFlags are
+// listed even before Get created them.
+//
+// Returns:
+//   - []LoggerLevel: The named loggers and their levels
+func Loggers() []LoggerLevel {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	z, ok := unwrapLogger(registry.rootLocked())
+	names := slices.Collect(maps.Keys(registry.loggers))
+	if !ok {
+		slices.Sort(names)
+		return namedLevels(names, nil, LevelInfo)
+	}
+	overrides := z.state.overrides.load()
+	if overrides != nil {
+		for name := range overrides.names {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return namedLevels(names, overrides, levelName(z.state.zapConfig.Level.Level()))
+}
+
+// SetLoggerLevel changes the level of a named logger of the process-wide registry and
+// its children at runtime, leaving the root and other named loggers unchanged. An empty
+// level removes the level of the name, so it follows its parent again. The change is
+// recorded by an audit entry like in SetLevel. WatchFlags replaces the levels set here
+// whenever its logger_levels change.
+//
+// Parameters:
+//   - ctx: The context of the change, identifying who made it
+//   - name: The name of the logger, as passed to Get
+//   - level: The new minimum level, or empty to remove it
+//
+// Returns:
+//   - error: An error if the name is empty, the level is invalid or the root logger was
+//     not created by NewLogger
+//
+// Example:
+//
+//	err := logger.SetLoggerLevel(ctx, "payments.stripe", logger.LevelDebug)
+func SetLoggerLevel(ctx context.Context, name string, level Level) error {
+	if name == "" {
+		return errors.New("logger name cannot be empty, use SetLevel for the root logger")
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	z, ok := unwrapLogger(registry.rootLocked())
+	if !ok {
+		return errRuntimeUnsupported
+	}
+
+	old := z.state.overrides.load()
+	settings := &overrideSettings{names: map[string]zapcore.Level{}, tenants: map[string]struct{}{}}
+	if old != nil {
+		maps.Copy(settings.names, old.names)
+		maps.Copy(settings.tenants, old.tenants)
+	}
+	if level == "" {
+		delete(settings.names, name)
+	} else {
+		parsed, err := parseLevel(level)
+		if err != nil {
+			return err
+		}
+		settings.names[name] = parsed.Level()
+	}
+
+	z.state.overrides.store(settings)
+	if before, after := old.namesString(), settings.namesString(); before != after {
+		auditChange(ctx, z, "logger_levels", before, after)
+	}
+	return nil
+}
+
+// LevelHandler returns an HTTP handler managing the levels of the process-wide
+// registry. GET responds with the RegistryLevels as JSON. PUT and POST change a level
+// with a JSON LoggerLevel body: the level of the named logger, or of the root logger
+// when the name is empty. Changes are audited with the user ID of the request context,
+// so mount the handler behind authentication.
+//
+// Returns:
+//   - http.Handler: The handler
+//
+// Example:
+//
+//	mux.Handle("/debug/log-levels", authenticate(logger.LevelHandler()))
+//
+//	// curl -X PUT -d '{"name":"payments","level":"debug"}' http://localhost:8080/debug/log-levels
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var change LoggerLevel
+			if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+				http.Error(w, fmt.Sprintf("invalid level change: %v", err), http.StatusBadRequest)
+				return
+			}
+			var err error
+			if change.Name == "" {
+				err = SetLevel(r.Context(), Get(""), change.Level)
+			} else {
+				err = SetLoggerLevel(r.Context(), change.Name, change.Level)
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		levels := RegistryLevels{Level: LevelInfo, Loggers: Loggers()}
+		if z, ok := unwrapLogger(Get("")); ok {
+			levels.Level = levelName(z.state.zapConfig.Level.Level())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(levels)
+	})
+}
+
+// rootLocked returns the root logger, creating it with the default configuration when
+// none was set. The registry must be locked.
+func (r *loggerRegistry) rootLocked() Logger {
+	if r.root == nil {
+		// The default configuration is valid, so NewLogger cannot fail.
+		r.root, _ = NewLogger()
+	}
+	return r.root
+}
+
+// namedLevels returns the levels of the names: their override or the root level.
+func namedLevels(names []string, overrides *overrideSettings, root Level) []LoggerLevel {
+	levels := make([]LoggerLevel, 0, len(names))
+	for _, name := range names {
+		level := root
+		if overrides != nil {
+			if l, ok := overrides.level(name); ok {
+				level = levelName(l)
+			}
+		}
+		levels = append(levels, LoggerLevel{Name: name, Level: level})
+	}
+	return levels
+}
+
+// levelName returns the Level of a zap level.
+func levelName(level zapcore.Level) Level {
+	switch level {
+	case zapcore.DebugLevel:
+		return LevelDebug
+	case zapcore.InfoLevel:
+		return LevelInfo
+	case zapcore.WarnLevel:
+		return LevelWarning
+	case zapcore.ErrorLevel:
+		return LevelError
+	case zapcore.DPanicLevel, zapcore.PanicLevel:
+		return LevelPanic
+	default:
+		return LevelFatal
+	}
+}
@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys naming the region whose sinks an entry is routed to, in order of precedence.
+const (
+	FieldKeyDataResidency = "data_residency"
+	FieldKeyRegion        = "region"
+)
+
+type (
+	// ResidencyConfig routes entries to the sinks of their data residency region.
+	ResidencyConfig struct {
+		// Regions maps each region to its output paths, e.g. "eu" to the EU collector.
+		// Entries of a region are written to its sinks only, never to the output paths
+		// of the logger.
+		Regions map[string][]string
+		// Default is the region of unclassified entries: entries without a region, or
+		// with a region missing from Regions. Empty leaves them to RejectUnclassified.
+		Default string
+		// RejectUnclassified drops unclassified entries when there is no Default. When
+		// false they are written to the output paths of the logger.
+		RejectUnclassified bool
+	}
+
+	// residencyState counts the entries rejected by residency routing.
+	residencyState struct {
+		rejected atomic.Uint64
+	}
+
+	// residencyCore routes entries to the core of their region, falling back to the
+	// wrapped core of the output paths for unclassified entries.
+	residencyCore struct {
+		zapcore.Core
+		regions map[string]zapcore.Core
+		config  *ResidencyConfig
+		state   *residencyState
+		// region is the region set by fields added with With, if any.
+		region string
+	}
+)
+
+// newResidencyCore wraps the core of the output paths with the residency routing of
+// cfg, opening the sinks of every region. It returns core unchanged, a nil state and a
+// nil close function when routing is not configured.
+func newResidencyCore(core zapcore.Core, encoder zapcore.Encoder, level zapcore.LevelEnabler, cfg *config) (zapcore.Core, *residencyState, func(), error) {
+	residency := cfg.Residency
+	if residency == nil {
+		return core, nil, nil, nil
+	}
+	if len(residency.Regions) == 0 {
+		return nil, nil, nil, errors.New("data residency routing needs at least one region")
+	}
+	if _, ok := residency.Regions[residency.Default]; residency.Default != "" && !ok {
+		return nil, nil, nil, fmt.Errorf("default data residency region %q has no sinks", residency.Default)
+	}
+
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	regions := make(map[string]zapcore.Core, len(residency.Regions))
+	for _, region := range residencyRegionNames(residency) {
+		paths := residency.Regions[region]
+		if len(paths) == 0 {
+			closeAll()
+			return nil, nil, nil, fmt.Errorf("data residency region %q has no sinks", region)
+		}
+		writers, closeSinks, err := openSinkList(paths, cfg)
+		if err != nil {
+			closeAll()
+			return nil, nil, nil, fmt.Errorf("failed to open sinks of data residency region %q: %w", region, err)
+		}
+		closers = append(closers, closeSinks)
+
+		cores := make([]zapcore.Core, 0, len(writers))
+		for i, ws := range writers {
+			policy, _ := findSinkPolicy(cfg.SinkPolicies, paths[i])
+			cores = append(cores, newClassCore(zapcore.NewCore(encoder.Clone(), ws, level), policy))
+		}
+		regions[region] = zapcore.NewTee(cores...)
+	}
+
+	state := &residencyState{}
+	return &residencyCore{Core: core, regions: regions, config: residency, state: state}, state, closeAll, nil
+}
+
+// residencyRegionNames returns the regions in order, none without routing.
+func residencyRegionNames(r *ResidencyConfig) []string {
+	if r == nil {
+		return []string{}
+	}
+	names := make([]string, 0, len(r.Regions))
+	for region := range r.Regions {
+		names = append(names, region)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// residencyPaths returns the output paths of every region.
+func residencyPaths(residency *ResidencyConfig) []string {
+	if residency == nil {
+		return nil
+	}
+	var paths []string
+	for _, p := range residency.Regions {
+		paths = append(paths, p...)
+	}
+	return paths
+}
+
+// regionOf returns the region named by the fields, or fallback when none names one.
+func regionOf(fields []Field, fallback string) string {
+	region, rank := fallback, 0
+	for _, f := range fields {
+		if f.Type != zapcore.StringType {
+			continue
+		}
+		switch {
+		case f.Key == FieldKeyDataResidency:
+			region, rank = f.String, 2
+		case f.Key == FieldKeyRegion && rank < 2:
+			region, rank = f.String, 1
+		}
+	}
+	return region
+}
+
+// With returns a child core with the fields added to every region and remembering the
+// region they name.
+func (c *residencyCore) With(fields []Field) zapcore.Core {
+	regions := make(map[string]zapcore.Core, len(c.regions))
+	for name, core := range c.regions {
+		regions[name] = core.With(fields)
+	}
+	return &residencyCore{
+		Core:    c.Core.With(fields),
+		regions: regions,
+		config:  c.config,
+		state:   c.state,
+		region:  regionOf(fields, c.region),
+	}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *residencyCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry to the sinks of its region. Unclassified entries go to the
+// default region, are rejected or go to the output paths.
+func (c *residencyCore) Write(ent zapcore.Entry, fields []Field) error {
+	if core, ok := c.regions[regionOf(fields, c.region)]; ok {
+		return core.Write(ent, fields)
+	}
+	if core, ok := c.regions[c.config.Default]; ok {
+		return core.Write(ent, fields)
+	}
+	if c.config.RejectUnclassified {
+		c.state.rejected.Add(1)
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// Sync flushes the output paths and the sinks of every region.
+func (c *residencyCore) Sync() error {
+	errs := []error{c.Core.Sync()}
+	for _, core := range c.regions {
+		errs = append(errs, core.Sync())
+	}
+	return errors.Join(errs...)
+}
+
+// rejectedCount returns the number of rejected entries, zero without routing.
+func (s *residencyState) rejectedCount() uint64 {
+	if s == nil {
+		return 0
+	}
+	return s.rejected.Load()
+}
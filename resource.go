@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// resourceSDKPrefix is the prefix of the resource attributes describing the OpenTelemetry
+// SDK rather than the workload, which are not added to entries.
+const resourceSDKPrefix = "telemetry.sdk."
+
+// OTelResource is the set of attributes describing the entity producing telemetry,
+// implemented by *resource.Resource of the OpenTelemetry SDK, e.g. the result of
+// resource.New with the detectors of the tracer provider.
+type OTelResource interface {
+	// Attributes returns the attributes of the resource.
+	Attributes() []attribute.KeyValue
+}
+
+// resourceFields converts the attributes of a resource to fields keyed by the attribute
+// keys, skipping the telemetry.sdk.* attributes.
+func resourceFields(res OTelResource) []Field {
+	if res == nil {
+		return nil
+	}
+	attrs := res.Attributes()
+	fields := make([]Field, 0, len(attrs))
+	for _, attr := range attrs {
+		key := string(attr.Key)
+		if strings.HasPrefix(key, resourceSDKPrefix) {
+			continue
+		}
+		fields = append(fields, attributeField(key, attr.Value))
+	}
+	return fields
+}
+
+// attributeField converts an OpenTelemetry attribute value to a field of the same type.
+func attributeField(key string, value attribute.Value) Field {
+	switch value.Type() {
+	case attribute.BOOL:
+		return zap.Bool(key, value.AsBool())
+	case attribute.INT64:
+		return zap.Int64(key, value.AsInt64())
+	case attribute.FLOAT64:
+		return zap.Float64(key, value.AsFloat64())
+	case attribute.STRING:
+		return zap.String(key, value.AsString())
+	case attribute.BOOLSLICE:
+		return zap.Bools(key, value.AsBoolSlice())
+	case attribute.INT64SLICE:
+		return zap.Int64s(key, value.AsInt64Slice())
+	case attribute.FLOAT64SLICE:
+		return zap.Float64s(key, value.AsFloat64Slice())
+	case attribute.STRINGSLICE:
+		return zap.Strings(key, value.AsStringSlice())
+	default:
+		return zap.String(key, value.Emit())
+	}
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strings"
+)
+
+type (
+	// RouteConfig overrides how HTTPMiddleware logs the requests of a route.
+	RouteConfig struct {
+		// Skip disables the access log entry of the route, e.g. for health checks.
+		Skip bool
+		// SampleRate logs the access log entry of this fraction of requests, e.g. 0.01
+		// for 1%. Zero or one logs every request.
+		SampleRate float64
+		// Debug writes debug entries logged with the request context even when the
+		// logger's level is higher.
+		Debug bool
+	}
+
+	// routeRule is a RouteConfig with the requests it applies to.
+	routeRule struct {
+		method  string
+		pattern string
+		config  RouteConfig
+	}
+
+	// forceDebugKey is the context key marking contexts whose debug entries are written.
+	forceDebugKey struct{}
+)
+
+// WithRoute overrides the logging of requests matching the pattern.
+// Patterns have the form "[METHOD ]PATH", e.g. "/healthz" or "GET /metrics". PATH is
+// matched with path.Match, and a trailing "*" matches any suffix, so "/admin/*" covers
+// every path below /admin/. Without a method every method matches. The first matching
+// route in the order of the options applies. Skip and SampleRate only apply to
+// responses below 500, so failing requests are always logged. Requests not logged are
+// not recorded by WithLatencyHistogram either.
+//
+// Parameters:
+//   - pattern: The requests the configuration applies to
+//   - config: The logging configuration of the route
+//
+// Example:
+//
+//	middleware := HTTPMiddleware(log,
+//	    WithRoute("/healthz", RouteConfig{Skip: true}),
+//	    WithRoute("/admin/*", RouteConfig{Debug: true}),
+//	    WithRoute("GET /metrics", RouteConfig{SampleRate: 0.01}),
+//	)
+func WithRoute(pattern string, config RouteConfig) HTTPOption {
+	rule := routeRule{pattern: pattern, config: config}
+	if method, rest, ok := strings.Cut(pattern, " "); ok {
+		rule.method, rule.pattern = method, strings.TrimSpace(rest)
+	}
+	return func(c *httpConfig) {
+		c.Routes = append(c.Routes, rule)
+	}
+}
+
+// route returns the configuration of the first route matching the request.
+func (c *httpConfig) route(r *http.Request) (RouteConfig, bool) {
+	for _, rule := range c.Routes {
+		if rule.matches(r.Method, r.URL.Path) {
+			return rule.config, true
+		}
+	}
+	return RouteConfig{}, false
+}
+
+// matches reports whether the rule applies to the method and path.
+func (r routeRule) matches(method, requestPath string) bool {
+	if r.method != "" && r.method != method {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.pattern, "*"); ok && !strings.ContainsAny(prefix, "*?[") {
+		return strings.HasPrefix(requestPath, prefix)
+	}
+	matched, err := path.Match(r.pattern, requestPath)
+	return err == nil && matched
+}
+
+// logged reports whether the access log entry of a response with the status is written.
+func (c RouteConfig) logged(status int) bool {
+	if status >= http.StatusInternalServerError {
+		return true
+	}
+	if c.Skip {
+		return false
+	}
+	if c.SampleRate > 0 && c.SampleRate < 1 {
+		return rand.Float64() < c.SampleRate
+	}
+	return true
+}
+
+// alwaysLogged reports whether every request of the route is logged, whatever its status.
+func (c RouteConfig) alwaysLogged() bool {
+	return !c.Skip && (c.SampleRate <= 0 || c.SampleRate >= 1)
+}
+
+// contextWithForcedDebug marks the context so its debug entries are written.
+func contextWithForcedDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceDebugKey{}, true)
+}
+
+// debugForced reports whether debug entries of the context are written.
+func debugForced(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	forced, _ := ctx.Value(forceDebugKey{}).(bool)
+	return forced
+}
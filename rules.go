@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+// Kinds of metrics maintained by metric rules.
+const (
+	MetricCounter MetricKind = "counter"
+	MetricGauge   MetricKind = "gauge"
+)
+
+type (
+	// MetricKind is the kind of metric a MetricRule maintains.
+	MetricKind string
+
+	// MetricRule turns matching log entries into a Prometheus metric.
+	// Counters are incremented by one, or by the value of ValueField when set, with
+	// the trace_id and span_id of the entry as exemplar. Gauges are set to the value
+	// of ValueField. Label values are read from the
+	// fields named by Labels, so the same field keys used for searching logs
+	// become metric dimensions.
+	MetricRule struct {
+		// Name is the metric name, e.g. payment_failed_total.
+		Name string
+		// Help is the metric description; defaults to a description of the rule.
+		Help string
+		// Kind is MetricCounter or MetricGauge (default: MetricCounter).
+		Kind MetricKind
+		// Match selects the entries of the rule. Filter helpers such as
+		// FilterMessage and FilterFieldEquals can be used as predicates.
+		Match Filter
+		// Labels are the field keys whose values become labels. Missing fields yield "".
+		Labels []string
+		// ValueField is the numeric field added to counters or set on gauges.
+		ValueField string
+	}
+
+	// ruleProcessor maintains the metric of a MetricRule.
+	ruleProcessor struct {
+		rule    MetricRule
+		counter *prometheus.CounterVec
+		gauge   *prometheus.GaugeVec
+	}
+)
+
+// newRuleProcessor validates the rule and registers its metric with the registerer.
+func newRuleProcessor(registerer prometheus.Registerer, rule MetricRule) (*ruleProcessor, error) {
+	if rule.Name == "" {
+		return nil, errors.New("metric rule without name")
+	}
+	if rule.Match == nil {
+		return nil, fmt.Errorf("metric rule %q without match predicate", rule.Name)
+	}
+	if registerer == nil {
+		registerer = prometheus.DefaultRegisterer
+	}
+	if rule.Help == "" {
+		rule.Help = fmt.Sprintf("Metric %s derived from log entries.", rule.Name)
+	}
+
+	p := &ruleProcessor{rule: rule}
+	var err error
+	switch rule.Kind {
+	case MetricCounter, "":
+		opts := prometheus.CounterOpts{Name: rule.Name, Help: rule.Help}
+		p.counter, err = registerCollector(registerer, prometheus.NewCounterVec(opts, rule.Labels))
+	case MetricGauge:
+		if rule.ValueField == "" {
+			return nil, fmt.Errorf("gauge rule %q without value field", rule.Name)
+		}
+		opts := prometheus.GaugeOpts{Name: rule.Name, Help: rule.Help}
+		p.gauge, err = registerCollector(registerer, prometheus.NewGaugeVec(opts, rule.Labels))
+	default:
+		return nil, fmt.Errorf("metric rule %q has unknown kind %q", rule.Name, rule.Kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to register metric rule %q: %w", rule.Name, err)
+	}
+	return p, nil
+}
+
+// wants reports true; rules match on fields, which are only known when the entry is written.
+func (p *ruleProcessor) wants(zapcore.Entry) bool {
+	return true
+}
+
+// observe updates the metric when the rule matches the entry.
+// Entries whose value field is missing or not numeric are ignored.
+func (p *ruleProcessor) observe(entry Entry) {
+	if !p.rule.Match(entry) {
+		return
+	}
+
+	value := 1.0
+	if p.rule.ValueField != "" {
+		f, ok := entry.Field(p.rule.ValueField)
+		if !ok {
+			return
+		}
+		if value, ok = fieldFloat(f); !ok {
+			return
+		}
+	}
+
+	labels := make([]string, len(p.rule.Labels))
+	for i, key := range p.rule.Labels {
+		if f, ok := entry.Field(key); ok {
+			labels[i] = fieldString(f)
+		}
+	}
+
+	if p.gauge != nil {
+		p.gauge.WithLabelValues(labels...).Set(value)
+		return
+	}
+	if value >= 0 {
+		addWithExemplar(p.counter.WithLabelValues(labels...), value, exemplarLabels(entry))
+	}
+}
+
+// fieldString renders the value of a field as a label value.
+func fieldString(f Field) string {
+	switch f.Type {
+	case zapcore.StringType:
+		return f.String
+	case zapcore.BoolType:
+		return strconv.FormatBool(f.Integer == 1)
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return strconv.FormatInt(f.Integer, 10)
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return strconv.FormatUint(uint64(f.Integer), 10)
+	case zapcore.Float64Type:
+		return strconv.FormatFloat(math.Float64frombits(uint64(f.Integer)), 'g', -1, 64)
+	case zapcore.DurationType:
+		return time.Duration(f.Integer).String()
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(fmt.Stringer); ok {
+			return s.String()
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok {
+			return err.Error()
+		}
+	}
+	return ""
+}
+
+// fieldFloat returns the numeric value of a field. Durations are in seconds.
+func fieldFloat(f Field) (float64, bool) {
+	switch f.Type {
+	case zapcore.Int64Type, zapcore.Int32Type, zapcore.Int16Type, zapcore.Int8Type:
+		return float64(f.Integer), true
+	case zapcore.Uint64Type, zapcore.Uint32Type, zapcore.Uint16Type, zapcore.Uint8Type, zapcore.UintptrType:
+		return float64(uint64(f.Integer)), true
+	case zapcore.Float64Type:
+		return math.Float64frombits(uint64(f.Integer)), true
+	case zapcore.Float32Type:
+		return float64(math.Float32frombits(uint32(f.Integer))), true
+	case zapcore.DurationType:
+		return time.Duration(f.Integer).Seconds(), true
+	case zapcore.StringType:
+		v, err := strconv.ParseFloat(f.String, 64)
+		return v, err == nil
+	}
+	return 0, false
+}
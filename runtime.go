@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AuditLoggerName is the logger name of the entries recording runtime configuration changes.
+const AuditLoggerName = "logger.audit"
+
+// Field keys written to audit entries.
+const (
+	FieldKeyChange    = "change"
+	FieldKeyOldValue  = "old_value"
+	FieldKeyNewValue  = "new_value"
+	FieldKeyChangedBy = "changed_by"
+)
+
+// errRuntimeUnsupported is returned when a runtime change targets a logger not created by NewLogger.
+var errRuntimeUnsupported = errors.New("logger does not support runtime changes: it was not created by NewLogger")
+
+// SetLevel changes the minimum level of the logger and all its children at runtime.
+// The change is recorded by an audit entry named logger.audit carrying the old and
+// new level and the user_id of the context as changed_by. Audit entries are written
+// regardless of the level, so lowering verbosity is recorded as well.
+//
+// Parameters:
+//   - ctx: The context of the change, identifying who made it
+//   - log: The logger created by NewLogger
+//   - level: The new minimum level
+//
+// Returns:
+//   - error: An error if the level is invalid or the logger was not created by NewLogger
+//
+// Example:
+//
+//	ctx = context.WithValue(ctx, ContextKeyUserID, "oncall@example.com")
+//	if err := SetLevel(ctx, log, LevelDebug); err != nil {
+//	    return err
+//	}
+func SetLevel(ctx context.Context, log Logger, level Level) error {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return errRuntimeUnsupported
+	}
+
+	parsed, err := parseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	setLevel(ctx, z, parsed.Level())
+	return nil
+}
+
+// setLevel changes the minimum level of the logger and records the change.
+func setLevel(ctx context.Context, z *zapLogger, level zapcore.Level) {
+	old := z.state.zapConfig.Level.Level()
+	z.state.zapConfig.Level.SetLevel(level)
+	auditChange(ctx, z, "level", old.String(), level.String())
+}
+
+// SetSampling changes the sampling of the logger and all its children at runtime.
+// Per second, the first initial entries with the same level and message are logged
+// and every thereafter-th entry after that. Passing zero for both disables sampling.
+// The change is recorded by an audit entry like in SetLevel.
+//
+// Parameters:
+//   - ctx: The context of the change, identifying who made it
+//   - log: The logger created by NewLogger
+//   - initial: The number of entries logged per second before sampling starts
+//   - thereafter: Every how many entries one is logged once sampling started
+//
+// Returns:
+//   - error: An error if a value is negative or the logger was not created by NewLogger
+//
+// Example:
+//
+//	err := SetSampling(ctx, log, 100, 100) // zap's production defaults
+//	err = SetSampling(ctx, log, 0, 0)      // log everything during an incident
+func SetSampling(ctx context.Context, log Logger, initial, thereafter int) error {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return errRuntimeUnsupported
+	}
+	if initial < 0 || thereafter < 0 {
+		return errors.New("sampling values cannot be negative")
+	}
+
+	old := samplingString(z.state.sampler.current())
+	if initial == 0 && thereafter == 0 {
+		z.state.sampler.disable()
+	} else {
+		z.state.sampler.set(initial, thereafter)
+	}
+	auditChange(ctx, z, "sampling", old, samplingString(z.state.sampler.current()))
+	return nil
+}
+
+// SetRedactedFields replaces the keys of the fields whose values are written as
+// [REDACTED] for the logger and all its children at runtime. Passing no keys disables
+// field redaction. Fields added with With are redacted with the keys in effect when the
+// child logger is created. The change is recorded by an audit entry like in SetLevel.
+//
+// Parameters:
+//   - ctx: The context of the change, identifying who made it
+//   - log: The logger created by NewLogger
+//   - keys: The keys of the fields to redact
+//
+// Returns:
+//   - error: An error if the logger was not created by NewLogger
+//
+// Example:
+//
+//	err := SetRedactedFields(ctx, log, "password", "card_number", "email")
+func SetRedactedFields(ctx context.Context, log Logger, keys ...string) error {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return errRuntimeUnsupported
+	}
+
+	old := z.state.redacted.String()
+	z.state.redacted.set(keys)
+	auditChange(ctx, z, "redaction", old, z.state.redacted.String())
+	return nil
+}
+
+// auditChange writes the audit entry of a runtime configuration change.
+// The entry bypasses the level and sampling of the logger so it is never lost.
+func auditChange(ctx context.Context, z *zapLogger, change string, old, new string) {
+	changedBy, ok := getStringFromContext(ctx, ContextKeyUserID)
+	if !ok {
+		changedBy = "unknown"
+	}
+
+	auditLogger(z).Warn(ctx, "logger configuration changed",
+		zap.String(FieldKeyChange, change),
+		zap.String(FieldKeyOldValue, old),
+		zap.String(FieldKeyNewValue, new),
+		zap.String(FieldKeyChangedBy, changedBy),
+	)
+}
+
+// samplingString describes sampling settings for audit entries.
+func samplingString(settings *samplerSettings) string {
+	if settings == nil {
+		return "disabled"
+	}
+	return fmt.Sprintf("initial=%d thereafter=%d", settings.Initial, settings.Thereafter)
+}
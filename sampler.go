@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// samplerLevels is the number of zap levels from DebugLevel to FatalLevel.
+	samplerLevels = int(zapcore.FatalLevel-zapcore.DebugLevel) + 1
+	// samplerCounters is the number of message buckets per level, as in zap's sampler.
+	samplerCounters = 4096
+	// samplerTick is the interval after which the sampling counters reset.
+	samplerTick = time.Second
+)
+
+type (
+	// sampler holds the sampling settings shared by a logger and its children.
+	// Unlike zap's sampler the settings can be replaced at runtime.
+	sampler struct {
+		settings atomic.Pointer[samplerSettings]
+	}
+
+	// samplerSettings logs the first Initial entries with the same level and message
+	// per tick and every Thereafter-th entry after that.
+	samplerSettings struct {
+		Initial    int
+		Thereafter int
+		counters   *[samplerLevels][samplerCounters]samplerCounter
+	}
+
+	// samplerCounter counts the entries of a message bucket within the current tick.
+	samplerCounter struct {
+		resetAt atomic.Int64
+		count   atomic.Uint64
+	}
+
+	// samplingCore wraps a zapcore.Core and drops entries according to the sampler settings.
+	samplingCore struct {
+		zapcore.Core
+		sampler *sampler
+	}
+)
+
+// newSampler creates a sampler with the given settings; nil settings disable sampling.
+func newSampler(initial, thereafter int, enabled bool) *sampler {
+	s := &sampler{}
+	if enabled {
+		s.set(initial, thereafter)
+	}
+	return s
+}
+
+// set replaces the sampling settings and resets the counters.
+func (s *sampler) set(initial, thereafter int) {
+	s.settings.Store(&samplerSettings{
+		Initial:    initial,
+		Thereafter: thereafter,
+		counters:   new([samplerLevels][samplerCounters]samplerCounter),
+	})
+}
+
+// disable turns sampling off.
+func (s *sampler) disable() {
+	s.settings.Store(nil)
+}
+
+// current returns the active settings, or nil when sampling is disabled.
+func (s *sampler) current() *samplerSettings {
+	return s.settings.Load()
+}
+
+// With returns a child core sharing the sampler.
+func (c *samplingCore) With(fields []Field) zapcore.Core {
+	return &samplingCore{Core: c.Core.With(fields), sampler: c.sampler}
+}
+
+// lowered returns a copy of the core sharing the sampler.
+func (c *samplingCore) lowered() zapcore.Core {
+	return &samplingCore{Core: lowerCore(c.Core), sampler: c.sampler}
+}
+
+// Check adds the core to the checked entry unless the sampler drops the entry. Audit
+// entries are never sampled.
+func (c *samplingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if settings := c.sampler.current(); settings != nil && !isAuditEntry(ent) && !settings.allow(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// allow counts the entry and reports whether it is within the sampling budget.
+func (s *samplerSettings) allow(ent zapcore.Entry) bool {
+	level := int(ent.Level - zapcore.DebugLevel)
+	if level < 0 || level >= samplerLevels {
+		return true
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ent.Message))
+	n := s.counters[level][h.Sum32()%samplerCounters].inc(ent.Time)
+
+	initial, thereafter := uint64(s.Initial), uint64(s.Thereafter)
+	if n > initial && (thereafter == 0 || (n-initial)%thereafter != 0) {
+		return false
+	}
+	return true
+}
+
+// inc increments the counter, resetting it when the tick has passed.
+func (c *samplerCounter) inc(t time.Time) uint64 {
+	now := t.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+samplerTick.Nanoseconds()) {
+		return c.count.Add(1)
+	}
+	return 1
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traceSampledKey is the context key of the sampled flag of an incoming traceparent header.
+type traceSampledKey struct{}
+
+// TraceSampled reports whether the trace of the context is sampled.
+// The decision is taken from the OpenTelemetry span of the context, then from the
+// New Relic transaction, then from the flags of the traceparent header read by
+// HTTPMiddleware or the RPC interceptors. Contexts without a trace are not sampled.
+//
+// Parameters:
+//   - ctx: The context of the current request
+//
+// Returns:
+//   - bool: True if the trace is sampled
+func TraceSampled(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		return sc.IsSampled()
+	}
+	if txn := newrelic.FromContext(ctx); txn != nil {
+		return txn.IsSampled()
+	}
+	sampled, _ := ctx.Value(traceSampledKey{}).(bool)
+	return sampled
+}
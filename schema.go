@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// FieldKeySchemaVersion is the field stamped on every entry by WithLogSchemaVersion.
+const FieldKeySchemaVersion = "schema_version"
+
+type (
+	// fieldRename renames a field key, optionally keeping the old key as well.
+	fieldRename struct {
+		From      string
+		To        string
+		DualWrite bool
+	}
+
+	// renameCore wraps a zapcore.Core and renames field keys right before encoding.
+	renameCore struct {
+		zapcore.Core
+		renames map[string]fieldRename
+	}
+)
+
+// newRenameCore wraps core with the renames, or returns core unchanged when there are none.
+func newRenameCore(core zapcore.Core, renames []fieldRename) zapcore.Core {
+	if len(renames) == 0 {
+		return core
+	}
+	byKey := make(map[string]fieldRename, len(renames))
+	for _, r := range renames {
+		byKey[r.From] = r
+	}
+	return &renameCore{Core: core, renames: byKey}
+}
+
+// With returns a child core with the renamed fields.
+func (c *renameCore) With(fields []Field) zapcore.Core {
+	return &renameCore{Core: c.Core.With(c.rename(fields)), renames: c.renames}
+}
+
+// lowered returns a copy of the core keeping the renames.
+func (c *renameCore) lowered() zapcore.Core {
+	return &renameCore{Core: lowerCore(c.Core), renames: c.renames}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *renameCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry with the renamed fields.
+func (c *renameCore) Write(ent zapcore.Entry, fields []Field) error {
+	return c.Core.Write(ent, c.rename(fields))
+}
+
+// rename returns the fields with renamed keys. The input slice is never modified.
+func (c *renameCore) rename(fields []Field) []Field {
+	var out []Field
+	for i, f := range fields {
+		r, ok := c.renames[f.Key]
+		if !ok {
+			if out != nil {
+				out = append(out, f)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]Field, i, len(fields)+1)
+			copy(out, fields[:i])
+		}
+		if r.DualWrite {
+			out = append(out, f)
+		}
+		f.Key = r.To
+		out = append(out, f)
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/codes"
+)
+
+// Syslog severities of RFC 5424, from the most to the least severe.
+const (
+	SyslogEmergency = iota
+	SyslogAlert
+	SyslogCritical
+	SyslogError
+	SyslogWarning
+	SyslogNotice
+	SyslogInformational
+	SyslogDebug
+)
+
+// LevelFromSyslog converts an RFC 5424 syslog severity to a Level. Emergencies are
+// fatal; alerts and critical conditions are errors, the highest level that does not
+// stop the process; notices are info. Values outside 0 to 7 are info.
+//
+// Parameters:
+//   - severity: The syslog severity, 0 (emergency) to 7 (debug)
+//
+// Returns:
+//   - Level: The matching level
+//
+// Example:
+//
+//	level := logger.LevelFromSyslog(logger.SyslogWarning) // LevelWarning
+func LevelFromSyslog(severity int) Level {
+	switch severity {
+	case SyslogEmergency:
+		return LevelFatal
+	case SyslogAlert, SyslogCritical, SyslogError:
+		return LevelError
+	case SyslogWarning:
+		return LevelWarning
+	case SyslogDebug:
+		return LevelDebug
+	default:
+		return LevelInfo
+	}
+}
+
+// LevelFromHTTPStatus converts an HTTP response status to a Level by its class: server
+// errors (5xx) are errors, client errors (4xx) are warnings and any other status is
+// info. It is the level of the access log entries of HTTPMiddleware.
+//
+// Parameters:
+//   - status: The HTTP status code
+//
+// Returns:
+//   - Level: The matching level
+//
+// Example:
+//
+//	level := logger.LevelFromHTTPStatus(http.StatusNotFound) // LevelWarning
+func LevelFromHTTPStatus(status int) Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return LevelError
+	case status >= http.StatusBadRequest:
+		return LevelWarning
+	default:
+		return LevelInfo
+	}
+}
+
+// LevelFromGRPCCode converts a gRPC status code to a Level. OK is info, codes caused
+// by the caller, such as InvalidArgument or NotFound, are warnings and codes indicating
+// a server fault, such as Internal or Unavailable, are errors. It is the level of the
+// access log entries of the gRPC and Connect interceptors.
+//
+// Parameters:
+//   - code: The gRPC status code
+//
+// Returns:
+//   - Level: The matching level
+//
+// Example:
+//
+//	level := logger.LevelFromGRPCCode(codes.Unavailable) // LevelError
+func LevelFromGRPCCode(code codes.Code) Level {
+	switch code {
+	case codes.OK:
+		return LevelInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition,
+		codes.OutOfRange, codes.ResourceExhausted, codes.Aborted:
+		return LevelWarning
+	default:
+		return LevelError
+	}
+}
+
+// LevelFromOTelSeverity converts an OpenTelemetry log severity number to a Level.
+// TRACE (1-4) and DEBUG (5-8) are debug, INFO (9-12) is info, WARN (13-16) is warning,
+// ERROR (17-20) is error and FATAL (21-24) is fatal. Unspecified (0) and values out of
+// range are info.
+//
+// Parameters:
+//   - severity: The OpenTelemetry SeverityNumber
+//
+// Returns:
+//   - Level: The matching level
+//
+// Example:
+//
+//	level := logger.LevelFromOTelSeverity(int(record.Severity())) // 17 -> LevelError
+func LevelFromOTelSeverity(severity int) Level {
+	switch {
+	case severity >= 1 && severity <= 8:
+		return LevelDebug
+	case severity >= 13 && severity <= 16:
+		return LevelWarning
+	case severity >= 17 && severity <= 20:
+		return LevelError
+	case severity >= 21 && severity <= 24:
+		return LevelFatal
+	default:
+		return LevelInfo
+	}
+}
+
+// LevelFromName converts a level name used by another logging library to a Level,
+// ignoring case: trace and debug are debug; warn and warning are warning; err, error,
+// crit, critical and alert are error; dpanic and panic are panic; emerg, emergency
+// and fatal are fatal. Any other name, such as info or notice, is info.
+//
+// Parameters:
+//   - name: The level name, e.g. the "level" value of a go-kit or logrus entry
+//
+// Returns:
+//   - Level: The matching level
+//
+// Example:
+//
+//	level := logger.LevelFromName("WARN") // LevelWarning
+func LevelFromName(name string) Level {
+	switch strings.ToLower(name) {
+	case "trace", "debug":
+		return LevelDebug
+	case "warn", "warning":
+		return LevelWarning
+	case "err", "error", "crit", "critical", "alert":
+		return LevelError
+	case "dpanic", "panic":
+		return LevelPanic
+	case "emerg", "emergency", "fatal":
+		return LevelFatal
+	default:
+		return LevelInfo
+	}
+}
+
+// zapLevel returns the zap level of a Level, the inverse of levelName. Unknown levels
+// are InfoLevel.
+func zapLevel(level Level) zapcore.Level {
+	switch level {
+	case LevelDebug:
+		return zapcore.DebugLevel
+	case LevelWarning:
+		return zapcore.WarnLevel
+	case LevelError:
+		return zapcore.ErrorLevel
+	case LevelPanic:
+		return zapcore.PanicLevel
+	case LevelFatal:
+		return zapcore.FatalLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultShutdownTimeout bounds the shutdown run by Fatal before the process exits.
+const defaultShutdownTimeout = 5 * time.Second
+
+// errShutdownUnsupported is returned when shutdown targets a logger not created by NewLogger.
+var errShutdownUnsupported = errors.New("logger does not support shutdown hooks: it was not created by NewLogger")
+
+type (
+	// ShutdownHook is a cleanup function run when a logger is closed.
+	// It must return once the context is done.
+	ShutdownHook func(context.Context) error
+
+	// shutdown runs the hooks of a logger, syncs it and closes its sinks exactly once.
+	shutdown struct {
+		mu     sync.Mutex
+		hooks  []ShutdownHook
+		closed bool
+
+		once sync.Once
+		err  error
+
+		// timeout bounds the shutdown run by Fatal.
+		timeout time.Duration
+		// sync flushes the logger; set by NewLogger once the logger is built.
+		sync func() error
+		// closeSinks closes the output sinks.
+		closeSinks func()
+	}
+
+	// fatalHook writes the crash report and runs the shutdown before exiting the process
+	// after a fatal entry.
+	fatalHook struct {
+		shutdown *shutdown
+		// crash writes the crash report; nil without WithCrashReport.
+		crash *crashReport
+	}
+)
+
+// newShutdown creates the shutdown of a logger closing the sinks last.
+func newShutdown(timeout time.Duration, closeSinks func()) *shutdown {
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	return &shutdown{timeout: timeout, closeSinks: closeSinks}
+}
+
+// OnShutdown registers a hook run when the logger is closed with Close or exits
+// through Fatal. Sinks, spools and application code use it to flush and release
+// resources in order with the logger. Hooks run in reverse order of registration,
+// like deferred calls, before the logger is synced and its sinks are closed, so
+// hooks can still log. Hooks registered after the logger was closed are not run.
+//
+// Parameters:
+//   - log: The logger the hook belongs to, shared with all its children
+//   - hook: The cleanup function
+//
+// Returns:
+//   - error: An error if the logger was not created by NewLogger
+//
+// Example:
+//
+//	logger.OnShutdown(log, func(ctx context.Context) error {
+//	    return producer.Flush(ctx)
+//	})
+func OnShutdown(log Logger, hook ShutdownHook) error {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return errShutdownUnsupported
+	}
+
+	s := z.state.shutdown
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.hooks = append(s.hooks, hook)
+	}
+	return nil
+}
+
+// Close shuts the logger down: it runs the shutdown hooks in reverse order of
+// registration, syncs the logger and closes its sinks. Hooks still running when the
+// context is done are abandoned and the remaining hooks are skipped, so the deadline
+// of ctx bounds the shutdown. The sinks are always synced and closed. Closing a
+// logger closes all its children; later calls return the result of the first.
+//
+// Parameters:
+//   - ctx: The context bounding the shutdown
+//   - log: The logger to close
+//
+// Returns:
+//   - error: The joined errors of the hooks, the sync and the deadline, if any
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	if err := logger.Close(ctx, log); err != nil {
+//	    fmt.Fprintln(os.Stderr, "logger shutdown:", err)
+//	}
+func Close(ctx context.Context, log Logger) error {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return errShutdownUnsupported
+	}
+	return z.state.shutdown.run(ctx)
+}
+
+// run performs the shutdown once.
+func (s *shutdown) run(ctx context.Context) error {
+	s.once.Do(func() {
+		s.mu.Lock()
+		hooks := s.hooks
+		s.hooks, s.closed = nil, true
+		s.mu.Unlock()
+
+		var errs []error
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := ctx.Err(); err != nil {
+				errs = append(errs, fmt.Errorf("%d shutdown hooks skipped: %w", i+1, err))
+				break
+			}
+			if err := runHook(ctx, hooks[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if s.sync != nil {
+			if err := s.sync(); err != nil && !isIgnorableSyncError(err) {
+				errs = append(errs, fmt.Errorf("failed to sync logger: %w", err))
+			}
+		}
+		if s.closeSinks != nil {
+			s.closeSinks()
+		}
+		s.err = errors.Join(errs...)
+	})
+	return s.err
+}
+
+// runHook runs a hook and waits until it returns or the context is done.
+// Panics of the hook are returned as errors.
+func runHook(ctx context.Context, hook ShutdownHook) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("shutdown hook: %w", panicError(r))
+			}
+		}()
+		done <- hook(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("shutdown hook abandoned: %w", ctx.Err())
+	}
+}
+
+// isIgnorableSyncError reports whether a sync error only means that the sink
+// cannot be synced, as for terminals and pipes.
+func isIgnorableSyncError(err error) bool {
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY) || errors.Is(err, syscall.ENOTSUP)
+}
+
+// OnWrite writes the crash report, runs the shutdown, bounded by the shutdown timeout,
+// and exits the process.
+func (h fatalHook) OnWrite(ce *zapcore.CheckedEntry, _ []Field) {
+	if h.crash != nil {
+		h.crash.write(CrashReasonFatal, ce.Time, ce.Message, crashStack(ce.Entry))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), h.shutdown.timeout)
+	_ = h.shutdown.run(ctx)
+	cancel()
+	os.Exit(1)
+}
@@ -0,0 +1,292 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// openSinks opens every output path and combines them into a single WriteSyncer.
+// Standard streams and local files are opened by this package so that file
+// permissions, ownership and directory creation follow the logger configuration.
+// Any other URL scheme is delegated to zap's sink registry.
+//
+// Parameters:
+//   - paths: The output destinations (stdout, stderr, file paths or URLs)
+//   - cfg: The logger configuration holding the file sink settings
+//
+// Returns:
+//   - zapcore.WriteSyncer: The combined, concurrency-safe writer
+//   - func(): A function closing every opened sink
+//   - error: An error if any of the sinks cannot be opened
+func openSinks(paths []string, cfg *config) (zapcore.WriteSyncer, func(), error) {
+	writers, closeAll, err := openSinkList(paths, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return zap.CombineWriteSyncers(writers...), closeAll, nil
+}
+
+// openSinkList opens every output path like openSinks but returns the writers
+// separately, in the order of the paths.
+func openSinkList(paths []string, cfg *config) ([]zapcore.WriteSyncer, func(), error) {
+	var (
+		writers = make([]zapcore.WriteSyncer, 0, len(paths))
+		closers = make([]func() error, 0, len(paths))
+	)
+
+	closeAll := func() {
+		for _, c := range closers {
+			_ = c()
+		}
+	}
+
+	for _, path := range paths {
+		ws, closeFn, err := openSink(path, cfg)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("failed to open log sink %q: %w", path, err)
+		}
+		writers = append(writers, ws)
+		closers = append(closers, closeFn)
+	}
+
+	return writers, closeAll, nil
+}
+
+// openSink opens a single output path, or shares it when the pool of the logger
+// already holds it with the same file settings.
+func openSink(path string, cfg *config) (zapcore.WriteSyncer, func() error, error) {
+	return cfg.SinkPool.open(newSinkKey(path, cfg), func() (zapcore.WriteSyncer, func() error, error) {
+		return openUnpooledSink(path, cfg)
+	})
+}
+
+// openUnpooledSink opens a single output path.
+func openUnpooledSink(path string, cfg *config) (zapcore.WriteSyncer, func() error, error) {
+	nop := func() error { return nil }
+
+	switch path {
+	case "stdout":
+		return consoleSink{os.Stdout}, nop, nil
+	case "stderr":
+		return consoleSink{os.Stderr}, nop, nil
+	}
+
+	filePath, ok, err := localFilePath(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !ok {
+		ws, closeFn, err := zap.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ws, func() error { closeFn(); return nil }, nil
+	}
+
+	f, err := openLogFile(filePath, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.SharedFileWrites {
+		if cfg.FileEncryption != nil {
+			_ = f.Close()
+			return nil, nil, errors.New("shared file writes cannot be combined with file encryption")
+		}
+		sf := newSharedFile(f, cfg.MaxRecordSize)
+		return sf, f.Close, nil
+	}
+
+	if cfg.FileEncryption != nil {
+		ef, err := newEncryptedFile(f, cfg.FileEncryption)
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, err
+		}
+		return ef, ef.Close, nil
+	}
+
+	return f, f.Close, nil
+}
+
+// localFilePath reports whether the output path refers to a local file and
+// returns its filesystem path. Both plain paths and file:// URLs are supported.
+func localFilePath(path string) (string, bool, error) {
+	if filepath.IsAbs(path) {
+		return path, true, nil
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return "", false, fmt.Errorf("can't parse %q as a URL: %w", path, err)
+	}
+
+	switch u.Scheme {
+	case "":
+		return path, true, nil
+	case "file":
+		if u.Host != "" && u.Host != "localhost" {
+			return "", false, errors.New("file URLs must leave host empty or use localhost")
+		}
+		return u.Path, true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+// openLogFile opens a log file for appending using the configured permissions.
+// Files are always opened with O_APPEND and written without user-space buffering,
+// so every log entry reaches the kernel as a single write. This keeps entries from
+// several processes sharing the same file from overwriting each other. The mode and
+// owner are only applied to files the call creates; existing files, e.g. managed by
+// an operator, and devices such as /dev/null keep theirs.
+//
+// Parameters:
+//   - path: The filesystem path of the log file
+//   - cfg: The logger configuration holding the file sink settings
+//
+// Returns:
+//   - *os.File: The opened file
+//   - error: An error if the directory or file cannot be created
+func openLogFile(path string, cfg *config) (*os.File, error) {
+	if cfg.CreateDirs {
+		if err := os.MkdirAll(filepath.Dir(path), cfg.DirMode); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, cfg.FileMode)
+	if errors.Is(err, fs.ErrExist) {
+		// The file exists, or was created by another process in the meantime.
+		return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, cfg.FileMode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The mode passed to OpenFile is subject to the umask, so enforce it explicitly.
+	if err := f.Chmod(cfg.FileMode); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	if cfg.FileUID >= 0 || cfg.FileGID >= 0 {
+		if err := f.Chown(cfg.FileUID, cfg.FileGID); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+// defaultMaxRecordSize is the record size limit of shared files when none is configured.
+const defaultMaxRecordSize = 64 * 1024
+
+// truncatedMarker ends records cut to the record size limit of shared files.
+const truncatedMarker = " [truncated]\n"
+
+// sharedFile writes records to a file shared by several processes.
+// The file is opened with O_APPEND, so the kernel positions every write at the end
+// of the file atomically. Each record is handed to the kernel in exactly one write
+// call and records longer than the limit are cut, so a record can neither be split
+// into several writes nor exceed what the filesystem writes in one piece. Lines of
+// different processes therefore never interleave.
+type sharedFile struct {
+	file *os.File
+	max  int
+}
+
+// newSharedFile wraps the file, using defaultMaxRecordSize when max is not positive.
+func newSharedFile(f *os.File, max int) *sharedFile {
+	if max <= 0 {
+		max = defaultMaxRecordSize
+	}
+	if max <= len(truncatedMarker) {
+		max = len(truncatedMarker) + 1
+	}
+	return &sharedFile{file: f, max: max}
+}
+
+// Write writes the record in a single write call, cut to the record size limit.
+// Writes to regular files are only short on errors such as a full disk, in which
+// case the error is returned and the remainder is dropped rather than written in
+// a second call that could interleave with other processes.
+func (s *sharedFile) Write(p []byte) (int, error) {
+	record := p
+	if len(record) > s.max {
+		cut := s.max - len(truncatedMarker)
+		for cut > 0 && !utf8.RuneStart(p[cut]) {
+			cut--
+		}
+		record = make([]byte, 0, cut+len(truncatedMarker))
+		record = append(record, p[:cut]...)
+		record = append(record, truncatedMarker...)
+	}
+
+	if _, err := s.file.Write(record); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync flushes the file to stable storage.
+func (s *sharedFile) Sync() error {
+	return s.file.Sync()
+}
+
+// consoleSink writes to stdout or stderr. Terminals and pipes cannot be synced, so their
+// sync errors are ignored and only redirections to regular files are flushed.
+type consoleSink struct {
+	*os.File
+}
+
+// Sync flushes the file when it can be synced.
+func (s consoleSink) Sync() error {
+	if err := s.File.Sync(); err != nil && !isIgnorableSyncError(err) {
+		return err
+	}
+	return nil
+}
+
+// syncCore wraps a zapcore.Core and flushes it to stable storage after every
+// entry at or above the configured level.
+type syncCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+// With returns a child core that keeps the sync policy.
+func (c *syncCore) With(fields []Field) zapcore.Core {
+	return &syncCore{Core: c.Core.With(fields), level: c.level}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *syncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry and syncs the underlying sinks for high severity entries.
+func (c *syncCore) Write(ent zapcore.Entry, fields []Field) error {
+	if err := c.Core.Write(ent, fields); err != nil {
+		return err
+	}
+
+	if ent.Level >= c.level {
+		return c.Core.Sync()
+	}
+	return nil
+}
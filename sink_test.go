@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+)
+
+// TestFsyncOnErrorIgnoresPipedStdout logs an error to a piped stdout with
+// WithFsyncOnError and checks that the failed sync of the pipe is not reported.
+func TestFsyncOnErrorIgnoresPipedStdout(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		_, _ = io.Copy(io.Discard, r)
+	}()
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	log, err := NewLogger(
+		WithOutputPaths([]string{"stdout"}),
+		WithErrorOutputPaths([]string{"stdout"}),
+		WithFsyncOnError(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = stdout
+
+	ctx := context.Background()
+	log.Error(ctx, "piped error")
+	if err := Close(ctx, log); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+	<-drained
+
+	if stats := GetStats(log); stats.SinkWriteErrors > 0 || stats.SinkSyncErrors > 0 {
+		t.Errorf("got %d write and %d sync errors, want none", stats.SinkWriteErrors, stats.SinkSyncErrors)
+	}
+}
@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"os"
+	"reflect"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// sinkPool shares the sinks opened by a logger with the loggers derived from it, so
+	// every path is opened, and every batch buffered, once however many loggers write to
+	// it. Shared sinks are closed when the last logger holding them closes.
+	sinkPool struct {
+		mu    sync.Mutex
+		sinks map[sinkKey]*pooledSink
+	}
+
+	// sinkKey identifies a sink of a pool: its output path, whether it is the batch
+	// writer of the path, and for files the settings they are opened with, so loggers
+	// with different file settings never share a file sink.
+	sinkKey struct {
+		path  string
+		batch bool
+		file  fileSettings
+	}
+
+	// fileSettings are the settings a file sink is opened with.
+	fileSettings struct {
+		mode          os.FileMode
+		uid, gid      int
+		shared        bool
+		maxRecordSize int
+		// encryption is the key wrapper, or a pointer to it when its type is not
+		// comparable, so that such wrappers never share a sink.
+		encryption any
+	}
+
+	// pooledSink is a sink of a pool and the number of loggers holding it.
+	pooledSink struct {
+		ws    zapcore.WriteSyncer
+		close func() error
+		refs  int
+	}
+)
+
+// newSinkPool creates an empty sink pool.
+func newSinkPool() *sinkPool {
+	return &sinkPool{sinks: make(map[sinkKey]*pooledSink)}
+}
+
+// newSinkKey returns the key of the sink of an output path. The file settings of the
+// configuration are part of the key of local files and ignored for other sinks.
+func newSinkKey(path string, cfg *config) sinkKey {
+	key := sinkKey{path: path}
+	if _, ok, _ := localFilePath(path); !ok {
+		return key
+	}
+	key.file = fileSettings{
+		mode:          cfg.FileMode,
+		uid:           cfg.FileUID,
+		gid:           cfg.FileGID,
+		shared:        cfg.SharedFileWrites,
+		maxRecordSize: cfg.MaxRecordSize,
+	}
+	if cfg.FileEncryption != nil {
+		key.file.encryption = cfg.FileEncryption
+		if !reflect.TypeOf(cfg.FileEncryption).Comparable() {
+			key.file.encryption = &cfg.FileEncryption
+		}
+	}
+	return key
+}
+
+// open returns the sink held under key, opening it with open the first time. The
+// returned close function releases the sink; the last release closes it. A nil pool
+// opens a sink every time.
+//
+// Parameters:
+//   - key: The key of the sink, see newSinkKey
+//   - open: The function opening the sink
+//
+// Returns:
+//   - zapcore.WriteSyncer: The shared sink
+//   - func() error: The function releasing the sink
+//   - error: The error of open
+func (p *sinkPool) open(key sinkKey, open func() (zapcore.WriteSyncer, func() error, error)) (zapcore.WriteSyncer, func() error, error) {
+	if p == nil {
+		return open()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sink, ok := p.sinks[key]
+	if !ok {
+		ws, closeFn, err := open()
+		if err != nil {
+			return nil, nil, err
+		}
+		sink = &pooledSink{ws: ws, close: closeFn}
+		p.sinks[key] = sink
+	}
+	sink.refs++
+
+	var once sync.Once
+	release := func() error {
+		var err error
+		once.Do(func() { err = p.release(key, sink) })
+		return err
+	}
+	return sink.ws, release, nil
+}
+
+// release drops a reference to the sink and closes it once no logger holds it.
+func (p *sinkPool) release(key sinkKey, sink *pooledSink) error {
+	p.mu.Lock()
+	sink.refs--
+	last := sink.refs == 0
+	if last && p.sinks[key] == sink {
+		delete(p.sinks, key)
+	}
+	p.mu.Unlock()
+
+	if !last {
+		return nil
+	}
+	return sink.close()
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestDerivedLoggerDoesNotShareFileWithOtherSettings derives an encrypting logger
+// writing to the file of its parent and checks that its entries are encrypted instead of
+// going through the plaintext sink of the parent.
+func TestDerivedLoggerDoesNotShareFileWithOtherSettings(t *testing.T) {
+	wrapper, err := NewAESKeyWrapper(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "app.log")
+	parent, err := NewLogger(WithOutputPaths([]string{path}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := parent.WithOptions(WithFileEncryption(wrapper))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	parent.Info(ctx, "plain entry")
+	child.Info(ctx, "secret entry")
+	for _, log := range []Logger{child, parent} {
+		if err := Close(ctx, log); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out := string(b); !strings.Contains(out, "plain entry") || strings.Contains(out, "secret entry") {
+		t.Errorf("file holds %q, want the plain entry and the secret entry encrypted", out)
+	}
+}
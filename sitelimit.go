@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// maxCallSites bounds the number of call sites rate limited separately.
+// Entries of call sites beyond the bound are written without rate limiting.
+const maxCallSites = 1024
+
+type (
+	// siteLimitCore wraps a zapcore.Core and drops the entries of a call site exceeding
+	// its token bucket budget.
+	siteLimitCore struct {
+		zapcore.Core
+		limiter *siteLimiter
+	}
+
+	// siteLimiter holds a token bucket and a drop counter per call site.
+	siteLimiter struct {
+		rate  float64
+		burst int
+
+		mu    sync.RWMutex
+		sites map[string]*siteBudget
+	}
+
+	// siteBudget is the token bucket and drop counter of a call site.
+	siteBudget struct {
+		bucket  *tokenBucket
+		dropped atomic.Uint64
+	}
+)
+
+// newSiteLimitCore wraps core with rate limiting per call site. It returns core unchanged
+// and a nil limiter when rate is zero or less.
+func newSiteLimitCore(core zapcore.Core, rate float64, burst int) (zapcore.Core, *siteLimiter) {
+	if rate <= 0 {
+		return core, nil
+	}
+	limiter := &siteLimiter{rate: rate, burst: burst, sites: make(map[string]*siteBudget)}
+	return &siteLimitCore{Core: core, limiter: limiter}, limiter
+}
+
+// With returns a child core sharing the call site budgets.
+func (c *siteLimitCore) With(fields []Field) zapcore.Core {
+	return &siteLimitCore{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+// lowered returns a copy of the core sharing the budgets of the call sites.
+func (c *siteLimitCore) lowered() zapcore.Core {
+	return &siteLimitCore{Core: lowerCore(c.Core), limiter: c.limiter}
+}
+
+// Check adds the core to the checked entry when the level is enabled. The call site is
+// only known when the entry is written, so the budget is applied by Write.
+func (c *siteLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry when the budget of its call site allows it and counts it as
+// dropped otherwise. Panic and fatal entries and entries without a call site are
+// always written.
+func (c *siteLimitCore) Write(ent zapcore.Entry, fields []Field) error {
+	if !ent.Caller.Defined || ent.Level >= zapcore.DPanicLevel {
+		return c.Core.Write(ent, fields)
+	}
+	if budget := c.limiter.budget(ent.Caller); budget != nil && !budget.bucket.Allow() {
+		budget.dropped.Add(1)
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// budget returns the budget of the call site, keyed by package/file.go:line as in the
+// caller field, creating it on first use, or nil when maxCallSites are already tracked.
+// Known call sites are looked up under the read lock, so concurrent entries do not
+// serialize.
+func (l *siteLimiter) budget(caller zapcore.EntryCaller) *siteBudget {
+	site := caller.TrimmedPath()
+
+	l.mu.RLock()
+	budget, ok := l.sites[site]
+	l.mu.RUnlock()
+	if ok {
+		return budget
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	budget, ok = l.sites[site]
+	if !ok {
+		if len(l.sites) >= maxCallSites {
+			return nil
+		}
+		budget = &siteBudget{bucket: newTokenBucket(l.rate, l.burst)}
+		l.sites[site] = budget
+	}
+	return budget
+}
+
+// dropped returns the number of dropped entries of every call site with drops, nil when
+// none were dropped or rate limiting is disabled.
+func (l *siteLimiter) dropped() map[string]uint64 {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var dropped map[string]uint64
+	for site, budget := range l.sites {
+		if n := budget.dropped.Load(); n > 0 {
+			if dropped == nil {
+				dropped = make(map[string]uint64)
+			}
+			dropped[site] = n
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,266 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Field keys written to slow query entries.
+const (
+	FieldKeySQLQuery        = "sql_query"
+	FieldKeySQLArgs         = "sql_args"
+	FieldKeySQLRowsAffected = "sql_rows_affected"
+	FieldKeySQLExplain      = "sql_explain"
+	FieldKeySQLExplainError = "sql_explain_error"
+)
+
+// FieldKeyEvent is the field naming the event an entry records.
+const FieldKeyEvent = "event"
+
+// SlowQueryEvent is the event of slow query entries, written as the event field.
+const SlowQueryEvent = "slow_query"
+
+const (
+	// defaultSlowQueryThreshold is the duration from which queries are logged as slow
+	// when none is configured.
+	defaultSlowQueryThreshold = 200 * time.Millisecond
+	// defaultExplainTimeout bounds the capture of a query plan when no timeout is configured.
+	defaultExplainTimeout = 5 * time.Second
+	// slowQueryMessage is the message of slow query entries.
+	slowQueryMessage = "slow query"
+)
+
+type (
+	// SQLParam is a bind parameter of a query.
+	SQLParam struct {
+		// Ordinal is the position of the parameter, starting at 1.
+		Ordinal int
+		// Name is the name of a named parameter, e.g. set with sql.Named; empty for
+		// positional parameters.
+		Name string
+		// Value is the value bound to the parameter.
+		Value any
+	}
+
+	// ParamRedactor reports whether a bind parameter of a query is written as
+	// [REDACTED] in slow query entries. See RedactAllParams, RedactParamNames and
+	// RedactParamValues.
+	ParamRedactor func(query string, param SQLParam) bool
+
+	// ExplainFunc captures the plan of a slow query, e.g. by running EXPLAIN on it with
+	// the same arguments; see NewSQLExplain. Queries run by the function are not
+	// checked for slowness themselves.
+	ExplainFunc func(ctx context.Context, query string, args []any) (string, error)
+
+	// SlowQueryConfig configures the slow query log of a SlowQueryLogger.
+	SlowQueryConfig struct {
+		// Threshold is the duration from which a query is logged as slow (default: 200ms).
+		Threshold time.Duration
+		// Level is the level of slow query entries (default: LevelWarning).
+		Level Level
+		// Explain captures the plan of slow queries, written as sql_explain; nil
+		// disables it. It runs before the entry is written, delaying the caller.
+		Explain ExplainFunc
+		// ExplainTimeout bounds Explain (default: 5s).
+		ExplainTimeout time.Duration
+		// Redact are the rules redacting bind parameters; a parameter matching any rule
+		// is written as [REDACTED]. Explain still receives the actual values.
+		Redact []ParamRedactor
+	}
+
+	// SlowQueryLogger writes a slow_query event for every query taking at least the
+	// threshold, with the query, its redacted bind parameters and optionally its plan.
+	// It is used by the database/sql driver of NewSQLConnector and NewSQLDriver and
+	// the GORM logger of the gormlogger package, and can observe queries of any
+	// other database client. A SlowQueryLogger is safe for concurrent use.
+	SlowQueryLogger struct {
+		log    Logger
+		config SlowQueryConfig
+	}
+
+	// explainKey marks the context of queries run by an ExplainFunc.
+	explainKey struct{}
+)
+
+// NewSlowQueryLogger creates a slow query log writing to log.
+//
+// Parameters:
+//   - log: The logger receiving the slow_query events
+//   - config: The threshold, level, plan capture and bind parameter redaction
+//
+// Returns:
+//   - *SlowQueryLogger: The slow query log
+//
+// Example:
+//
+//	slow := logger.NewSlowQueryLogger(log, logger.SlowQueryConfig{
+//	    Threshold: 500 * time.Millisecond,
+//	    Redact:    []logger.ParamRedactor{logger.RedactParamNames("password", "email")},
+//	})
+//	// {"level":"WARN","message":"slow query","event":"slow_query","sql_query":"SELECT * FROM users WHERE email = @email","sql_args":["[REDACTED]"],"duration":"812ms"}
+func NewSlowQueryLogger(log Logger, config SlowQueryConfig) *SlowQueryLogger {
+	if config.Threshold <= 0 {
+		config.Threshold = defaultSlowQueryThreshold
+	}
+	if config.Level == "" {
+		config.Level = LevelWarning
+	}
+	if config.ExplainTimeout <= 0 {
+		config.ExplainTimeout = defaultExplainTimeout
+	}
+	config.Redact = slices.Clone(config.Redact)
+	return &SlowQueryLogger{log: log, config: config}
+}
+
+// Threshold returns the duration from which queries are logged as slow.
+//
+// Returns:
+//   - time.Duration: The threshold
+func (s *SlowQueryLogger) Threshold() time.Duration {
+	return s.config.Threshold
+}
+
+// Observe logs the query as a slow_query event when it took at least the threshold
+// since begin. Queries run by the ExplainFunc are never logged.
+//
+// Parameters:
+//   - ctx: The context of the query, providing the context fields of the entry
+//   - query: The query, with placeholders for the bind parameters
+//   - params: The bind parameters
+//   - begin: The time the query started
+//   - rowsAffected: The number of rows affected, or -1 when unknown
+//   - err: The error of the query, if any
+//   - fields: Further fields of the entry
+//
+// Returns:
+//   - bool: Whether the query was slow and logged
+//
+// Example:
+//
+//	begin := time.Now()
+//	res, err := client.Exec(ctx, query, id)
+//	slow.Observe(ctx, query, []logger.SQLParam{{Ordinal: 1, Value: id}}, begin, -1, err)
+func (s *SlowQueryLogger) Observe(ctx context.Context, query string, params []SQLParam, begin time.Time, rowsAffected int64, err error, fields ...Field) bool {
+	elapsed := time.Since(begin)
+	if elapsed < s.config.Threshold || ctx.Value(explainKey{}) != nil {
+		return false
+	}
+
+	fields = append([]Field{
+		zap.String(FieldKeyEvent, SlowQueryEvent),
+		zap.String(FieldKeySQLQuery, query),
+		zap.Duration(FieldKeyDuration, elapsed),
+	}, fields...)
+	if len(params) > 0 {
+		fields = append(fields, zap.Array(FieldKeySQLArgs, sqlArgs(s.redactParams(query, params))))
+	}
+	if rowsAffected >= 0 {
+		fields = append(fields, zap.Int64(FieldKeySQLRowsAffected, rowsAffected))
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	if s.config.Explain != nil {
+		plan, explainErr := s.explain(ctx, query, params)
+		if explainErr != nil {
+			fields = append(fields, zap.NamedError(FieldKeySQLExplainError, explainErr))
+		} else {
+			fields = append(fields, zap.String(FieldKeySQLExplain, plan))
+		}
+	}
+	logAt(ctx, s.log, s.config.Level, slowQueryMessage, fields...)
+	return true
+}
+
+// RedactParams returns the values of the bind parameters with the redacted ones
+// replaced by [REDACTED], e.g. for a database client that interpolates them.
+//
+// Parameters:
+//   - query: The query, with placeholders for the bind parameters
+//   - params: The bind parameters
+//
+// Returns:
+//   - []any: The values in the order of params
+func (s *SlowQueryLogger) RedactParams(query string, params []SQLParam) []any {
+	return s.redactParams(query, params)
+}
+
+// redactParams returns the values of the parameters, redacted by the rules.
+func (s *SlowQueryLogger) redactParams(query string, params []SQLParam) []any {
+	values := make([]any, len(params))
+	for i, param := range params {
+		values[i] = param.Value
+		for _, redact := range s.config.Redact {
+			if redact(query, param) {
+				values[i] = redactedValue
+				break
+			}
+		}
+	}
+	return values
+}
+
+// explain captures the plan of the query, bounded by the explain timeout.
+func (s *SlowQueryLogger) explain(ctx context.Context, query string, params []SQLParam) (string, error) {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.WithoutCancel(ctx), explainKey{}, true), s.config.ExplainTimeout)
+	defer cancel()
+
+	args := make([]any, len(params))
+	for i, param := range params {
+		args[i] = param.Value
+		if param.Name != "" {
+			args[i] = namedArg(param.Name, param.Value)
+		}
+	}
+	return s.config.Explain(ctx, query, args)
+}
+
+// RedactAllParams returns a rule redacting every bind parameter, so slow query entries
+// only show the shape of the query.
+//
+// Returns:
+//   - ParamRedactor: The rule
+func RedactAllParams() ParamRedactor {
+	return func(string, SQLParam) bool {
+		return true
+	}
+}
+
+// RedactParamNames returns a rule redacting the named parameters with one of the
+// names, compared case-insensitively.
+//
+// Parameters:
+//   - names: The names of the parameters, e.g. "password"
+//
+// Returns:
+//   - ParamRedactor: The rule
+func RedactParamNames(names ...string) ParamRedactor {
+	return func(_ string, param SQLParam) bool {
+		return param.Name != "" && slices.ContainsFunc(names, func(name string) bool {
+			return strings.EqualFold(name, param.Name)
+		})
+	}
+}
+
+// RedactParamValues returns a rule redacting the parameters whose value, formatted
+// with %v, matches the pattern, e.g. card numbers or e-mail addresses.
+//
+// Parameters:
+//   - pattern: The pattern of the redacted values
+//
+// Returns:
+//   - ParamRedactor: The rule
+func RedactParamValues(pattern *regexp.Regexp) ParamRedactor {
+	return func(_ string, param SQLParam) bool {
+		if b, ok := param.Value.([]byte); ok {
+			return pattern.Match(b)
+		}
+		return pattern.MatchString(fmt.Sprint(param.Value))
+	}
+}
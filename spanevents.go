@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap/zapcore"
+)
+
+// Names of the span events and attributes written by WithSpanEvents.
+const (
+	spanEventName          = "log"
+	spanAttributeSeverity  = "log.severity"
+	spanAttributeMessage   = "log.message"
+	newRelicWarningMessage = "log.warning.message"
+)
+
+// mirrorToSpan records a warning or error entry on the OpenTelemetry span and the New
+// Relic transaction of the context. Errors are recorded with RecordError when the
+// fields carry an error, and as a log event otherwise; New Relic receives errors as
+// noticed errors and the message of the last warning as a transaction attribute.
+func mirrorToSpan(ctx context.Context, level zapcore.Level, msg string, fields []Field) {
+	if ctx == nil {
+		return
+	}
+
+	values, err := spanFieldValues(fields)
+
+	if span := trace.SpanFromContext(ctx); span.IsRecording() {
+		attrs := make([]attribute.KeyValue, 0, len(values)+2)
+		attrs = append(attrs,
+			attribute.String(spanAttributeSeverity, level.String()),
+			attribute.String(spanAttributeMessage, msg),
+		)
+		attrs = append(attrs, spanAttributes(values)...)
+		if level >= zapcore.ErrorLevel && err != nil {
+			span.RecordError(err, trace.WithAttributes(attrs...))
+		} else {
+			span.AddEvent(spanEventName, trace.WithAttributes(attrs...))
+		}
+	}
+
+	if txn := newrelic.FromContext(ctx); txn != nil {
+		if level < zapcore.ErrorLevel {
+			txn.AddAttribute(newRelicWarningMessage, msg)
+			return
+		}
+		attrs := make(map[string]any, len(values))
+		for k, v := range values {
+			attrs[k] = primitiveValue(v)
+		}
+		noticed := newrelic.Error{Message: msg, Class: "log", Attributes: attrs}
+		if err != nil {
+			noticed.Message = msg + ": " + err.Error()
+			noticed.Class = fmt.Sprintf("%T", err)
+		}
+		txn.NoticeError(noticed)
+	}
+}
+
+// spanFieldValues encodes the fields into a map of values and returns the first error
+// among them.
+func spanFieldValues(fields []Field) (map[string]any, error) {
+	enc := zapcore.NewMapObjectEncoder()
+	var firstErr error
+	for _, f := range fields {
+		if f.Type == zapcore.ErrorType && firstErr == nil {
+			firstErr, _ = f.Interface.(error)
+		}
+		f.AddTo(enc)
+	}
+	return enc.Fields, firstErr
+}
+
+// spanAttributes converts encoded field values into span attributes sorted by key.
+func spanAttributes(values map[string]any) []attribute.KeyValue {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]attribute.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		switch v := primitiveValue(values[k]).(type) {
+		case bool:
+			attrs = append(attrs, attribute.Bool(k, v))
+		case int64:
+			attrs = append(attrs, attribute.Int64(k, v))
+		case float64:
+			attrs = append(attrs, attribute.Float64(k, v))
+		default:
+			attrs = append(attrs, attribute.String(k, v.(string)))
+		}
+	}
+	return attrs
+}
+
+// primitiveValue returns an encoded field value as a string, bool, int64 or float64.
+// Other values are formatted with fmt.
+func primitiveValue(v any) any {
+	switch v := v.(type) {
+	case string, bool, int64, float64:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
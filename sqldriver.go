@@ -0,0 +1,448 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type (
+	// sqlDriver wraps a database/sql driver and logs its slow queries.
+	sqlDriver struct {
+		driver.Driver
+		slow *SlowQueryLogger
+	}
+
+	// sqlConnector wraps a connector and logs the slow queries of its connections.
+	sqlConnector struct {
+		connector driver.Connector
+		driver    *sqlDriver
+	}
+
+	// dsnConnector opens connections of a driver not implementing driver.DriverContext.
+	dsnConnector struct {
+		name   string
+		driver driver.Driver
+	}
+
+	// sqlConn wraps a connection and logs its slow queries.
+	sqlConn struct {
+		conn driver.Conn
+		slow *SlowQueryLogger
+	}
+
+	// sqlStmt wraps a prepared statement and logs its slow executions.
+	sqlStmt struct {
+		stmt  driver.Stmt
+		conn  *sqlConn
+		query string
+	}
+
+	// sqlArgs writes the bind parameters of a slow query as an array.
+	sqlArgs []any
+)
+
+// NewSQLConnector wraps a database/sql connector so every query of the database is
+// checked by the slow query log, see NewSlowQueryLogger. Queries and statements run
+// through the database, a connection or a transaction are timed until the driver
+// returns their result; the rows of a query are not included.
+//
+// Parameters:
+//   - connector: The connector of the driver, e.g. from pq.NewConnector or mysql.NewConnector
+//   - slow: The slow query log
+//
+// Returns:
+//   - driver.Connector: The connector to open the database with sql.OpenDB; it closes
+//     the wrapped connector when the database is closed
+//
+// Example:
+//
+//	connector, _ := pq.NewConnector(dsn)
+//	slow := logger.NewSlowQueryLogger(log, logger.SlowQueryConfig{Threshold: 500 * time.Millisecond})
+//	db := sql.OpenDB(logger.NewSQLConnector(connector, slow))
+func NewSQLConnector(connector driver.Connector, slow *SlowQueryLogger) driver.Connector {
+	return &sqlConnector{connector: connector, driver: &sqlDriver{Driver: connector.Driver(), slow: slow}}
+}
+
+// NewSQLDriver wraps a database/sql driver so every query of the databases it opens
+// is checked by the slow query log, like NewSQLConnector. Register the wrapped driver
+// under a name of its own.
+//
+// Parameters:
+//   - drv: The driver
+//   - slow: The slow query log
+//
+// Returns:
+//   - driver.Driver: The driver to register with sql.Register
+//
+// Example:
+//
+//	sql.Register("postgres-slowlog", logger.NewSQLDriver(&pq.Driver{}, slow))
+//	db, err := sql.Open("postgres-slowlog", dsn)
+func NewSQLDriver(drv driver.Driver, slow *SlowQueryLogger) driver.Driver {
+	return &sqlDriver{Driver: drv, slow: slow}
+}
+
+// NewSQLExplain returns an ExplainFunc capturing the plan of slow queries by running
+// them on the database with the prefix, one line per row of the result with the
+// columns separated by tabs. The queries are run with the arguments of the slow
+// query; make sure the prefix only explains the query rather than running it, e.g.
+// use EXPLAIN rather than EXPLAIN ANALYZE for statements changing data.
+//
+// Parameters:
+//   - db: Returns the database the slow queries run on; a function, since the slow
+//     query log is created before the database it observes
+//   - prefix: The statement explaining a query, e.g. "EXPLAIN " or "EXPLAIN QUERY PLAN "
+//
+// Returns:
+//   - ExplainFunc: The function capturing the plan
+//
+// Example:
+//
+//	var db *sql.DB
+//	slow := logger.NewSlowQueryLogger(log, logger.SlowQueryConfig{
+//	    Explain: logger.NewSQLExplain(func() *sql.DB { return db }, "EXPLAIN "),
+//	})
+//	db = sql.OpenDB(logger.NewSQLConnector(connector, slow))
+func NewSQLExplain(db func() *sql.DB, prefix string) ExplainFunc {
+	return func(ctx context.Context, query string, args []any) (string, error) {
+		rows, err := db().QueryContext(ctx, prefix+query, args...)
+		if err != nil {
+			return "", err
+		}
+		defer rows.Close()
+
+		columns, err := rows.Columns()
+		if err != nil {
+			return "", err
+		}
+		var (
+			plan   strings.Builder
+			values = make([]sql.NullString, len(columns))
+			dest   = make([]any, len(columns))
+		)
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(dest...); err != nil {
+				return "", err
+			}
+			for i, v := range values {
+				if i > 0 {
+					plan.WriteByte('\t')
+				}
+				plan.WriteString(v.String)
+			}
+			plan.WriteByte('\n')
+		}
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(plan.String(), "\n"), nil
+	}
+}
+
+// Open opens a connection logging its slow queries.
+func (d *sqlDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{conn: conn, slow: d.slow}, nil
+}
+
+// OpenConnector returns a connector opening connections logging their slow queries.
+func (d *sqlDriver) OpenConnector(name string) (driver.Connector, error) {
+	var connector driver.Connector = &dsnConnector{name: name, driver: d.Driver}
+	if dc, ok := d.Driver.(driver.DriverContext); ok {
+		var err error
+		if connector, err = dc.OpenConnector(name); err != nil {
+			return nil, err
+		}
+	}
+	return &sqlConnector{connector: connector, driver: d}, nil
+}
+
+// Connect opens a connection logging its slow queries.
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{conn: conn, slow: c.driver.slow}, nil
+}
+
+// Driver returns the wrapped driver.
+func (c *sqlConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Close closes the wrapped connector when it implements io.Closer; sql.DB.Close calls
+// it when the database is closed.
+func (c *sqlConnector) Close() error {
+	if closer, ok := c.connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Connect opens a connection with the data source name.
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.name)
+}
+
+// Driver returns the driver.
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Prepare prepares a statement logging its slow executions.
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext prepares a statement logging its slow executions.
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		stmt driver.Stmt
+		err  error
+	)
+	if pc, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sqlStmt{stmt: stmt, conn: c, query: query}, nil
+}
+
+// Close closes the connection.
+func (c *sqlConn) Close() error {
+	return c.conn.Close()
+}
+
+// Begin starts a transaction; database/sql calls BeginTx instead.
+func (c *sqlConn) Begin() (driver.Tx, error) {
+	return c.conn.Begin()
+}
+
+// BeginTx starts a transaction, like database/sql does for drivers without BeginTx.
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bt, ok := c.conn.(driver.ConnBeginTx); ok {
+		return bt.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		return nil, errors.New("sql: driver does not support non-default isolation level")
+	}
+	if opts.ReadOnly {
+		return nil, errors.New("sql: driver does not support read-only transactions")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return c.conn.Begin()
+}
+
+// ExecContext runs a statement without preparing it when the driver supports it.
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	begin := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		c.slow.Observe(ctx, query, sqlParams(args), begin, rowsAffected(result, err), err)
+	}
+	return result, err
+}
+
+// QueryContext runs a query without preparing it when the driver supports it.
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	begin := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		c.slow.Observe(ctx, query, sqlParams(args), begin, -1, err)
+	}
+	return rows, err
+}
+
+// Ping checks the connection when the driver supports it.
+func (c *sqlConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the connection when the driver supports it.
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the connection can be reused; true unless the driver
+// tells otherwise.
+func (c *sqlConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue converts arguments with the checker of the driver, if any.
+func (c *sqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// Close closes the statement.
+func (s *sqlStmt) Close() error {
+	return s.stmt.Close()
+}
+
+// NumInput returns the number of placeholders of the statement.
+func (s *sqlStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+// Exec executes the statement; database/sql calls ExecContext instead.
+func (s *sqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.stmt.Exec(args)
+}
+
+// Query executes the query; database/sql calls QueryContext instead.
+func (s *sqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.stmt.Query(args)
+}
+
+// ExecContext executes the statement and logs it when it is slow.
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	begin := time.Now()
+	var (
+		result driver.Result
+		err    error
+	)
+	if execer, ok := s.stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			result, err = s.stmt.Exec(values)
+		}
+	}
+	s.conn.slow.Observe(ctx, s.query, sqlParams(args), begin, rowsAffected(result, err), err)
+	return result, err
+}
+
+// QueryContext executes the query and logs it when it is slow.
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	begin := time.Now()
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if queryer, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = s.stmt.Query(values)
+		}
+	}
+	s.conn.slow.Observe(ctx, s.query, sqlParams(args), begin, -1, err)
+	return rows, err
+}
+
+// CheckNamedValue converts arguments with the checker of the statement or, like
+// database/sql, of its connection.
+func (s *sqlStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+// MarshalLogArray writes the parameters, formatting values of other types with %v.
+func (a sqlArgs) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, v := range a {
+		switch v := v.(type) {
+		case nil:
+			enc.AppendString("NULL")
+		case string:
+			enc.AppendString(v)
+		case []byte:
+			enc.AppendByteString(v)
+		case int64:
+			enc.AppendInt64(v)
+		case int:
+			enc.AppendInt(v)
+		case float64:
+			enc.AppendFloat64(v)
+		case bool:
+			enc.AppendBool(v)
+		case time.Time:
+			enc.AppendTime(v)
+		default:
+			enc.AppendString(fmt.Sprint(v))
+		}
+	}
+	return nil
+}
+
+// sqlParams returns the bind parameters of the arguments of a driver.
+func sqlParams(args []driver.NamedValue) []SQLParam {
+	params := make([]SQLParam, len(args))
+	for i, arg := range args {
+		params[i] = SQLParam{Ordinal: arg.Ordinal, Name: arg.Name, Value: arg.Value}
+	}
+	return params
+}
+
+// namedValues returns the values of positional arguments, for drivers without
+// context support.
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sql: driver does not support the use of Named Parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// namedArg returns the argument of a named parameter, for re-running a query.
+func namedArg(name string, value any) any {
+	return sql.Named(name, value)
+}
+
+// rowsAffected returns the number of rows affected by a statement, or -1 when unknown.
+func rowsAffected(result driver.Result, err error) int64 {
+	if err != nil || result == nil {
+		return -1
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,241 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type (
+	// fakeDriver is a database/sql driver taking delay for every statement. Its
+	// connections run statements without preparing them unless basic is set, and
+	// return driver.ErrSkip for them when skip is set.
+	fakeDriver struct {
+		delay time.Duration
+		basic bool
+		skip  bool
+	}
+
+	// fakeConnector opens connections of a fakeDriver and records whether it was closed.
+	fakeConnector struct {
+		driver *fakeDriver
+		closed bool
+	}
+
+	// fakeConn is a connection only preparing statements.
+	fakeConn struct {
+		driver *fakeDriver
+	}
+
+	// fakeContextConn is a connection also running statements without preparing them.
+	fakeContextConn struct {
+		fakeConn
+	}
+
+	// fakeStmt is a prepared statement of a fakeConn.
+	fakeStmt struct {
+		driver *fakeDriver
+		query  string
+	}
+
+	// fakeRows is the single row "plan of <query>" of a single column.
+	fakeRows struct {
+		query string
+		done  bool
+	}
+)
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	if d.basic {
+		return &fakeConn{driver: d}, nil
+	}
+	return &fakeContextConn{fakeConn{driver: d}}, nil
+}
+
+func (c *fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c *fakeConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func (c *fakeConnector) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{driver: c.driver, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *fakeContextConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if c.driver.skip {
+		return nil, driver.ErrSkip
+	}
+	time.Sleep(c.driver.delay)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeContextConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if c.driver.skip {
+		return nil, driver.ErrSkip
+	}
+	time.Sleep(c.driver.delay)
+	return &fakeRows{query: query}, nil
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	time.Sleep(s.driver.delay)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	time.Sleep(s.driver.delay)
+	return &fakeRows{query: s.query}, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"plan"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = "plan of " + r.query
+	return nil
+}
+
+// readEntries returns the JSON entries of a log file.
+func readEntries(t *testing.T, path string) []map[string]any {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []map[string]any
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid entry %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// TestSQLConnectorLogsSlowQueries runs a statement and a query through connections
+// running statements directly, returning driver.ErrSkip for them and only preparing
+// them, and checks the slow query entries: redacted arguments, the plan captured
+// without logging the EXPLAIN query itself, and nothing below the threshold.
+func TestSQLConnectorLogsSlowQueries(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		driver    fakeDriver
+		threshold time.Duration
+		slow      int
+	}{
+		{name: "direct", driver: fakeDriver{delay: 5 * time.Millisecond}, threshold: time.Millisecond, slow: 2},
+		{name: "skip", driver: fakeDriver{delay: 5 * time.Millisecond, skip: true}, threshold: time.Millisecond, slow: 2},
+		{name: "prepared", driver: fakeDriver{delay: 5 * time.Millisecond, basic: true}, threshold: time.Millisecond, slow: 2},
+		{name: "fast", driver: fakeDriver{delay: 5 * time.Millisecond}, threshold: time.Hour},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			log, err := NewLogger(WithEncoding(EncodingJson), WithOutputPaths([]string{path}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var db *sql.DB
+			slow := NewSlowQueryLogger(log, SlowQueryConfig{
+				Threshold: tc.threshold,
+				Explain:   NewSQLExplain(func() *sql.DB { return db }, "EXPLAIN "),
+				Redact:    []ParamRedactor{RedactParamValues(regexp.MustCompile(`hunter2`))},
+			})
+			connector := &fakeConnector{driver: &tc.driver}
+			db = sql.OpenDB(NewSQLConnector(connector, slow))
+
+			ctx := context.Background()
+			if _, err := db.ExecContext(ctx, "UPDATE users SET password = ? WHERE id = ?", "hunter2", 7); err != nil {
+				t.Fatal(err)
+			}
+			rows, err := db.QueryContext(ctx, "SELECT name FROM users WHERE id = ?", 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = rows.Close()
+			if err := db.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if !connector.closed {
+				t.Error("closing the database did not close the connector")
+			}
+			if err := Close(ctx, log); err != nil {
+				t.Fatal(err)
+			}
+
+			var slowEntries []map[string]any
+			for _, entry := range readEntries(t, path) {
+				if entry[FieldKeyEvent] == SlowQueryEvent {
+					slowEntries = append(slowEntries, entry)
+				}
+			}
+			if len(slowEntries) != tc.slow {
+				t.Fatalf("got %d slow query entries, want %d: %v", len(slowEntries), tc.slow, slowEntries)
+			}
+			for _, entry := range slowEntries {
+				query, _ := entry[FieldKeySQLQuery].(string)
+				if strings.HasPrefix(query, "EXPLAIN ") {
+					t.Errorf("the query capturing the plan was logged: %v", entry)
+				}
+				if plan := entry[FieldKeySQLExplain]; plan != "plan of EXPLAIN "+query {
+					t.Errorf("plan of %q is %v", query, plan)
+				}
+				args, _ := json.Marshal(entry[FieldKeySQLArgs])
+				if strings.Contains(string(args), "hunter2") || strings.HasPrefix(query, "UPDATE") && !strings.Contains(string(args), redactedValue) {
+					t.Errorf("arguments of %q are not redacted: %s", query, args)
+				}
+			}
+		})
+	}
+}
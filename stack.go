@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"go/build"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// stackFramePrefixes are the function prefixes of frames skipped by WithStacktraceFilter:
+// the runtime, zap and this module.
+var stackFramePrefixes = []string{
+	"runtime.",
+	"go.uber.org/zap.",
+	"go.uber.org/zap/",
+	reflect.TypeOf(logger{}).PkgPath() + ".",
+	reflect.TypeOf(logger{}).PkgPath() + "/",
+}
+
+// stackPathPrefixes are the file path prefixes trimmed by WithStacktraceFilter: the
+// module cache, GOPATH sources and the standard library.
+var stackPathPrefixes = func() []string {
+	var prefixes []string
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		prefixes = append(prefixes,
+			filepath.ToSlash(filepath.Join(gopath, "pkg", "mod"))+"/",
+			filepath.ToSlash(filepath.Join(gopath, "src"))+"/",
+		)
+	}
+	if goroot := runtime.GOROOT(); goroot != "" {
+		prefixes = append(prefixes, filepath.ToSlash(filepath.Join(goroot, "src"))+"/")
+	}
+	return prefixes
+}()
+
+// stackEncoder wraps an encoder and shortens the stack traces of entries.
+type stackEncoder struct {
+	zapcore.Encoder
+	depth  int
+	filter bool
+}
+
+// newStackEncoder wraps the encoder to keep at most depth frames, after skipping the
+// runtime, zap and logger frames when filter is set. It returns the encoder unchanged
+// when neither applies.
+func newStackEncoder(encoder zapcore.Encoder, depth int, filter bool) zapcore.Encoder {
+	if depth <= 0 && !filter {
+		return encoder
+	}
+	return &stackEncoder{Encoder: encoder, depth: depth, filter: filter}
+}
+
+// Clone returns a copy of the encoder that still shortens stack traces.
+func (e *stackEncoder) Clone() zapcore.Encoder {
+	return &stackEncoder{Encoder: e.Encoder.Clone(), depth: e.depth, filter: e.filter}
+}
+
+// EncodeEntry encodes the entry with its stack trace shortened.
+func (e *stackEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	if ent.Stack != "" {
+		ent.Stack = shortenStack(ent.Stack, e.depth, e.filter)
+	}
+	return e.Encoder.EncodeEntry(ent, fields)
+}
+
+// shortenStack rewrites a stack trace in zap's format, a function line followed by a
+// tab-indented file:line line per frame. Filtering keeps the stack unchanged when every
+// frame would be skipped.
+func shortenStack(stack string, depth int, filter bool) string {
+	lines := strings.Split(strings.TrimSuffix(stack, "\n"), "\n")
+	if len(lines)%2 != 0 {
+		return stack
+	}
+
+	frames := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i += 2 {
+		function, file := lines[i], lines[i+1]
+		if filter {
+			if skipStackFrame(function) {
+				continue
+			}
+			file = "\t" + trimStackPath(strings.TrimPrefix(file, "\t"))
+		}
+		frames = append(frames, function, file)
+		if depth > 0 && len(frames)/2 == depth {
+			break
+		}
+	}
+	if len(frames) == 0 {
+		return shortenStack(stack, depth, false)
+	}
+	return strings.Join(frames, "\n")
+}
+
+// skipStackFrame reports whether the frame of the function is skipped by the filter.
+func skipStackFrame(function string) bool {
+	for _, prefix := range stackFramePrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// trimStackPath removes the module cache, GOPATH or GOROOT prefix of a file path.
+func trimStackPath(file string) string {
+	for _, prefix := range stackPathPrefixes {
+		if strings.HasPrefix(file, prefix) {
+			return file[len(prefix):]
+		}
+	}
+	return file
+}
@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+)
+
+// startupCheckTimeout bounds the checks of all sinks run by NewLogger.
+const startupCheckTimeout = 10 * time.Second
+
+// SinkCheck checks that entries can be delivered to a sink without writing one, e.g.
+// by authenticating against a collector or looking up a Kafka topic.
+type SinkCheck func(ctx context.Context, u *url.URL) error
+
+var (
+	sinkChecksMu sync.RWMutex
+	sinkChecks   = map[string]SinkCheck{}
+)
+
+// RegisterSinkCheck registers the startup check of the sinks with the URL scheme,
+// typically next to the zap.RegisterSink call of the scheme. NewLogger runs the check
+// for every output path with the scheme, after resolving its host. Registering a
+// scheme again replaces its check.
+//
+// Parameters:
+//   - scheme: The URL scheme of the sinks, e.g. "kafka"
+//   - check: The check run at startup
+//
+// Example:
+//
+//	_ = zap.RegisterSink("kafka", newKafkaSink)
+//	logger.RegisterSinkCheck("kafka", func(ctx context.Context, u *url.URL) error {
+//	    return kafkaAdmin.DescribeTopic(ctx, strings.TrimPrefix(u.Path, "/"))
+//	})
+func RegisterSinkCheck(scheme string, check SinkCheck) {
+	sinkChecksMu.Lock()
+	defer sinkChecksMu.Unlock()
+	sinkChecks[scheme] = check
+}
+
+// checkSinks checks every output path concurrently, bounded by startupCheckTimeout,
+// and returns the failures. Standard streams and local files are skipped: NewLogger
+// opens them for writing, which already fails when they are not writable. URL sinks
+// get their host resolved and the check registered for their scheme run.
+func checkSinks(ctx context.Context, paths []string) error {
+	ctx, cancel := context.WithTimeout(ctx, startupCheckTimeout)
+	defer cancel()
+
+	paths = slices.Compact(slices.Sorted(slices.Values(paths)))
+	errs := make([]error, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := checkSink(ctx, path); err != nil {
+				errs[i] = fmt.Errorf("sink %q: %w", sanitizePath(path), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// checkSink resolves the host of a URL sink and runs the check of its scheme.
+func checkSink(ctx context.Context, path string) error {
+	if path == "stdout" || path == "stderr" {
+		return nil
+	}
+	if _, local, err := localFilePath(path); err != nil || local {
+		return err
+	}
+
+	u, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+	if host := u.Hostname(); host != "" && net.ParseIP(host) == nil {
+		if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+			return fmt.Errorf("failed to resolve host: %w", err)
+		}
+	}
+
+	sinkChecksMu.RLock()
+	check, ok := sinkChecks[u.Scheme]
+	sinkChecksMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return check(ctx, u)
+}
@@ -0,0 +1,132 @@
+package logger
+
+import "time"
+
+// Stats is a snapshot of the internal counters of a logger.
+type Stats struct {
+	// SinkWriteErrors counts entries that could not be written to a sink.
+	SinkWriteErrors uint64
+	// SinkSyncErrors counts failed sink flushes.
+	SinkSyncErrors uint64
+	// DroppedBatches counts batches dropped by batching sinks.
+	DroppedBatches uint64
+	// RedactorPanics counts panics recovered from redactors.
+	RedactorPanics uint64
+	// FieldPanics counts fields replaced by a placeholder because encoding them panicked.
+	FieldPanics uint64
+	// DiagnosticsSuppressed counts diagnostic entries not written because of rate limiting.
+	DiagnosticsSuppressed uint64
+	// StormSuppressed counts error entries suppressed by WithErrorStormSuppression.
+	StormSuppressed uint64
+	// CallSiteDropped counts the entries dropped by WithCallSiteRateLimit per call site.
+	CallSiteDropped map[string]uint64
+	// EncodedEntries counts the entries encoded since WithEntrySizeTracking enabled tracking.
+	EncodedEntries uint64
+	// EncodedBytes is the total size of the encoded entries.
+	EncodedBytes uint64
+	// OversizedEntries counts the encoded entries larger than the entry size threshold.
+	OversizedEntries uint64
+	// MaxEntrySize is the size of the largest encoded entry.
+	MaxEntrySize int
+	// MaxEntryCaller is the call site of the largest encoded entry, if callers are enabled.
+	MaxEntryCaller string
+	// ResidencyRejected counts unclassified entries dropped by residency routing.
+	ResidencyRejected uint64
+	// IngestBudgets holds the ingest budget of each output path configured with WithIngestBudget.
+	IngestBudgets map[string]IngestBudgetStats
+	// SinkLadders holds the degradation ladder of each output path configured with WithSinkFallback.
+	SinkLadders map[string]SinkLadderStats
+	// EntriesByLevel counts the entries that passed the level, sampling, filters and rate
+	// limits, per level. Entries dropped by ingest budgets are included.
+	EntriesByLevel map[Level]uint64
+	// Goroutines holds the liveness of the background goroutines of the logger, such as the
+	// flushers of batched sinks and the watchers of WatchConfigFile and WatchFlags.
+	Goroutines map[string]GoroutineStats
+	// UncorrelatedEntries counts the entries written without correlation fields, see
+	// WithCorrelationCheck.
+	UncorrelatedEntries uint64
+	// DualWrite is the progress of the migration configured with WithDualWrite.
+	DualWrite DualWriteStats
+	// FieldPool reports the reuse of the field slices built from contexts, shared by all loggers.
+	FieldPool PoolStats
+	// LastError describes the most recent internal failure.
+	LastError string
+	// LastErrorAt is the time of the most recent internal failure.
+	LastErrorAt time.Time
+}
+
+// GetStats returns a snapshot of the internal counters of the logger.
+// Counters are shared by a logger and all its children. Loggers not created by
+// NewLogger, such as mocks, report zero values.
+//
+// Parameters:
+//   - log: The logger to inspect
+//
+// Returns:
+//   - Stats: The counters
+//
+// Example:
+//
+//	if stats := GetStats(log); stats.SinkWriteErrors > 0 {
+//	    healthy = false
+//	}
+func GetStats(log Logger) Stats {
+	z, ok := unwrapLogger(log)
+	if !ok {
+		return Stats{}
+	}
+
+	d := z.state.diag
+	d.mu.Lock()
+	lastError, lastErrorAt := d.lastError, d.lastErrorAt
+	d.mu.Unlock()
+
+	var stormSuppressed uint64
+	if z.state.storms != nil {
+		stormSuppressed = z.state.storms.suppressed.Load()
+	}
+
+	var budgets map[string]IngestBudgetStats
+	if len(z.state.budgets) > 0 {
+		budgets = make(map[string]IngestBudgetStats, len(z.state.budgets))
+		for _, t := range z.state.budgets {
+			budgets[t.sink] = t.stats()
+		}
+	}
+
+	var ladders map[string]SinkLadderStats
+	if len(z.state.ladders) > 0 {
+		ladders = make(map[string]SinkLadderStats, len(z.state.ladders))
+		for _, l := range z.state.ladders {
+			ladders[l.paths[0]] = l.stats()
+		}
+	}
+
+	stats := Stats{
+		SinkWriteErrors:       d.sinkWriteErrors.Load(),
+		SinkSyncErrors:        d.sinkSyncErrors.Load(),
+		DroppedBatches:        d.droppedBatches.Load(),
+		RedactorPanics:        d.redactorPanics.Load(),
+		FieldPanics:           d.fieldPanics.Load(),
+		DiagnosticsSuppressed: d.suppressed.Load(),
+		StormSuppressed:       stormSuppressed,
+		CallSiteDropped:       z.state.sites.dropped(),
+		ResidencyRejected:     z.state.residency.rejectedCount(),
+		IngestBudgets:         budgets,
+		SinkLadders:           ladders,
+		EntriesByLevel:        d.entries.snapshot(),
+		Goroutines:            z.state.supervisor.stats(),
+		DualWrite:             z.state.dualWrite.stats(),
+		UncorrelatedEntries:   z.state.correlation.uncorrelatedCount(),
+		FieldPool:             fieldPool.stats(),
+		LastError:             lastError,
+		LastErrorAt:           lastErrorAt,
+	}
+	if sizes := z.state.sizes; sizes != nil {
+		stats.EncodedEntries = sizes.entries.load()
+		stats.EncodedBytes = sizes.bytes.load()
+		stats.OversizedEntries = sizes.oversized.load()
+		stats.MaxEntrySize, stats.MaxEntryCaller = sizes.maximum.load()
+	}
+	return stats
+}
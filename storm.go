@@ -0,0 +1,222 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys of the summaries written for suppressed error entries.
+const (
+	FieldKeySuppressedCount = "suppressed_count"
+	FieldKeySuppressedFirst = "first_suppressed_at"
+	FieldKeySuppressedLast  = "last_suppressed_at"
+)
+
+// maxStormKeys bounds the number of distinct error entries tracked for storms.
+// Entries beyond the bound are written without suppression.
+const maxStormKeys = 1024
+
+type (
+	// stormCore wraps a zapcore.Core and suppresses storms of identical error entries.
+	stormCore struct {
+		zapcore.Core
+		tracker *stormTracker
+	}
+
+	// stormTracker counts identical error entries and writes summaries of suppressed ones.
+	// Entries are identical when their logger name and message are equal. Once more than
+	// threshold of them are seen within window, further ones are suppressed and a summary
+	// is written every interval until an interval passes without occurrences.
+	stormTracker struct {
+		// base writes the summaries; it carries no fields added with With.
+		base      zapcore.Core
+		threshold int
+		window    time.Duration
+		interval  time.Duration
+
+		mu     sync.Mutex
+		states map[stormKey]*stormState
+
+		// suppressed counts all suppressed entries for GetStats.
+		suppressed atomic.Uint64
+	}
+
+	// stormKey identifies identical error entries.
+	stormKey struct {
+		loggerName string
+		message    string
+	}
+
+	// stormState tracks the occurrences of one error entry.
+	stormState struct {
+		windowStart time.Time
+		count       int
+		suppressing bool
+		// seen reports occurrences since the last summary; a storm ends without them.
+		seen       bool
+		suppressed int
+		first      time.Time
+		last       time.Time
+		timer      *time.Timer
+	}
+)
+
+// newStormCore wraps core with error storm suppression, or returns core unchanged
+// when the threshold is not positive. The tracker is returned for GetStats.
+func newStormCore(core zapcore.Core, threshold int, window, interval time.Duration) (zapcore.Core, *stormTracker) {
+	if threshold <= 0 {
+		return core, nil
+	}
+	tracker := &stormTracker{
+		base:      core,
+		threshold: threshold,
+		window:    window,
+		interval:  interval,
+		states:    make(map[stormKey]*stormState),
+	}
+	return &stormCore{Core: core, tracker: tracker}, tracker
+}
+
+// With returns a child core sharing the storm tracker.
+func (c *stormCore) With(fields []Field) zapcore.Core {
+	return &stormCore{Core: c.Core.With(fields), tracker: c.tracker}
+}
+
+// lowered returns a copy of the core sharing the storm tracker.
+func (c *stormCore) lowered() zapcore.Core {
+	return &stormCore{Core: lowerCore(c.Core), tracker: c.tracker}
+}
+
+// Check adds the core to the checked entry unless the entry is part of a storm.
+func (c *stormCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if ent.Level == zapcore.ErrorLevel && !c.tracker.allow(ent) {
+		return ce
+	}
+	return ce.AddCore(ent, c)
+}
+
+// Sync writes the summaries of pending storms and flushes the sinks.
+func (c *stormCore) Sync() error {
+	c.tracker.flushAll()
+	return c.Core.Sync()
+}
+
+// allow records an occurrence of the entry and reports whether it is written.
+func (t *stormTracker) allow(ent zapcore.Entry) bool {
+	key := stormKey{loggerName: ent.LoggerName, message: ent.Message}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.states[key]
+	if !ok {
+		if len(t.states) >= maxStormKeys && !t.prune(ent.Time) {
+			return true
+		}
+		st = &stormState{windowStart: ent.Time}
+		t.states[key] = st
+	}
+
+	if st.suppressing {
+		if st.suppressed == 0 {
+			st.first = ent.Time
+		}
+		st.seen = true
+		st.suppressed++
+		st.last = ent.Time
+		t.suppressed.Add(1)
+		return false
+	}
+
+	if ent.Time.Sub(st.windowStart) > t.window {
+		st.windowStart, st.count = ent.Time, 0
+	}
+	st.count++
+	if st.count <= t.threshold {
+		return true
+	}
+
+	st.suppressing, st.seen = true, true
+	st.suppressed, st.first, st.last = 1, ent.Time, ent.Time
+	t.suppressed.Add(1)
+	st.timer = time.AfterFunc(t.interval, func() { t.flush(key, st) })
+	return false
+}
+
+// prune removes the states of entries that are neither suppressed nor within their
+// window. It reports whether room was made. The caller holds t.mu.
+func (t *stormTracker) prune(now time.Time) bool {
+	for key, st := range t.states {
+		if !st.suppressing && now.Sub(st.windowStart) > t.window {
+			delete(t.states, key)
+		}
+	}
+	return len(t.states) < maxStormKeys
+}
+
+// flush writes the summary of a storm and schedules the next one. A storm without
+// occurrences since the last summary ends, and the entry is written again.
+func (t *stormTracker) flush(key stormKey, st *stormState) {
+	t.mu.Lock()
+	if !st.seen {
+		st.suppressing = false
+		st.count = 0
+		st.timer = nil
+		t.mu.Unlock()
+		return
+	}
+	count, first, last := st.suppressed, st.first, st.last
+	st.seen, st.suppressed = false, 0
+	st.timer.Reset(t.interval)
+	t.mu.Unlock()
+
+	if count > 0 {
+		t.writeSummary(key, count, first, last)
+	}
+}
+
+// flushAll writes the summaries of all storms with suppressed entries.
+func (t *stormTracker) flushAll() {
+	type summary struct {
+		key         stormKey
+		count       int
+		first, last time.Time
+	}
+
+	t.mu.Lock()
+	var summaries []summary
+	for key, st := range t.states {
+		if st.suppressing && st.suppressed > 0 {
+			summaries = append(summaries, summary{key: key, count: st.suppressed, first: st.first, last: st.last})
+			st.suppressed = 0
+		}
+	}
+	t.mu.Unlock()
+
+	for _, s := range summaries {
+		t.writeSummary(s.key, s.count, s.first, s.last)
+	}
+}
+
+// writeSummary writes an error entry with the message of the suppressed entries and
+// the number and time range of the suppressed occurrences.
+func (t *stormTracker) writeSummary(key stormKey, count int, first, last time.Time) {
+	ent := zapcore.Entry{
+		Level:      zapcore.ErrorLevel,
+		Time:       time.Now(),
+		LoggerName: key.loggerName,
+		Message:    key.message,
+	}
+	_ = t.base.Write(ent, []Field{
+		zap.Int(FieldKeySuppressedCount, count),
+		zap.Time(FieldKeySuppressedFirst, first),
+		zap.Time(FieldKeySuppressedLast, last),
+	})
+}
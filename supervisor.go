@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DiagnosticGoroutinePanic is the kind of diagnostic reported when a background
+// goroutine of the logger panics and is restarted.
+const DiagnosticGoroutinePanic = "goroutine_panic"
+
+// FieldKeyGoroutine names the background goroutine of a goroutine_panic diagnostic.
+const FieldKeyGoroutine = "goroutine"
+
+const (
+	// supervisorMinBackoff is the delay before the first restart of a crashed goroutine.
+	supervisorMinBackoff = 100 * time.Millisecond
+	// supervisorMaxBackoff bounds the delay between restarts. A goroutine running longer
+	// than this before crashing again restarts after supervisorMinBackoff.
+	supervisorMaxBackoff = 30 * time.Second
+)
+
+type (
+	// GoroutineStats is the liveness of a background goroutine of a logger, such as the
+	// flusher of a batched sink or a configuration watcher.
+	GoroutineStats struct {
+		// Alive is false while the goroutine waits to be restarted after a panic.
+		Alive bool
+		// Restarts counts the restarts after panics.
+		Restarts uint64
+		// LastPanic is the value of the most recent panic.
+		LastPanic string
+		// LastPanicAt is the time of the most recent panic.
+		LastPanicAt time.Time
+	}
+
+	// supervisor runs the background goroutines of a logger, restarting them with
+	// exponential backoff when they panic, so a panicking sink or watcher does not
+	// silently stop log delivery. Panics are reported on the diagnostics channel.
+	supervisor struct {
+		diag *diagnostics
+
+		mu    sync.Mutex
+		tasks map[*supervisedTask]struct{}
+	}
+
+	// supervisedTask is the liveness of a goroutine run by a supervisor.
+	supervisedTask struct {
+		name     string
+		alive    atomic.Bool
+		restarts atomic.Uint64
+
+		mu          sync.Mutex
+		lastPanic   string
+		lastPanicAt time.Time
+	}
+)
+
+// newSupervisor creates a supervisor reporting panics to the diagnostics channel.
+func newSupervisor(diag *diagnostics) *supervisor {
+	return &supervisor{diag: diag, tasks: make(map[*supervisedTask]struct{})}
+}
+
+// supervise runs fn until it returns, restarting it after a backoff when it panics.
+// It returns once fn returns or stop is closed while waiting for a restart. The
+// goroutine is listed by GetStats under its name while supervise runs.
+func (s *supervisor) supervise(name string, stop <-chan struct{}, fn func()) {
+	task := &supervisedTask{name: name}
+	task.alive.Store(true)
+	s.mu.Lock()
+	s.tasks[task] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.tasks, task)
+		s.mu.Unlock()
+	}()
+
+	backoff := supervisorMinBackoff
+	for {
+		started := time.Now()
+		recovered, stack := runRecovered(fn)
+		if recovered == nil {
+			return
+		}
+
+		task.crashed(recovered)
+		s.diag.report(DiagnosticGoroutinePanic, fmt.Errorf("panic: %v", recovered),
+			zap.String(FieldKeyGoroutine, name), zap.ByteString("stack", stack))
+
+		if time.Since(started) > supervisorMaxBackoff {
+			backoff = supervisorMinBackoff
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, supervisorMaxBackoff)
+		task.restarts.Add(1)
+		task.alive.Store(true)
+	}
+}
+
+// runRecovered runs fn and returns the value and stack of its panic, nil if it returned.
+func runRecovered(fn func()) (recovered any, stack []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			recovered, stack = r, debug.Stack()
+		}
+	}()
+	fn()
+	return nil, nil
+}
+
+// crashed records a panic of the goroutine.
+func (t *supervisedTask) crashed(recovered any) {
+	t.alive.Store(false)
+	t.mu.Lock()
+	t.lastPanic = fmt.Sprint(recovered)
+	t.lastPanicAt = time.Now()
+	t.mu.Unlock()
+}
+
+// stats returns the liveness of every supervised goroutine, nil when there are none.
+// Goroutines sharing a name are merged: the name is alive when all of them are.
+func (s *supervisor) stats() map[string]GoroutineStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.tasks) == 0 {
+		return nil
+	}
+
+	stats := make(map[string]GoroutineStats, len(s.tasks))
+	for task := range s.tasks {
+		task.mu.Lock()
+		lastPanic, lastPanicAt := task.lastPanic, task.lastPanicAt
+		task.mu.Unlock()
+
+		current, seen := stats[task.name]
+		current.Alive = task.alive.Load() && (current.Alive || !seen)
+		current.Restarts += task.restarts.Load()
+		if lastPanicAt.After(current.LastPanicAt) {
+			current.LastPanic, current.LastPanicAt = lastPanic, lastPanicAt
+		}
+		stats[task.name] = current
+	}
+	return stats
+}
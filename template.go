@@ -0,0 +1,123 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// templateBufferPool provides the buffers templateEncoder renders into and returns.
+var templateBufferPool = buffer.NewPool()
+
+type (
+	// ConsoleLine is the data available to console line templates.
+	ConsoleLine struct {
+		// Time is the entry time in ISO8601 format with milliseconds.
+		Time string
+		// Level is the upper-case level, e.g. INFO.
+		Level string
+		// Logger is the logger name, empty for unnamed loggers.
+		Logger string
+		// Caller is the caller path (package/file.go:line or module relative), empty when disabled.
+		Caller string
+		// Message is the log message.
+		Message string
+		// Fields holds the structured fields as a JSON object, empty when there are none.
+		Fields string
+		// Stack is the stack trace, empty when not captured.
+		Stack string
+		// Entry is the raw zap entry for advanced formatting.
+		Entry zapcore.Entry
+	}
+
+	// templateEncoder renders console lines with a text/template.
+	// Fields are encoded by an embedded JSON encoder without entry keys, so fields added
+	// with With are kept across clones like in zap's console encoder.
+	templateEncoder struct {
+		zapcore.Encoder
+		tmpl     *template.Template
+		humanize bool
+		caller   *callerFormatter
+	}
+)
+
+// newTemplateEncoder creates an encoder rendering lines with the template.
+// Callers are rendered by caller when set, otherwise in zap's short format.
+func newTemplateEncoder(encoderConfig zapcore.EncoderConfig, tmpl *template.Template, humanize bool, caller *callerFormatter) zapcore.Encoder {
+	fieldsConfig := zapcore.EncoderConfig{
+		EncodeDuration: encoderConfig.EncodeDuration,
+		EncodeTime:     encoderConfig.EncodeTime,
+		SkipLineEnding: true,
+	}
+	var fields zapcore.Encoder = zapcore.NewJSONEncoder(fieldsConfig)
+	if humanize {
+		fields = &humanEncoder{Encoder: fields}
+	}
+	return &templateEncoder{Encoder: fields, tmpl: tmpl, humanize: humanize, caller: caller}
+}
+
+// Clone returns a copy of the encoder sharing the template.
+func (e *templateEncoder) Clone() zapcore.Encoder {
+	return &templateEncoder{Encoder: e.Encoder.Clone(), tmpl: e.tmpl, humanize: e.humanize, caller: e.caller}
+}
+
+// EncodeEntry renders the entry with the template.
+func (e *templateEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	encoded, err := e.Encoder.EncodeEntry(zapcore.Entry{}, fields)
+	if err != nil {
+		return nil, err
+	}
+	fieldsJSON := encoded.String()
+	encoded.Free()
+	if fieldsJSON == "{}" {
+		fieldsJSON = ""
+	}
+
+	line := ConsoleLine{
+		Time:    ent.Time.Format("2006-01-02T15:04:05.000Z0700"),
+		Level:   ent.Level.CapitalString(),
+		Logger:  ent.LoggerName,
+		Message: ent.Message,
+		Fields:  fieldsJSON,
+		Stack:   ent.Stack,
+		Entry:   ent,
+	}
+	if ent.Caller.Defined {
+		if e.caller != nil {
+			line.Caller = e.caller.format(ent.Caller)
+		} else {
+			line.Caller = ent.Caller.TrimmedPath()
+		}
+	}
+
+	out := templateBufferPool.Get()
+	defer out.Free()
+	if err := e.tmpl.Execute(out, line); err != nil {
+		return nil, err
+	}
+
+	rendered := out.Bytes()
+	buf := templateBufferPool.Get()
+	_, _ = buf.Write(bytes.TrimRight(rendered, " "))
+	if !bytes.HasSuffix(rendered, []byte("\n")) {
+		buf.AppendByte('\n')
+	}
+	return buf, nil
+}
+
+// parseConsoleTemplate parses a console line template.
+func parseConsoleTemplate(text string) (*template.Template, error) {
+	return template.New("console").Funcs(template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"pad": func(width int, s string) string {
+			if len(s) >= width {
+				return s
+			}
+			return s + strings.Repeat(" ", width-len(s))
+		},
+	}).Parse(text)
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// Field keys written by the Temporal adapter.
+const (
+	FieldKeyTemporalNamespace = "temporal_namespace"
+	FieldKeyTaskQueue         = "task_queue"
+	FieldKeyWorkerID          = "worker_id"
+	FieldKeyWorkflowType      = "workflow_type"
+	FieldKeyWorkflowID        = "workflow_id"
+	FieldKeyRunID             = "run_id"
+	FieldKeyActivityType      = "activity_type"
+	FieldKeyActivityID        = "activity_id"
+	FieldKeyAttempt           = "attempt"
+)
+
+// temporalFieldKeys maps the keys the Temporal SDK attaches to workflow and activity
+// loggers to the snake_case field keys used by this package.
+var temporalFieldKeys = map[string]string{
+	"Namespace":    FieldKeyTemporalNamespace,
+	"TaskQueue":    FieldKeyTaskQueue,
+	"WorkerID":     FieldKeyWorkerID,
+	"WorkflowType": FieldKeyWorkflowType,
+	"WorkflowID":   FieldKeyWorkflowID,
+	"RunID":        FieldKeyRunID,
+	"ActivityType": FieldKeyActivityType,
+	"ActivityID":   FieldKeyActivityID,
+	"Attempt":      FieldKeyAttempt,
+	"Error":        "error",
+}
+
+// temporalLoggerCallerSkip is the number of frames of TemporalLogger between the
+// caller and the Logger call: the level method, write and logAt.
+const temporalLoggerCallerSkip = 3
+
+// TemporalLogger adapts a Logger to the log.Logger interface of the Temporal Go SDK.
+// The SDK passes the namespace, task queue, workflow and activity IDs of the running
+// code as key-value pairs; they are logged as workflow_id, run_id, activity_id and
+// similar fields so workflow and activity entries can be queried like any other entry.
+type TemporalLogger struct {
+	log Logger
+}
+
+// NewTemporalLogger creates a TemporalLogger.
+//
+// Parameters:
+//   - log: The logger receiving the entries
+//
+// Returns:
+//   - *TemporalLogger: The adapter
+//
+// Example:
+//
+//	c, err := client.Dial(client.Options{Logger: NewTemporalLogger(log)})
+//
+//	// in workflow code, IDs are added by the SDK
+//	workflow.GetLogger(ctx).Info("charging card", "amount", amount)
+func NewTemporalLogger(log Logger) *TemporalLogger {
+	return &TemporalLogger{log: AddCallerSkip(log.With(zap.String(FieldKeyComponent, "temporal")), temporalLoggerCallerSkip)}
+}
+
+// Debug logs a message at DebugLevel.
+func (t *TemporalLogger) Debug(msg string, keyvals ...any) {
+	t.write(LevelDebug, msg, keyvals)
+}
+
+// Info logs a message at InfoLevel.
+func (t *TemporalLogger) Info(msg string, keyvals ...any) {
+	t.write(LevelInfo, msg, keyvals)
+}
+
+// Warn logs a message at WarnLevel.
+func (t *TemporalLogger) Warn(msg string, keyvals ...any) {
+	t.write(LevelWarning, msg, keyvals)
+}
+
+// Error logs a message at ErrorLevel.
+func (t *TemporalLogger) Error(msg string, keyvals ...any) {
+	t.write(LevelError, msg, keyvals)
+}
+
+// write logs a message with the Temporal keys renamed to the field keys of this package.
+func (t *TemporalLogger) write(level Level, msg string, keyvals []any) {
+	fields := keysAndValuesToFields(keyvals)
+	for i := range fields {
+		if key, ok := temporalFieldKeys[fields[i].Key]; ok {
+			fields[i].Key = key
+		}
+	}
+	logAt(context.Background(), t.log, level, msg, fields...)
+}
@@ -0,0 +1,260 @@
+package logger
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// tenantIDKey is the field key identifying the tenant of entries written by tenant loggers.
+const tenantIDKey = "tenant_id"
+
+type (
+	// TenantConfig controls the logger of a single tenant.
+	TenantConfig struct {
+		// Level sets the minimum level of the tenant. An empty level keeps the base logger level.
+		// Entries below the base level are still sampled and filtered like the others.
+		Level Level
+		// RateLimit sets the sustained number of entries per second. Zero disables rate limiting.
+		RateLimit float64
+		// Burst sets how many entries may be written at once before RateLimit applies.
+		Burst int
+		// OutputPaths adds dedicated sinks receiving only the tenant's entries,
+		// in addition to the sinks of the base logger.
+		OutputPaths []string
+	}
+
+	// TenantLoggers hands out child loggers keyed by tenant ID.
+	// Every tenant gets its own level, rate budget and optional dedicated sinks.
+	// Loggers unused for longer than the idle timeout are evicted and their
+	// dedicated sinks closed, so tenant loggers should not be retained beyond a request.
+	// TenantLoggers is safe for concurrent use.
+	TenantLoggers struct {
+		base        *zapLogger
+		defaults    TenantConfig
+		idleTimeout time.Duration
+
+		mu        sync.Mutex
+		overrides map[string]TenantConfig
+		tenants   map[string]*tenantLogger
+		lastSweep time.Time
+	}
+
+	// tenantLogger is the cached logger of a single tenant.
+	tenantLogger struct {
+		logger   Logger
+		dropped  *atomic.Uint64
+		lastUsed atomic.Int64
+		close    func()
+	}
+)
+
+// NewTenantLoggers creates a tenant logger manager deriving tenant loggers from base.
+//
+// Parameters:
+//   - base: The logger tenant loggers are derived from; must be created by NewLogger
+//   - defaults: The configuration of tenants without an override
+//   - idleTimeout: How long an unused tenant logger is kept; zero keeps loggers forever
+//
+// Returns:
+//   - *TenantLoggers: The tenant logger manager
+//   - error: An error if base was not created by NewLogger
+//
+// Example:
+//
+//	tenants, err := NewTenantLoggers(log, TenantConfig{RateLimit: 100, Burst: 200}, 10*time.Minute)
+//	tenants.SetConfig("acme", TenantConfig{Level: LevelDebug})
+//	tenants.Get("acme").Debug(ctx, "Verbose logging for acme only")
+func NewTenantLoggers(base Logger, defaults TenantConfig, idleTimeout time.Duration) (*TenantLoggers, error) {
+	zl, ok := unwrapLogger(base)
+	if !ok {
+		return nil, errors.New("tenant loggers require a logger created by NewLogger")
+	}
+
+	if _, err := tenantLevel(defaults.Level); err != nil {
+		return nil, err
+	}
+
+	return &TenantLoggers{
+		base:        zl,
+		defaults:    defaults,
+		idleTimeout: idleTimeout,
+		overrides:   make(map[string]TenantConfig),
+		tenants:     make(map[string]*tenantLogger),
+		lastSweep:   time.Now(),
+	}, nil
+}
+
+// Get returns the logger of the tenant, creating it on first use.
+// Every entry written through it carries a tenant_id field.
+// If the dedicated sinks of the tenant cannot be opened the logger falls back
+// to the base sinks only and the failure is logged through the base logger.
+func (t *TenantLoggers) Get(tenantID string) Logger {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweep(now)
+
+	tl, ok := t.tenants[tenantID]
+	if !ok {
+		tl = t.build(tenantID)
+		t.tenants[tenantID] = tl
+	}
+	tl.lastUsed.Store(now.UnixNano())
+
+	return tl.logger
+}
+
+// SetConfig overrides the configuration of a tenant.
+// The tenant logger is rebuilt on the next call to Get.
+//
+// Returns:
+//   - error: An error if the configured level is invalid
+func (t *TenantLoggers) SetConfig(tenantID string, cfg TenantConfig) error {
+	if _, err := tenantLevel(cfg.Level); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.overrides[tenantID] = cfg
+	t.evict(tenantID)
+	return nil
+}
+
+// ResetConfig removes the override of a tenant so it uses the defaults again.
+func (t *TenantLoggers) ResetConfig(tenantID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.overrides, tenantID)
+	t.evict(tenantID)
+}
+
+// Dropped returns how many entries of the tenant were dropped by its rate budget
+// since its logger was created.
+func (t *TenantLoggers) Dropped(tenantID string) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tl, ok := t.tenants[tenantID]; ok {
+		return tl.dropped.Load()
+	}
+	return 0
+}
+
+// Len returns the number of tenant loggers currently cached.
+func (t *TenantLoggers) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.tenants)
+}
+
+// Close evicts every tenant logger and closes their dedicated sinks.
+func (t *TenantLoggers) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id := range t.tenants {
+		t.evict(id)
+	}
+}
+
+// build creates the logger of a tenant; the caller must hold the lock.
+func (t *TenantLoggers) build(tenantID string) *tenantLogger {
+	cfg, ok := t.overrides[tenantID]
+	if !ok {
+		cfg = t.defaults
+	}
+
+	tl := &tenantLogger{dropped: &atomic.Uint64{}, close: func() {}}
+
+	var (
+		state     = t.base.state
+		dedicated zapcore.WriteSyncer
+		openErr   error
+	)
+	if len(cfg.OutputPaths) > 0 {
+		var closeSinks func()
+		dedicated, closeSinks, openErr = openSinks(cfg.OutputPaths, state.cfg)
+		if openErr == nil {
+			tl.close = closeSinks
+		}
+	}
+
+	level, _ := tenantLevel(cfg.Level)
+	tenantField := zap.String(tenantIDKey, tenantID)
+
+	zl := t.base.zapLogger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if dedicated != nil {
+			dedicatedCore := newClassCore(zapcore.NewCore(state.encoder.Clone(), dedicated, zapcore.DebugLevel), defaultSinkPolicy)
+			core = teeCore{core, &redactCore{Core: dedicatedCore, redacted: state.redacted}}
+		}
+		if level != nil {
+			core = newLevelCore(core, level)
+		}
+		if cfg.RateLimit > 0 {
+			core = &rateLimitCore{Core: core, bucket: newTokenBucket(cfg.RateLimit, cfg.Burst), dropped: tl.dropped}
+		}
+		return core
+	})).With(tenantField)
+
+	// The fields are kept for loggers derived with WithOptions.
+	fields := slices.Concat(t.base.fields, []Field{tenantField})
+	tl.logger = &logger{logger: &zapLogger{zapLogger: zl, state: state, fields: fields}}
+
+	if openErr != nil {
+		t.base.zapLogger.Error("failed to open dedicated tenant sinks",
+			zap.String(tenantIDKey, tenantID),
+			zap.Error(openErr),
+		)
+	}
+
+	return tl
+}
+
+// sweep evicts idle tenant loggers; the caller must hold the lock.
+// Sweeping runs at most twice per idle timeout to keep Get cheap.
+func (t *TenantLoggers) sweep(now time.Time) {
+	if t.idleTimeout <= 0 || now.Sub(t.lastSweep) < t.idleTimeout/2 {
+		return
+	}
+	t.lastSweep = now
+
+	cutoff := now.Add(-t.idleTimeout).UnixNano()
+	for id, tl := range t.tenants {
+		if tl.lastUsed.Load() < cutoff {
+			t.evict(id)
+		}
+	}
+}
+
+// evict removes a tenant logger and closes its dedicated sinks; the caller must hold the lock.
+func (t *TenantLoggers) evict(tenantID string) {
+	if tl, ok := t.tenants[tenantID]; ok {
+		delete(t.tenants, tenantID)
+		tl.close()
+	}
+}
+
+// tenantLevel converts a tenant level to a zap level. An empty level returns nil.
+func tenantLevel(level Level) (zapcore.LevelEnabler, error) {
+	if level == "" {
+		return nil, nil
+	}
+
+	atomicLevel, err := parseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return atomicLevel.Level(), nil
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// TestTenantLoggerDerivedKeepsFields derives a logger from a tenant logger of a base
+// logger with fields and checks that its entries carry the base fields and the tenant.
+func TestTenantLoggerDerivedKeepsFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	base, err := NewLogger(WithEncoding(EncodingJson), WithOutputPaths([]string{path}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenants, err := NewTenantLoggers(base.With(zap.String("service", "billing")), TenantConfig{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived, err := tenants.Get("acme").WithOptions(WithLevel(LevelDebug))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	derived.Debug(ctx, "derived entry")
+	for _, log := range []Logger{derived, base} {
+		if err := Close(ctx, log); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, want := range []string{`"message":"derived entry"`, `"service":"billing"`, `"tenant_id":"acme"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("entry %q lacks %s", out, want)
+		}
+	}
+}
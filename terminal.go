@@ -0,0 +1,74 @@
+package logger
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// isTerminal reports whether f is a terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runningInCI reports whether the process runs in a CI system, which sets the CI
+// environment variable, e.g. GitHub Actions, GitLab CI and CircleCI.
+func runningInCI() bool {
+	ci := os.Getenv("CI")
+	return ci != "" && ci != "false" && ci != "0"
+}
+
+// writesToTerminal reports whether the output paths include a standard stream and
+// every standard stream among them is a terminal. Files and network sinks are ignored,
+// so a terminal with a copy of the output in a file still counts.
+func writesToTerminal(paths []string) bool {
+	found := false
+	for _, path := range paths {
+		var f *os.File
+		switch path {
+		case "stdout":
+			f = os.Stdout
+		case "stderr":
+			f = os.Stderr
+		default:
+			continue
+		}
+		if !isTerminal(f) {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// lightBackground reports whether the COLORFGBG environment variable, set by terminals
+// such as rxvt and Konsole as "foreground;background", names a light background.
+func lightBackground() bool {
+	colors := os.Getenv("COLORFGBG")
+	background, err := strconv.Atoi(colors[strings.LastIndexByte(colors, ';')+1:])
+	return err == nil && (background == 7 || background >= 9 && background <= 15)
+}
+
+// resolveAutoEncoding picks the encoding of WithAutoEncoding: colored console output
+// when the output goes to a terminal, uncolored console output in CI and JSON
+// otherwise. A theme set with WithColorTheme is kept.
+func (c *config) resolveAutoEncoding() {
+	switch {
+	case runningInCI():
+		c.Encoding = EncodingConsole
+		theme := NoColorTheme()
+		c.ColorTheme = &theme
+	case writesToTerminal(c.OutputPaths):
+		c.Encoding = EncodingConsole
+		if c.ColorTheme == nil {
+			theme := DarkTheme()
+			if lightBackground() {
+				theme = LightTheme()
+			}
+			c.ColorTheme = &theme
+		}
+	default:
+		c.Encoding = EncodingJson
+	}
+}
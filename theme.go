@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// colorReset ends a colored span.
+const colorReset = "\x1b[0m"
+
+// themeBufferPool provides the buffers returned by themeEncoder.
+var themeBufferPool = buffer.NewPool()
+
+// memberPrefixes precede the keys of the fields of console lines, which zap writes as
+// JSON with a space after commas.
+var memberPrefixes = []string{"{", ", ", ","}
+
+type (
+	// Color is the ANSI SGR parameters of a color, e.g. "31" for red, "1;97;41" for bold
+	// white on red or "38;5;130" for a 256-color dark orange. Empty leaves the text
+	// uncolored.
+	Color string
+
+	// ColorTheme colors console output, see WithColorTheme. Parts without a color are
+	// written uncolored.
+	ColorTheme struct {
+		// Levels are the colors of the level names.
+		Levels map[Level]Color
+		// Time is the color of the time.
+		Time Color
+		// Logger is the color of the logger name.
+		Logger Color
+		// Caller is the color of the caller.
+		Caller Color
+		// Message is the color of the message.
+		Message Color
+		// Keys are the colors of field keys, e.g. "error" or "request_id".
+		Keys map[string]Color
+	}
+
+	// themeEncoder wraps the console encoder and colors the message and field keys of
+	// entries; levels, times, logger names and callers are colored by the encoder
+	// functions set by ColorTheme.apply.
+	themeEncoder struct {
+		zapcore.Encoder
+		theme *ColorTheme
+	}
+
+	// colorArrayEncoder colors the strings appended by a zap encoder function.
+	colorArrayEncoder struct {
+		zapcore.PrimitiveArrayEncoder
+		color Color
+	}
+)
+
+// DarkTheme returns the color theme for dark terminal backgrounds, with zap's level
+// colors: magenta debug, blue info, yellow warnings and red errors.
+//
+// Returns:
+//   - ColorTheme: The theme
+func DarkTheme() ColorTheme {
+	return ColorTheme{
+		Levels: map[Level]Color{
+			LevelDebug:   "35",
+			LevelInfo:    "34",
+			LevelWarning: "33",
+			LevelError:   "31",
+			LevelPanic:   "1;31",
+			LevelFatal:   "1;31",
+		},
+		Time:   "90",
+		Logger: "36",
+		Caller: "90",
+		Keys:   map[string]Color{"error": "31"},
+	}
+}
+
+// LightTheme returns the color theme for light terminal backgrounds: it avoids yellow
+// and the bright colors that wash out on white, using dark 256-color shades.
+//
+// Returns:
+//   - ColorTheme: The theme
+func LightTheme() ColorTheme {
+	return ColorTheme{
+		Levels: map[Level]Color{
+			LevelDebug:   "38;5;240",
+			LevelInfo:    "38;5;25",
+			LevelWarning: "38;5;130",
+			LevelError:   "38;5;124",
+			LevelPanic:   "1;38;5;124",
+			LevelFatal:   "1;38;5;124",
+		},
+		Time:   "38;5;242",
+		Logger: "38;5;23",
+		Caller: "38;5;242",
+		Keys:   map[string]Color{"error": "38;5;124"},
+	}
+}
+
+// HighContrastTheme returns a color theme readable on any background and for users
+// with low vision: bold levels on solid backgrounds and a bold message.
+//
+// Returns:
+//   - ColorTheme: The theme
+func HighContrastTheme() ColorTheme {
+	return ColorTheme{
+		Levels: map[Level]Color{
+			LevelDebug:   "1;30;47",
+			LevelInfo:    "1;97;44",
+			LevelWarning: "1;30;43",
+			LevelError:   "1;97;41",
+			LevelPanic:   "1;97;45",
+			LevelFatal:   "1;97;45",
+		},
+		Message: "1",
+		Keys:    map[string]Color{"error": "1;4"},
+	}
+}
+
+// NoColorTheme returns a theme without colors, e.g. for terminals that cannot show
+// them or output piped to a file.
+//
+// Returns:
+//   - ColorTheme: The theme
+func NoColorTheme() ColorTheme {
+	return ColorTheme{}
+}
+
+// colorsDisabled reports whether the NO_COLOR environment variable asks for output
+// without colors, see https://no-color.org.
+func colorsDisabled() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// colored reports whether console output of the configuration is colored: a theme is
+// set, the encoding is console without a template, output is not deterministic and
+// NO_COLOR is not set.
+func (c *config) colored() bool {
+	return c.ColorTheme != nil && c.Encoding == EncodingConsole && c.ConsoleTemplate == "" && !c.Deterministic && !colorsDisabled()
+}
+
+// wrap returns s in the color, or s unchanged without a color.
+func (c Color) wrap(s string) string {
+	if c == "" {
+		return s
+	}
+	return "\x1b[" + string(c) + "m" + s + colorReset
+}
+
+// apply sets the encoder functions of encoderConfig coloring levels, times, logger
+// names and callers.
+func (t *ColorTheme) apply(encoderConfig *zapcore.EncoderConfig) {
+	if len(t.Levels) > 0 {
+		encoderConfig.EncodeLevel = func(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(t.Levels[levelName(level)].wrap(level.CapitalString()))
+		}
+	}
+	if t.Time != "" && encoderConfig.EncodeTime != nil {
+		encodeTime := encoderConfig.EncodeTime
+		encoderConfig.EncodeTime = func(tm time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			encodeTime(tm, colorArrayEncoder{PrimitiveArrayEncoder: enc, color: t.Time})
+		}
+	}
+	if t.Logger != "" {
+		encodeName := encoderConfig.EncodeName
+		if encodeName == nil {
+			// zap writes names in full without a name encoder.
+			encodeName = zapcore.FullNameEncoder
+		}
+		encoderConfig.EncodeName = func(name string, enc zapcore.PrimitiveArrayEncoder) {
+			encodeName(name, colorArrayEncoder{PrimitiveArrayEncoder: enc, color: t.Logger})
+		}
+	}
+	if t.Caller != "" && encoderConfig.EncodeCaller != nil {
+		encodeCaller := encoderConfig.EncodeCaller
+		encoderConfig.EncodeCaller = func(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+			encodeCaller(caller, colorArrayEncoder{PrimitiveArrayEncoder: enc, color: t.Caller})
+		}
+	}
+}
+
+// wrap returns the encoder coloring messages and field keys, or the encoder unchanged
+// when the theme colors neither.
+func (t *ColorTheme) wrap(encoder zapcore.Encoder) zapcore.Encoder {
+	if t.Message == "" && len(t.Keys) == 0 {
+		return encoder
+	}
+	return &themeEncoder{Encoder: encoder, theme: t}
+}
+
+// AppendString appends the string in the color.
+func (e colorArrayEncoder) AppendString(s string) {
+	e.PrimitiveArrayEncoder.AppendString(e.color.wrap(s))
+}
+
+// Clone returns a copy of the encoder keeping the theme.
+func (e *themeEncoder) Clone() zapcore.Encoder {
+	return &themeEncoder{Encoder: e.Encoder.Clone(), theme: e.theme}
+}
+
+// EncodeEntry encodes the entry with the message and the keys of the theme colored.
+// Keys are only colored where they start a JSON member of the fields, after { or a
+// comma, so quotes inside string values, which are escaped, are never taken for keys.
+func (e *themeEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	ent.Message = e.theme.Message.wrap(ent.Message)
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil || len(e.theme.Keys) == 0 {
+		return buf, err
+	}
+
+	line := buf.Bytes()
+	for key, color := range e.theme.Keys {
+		if color == "" {
+			continue
+		}
+		quoted := []byte(`"` + key + `":`)
+		colored := []byte(color.wrap(`"`+key+`"`) + ":")
+		for _, prefix := range memberPrefixes {
+			line = bytes.ReplaceAll(line, append([]byte(prefix), quoted...), append([]byte(prefix), colored...))
+		}
+	}
+	out := themeBufferPool.Get()
+	_, _ = out.Write(line)
+	buf.Free()
+	return out, nil
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys written to the entries of two-phase transactions.
+const (
+	FieldKeyTxnID     = "txn_id"
+	FieldKeyTxnPhase  = "txn_phase"
+	FieldKeyTxnStatus = "txn_status"
+)
+
+// Phases of a two-phase transaction, written as txn_phase.
+const (
+	TxnPhaseBegin = "begin"
+	TxnPhaseEnd   = "end"
+)
+
+// Messages of the entries written by BeginRequest and Txn.
+const (
+	txnBeginMessage = "request started"
+	txnEndMessage   = "request finished"
+)
+
+// Txn is a request, job or message whose start and end are logged as two entries
+// sharing a txn_id, so requests that never finish show up as a begin entry without
+// an end entry. A Txn is safe for concurrent use; only its first end is logged.
+type Txn struct {
+	ctx    context.Context
+	log    Logger
+	id     string
+	start  time.Time
+	fields []Field
+	ended  atomic.Bool
+}
+
+// BeginRequest logs the start of a request, batch job or consumed message and returns
+// the transaction to end once it is done. The begin entry carries a new txn_id,
+// generated like request IDs, and the fields; the end entry carries them again with
+// the status and duration.
+//
+// Parameters:
+//   - ctx: The context of the transaction, used for both entries
+//   - log: The logger writing the entries
+//   - fields: Fields describing the transaction, written to both entries
+//
+// Returns:
+//   - *Txn: The transaction
+//
+// Example:
+//
+//	txn := logger.BeginRequest(ctx, log, zap.String("job", "nightly-export"))
+//	if err := export(ctx); err != nil {
+//	    txn.Fail("failed", err)
+//	    return
+//	}
+//	txn.End("ok", zap.Int("rows", rows))
+//	// {"message":"request started","txn_id":"4f1c...","txn_phase":"begin","job":"nightly-export"}
+//	// {"message":"request finished","txn_id":"4f1c...","txn_phase":"end","job":"nightly-export","txn_status":"ok","duration":"2m3.1s","rows":52113}
+func BeginRequest(ctx context.Context, log Logger, fields ...Field) *Txn {
+	t := &Txn{ctx: ctx, log: log, id: idGenerator(log)(), start: time.Now(), fields: fields}
+	log.Info(ctx, txnBeginMessage, append(t.phaseFields(TxnPhaseBegin), fields...)...)
+	return t
+}
+
+// ID returns the txn_id of the transaction, e.g. to hand it to a downstream system.
+func (t *Txn) ID() string {
+	return t.id
+}
+
+// End logs the successful end of the transaction at InfoLevel.
+//
+// Parameters:
+//   - status: The outcome of the transaction, e.g. "ok" or "200"
+//   - fields: Fields added to the end entry only
+func (t *Txn) End(status string, fields ...Field) {
+	t.finish(zapcore.InfoLevel, txnEndMessage, t.endFields(status, nil, fields))
+}
+
+// Fail logs the failed end of the transaction at ErrorLevel.
+//
+// Parameters:
+//   - status: The outcome of the transaction, e.g. "failed" or "500"
+//   - err: The cause of the failure
+//   - fields: Fields added to the end entry only
+func (t *Txn) Fail(status string, err error, fields ...Field) {
+	t.finish(zapcore.ErrorLevel, txnEndMessage, t.endFields(status, err, fields))
+}
+
+// endFields returns the fields of the end entry: the fields of the begin entry, the
+// status, the duration, the error if any and the fields passed to End or Fail.
+func (t *Txn) endFields(status string, err error, fields []Field) []Field {
+	out := make([]Field, 0, len(t.fields)+len(fields)+3)
+	out = append(out, t.fields...)
+	out = append(out, zap.String(FieldKeyTxnStatus, status), zap.Duration(FieldKeyDuration, time.Since(t.start)))
+	if err != nil {
+		out = append(out, zap.Error(err))
+	}
+	return append(out, fields...)
+}
+
+// finish writes the end entry at the level unless the transaction already ended. The
+// entry carries the txn_id and txn_phase followed by the fields.
+func (t *Txn) finish(level zapcore.Level, msg string, fields []Field) {
+	if t.ended.Swap(true) {
+		return
+	}
+
+	entry := append(t.phaseFields(TxnPhaseEnd), fields...)
+	switch {
+	case level >= zapcore.ErrorLevel:
+		t.log.Error(t.ctx, msg, entry...)
+	case level == zapcore.WarnLevel:
+		t.log.Warn(t.ctx, msg, entry...)
+	default:
+		t.log.Info(t.ctx, msg, entry...)
+	}
+}
+
+// phaseFields returns the txn_id and txn_phase fields.
+func (t *Txn) phaseFields(phase string) []Field {
+	return []Field{zap.String(FieldKeyTxnID, t.id), zap.String(FieldKeyTxnPhase, phase)}
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"github.com/mssola/useragent"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field keys added by WithUserAgentParsing.
+const (
+	FieldKeyUABrowser        = "ua_browser"
+	FieldKeyUABrowserVersion = "ua_browser_version"
+	FieldKeyUAOS             = "ua_os"
+	FieldKeyUAOSVersion      = "ua_os_version"
+	FieldKeyUADevice         = "ua_device"
+)
+
+// Device classes written under FieldKeyUADevice.
+const (
+	DeviceBot     = "bot"
+	DeviceMobile  = "mobile"
+	DeviceDesktop = "desktop"
+)
+
+// defaultUserAgentCacheSize is the number of parsed user agents cached when none is configured.
+const defaultUserAgentCacheSize = 1024
+
+type (
+	// userAgentCore wraps a zapcore.Core and adds the parsed browser, operating system
+	// and device class next to every user_agent field.
+	userAgentCore struct {
+		zapcore.Core
+		cache *lruCache[string, []Field]
+	}
+)
+
+// newUserAgentCore wraps core with user agent parsing, or returns core unchanged when disabled.
+func newUserAgentCore(core zapcore.Core, enabled bool, cacheSize int) zapcore.Core {
+	if !enabled {
+		return core
+	}
+	if cacheSize <= 0 {
+		cacheSize = defaultUserAgentCacheSize
+	}
+	return &userAgentCore{Core: core, cache: newLRUCache[string, []Field](cacheSize)}
+}
+
+// With returns a child core with the user agent of the fields parsed.
+func (c *userAgentCore) With(fields []Field) zapcore.Core {
+	return &userAgentCore{Core: c.Core.With(c.enrich(fields)), cache: c.cache}
+}
+
+// lowered returns a copy of the core sharing the parsed user agents.
+func (c *userAgentCore) lowered() zapcore.Core {
+	return &userAgentCore{Core: lowerCore(c.Core), cache: c.cache}
+}
+
+// Check adds the core to the checked entry when the level is enabled.
+func (c *userAgentCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry with the user agent of the fields parsed.
+func (c *userAgentCore) Write(ent zapcore.Entry, fields []Field) error {
+	return c.Core.Write(ent, c.enrich(fields))
+}
+
+// enrich returns the fields followed by the parsed user agent when they contain a
+// non-empty user_agent string. The input slice is never modified.
+func (c *userAgentCore) enrich(fields []Field) []Field {
+	for _, f := range fields {
+		if f.Key != FieldKeyUserAgent || f.Type != zapcore.StringType || f.String == "" {
+			continue
+		}
+		parsed, ok := c.cache.Get(f.String)
+		if !ok {
+			parsed = parseUserAgent(f.String)
+			c.cache.Set(f.String, parsed)
+		}
+		out := make([]Field, 0, len(fields)+len(parsed))
+		out = append(out, fields...)
+		return append(out, parsed...)
+	}
+	return fields
+}
+
+// parseUserAgent parses a User-Agent header into structured fields.
+func parseUserAgent(header string) []Field {
+	ua := useragent.New(header)
+	browser, browserVersion := ua.Browser()
+	os := ua.OSInfo()
+
+	device := DeviceDesktop
+	switch {
+	case ua.Bot():
+		device = DeviceBot
+	case ua.Mobile():
+		device = DeviceMobile
+	}
+
+	return []Field{
+		zap.String(FieldKeyUABrowser, browser),
+		zap.String(FieldKeyUABrowserVersion, browserVersion),
+		zap.String(FieldKeyUAOS, os.Name),
+		zap.String(FieldKeyUAOSVersion, os.Version),
+		zap.String(FieldKeyUADevice, device),
+	}
+}
@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+)
+
+// maxContextValueLength bounds the length of free-form context values such as user IDs.
+const maxContextValueLength = 256
+
+// Values are the correlation values set on a context by NewContext.
+// Empty fields are left unset.
+type Values struct {
+	// RequestID is a UUID or 32 hex characters, the format generated by HTTPMiddleware.
+	RequestID string
+	// TraceID is a W3C trace ID: 32 hex characters, not all zero.
+	TraceID string
+	// SpanID is a W3C span ID: 16 hex characters, not all zero.
+	SpanID string
+	// UserID is a printable string of at most 256 bytes.
+	UserID string
+	// IPAddress is an IPv4 or IPv6 address.
+	IPAddress string
+}
+
+// NewContext validates the values and returns a copy of the context carrying all of
+// them, so services wire their context in one call instead of a chain of
+// context.WithValue calls with raw keys. When any value is malformed, the context is
+// returned unchanged together with an error naming every malformed value, so typos
+// are caught where the context is built rather than found missing in the logs.
+//
+// Parameters:
+//   - ctx: The parent context
+//   - values: The values to set; empty fields are skipped
+//
+// Returns:
+//   - context.Context: The context carrying the values, or ctx on error
+//   - error: An error describing the malformed values
+//
+// Example:
+//
+//	ctx, err := logger.NewContext(ctx, logger.Values{
+//	    RequestID: msg.RequestID,
+//	    TraceID:   msg.TraceID,
+//	    UserID:    msg.UserID,
+//	})
+//	if err != nil {
+//	    log.Warn(ctx, "malformed message context", zap.Error(err))
+//	}
+func NewContext(ctx context.Context, values Values) (context.Context, error) {
+	if err := values.validate(); err != nil {
+		return ctx, err
+	}
+
+	if values.RequestID != "" {
+		ctx = WithRequestID(ctx, values.RequestID)
+	}
+	if values.TraceID != "" {
+		ctx = WithTraceID(ctx, strings.ToLower(values.TraceID))
+	}
+	if values.SpanID != "" {
+		ctx = WithSpanID(ctx, strings.ToLower(values.SpanID))
+	}
+	if values.UserID != "" {
+		ctx = WithUserID(ctx, values.UserID)
+	}
+	if values.IPAddress != "" {
+		ctx = context.WithValue(ctx, ContextKeyIpAddress, values.IPAddress)
+	}
+	return ctx, nil
+}
+
+// validate returns the joined errors of the malformed values.
+func (v Values) validate() error {
+	var errs []error
+	if v.RequestID != "" && !isUUID(v.RequestID) && !isHex(v.RequestID, 32) {
+		errs = append(errs, fmt.Errorf("invalid %s %q: must be a UUID or 32 hex characters", ContextKeyRequestID, v.RequestID))
+	}
+	if v.TraceID != "" && (!isHex(v.TraceID, 32) || strings.Trim(v.TraceID, "0") == "") {
+		errs = append(errs, fmt.Errorf("invalid %s %q: must be 32 hex characters, not all zero", ContextKeyTraceID, v.TraceID))
+	}
+	if v.SpanID != "" && (!isHex(v.SpanID, 16) || strings.Trim(v.SpanID, "0") == "") {
+		errs = append(errs, fmt.Errorf("invalid %s %q: must be 16 hex characters, not all zero", ContextKeySpanID, v.SpanID))
+	}
+	if v.SpanID != "" && v.TraceID == "" {
+		errs = append(errs, fmt.Errorf("invalid %s: set without %s", ContextKeySpanID, ContextKeyTraceID))
+	}
+	if v.UserID != "" && !isPrintable(v.UserID, maxContextValueLength) {
+		errs = append(errs, fmt.Errorf("invalid %s %q: must be printable and at most %d bytes", ContextKeyUserID, v.UserID, maxContextValueLength))
+	}
+	if v.IPAddress != "" && net.ParseIP(v.IPAddress) == nil {
+		errs = append(errs, fmt.Errorf("invalid %s %q: must be an IPv4 or IPv6 address", ContextKeyIpAddress, v.IPAddress))
+	}
+	return errors.Join(errs...)
+}
+
+// isUUID reports whether s is a UUID in the canonical 8-4-4-4-12 hex form.
+func isUUID(s string) bool {
+	parts := strings.Split(s, "-")
+	if len(parts) != 5 {
+		return false
+	}
+	for i, n := range []int{8, 4, 4, 4, 12} {
+		if !isHex(parts[i], n) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPrintable reports whether s is non-blank, at most max bytes long and free of
+// control characters.
+func isPrintable(s string, max int) bool {
+	if len(s) > max || strings.TrimSpace(s) == "" {
+		return false
+	}
+	return strings.IndexFunc(s, unicode.IsControl) < 0
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// FieldKeyLineTruncated marks entries of a NamedWriter holding a part of a line longer
+// than maxPendingLine.
+const FieldKeyLineTruncated = "line_truncated"
+
+// maxPendingLine bounds the bytes a NamedWriter buffers while waiting for a newline.
+const maxPendingLine = 64 << 10
+
+// componentWriterCallerSkip is the number of frames of componentWriter between the
+// code writing a line and the Logger call: Write, writeLine and logAt.
+const componentWriterCallerSkip = 3
+
+// componentWriter turns the lines written by a library into log entries tagged with its name.
+type componentWriter struct {
+	log   Logger
+	level Level
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// NamedWriter returns an io.Writer logging every written line as an entry tagged with a component name.
+// Use it for libraries that only accept an io.Writer, such as badger or embedded NATS.
+// Each component gets its own level, so a chatty library can be routed to DebugLevel
+// while another one logs at WarnLevel. Partial lines are buffered until their newline
+// arrives; a line longer than 64 KiB is logged in parts of 64 KiB with line_truncated
+// set, so a writer never sending a newline cannot grow the buffer without bound. The
+// writer is safe for concurrent use.
+//
+// Parameters:
+//   - log: The logger receiving the entries
+//   - name: The value of the component field
+//   - level: The level of the entries
+//
+// Returns:
+//   - io.Writer: The writer
+//
+// Example:
+//
+//	stdlog := log.New(NamedWriter(appLogger, "badger", LevelDebug), "", 0)
+//	natsCmd.Stderr = NamedWriter(appLogger, "nats", LevelWarning)
+func NamedWriter(log Logger, name string, level Level) io.Writer {
+	return &componentWriter{log: AddCallerSkip(log.With(zap.String(FieldKeyComponent, name)), componentWriterCallerSkip), level: level}
+}
+
+// Write logs every complete line in p and buffers the remainder.
+func (w *componentWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(w.buf[:i], false)
+		w.buf = w.buf[i+1:]
+	}
+	for len(w.buf) > maxPendingLine {
+		w.writeLine(w.buf[:maxPendingLine], true)
+		w.buf = w.buf[maxPendingLine:]
+	}
+	if len(w.buf) == 0 {
+		w.buf = nil
+	} else if cap(w.buf) > 2*maxPendingLine {
+		// Release the array of a large write once only its remainder is pending.
+		w.buf = bytes.Clone(w.buf)
+	}
+	return len(p), nil
+}
+
+// writeLine logs a single line, or a part of a line too long to buffer, skipping
+// empty lines.
+func (w *componentWriter) writeLine(line []byte, truncated bool) {
+	if truncated {
+		logAt(context.Background(), w.log, w.level, string(line), zap.Bool(FieldKeyLineTruncated, true))
+		return
+	}
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+	logAt(context.Background(), w.log, w.level, string(line))
+}
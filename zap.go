@@ -2,57 +2,154 @@ package logger
 
 import (
 	"context"
+	"slices"
+	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-// zapLogger is the concrete implementation of the Logger interface using zap.
-// It wraps a zap.Logger instance and provides the standardized logging methods.
-// This implementation is optimized for performance and provides structured logging capabilities.
-type zapLogger struct {
-	zapLogger *zap.Logger
+type (
+	// zapLogger is the concrete implementation of the Logger interface using zap.
+	// It wraps a zap.Logger instance and provides the standardized logging methods.
+	// This implementation is optimized for performance and provides structured logging capabilities.
+	zapLogger struct {
+		zapLogger *zap.Logger
+		state     *loggerState
+		// fields are the fields added with With, kept for loggers derived by WithOptions.
+		fields []Field
+	}
+
+	// loggerState holds the state shared by a logger and every child created from it.
+	loggerState struct {
+		// cfg is the configuration the logger was built from.
+		cfg *config
+		// zapConfig is the zap configuration derived from cfg. Its Level is shared with the core.
+		zapConfig zap.Config
+		// sampler holds the sampling settings, which can be changed at runtime.
+		sampler *sampler
+		// diag reports internal failures and counts them for GetStats.
+		diag *diagnostics
+		// storms suppresses storms of identical error entries; nil when disabled.
+		storms *stormTracker
+		// budgets track the ingest budgets of the output sinks.
+		budgets []*budgetTracker
+		// ladders are the degradation ladders of the output sinks with fallbacks.
+		ladders []*sinkLadder
+		// sites rate limits entries per call site; nil when disabled.
+		sites *siteLimiter
+		// sizes counts the sizes of encoded entries; nil when disabled.
+		sizes *entrySizes
+		// shutdown runs the shutdown hooks and closes the sinks.
+		shutdown *shutdown
+		// encoder is the encoder of the logger, cloned by cores writing to additional sinks.
+		encoder zapcore.Encoder
+		// recent remembers when a rate limited key was last logged by Once and Every.
+		recent *lruCache[string, time.Time]
+		// residency counts the entries rejected by residency routing; nil when disabled.
+		residency *residencyState
+		// redacted holds the keys of redacted fields, which can be changed at runtime.
+		redacted *redactedFields
+		// overrides holds the levels per logger name and the debug tenants set by WatchFlags.
+		overrides *levelOverrides
+		// capture records entries for CaptureWindow.
+		capture *captureState
+		// dualWrite copies entries to the secondary logger of a migration; nil when disabled.
+		dualWrite *dualWrite
+		// correlation reports entries without correlation fields; nil when disabled.
+		correlation *correlationChecker
+		// crash keeps the last entries for the crash report; nil when disabled.
+		crash *crashReport
+		// supervisor restarts the background goroutines of the logger when they panic.
+		supervisor *supervisor
+		// bodyRedactions describes the body redactors of the HTTPMiddlewares capturing
+		// bodies with the logger, for LogConfig.
+		bodyRedactions bodyRedactions
+	}
+)
+
+// newLoggerState creates the shared state for a logger built from cfg.
+func newLoggerState(cfg *config, zapConfig zap.Config, encoder zapcore.Encoder, sampler *sampler, diag *diagnostics, storms *stormTracker, budgets []*budgetTracker, ladders []*sinkLadder, sites *siteLimiter, sizes *entrySizes, residency *residencyState, redacted *redactedFields, overrides *levelOverrides, capture *captureState, dualWrite *dualWrite, correlation *correlationChecker, crash *crashReport, supervisor *supervisor, shutdown *shutdown) *loggerState {
+	return &loggerState{
+		cfg:         cfg,
+		zapConfig:   zapConfig,
+		sampler:     sampler,
+		diag:        diag,
+		storms:      storms,
+		budgets:     budgets,
+		ladders:     ladders,
+		sites:       sites,
+		sizes:       sizes,
+		shutdown:    shutdown,
+		encoder:     encoder,
+		recent:      newLRUCache[string, time.Time](cfg.OnceCacheSize),
+		residency:   residency,
+		redacted:    redacted,
+		overrides:   overrides,
+		capture:     capture,
+		dualWrite:   dualWrite,
+		correlation: correlation,
+		crash:       crash,
+		supervisor:  supervisor,
+	}
 }
 
 // Info logs a message at InfoLevel using the underlying zap logger.
 // This method is optimized for performance and supports structured logging.
 // Fields are added as key-value pairs to the log entry for better searchability.
 func (z *zapLogger) Info(ctx context.Context, msg string, fields ...Field) {
-	z.zapLogger.With(z.extractTrace(ctx)...).Info(msg, fields...)
+	z.withContext(z.zapLogger, ctx).Info(msg, fields...)
 }
 
 // Warn logs a message at WarnLevel using the underlying zap logger.
 // Use this for potentially harmful situations that are not errors.
 // The message and fields are structured for easy parsing and analysis.
 func (z *zapLogger) Warn(ctx context.Context, msg string, fields ...Field) {
-	z.zapLogger.With(z.extractTrace(ctx)...).Warn(msg, fields...)
+	z.withContext(z.zapLogger, ctx).Warn(msg, fields...)
+	if z.state.cfg.SpanEvents && z.zapLogger.Core().Enabled(zapcore.WarnLevel) {
+		mirrorToSpan(ctx, zapcore.WarnLevel, msg, fields)
+	}
 }
 
 // Error logs a message at ErrorLevel using the underlying zap logger.
 // This method should be used for error conditions that don't require immediate termination.
 // Structured fields help with error tracking and debugging.
 func (z *zapLogger) Error(ctx context.Context, msg string, fields ...Field) {
-	z.zapLogger.With(z.extractTrace(ctx)...).Error(msg, fields...)
+	z.withContext(z.zapLogger, ctx).Error(msg, fields...)
+	if z.state.cfg.SpanEvents && z.zapLogger.Core().Enabled(zapcore.ErrorLevel) {
+		mirrorToSpan(ctx, zapcore.ErrorLevel, msg, fields)
+	}
 }
 
 // Debug logs a message at DebugLevel using the underlying zap logger.
 // Debug messages are typically disabled in production for performance.
 // Use this for detailed diagnostic information during development.
 func (z *zapLogger) Debug(ctx context.Context, msg string, fields ...Field) {
-	z.zapLogger.With(z.extractTrace(ctx)...).Debug(msg, fields...)
+	log := z.zapLogger
+	if !log.Core().Enabled(zapcore.DebugLevel) {
+		// Disabled entries return before the fields of the context are collected.
+		if !debugForced(ctx) && !(z.state.cfg.DebugWhenSampled && TraceSampled(ctx)) {
+			return
+		}
+		log = log.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return newLevelCore(core, zapcore.DebugLevel)
+		}))
+	}
+	z.withContext(log, ctx).Debug(msg, fields...)
 }
 
 // Panic logs a message at PanicLevel using the underlying zap logger, then panics.
 // This method should only be used for severe errors that require immediate attention.
 // The application will terminate after logging the message.
 func (z *zapLogger) Panic(ctx context.Context, msg string, fields ...Field) {
-	z.zapLogger.With(z.extractTrace(ctx)...).Panic(msg, fields...)
+	z.withContext(z.zapLogger, ctx).Panic(msg, fields...)
 }
 
 // Fatal logs a message at FatalLevel using the underlying zap logger, then calls os.Exit(1).
 // This method should be used for critical errors that require application termination.
 // The application will exit immediately after logging the message.
 func (z *zapLogger) Fatal(ctx context.Context, msg string, fields ...Field) {
-	z.zapLogger.With(z.extractTrace(ctx)...).Fatal(msg, fields...)
+	z.withContext(z.zapLogger, ctx).Fatal(msg, fields...)
 }
 
 // With creates a new logger instance with additional structured fields.
@@ -63,25 +160,114 @@ func (z *zapLogger) Fatal(ctx context.Context, msg string, fields ...Field) {
 //   requestLogger := logger.With(zap.String("requestID", "12345"))
 //   requestLogger.Info("Processing request") // Will include requestID field
 func (z *zapLogger) With(fields ...Field) Logger {
-	return &zapLogger{zapLogger: z.zapLogger.With(fields...)}
+	return &zapLogger{zapLogger: z.zapLogger.With(fields...), state: z.state, fields: slices.Concat(z.fields, fields)}
+}
+
+// WithOptions derives a logger from the configuration of this logger, reconfigured by
+// the options, and adds the fields added with With. Unlike With, the derived logger is
+// built anew: it has its own statistics and shutdown, while by default it starts from
+// the parent's level, sinks, redacted fields and sampling and shares them and their
+// runtime changes, see WithoutInheriting. Derive loggers once, e.g. per component, rather than
+// per request.
+//
+// Example:
+//   dbLogger, err := logger.With(zap.String("component", "db")).WithOptions(WithLevel(LevelDebug))
+//   if err != nil {
+//       return err
+//   }
+//   dbLogger.Debug(ctx, "query planned") // Written with component even though the parent logs at info
+func (z *zapLogger) WithOptions(opts ...Option) (Logger, error) {
+	cfg, inherited := z.state.derive(opts)
+	derived, err := newLogger(cfg, inherited)
+	if err != nil {
+		return nil, err
+	}
+	if len(z.fields) > 0 {
+		derived = derived.With(z.fields...)
+	}
+	return derived, nil
+}
+
+// Once logs a message at InfoLevel only the first time the key is seen.
+// Keys are tracked in a bounded LRU cache shared with child loggers, so a key
+// evicted by a large number of newer keys may be logged again.
+//
+// Example:
+//   for _, item := range items {
+//       logger.Once(ctx, "legacy-format", "Legacy item format detected")
+//   }
+func (z *zapLogger) Once(ctx context.Context, key string, msg string, fields ...Field) {
+	first := false
+	z.state.recent.Update("once:"+key, func(_ time.Time, exists bool) (time.Time, bool) {
+		first = !exists
+		return time.Now(), true
+	})
+
+	if first {
+		z.withContext(z.zapLogger, ctx).Info(msg, fields...)
+	}
+}
+
+// Every logs a message at InfoLevel at most once per interval for the key.
+// Calls within the interval are dropped. Keys share the bounded LRU cache used by Once.
+//
+// Example:
+//   for msg := range queue {
+//       logger.Every(ctx, "queue-backlog", 10*time.Second, "Queue backlog", zap.Int("size", len(queue)))
+//   }
+func (z *zapLogger) Every(ctx context.Context, key string, interval time.Duration, msg string, fields ...Field) {
+	var (
+		now     = time.Now()
+		allowed = false
+	)
+	z.state.recent.Update("every:"+key, func(last time.Time, exists bool) (time.Time, bool) {
+		if !exists || now.Sub(last) >= interval {
+			allowed = true
+			return now, true
+		}
+		return last, true
+	})
+
+	if allowed {
+		z.withContext(z.zapLogger, ctx).Info(msg, fields...)
+	}
 }
 
 // GetLogger returns the underlying zap.Logger instance.
 // This method provides access to advanced zap features not exposed by the Logger interface.
 // Use with caution as it bypasses the standardized logging interface.
+// The returned logger reports the caller of its own methods.
 //
 // Example:
 //   zapLogger := logger.GetLogger()
 //   zapLogger.Sugar().Infof("Formatted message: %s", value)
 func (z *zapLogger) GetLogger() *zap.Logger {
-	return z.zapLogger
+	return z.zapLogger.WithOptions(zap.AddCallerSkip(-callerSkip))
 }
-func (z *zapLogger) extractTrace(ctx context.Context) []Field {
-	var fields []Field
-	ctxFields := GetLoggingFields(ctx)
-	for _, field := range ctxFields {
-		fields = append(fields, zap.String(field.Key.String(), field.Value))
+// withContext returns log with the fields of the context added. The fields are built
+// in a slice of the field pool, which is returned once log.With has encoded or copied
+// them.
+func (z *zapLogger) withContext(log *zap.Logger, ctx context.Context) *zap.Logger {
+	fields := fieldPool.get()
+	*fields = z.extractTrace(*fields, ctx)
+	// With clones every core even without fields.
+	if len(*fields) > 0 {
+		log = log.With(*fields...)
 	}
+	fieldPool.put(fields)
+	return log
+}
 
-	return fields
+// extractTrace appends the fields of the context to fields.
+func (z *zapLogger) extractTrace(fields []Field, ctx context.Context) []Field {
+	fields = appendContextKeyFields(fields, ctx)
+	fields = appendContextStructFields(fields, ctx)
+	fields = appendDimensionFields(fields, ctx)
+	fields = appendBaggageFields(fields, ctx, z.state.cfg.BaggageKeys)
+	fields = appendBagFields(fields, ctx)
+	fields = appendEnricherFields(fields, ctx, z.state.cfg.Enrichers)
+	if z.state.cfg.ContextDeadline {
+		fields = appendDeadlineFields(fields, ctx)
+	}
+	return appendGoroutineFields(fields, ctx, z.state.cfg.GoroutineID)
 }